@@ -43,14 +43,23 @@ import (
 	"gopkg.in/yaml.v2"
 
 	"github.com/optimizely/agent/config"
+	"github.com/optimizely/agent/pkg/grpcapi"
 	"github.com/optimizely/agent/pkg/handlers"
 	"github.com/optimizely/agent/pkg/metrics"
+	"github.com/optimizely/agent/pkg/middleware"
 	"github.com/optimizely/agent/pkg/optimizely"
 	"github.com/optimizely/agent/pkg/routers"
 	"github.com/optimizely/agent/pkg/server"
+	"github.com/optimizely/agent/pkg/utils/redisclient"
 	_ "github.com/optimizely/agent/plugins/cmabcache/all"          // Initiate the loading of the cmabCache plugins
+	_ "github.com/optimizely/agent/plugins/datafilestore/all"      // Initiate the loading of the datafileStore plugins
+	_ "github.com/optimizely/agent/plugins/deadletter/all"         // Initiate the loading of the deadLetter plugins
+	_ "github.com/optimizely/agent/plugins/decisionlog/all"        // Initiate the loading of the decisionLog plugins
+	_ "github.com/optimizely/agent/plugins/eventforwarder/all"     // Initiate the loading of the eventForwarder plugins
+	_ "github.com/optimizely/agent/plugins/eventqueue/all"         // Initiate the loading of the eventQueue plugins
 	_ "github.com/optimizely/agent/plugins/interceptors/all"       // Initiate the loading of the userprofileservice plugins
 	_ "github.com/optimizely/agent/plugins/odpcache/all"           // Initiate the loading of the odpCache plugins
+	_ "github.com/optimizely/agent/plugins/ratelimiter/all"        // Initiate the loading of the rateLimiter plugins
 	_ "github.com/optimizely/agent/plugins/userprofileservice/all" // Initiate the loading of the interceptor plugins
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
 )
@@ -99,11 +108,46 @@ func loadConfig(v *viper.Viper) *config.AgentConfig {
 		conf.Server.Interceptors = interceptors
 	}
 
+	// Check if JSON string was set using OPTIMIZELY_API_RATELIMIT_STORE environment variable
+	if rateLimitStore := v.GetStringMap("api.rateLimit.store"); len(rateLimitStore) > 0 {
+		conf.API.RateLimit.Store = rateLimitStore
+	}
+
+	// Check if JSON string was set using OPTIMIZELY_REDIS environment variable
+	if redisConf := v.GetStringMap("redis"); len(redisConf) > 0 {
+		conf.Redis = redisConf
+	}
+
 	// Check if JSON string was set using OPTIMIZELY_CLIENT_USERPROFILESERVICE environment variable
 	if userProfileService := v.GetStringMap("client.userprofileservice"); len(userProfileService) > 0 {
 		conf.Client.UserProfileService = userProfileService
 	}
 
+	// Check if JSON string was set using OPTIMIZELY_CLIENT_DATAFILESTORE environment variable
+	if datafileStore := v.GetStringMap("client.datafileStore"); len(datafileStore) > 0 {
+		conf.Client.DatafileStore = datafileStore
+	}
+
+	// Check if JSON string was set using OPTIMIZELY_CLIENT_EVENTQUEUE environment variable
+	if eventQueue := v.GetStringMap("client.eventQueue"); len(eventQueue) > 0 {
+		conf.Client.EventQueue = eventQueue
+	}
+
+	// Check if JSON string was set using OPTIMIZELY_CLIENT_DEADLETTER_STORE environment variable
+	if deadLetterStore := v.GetStringMap("client.deadLetter.store"); len(deadLetterStore) > 0 {
+		conf.Client.DeadLetter.Store = deadLetterStore
+	}
+
+	// Check if JSON string was set using OPTIMIZELY_CLIENT_EVENTFORWARDING_DESTINATIONS environment variable
+	if destinations := v.GetStringMap("client.eventForwarding.destinations"); len(destinations) > 0 {
+		conf.Client.EventForwarding.Destinations = destinations
+	}
+
+	// Check if JSON string was set using OPTIMIZELY_CLIENT_DECISIONLOG_SINK environment variable
+	if decisionLogSink := v.GetStringMap("client.decisionLog.sink"); len(decisionLogSink) > 0 {
+		conf.Client.DecisionLog.Sink = decisionLogSink
+	}
+
 	// Check if JSON string was set using OPTIMIZELY_CLIENT_ODP_SEGMENTSCACHE environment variable
 	if odpSegmentsCache := v.GetStringMap("client.odp.segmentsCache"); len(odpSegmentsCache) > 0 {
 		conf.Client.ODP.SegmentsCache = odpSegmentsCache
@@ -316,6 +360,10 @@ func main() {
 
 	setRuntimeEnvironment(conf.Runtime)
 
+	if err := redisclient.SetDefaults(conf.Redis); err != nil {
+		log.Panic().Err(err).Msg("Unable to initialize the shared redis configuration")
+	}
+
 	// Set metrics type to be used
 	agentMetricsRegistry := metrics.NewRegistry(conf.Admin.MetricsType)
 	sdkMetricsRegistry := optimizely.NewRegistry(agentMetricsRegistry)
@@ -332,6 +380,11 @@ func main() {
 	optlyCache := optimizely.NewCache(ctx, *conf, sdkMetricsRegistry, tracer)
 	optlyCache.Init(conf.SDKKeys)
 
+	notificationWebhooks := handlers.NewNotificationWebhookDispatcher(conf.API.NotificationWebhooks, handlers.NotificationReceiver(conf.Synchronization), agentMetricsRegistry)
+	if err := notificationWebhooks.Start(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed starting notification webhooks")
+	}
+
 	// goroutine to check for signals to gracefully shutdown listeners
 	go func() {
 		signalChannel := make(chan os.Signal, 1)
@@ -343,12 +396,29 @@ func main() {
 		cancel()
 	}()
 
-	apiRouter := routers.NewDefaultAPIRouter(optlyCache, *conf, agentMetricsRegistry)
-	adminRouter := routers.NewAdminRouter(*conf)
+	// The REST and gRPC APIs share the SDK key filter so that they always accept the same SDK keys
+	sdkKeys, err := middleware.NewSDKKeyFilter(conf.Client.SdkKeyFilter, agentMetricsRegistry.GetCounter(middleware.SDKKeyRejectedMetric))
+	if err != nil {
+		log.Panic().Err(err).Msg("Unable to initialize the SDK key filter")
+	}
+	defer sdkKeys.Close()
+	// The REST and gRPC APIs share the rate limiter so that their requests take from the same token buckets
+	rateLimiter, err := middleware.NewRateLimiter(conf.API.RateLimit, agentMetricsRegistry.GetCounter(middleware.RateLimitRejectedMetric))
+	if err != nil {
+		log.Panic().Err(err).Msg("Unable to initialize the api rate limiter")
+	}
+
+	apiRouter := routers.NewDefaultAPIRouter(optlyCache, *conf, agentMetricsRegistry, sdkKeys, rateLimiter)
+	adminRouter := routers.NewAdminRouter(*conf, optlyCache)
 
 	log.Info().Str("version", conf.Version).Msg("Starting services.")
 	sg.GoListenAndServe("api", conf.API.Port, apiRouter)
 	sg.GoListenAndServe("webhook", conf.Webhook.Port, routers.NewWebhookRouter(ctx, optlyCache, *conf))
+	if grpcServer, err := grpcapi.NewServer(ctx, optlyCache, *conf, agentMetricsRegistry, sdkKeys, rateLimiter); err != nil {
+		log.Error().Err(err).Msg("Failed creating grpc server")
+	} else {
+		sg.GoServe("grpc", conf.GRPC.Port, grpcServer)
+	}
 	sg.GoListenAndServe("admin", conf.Admin.Port, adminRouter) // Admin should be added last.
 
 	// wait for server group to shutdown
@@ -359,4 +429,5 @@ func main() {
 	}
 
 	optlyCache.Wait()
+	notificationWebhooks.Wait()
 }
@@ -66,6 +66,12 @@ func assertClient(t *testing.T, actual config.ClientConfig) {
 	assert.Equal(t, "https://localhost/v1/%s.json", actual.DatafileURLTemplate)
 	assert.Equal(t, "https://logx.localhost.com/v1", actual.EventURL)
 	assert.Equal(t, "custom-regex", actual.SdkKeyRegex)
+	assert.Equal(t, []string{"allowed1", "allowed2"}, actual.SdkKeyFilter.Allow)
+	assert.Equal(t, "/etc/agent/allow.txt", actual.SdkKeyFilter.AllowFile)
+	assert.Equal(t, []string{"denied"}, actual.SdkKeyFilter.Deny)
+	assert.Equal(t, "/etc/agent/deny.txt", actual.SdkKeyFilter.DenyFile)
+	assert.Equal(t, 50, actual.MaxClients)
+	assert.Equal(t, 10*time.Minute, actual.IdleTimeout)
 	assert.True(t, actual.ODP.Disable)
 	assert.Equal(t, 5*time.Second, actual.ODP.EventsFlushInterval)
 	assert.Equal(t, 5*time.Second, actual.ODP.EventsRequestTimeout)
@@ -94,6 +100,59 @@ func assertClient(t *testing.T, actual config.ClientConfig) {
 	}
 	assert.Equal(t, userProfileServices, actual.UserProfileService["services"])
 
+	assert.Equal(t, "file", actual.DatafileStore["default"])
+	datafileStoreServices := map[string]interface{}{
+		"file": map[string]interface{}{
+			"dir": "/tmp/datafiles",
+		},
+	}
+	assert.Equal(t, datafileStoreServices, actual.DatafileStore["services"])
+
+	assert.Equal(t, "file", actual.EventQueue["default"])
+	eventQueueServices := map[string]interface{}{
+		"file": map[string]interface{}{
+			"dir": "/tmp/events",
+		},
+	}
+	assert.Equal(t, eventQueueServices, actual.EventQueue["services"])
+
+	assert.Equal(t, 5, actual.DeadLetter.MaxAttempts)
+	assert.Equal(t, 2*time.Second, actual.DeadLetter.RetryInterval)
+	assert.Equal(t, "file", actual.DeadLetter.Store["default"])
+	deadLetterStoreServices := map[string]interface{}{
+		"file": map[string]interface{}{
+			"dir": "/tmp/dead-letters",
+		},
+	}
+	assert.Equal(t, deadLetterStoreServices, actual.DeadLetter.Store["services"])
+
+	assert.Equal(t, 500, actual.EventForwarding.QueueSize)
+	assert.Equal(t, 5, actual.EventForwarding.MaxRetries)
+	assert.Equal(t, 2*time.Second, actual.EventForwarding.RetryInterval)
+	destinations := config.EventForwarderConfigs{
+		"archive": map[string]interface{}{
+			"type": "file",
+			"path": "/tmp/forwarded-events.ndjson",
+		},
+	}
+	assert.Equal(t, destinations, actual.EventForwarding.Destinations)
+
+	assert.True(t, actual.Offline.Enable)
+	assert.Equal(t, "/tmp/offline", actual.Offline.DatafileDir)
+	assert.Equal(t, "/tmp/events.ndjson", actual.Offline.EventsFile)
+
+	assert.Equal(t, 500, actual.DecisionLog.BufferSize)
+	assert.Equal(t, 50, actual.DecisionLog.BatchSize)
+	assert.Equal(t, 5*time.Second, actual.DecisionLog.FlushInterval)
+	assert.Equal(t, "hash-key", actual.DecisionLog.AttributeHashKey)
+	assert.Equal(t, "file", actual.DecisionLog.Sink["default"])
+	decisionLogSinkServices := map[string]interface{}{
+		"file": map[string]interface{}{
+			"path": "/tmp/decisions.ndjson",
+		},
+	}
+	assert.Equal(t, decisionLogSinkServices, actual.DecisionLog.Sink["services"])
+
 	assert.Equal(t, "in-memory", actual.ODP.SegmentsCache["default"])
 	odpCacheServices := map[string]interface{}{
 		"custom": map[string]interface{}{
@@ -115,6 +174,21 @@ func assertClient(t *testing.T, actual config.ClientConfig) {
 	assert.EqualValues(t, "5s", actualInMemoryService["timeout"])
 }
 
+func assertClientEventPipelines(t *testing.T, actual []config.EventPipelineConfig) {
+	expected := []config.EventPipelineConfig{
+		{
+			SDKKeys: []string{"sdkKey1"},
+			Steps: []config.EventPipelineStep{
+				{Type: "deny", Keys: []string{"email"}},
+				{Type: "redact", Target: "tags", Pattern: "[0-9]{16}", Replacement: "[card]"},
+				{Type: "hash", Keys: []string{"userName"}, HashKey: "hash-key"},
+				{Type: "inject", Target: "attributes", Values: []config.EventPipelineValue{{Key: "region", Value: "us-east-1"}}},
+			},
+		},
+	}
+	assert.Equal(t, expected, actual)
+}
+
 func assertLog(t *testing.T, actual config.LogConfig) {
 	assert.True(t, actual.Pretty)
 	assert.False(t, actual.IncludeSDKKey)
@@ -142,9 +216,47 @@ func assertAdminAuth(t *testing.T, actual config.ServiceAuthConfig) {
 
 func assertAPI(t *testing.T, actual config.APIConfig) {
 	assert.Equal(t, 100, actual.MaxConns)
+	assert.Equal(t, 2.5, actual.RateLimit.Rate)
+	assert.Equal(t, 10, actual.RateLimit.Burst)
+	assert.Equal(t, "clientID", actual.RateLimit.Key)
+	assert.Equal(t, "redis", actual.RateLimit.Store["default"])
+	rateLimitStoreServices := map[string]interface{}{
+		"redis": map[string]interface{}{
+			"host":   "localhost:6379",
+			"prefix": "agent-ratelimit-",
+		},
+	}
+	assert.Equal(t, rateLimitStoreServices, actual.RateLimit.Store["services"])
 	assert.Equal(t, "3000", actual.Port)
 	assert.Equal(t, true, actual.EnableNotifications)
 	assert.Equal(t, true, actual.EnableOverrides)
+	assert.Equal(t, true, actual.EnableProfileManagement)
+	assertAPINotificationWebhooks(t, actual.NotificationWebhooks)
+	assert.Equal(t, 4, actual.DecideBulk.MaxConcurrency)
+}
+
+func assertAPINotificationWebhooks(t *testing.T, actual config.NotificationWebhooksConfig) {
+	assert.Equal(t, 50, actual.BatchSize)
+	assert.Equal(t, 5*time.Second, actual.FlushInterval)
+	assert.Equal(t, 500, actual.QueueSize)
+	assert.Equal(t, 3*time.Second, actual.Timeout)
+	assert.Equal(t, 5, actual.MaxRetries)
+	assert.Equal(t, 2*time.Second, actual.InitialBackoff)
+	assert.Equal(t, 1*time.Minute, actual.MaxBackoff)
+	assert.Equal(t, "/tmp/notifications.ndjson", actual.DeadLetterFile)
+}
+
+func assertAPINotificationWebhookSubscriptions(t *testing.T, actual map[string]config.NotificationWebhookSubscription) {
+	expected := map[string]config.NotificationWebhookSubscription{
+		"decisions": {
+			SDKKey:  "sdkKey1",
+			URL:     "https://example.com/notifications",
+			Secret:  "secret1",
+			Filter:  []string{"decision", "track"},
+			Headers: map[string]string{"authorization": "Bearer token"},
+		},
+	}
+	assert.Equal(t, expected, actual)
 }
 
 func assertAPIAuth(t *testing.T, actual config.ServiceAuthConfig) {
@@ -340,12 +452,14 @@ func TestViperYaml(t *testing.T) {
 	assertRoot(t, actual)
 	assertServer(t, actual.Server, true)
 	assertClient(t, actual.Client)
+	assertClientEventPipelines(t, actual.Client.EventPipelines)
 	assertLog(t, actual.Log)
 	assertAdmin(t, actual.Admin)
 	assertAdminAuth(t, actual.Admin.Auth)
 	assertAPI(t, actual.API)
 	assertAPIAuth(t, actual.API.Auth)
 	assertAPICORS(t, actual.API.CORS)
+	assertAPINotificationWebhookSubscriptions(t, actual.API.NotificationWebhooks.Subscriptions)
 	assertWebhook(t, actual.Webhook)
 	assertRuntime(t, actual.Runtime)
 }
@@ -376,6 +490,12 @@ func TestViperProps(t *testing.T) {
 	v.Set("client.datafileURLTemplate", "https://localhost/v1/%s.json")
 	v.Set("client.eventURL", "https://logx.localhost.com/v1")
 	v.Set("client.sdkKeyRegex", "custom-regex")
+	v.Set("client.sdkKeyFilter.allow", []string{"allowed1", "allowed2"})
+	v.Set("client.sdkKeyFilter.allowFile", "/etc/agent/allow.txt")
+	v.Set("client.sdkKeyFilter.deny", []string{"denied"})
+	v.Set("client.sdkKeyFilter.denyFile", "/etc/agent/deny.txt")
+	v.Set("client.maxClients", 50)
+	v.Set("client.idleTimeout", 10*time.Minute)
 	upsServices := map[string]interface{}{
 		"in-memory": map[string]interface{}{
 			"storageStrategy": "fifo",
@@ -401,6 +521,71 @@ func TestViperProps(t *testing.T) {
 	}
 	v.Set("client.userProfileService", userProfileServices)
 
+	datafileStore := map[string]interface{}{
+		"default": "file",
+		"services": map[string]interface{}{
+			"file": map[string]interface{}{
+				"dir": "/tmp/datafiles",
+			},
+		},
+	}
+	v.Set("client.datafileStore", datafileStore)
+
+	eventQueue := map[string]interface{}{
+		"default": "file",
+		"services": map[string]interface{}{
+			"file": map[string]interface{}{
+				"dir": "/tmp/events",
+			},
+		},
+	}
+	v.Set("client.eventQueue", eventQueue)
+	v.Set("client.deadLetter.maxAttempts", 5)
+	v.Set("client.deadLetter.retryInterval", "2s")
+	v.Set("client.deadLetter.store", map[string]interface{}{
+		"default": "file",
+		"services": map[string]interface{}{
+			"file": map[string]interface{}{
+				"dir": "/tmp/dead-letters",
+			},
+		},
+	})
+	v.Set("client.eventForwarding.queueSize", 500)
+	v.Set("client.eventForwarding.maxRetries", 5)
+	v.Set("client.eventForwarding.retryInterval", "2s")
+	v.Set("client.eventForwarding.destinations", map[string]interface{}{
+		"archive": map[string]interface{}{
+			"type": "file",
+			"path": "/tmp/forwarded-events.ndjson",
+		},
+	})
+	v.Set("client.eventPipelines", []map[string]interface{}{
+		{
+			"sdkKeys": []string{"sdkKey1"},
+			"steps": []map[string]interface{}{
+				{"type": "deny", "keys": []string{"email"}},
+				{"type": "redact", "target": "tags", "pattern": "[0-9]{16}", "replacement": "[card]"},
+				{"type": "hash", "keys": []string{"userName"}, "hashKey": "hash-key"},
+				{"type": "inject", "target": "attributes", "values": []map[string]interface{}{{"key": "region", "value": "us-east-1"}}},
+			},
+		},
+	})
+	v.Set("client.offline.enable", true)
+	v.Set("client.offline.datafileDir", "/tmp/offline")
+	v.Set("client.offline.eventsFile", "/tmp/events.ndjson")
+	v.Set("client.decisionLog.bufferSize", 500)
+	v.Set("client.decisionLog.batchSize", 50)
+	v.Set("client.decisionLog.flushInterval", "5s")
+	v.Set("client.decisionLog.attributeHashKey", "hash-key")
+	v.Set("client.decisionLog.sink", map[string]interface{}{
+		"default": "file",
+		"services": map[string]interface{}{
+			"file": map[string]interface{}{
+				"path": "/tmp/decisions.ndjson",
+			},
+		},
+	})
+
 	odpCacheServices := map[string]interface{}{
 		"in-memory": map[string]interface{}{
 			"size":    100,
@@ -448,8 +633,39 @@ func TestViperProps(t *testing.T) {
 	})
 
 	v.Set("api.maxConns", 100)
+	v.Set("api.rateLimit.rate", 2.5)
+	v.Set("api.rateLimit.burst", 10)
+	v.Set("api.rateLimit.key", "clientID")
+	v.Set("api.rateLimit.store", map[string]interface{}{
+		"default": "redis",
+		"services": map[string]interface{}{
+			"redis": map[string]interface{}{
+				"host":   "localhost:6379",
+				"prefix": "agent-ratelimit-",
+			},
+		},
+	})
 	v.Set("api.enableNotifications", true)
 	v.Set("api.enableOverrides", true)
+	v.Set("api.enableProfileManagement", true)
+	v.Set("api.notificationWebhooks.batchSize", 50)
+	v.Set("api.notificationWebhooks.flushInterval", "5s")
+	v.Set("api.notificationWebhooks.queueSize", 500)
+	v.Set("api.notificationWebhooks.timeout", "3s")
+	v.Set("api.notificationWebhooks.maxRetries", 5)
+	v.Set("api.notificationWebhooks.initialBackoff", "2s")
+	v.Set("api.notificationWebhooks.maxBackoff", "1m")
+	v.Set("api.notificationWebhooks.deadLetterFile", "/tmp/notifications.ndjson")
+	v.Set("api.decideBulk.maxConcurrency", 4)
+	v.Set("api.notificationWebhooks.subscriptions", map[string]interface{}{
+		"decisions": map[string]interface{}{
+			"sdkKey":  "sdkKey1",
+			"url":     "https://example.com/notifications",
+			"secret":  "secret1",
+			"filter":  []string{"decision", "track"},
+			"headers": map[string]string{"authorization": "Bearer token"},
+		},
+	})
 	v.Set("api.port", "3000")
 	v.Set("api.auth.ttl", "30m")
 
@@ -482,11 +698,13 @@ func TestViperProps(t *testing.T) {
 	assertRoot(t, actual)
 	assertServer(t, actual.Server, true)
 	assertClient(t, actual.Client)
+	assertClientEventPipelines(t, actual.Client.EventPipelines)
 	assertLog(t, actual.Log)
 	assertAdmin(t, actual.Admin)
 	assertAdminAuth(t, actual.Admin.Auth)
 	assertAPI(t, actual.API)
 	assertAPIAuth(t, actual.API.Auth)
+	assertAPINotificationWebhookSubscriptions(t, actual.API.NotificationWebhooks.Subscriptions)
 	assertWebhook(t, actual.Webhook)
 	assertRuntime(t, actual.Runtime)
 }
@@ -514,8 +732,31 @@ func TestViperEnv(t *testing.T) {
 	_ = os.Setenv("OPTIMIZELY_CLIENT_DATAFILEURLTEMPLATE", "https://localhost/v1/%s.json")
 	_ = os.Setenv("OPTIMIZELY_CLIENT_EVENTURL", "https://logx.localhost.com/v1")
 	_ = os.Setenv("OPTIMIZELY_CLIENT_SDKKEYREGEX", "custom-regex")
+	_ = os.Setenv("OPTIMIZELY_CLIENT_SDKKEYFILTER_ALLOW", "allowed1,allowed2")
+	_ = os.Setenv("OPTIMIZELY_CLIENT_SDKKEYFILTER_ALLOWFILE", "/etc/agent/allow.txt")
+	_ = os.Setenv("OPTIMIZELY_CLIENT_SDKKEYFILTER_DENY", "denied")
+	_ = os.Setenv("OPTIMIZELY_CLIENT_SDKKEYFILTER_DENYFILE", "/etc/agent/deny.txt")
+	_ = os.Setenv("OPTIMIZELY_CLIENT_MAXCLIENTS", "50")
+	_ = os.Setenv("OPTIMIZELY_CLIENT_IDLETIMEOUT", "10m")
 
 	_ = os.Setenv("OPTIMIZELY_CLIENT_USERPROFILESERVICE", `{"default":"in-memory","services":{"in-memory":{"storagestrategy":"fifo"},"redis":{"host":"localhost:6379","password":""},"rest":{"host":"http://localhost","lookuppath":"/ups/lookup","savepath":"/ups/save","headers":{"content-type":"application/json"},"async":true},"custom":{"path":"http://test2.com"}}}`)
+	_ = os.Setenv("OPTIMIZELY_CLIENT_OFFLINE_ENABLE", "true")
+	_ = os.Setenv("OPTIMIZELY_CLIENT_OFFLINE_DATAFILEDIR", "/tmp/offline")
+	_ = os.Setenv("OPTIMIZELY_CLIENT_OFFLINE_EVENTSFILE", "/tmp/events.ndjson")
+	_ = os.Setenv("OPTIMIZELY_CLIENT_DECISIONLOG_BUFFERSIZE", "500")
+	_ = os.Setenv("OPTIMIZELY_CLIENT_DECISIONLOG_BATCHSIZE", "50")
+	_ = os.Setenv("OPTIMIZELY_CLIENT_DECISIONLOG_FLUSHINTERVAL", "5s")
+	_ = os.Setenv("OPTIMIZELY_CLIENT_DECISIONLOG_ATTRIBUTEHASHKEY", "hash-key")
+	_ = os.Setenv("OPTIMIZELY_CLIENT_DECISIONLOG_SINK", `{"default":"file","services":{"file":{"path":"/tmp/decisions.ndjson"}}}`)
+	_ = os.Setenv("OPTIMIZELY_CLIENT_DATAFILESTORE", `{"default":"file","services":{"file":{"dir":"/tmp/datafiles"}}}`)
+	_ = os.Setenv("OPTIMIZELY_CLIENT_EVENTQUEUE", `{"default":"file","services":{"file":{"dir":"/tmp/events"}}}`)
+	_ = os.Setenv("OPTIMIZELY_CLIENT_DEADLETTER_MAXATTEMPTS", "5")
+	_ = os.Setenv("OPTIMIZELY_CLIENT_DEADLETTER_RETRYINTERVAL", "2s")
+	_ = os.Setenv("OPTIMIZELY_CLIENT_DEADLETTER_STORE", `{"default":"file","services":{"file":{"dir":"/tmp/dead-letters"}}}`)
+	_ = os.Setenv("OPTIMIZELY_CLIENT_EVENTFORWARDING_QUEUESIZE", "500")
+	_ = os.Setenv("OPTIMIZELY_CLIENT_EVENTFORWARDING_MAXRETRIES", "5")
+	_ = os.Setenv("OPTIMIZELY_CLIENT_EVENTFORWARDING_RETRYINTERVAL", "2s")
+	_ = os.Setenv("OPTIMIZELY_CLIENT_EVENTFORWARDING_DESTINATIONS", `{"archive":{"type":"file","path":"/tmp/forwarded-events.ndjson"}}`)
 	_ = os.Setenv("OPTIMIZELY_CLIENT_ODP_SEGMENTSCACHE", `{"default":"in-memory","services":{"in-memory":{"size":100,"timeout":"5s"},"redis":{"host":"localhost:6379","password":"","timeout":"5s","database": "123"},"custom":{"path":"http://test2.com"}}}`)
 	_ = os.Setenv("OPTIMIZELY_CLIENT_ODP_DISABLE", `true`)
 	_ = os.Setenv("OPTIMIZELY_CLIENT_ODP_EVENTSREQUESTTIMEOUT", `5s`)
@@ -530,9 +771,23 @@ func TestViperEnv(t *testing.T) {
 	_ = os.Setenv("OPTIMIZELY_ADMIN_METRICSTYPE", "prometheus")
 
 	_ = os.Setenv("OPTIMIZELY_API_MAXCONNS", "100")
+	_ = os.Setenv("OPTIMIZELY_API_RATELIMIT_RATE", "2.5")
+	_ = os.Setenv("OPTIMIZELY_API_RATELIMIT_BURST", "10")
+	_ = os.Setenv("OPTIMIZELY_API_RATELIMIT_KEY", "clientID")
+	_ = os.Setenv("OPTIMIZELY_API_RATELIMIT_STORE", `{"default":"redis","services":{"redis":{"host":"localhost:6379","prefix":"agent-ratelimit-"}}}`)
 	_ = os.Setenv("OPTIMIZELY_API_PORT", "3000")
 	_ = os.Setenv("OPTIMIZELY_API_ENABLENOTIFICATIONS", "true")
 	_ = os.Setenv("OPTIMIZELY_API_ENABLEOVERRIDES", "true")
+	_ = os.Setenv("OPTIMIZELY_API_ENABLEPROFILEMANAGEMENT", "true")
+	_ = os.Setenv("OPTIMIZELY_API_NOTIFICATIONWEBHOOKS_BATCHSIZE", "50")
+	_ = os.Setenv("OPTIMIZELY_API_NOTIFICATIONWEBHOOKS_FLUSHINTERVAL", "5s")
+	_ = os.Setenv("OPTIMIZELY_API_NOTIFICATIONWEBHOOKS_QUEUESIZE", "500")
+	_ = os.Setenv("OPTIMIZELY_API_NOTIFICATIONWEBHOOKS_TIMEOUT", "3s")
+	_ = os.Setenv("OPTIMIZELY_API_NOTIFICATIONWEBHOOKS_MAXRETRIES", "5")
+	_ = os.Setenv("OPTIMIZELY_API_NOTIFICATIONWEBHOOKS_INITIALBACKOFF", "2s")
+	_ = os.Setenv("OPTIMIZELY_API_NOTIFICATIONWEBHOOKS_MAXBACKOFF", "1m")
+	_ = os.Setenv("OPTIMIZELY_API_NOTIFICATIONWEBHOOKS_DEADLETTERFILE", "/tmp/notifications.ndjson")
+	_ = os.Setenv("OPTIMIZELY_API_DECIDEBULK_MAXCONCURRENCY", "4")
 
 	_ = os.Setenv("OPTIMIZELY_WEBHOOK_PORT", "3001")
 	_ = os.Setenv("OPTIMIZELY_WEBHOOK_PROJECTS_10000_SECRET", "secret-10000")
@@ -565,6 +820,8 @@ func TestViperEnv(t *testing.T) {
 	_ = os.Setenv("OPTIMIZELY_RUNTIME_BLOCKPROFILERATE", "1")
 	_ = os.Setenv("OPTIMIZELY_RUNTIME_MUTEXPROFILEFRACTION", "2")
 
+	_ = os.Setenv("OPTIMIZELY_REDIS", `{"mode":"cluster","addresses":["redis-0:6379","redis-1:6379"],"tls":{"enable":true}}`)
+
 	v := viper.New()
 	assert.NoError(t, initConfig(v))
 	actual := loadConfig(v)
@@ -578,6 +835,9 @@ func TestViperEnv(t *testing.T) {
 	//assertWebhook(t, actual.Webhook) // Maps don't appear to be supported
 	assertRuntime(t, actual.Runtime)
 	assertCMAB(t, actual.Client.CMAB)
+	assert.Equal(t, "cluster", actual.Redis["mode"])
+	assert.Equal(t, []interface{}{"redis-0:6379", "redis-1:6379"}, actual.Redis["addresses"])
+	assert.Equal(t, map[string]interface{}{"enable": true}, actual.Redis["tls"])
 }
 
 func TestLoggingWithIncludeSdkKey(t *testing.T) {
@@ -61,10 +61,37 @@ func NewDefaultConfig() *AgentConfig {
 				AllowedCredentials: false,
 				MaxAge:             300,
 			},
-			MaxConns:            0,
-			Port:                "8080",
-			EnableNotifications: false,
-			EnableOverrides:     false,
+			MaxConns: 0,
+			RateLimit: RateLimitConfig{
+				Rate:  0,
+				Burst: 0,
+				Key:   "sdkKey",
+				Store: RateLimitStoreConfigs{
+					"default":  "in-memory",
+					"services": map[string]interface{}{},
+				},
+			},
+			Port:                    "8080",
+			EnableNotifications:     false,
+			EnableOverrides:         false,
+			EnableProfileManagement: false,
+			NotificationWebhooks: NotificationWebhooksConfig{
+				BatchSize:      10,
+				FlushInterval:  1 * time.Second,
+				QueueSize:      1000,
+				Timeout:        10 * time.Second,
+				MaxRetries:     3,
+				InitialBackoff: 1 * time.Second,
+				MaxBackoff:     30 * time.Second,
+				DeadLetterFile: "",
+				Subscriptions:  map[string]NotificationWebhookSubscription{},
+			},
+			DecideBulk: DecideBulkConfig{
+				MaxConcurrency: 10,
+			},
+		},
+		GRPC: GRPCConfig{
+			Port: "0",
 		},
 		Log: LogConfig{
 			Pretty:        false,
@@ -83,10 +110,40 @@ func NewDefaultConfig() *AgentConfig {
 			EventURL:            "https://logx.optimizely.com/v1/events",
 			// https://github.com/google/re2/wiki/Syntax
 			SdkKeyRegex: "^[a-zA-Z0-9+/=_]+(:[a-zA-Z0-9+/=_]+)?$",
+			SdkKeyFilter: SDKKeyFilterConfig{
+				Allow:     []string{},
+				AllowFile: "",
+				Deny:      []string{},
+				DenyFile:  "",
+			},
+			// 0 means no limit
+			MaxClients:  0,
+			IdleTimeout: 0,
 			UserProfileService: UserProfileServiceConfigs{
 				"default":  "",
 				"services": map[string]interface{}{},
 			},
+			DatafileStore: DatafileStoreConfigs{
+				"default":  "",
+				"services": map[string]interface{}{},
+			},
+			EventQueue: EventQueueConfigs{
+				"default":  "",
+				"services": map[string]interface{}{},
+			},
+			DeadLetter: DeadLetterConfig{
+				MaxAttempts:   3,
+				RetryInterval: 1 * time.Second,
+				Store: DeadLetterStoreConfigs{
+					"default":  "",
+					"services": map[string]interface{}{},
+				},
+			},
+			Offline: OfflineConfig{
+				Enable:      false,
+				DatafileDir: "",
+				EventsFile:  "",
+			},
 			ODP: OdpConfig{
 				Disable:                false,
 				EventsRequestTimeout:   10 * time.Second,
@@ -121,6 +178,23 @@ func NewDefaultConfig() *AgentConfig {
 					BackoffMultiplier: 2.0,
 				},
 			},
+			EventForwarding: EventForwardingConfig{
+				QueueSize:     1000,
+				MaxRetries:    3,
+				RetryInterval: 1 * time.Second,
+				Destinations:  EventForwarderConfigs{},
+			},
+			EventPipelines: []EventPipelineConfig{},
+			DecisionLog: DecisionLogConfig{
+				BufferSize:       10000,
+				BatchSize:        100,
+				FlushInterval:    1 * time.Second,
+				AttributeHashKey: "",
+				Sink: DecisionLogSinkConfigs{
+					"default":  "",
+					"services": map[string]interface{}{},
+				},
+			},
 		},
 		Runtime: RuntimeConfig{
 			BlockProfileRate:     0, // 0 is disabled
@@ -173,6 +247,7 @@ type AgentConfig struct {
 
 	Admin           AdminConfig   `json:"admin"`
 	API             APIConfig     `json:"api"`
+	GRPC            GRPCConfig    `json:"grpc"`
 	Log             LogConfig     `json:"log"`
 	Tracing         TracingConfig `json:"tracing"`
 	Client          ClientConfig  `json:"client"`
@@ -180,6 +255,8 @@ type AgentConfig struct {
 	Server          ServerConfig  `json:"server"`
 	Webhook         WebhookConfig `json:"webhook"`
 	Synchronization SyncConfig    `json:"synchronization"`
+	// Redis holds the connection settings shared by the redis integrations
+	Redis map[string]interface{} `json:"redis"`
 }
 
 // SyncConfig contains Synchronization configuration for the multiple Agent nodes
@@ -222,6 +299,69 @@ type UserProfileServiceConfigs map[string]interface{}
 // ODPCacheConfigs defines the generic mapping of odp cache plugins
 type ODPCacheConfigs map[string]interface{}
 
+// DatafileStoreConfigs defines the generic mapping of datafile store plugins
+type DatafileStoreConfigs map[string]interface{}
+
+// EventQueueConfigs defines the generic mapping of event queue plugins
+type EventQueueConfigs map[string]interface{}
+
+// DeadLetterStoreConfigs defines the generic mapping of dead letter store plugins
+type DeadLetterStoreConfigs map[string]interface{}
+
+// DeadLetterConfig holds the configuration of the event dispatch dead-letter store. Event batches that could
+// not be dispatched are written to the store instead of being dropped and retried up to MaxAttempts attempts
+type DeadLetterConfig struct {
+	MaxAttempts int `json:"maxAttempts"`
+	// RetryInterval is the wait before the second attempt, it doubles after every failed attempt
+	RetryInterval time.Duration          `json:"retryInterval"`
+	Store         DeadLetterStoreConfigs `json:"store"`
+}
+
+// EventForwarderConfigs defines the named event forwarding destinations, every destination sets the
+// event forwarder plugin in "type" next to the settings of that plugin
+type EventForwarderConfigs map[string]interface{}
+
+// EventForwardingConfig holds the configuration of event forwarding. Every dispatched event batch is also sent
+// to each destination, which has its own queue of QueueSize batches and retries MaxRetries times
+type EventForwardingConfig struct {
+	QueueSize  int `json:"queueSize"`
+	MaxRetries int `json:"maxRetries"`
+	// RetryInterval is the wait before the first retry, it doubles after every failed retry
+	RetryInterval time.Duration         `json:"retryInterval"`
+	Destinations  EventForwarderConfigs `json:"destinations"`
+}
+
+// EventPipelineConfig holds the steps applied in order to the user attributes and event tags of the impression
+// and conversion events of SDKKeys before they are queued for dispatch
+type EventPipelineConfig struct {
+	// SDKKeys selects the SDK keys of the pipeline, it applies to every SDK key when empty
+	SDKKeys []string            `json:"sdkKeys"`
+	Steps   []EventPipelineStep `json:"steps"`
+}
+
+// EventPipelineStep is a processing step of an event pipeline
+type EventPipelineStep struct {
+	// Type is one of "allow", "deny", "redact", "hash" or "inject"
+	Type string `json:"type"`
+	// Target is "attributes", "tags" or "all", the default
+	Target string `json:"target"`
+	// Keys selects the attributes and tags of the step, redact and hash apply to every value when empty
+	Keys []string `json:"keys"`
+	// Pattern is the regular expression replaced with Replacement in the string values of a redact step
+	Pattern     string `json:"pattern"`
+	Replacement string `json:"replacement"`
+	// HashKey is the HMAC key of a hash step, values are hashed with plain SHA-256 when empty
+	HashKey string `json:"-"`
+	// Values are set by an inject step, environment variables are expanded in string values
+	Values []EventPipelineValue `json:"values"`
+}
+
+// EventPipelineValue is an attribute or event tag set by an inject step
+type EventPipelineValue struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
 // ClientConfig holds the configuration options for the Optimizely Client.
 type ClientConfig struct {
 	PollingInterval     time.Duration             `json:"pollingInterval"`
@@ -231,9 +371,54 @@ type ClientConfig struct {
 	DatafileURLTemplate string                    `json:"datafileURLTemplate"`
 	EventURL            string                    `json:"eventURL"`
 	SdkKeyRegex         string                    `json:"sdkKeyRegex"`
+	SdkKeyFilter        SDKKeyFilterConfig        `json:"sdkKeyFilter"`
+	MaxClients          int                       `json:"maxClients"`
+	IdleTimeout         time.Duration             `json:"idleTimeout"`
 	UserProfileService  UserProfileServiceConfigs `json:"userProfileService"`
+	DatafileStore       DatafileStoreConfigs      `json:"datafileStore"`
+	EventQueue          EventQueueConfigs         `json:"eventQueue"`
+	DeadLetter          DeadLetterConfig          `json:"deadLetter"`
+	EventForwarding     EventForwardingConfig     `json:"eventForwarding"`
+	EventPipelines      []EventPipelineConfig     `json:"eventPipelines"`
+	Offline             OfflineConfig             `json:"offline"`
 	ODP                 OdpConfig                 `json:"odp"`
 	CMAB                CMABConfig                `json:"cmab" mapstructure:"cmab"`
+	DecisionLog         DecisionLogConfig         `json:"decisionLog"`
+}
+
+// SDKKeyFilterConfig restricts the SDK keys that Agent will load, independently of the API auth configuration.
+// Allow and deny files hold one SDK key per line and are reloaded when they change
+type SDKKeyFilterConfig struct {
+	// Allow lists the accepted SDK keys, when both Allow and AllowFile are empty every key is accepted
+	Allow     []string `json:"allow"`
+	AllowFile string   `json:"allowFile"`
+	// Deny lists rejected SDK keys, deny entries take precedence over allow entries
+	Deny     []string `json:"deny"`
+	DenyFile string   `json:"denyFile"`
+}
+
+// OfflineConfig holds the configuration of the offline client mode, in which datafiles are read
+// from a local directory and events are written to a local file
+type OfflineConfig struct {
+	Enable bool `json:"enable"`
+	// DatafileDir holds one <sdkKey>.json datafile per SDK key and is watched for changes
+	DatafileDir string `json:"datafileDir"`
+	// EventsFile receives event batches as newline delimited JSON, events are dropped when empty
+	EventsFile string `json:"eventsFile"`
+}
+
+// DecisionLogSinkConfigs defines the generic mapping of decision log sink plugins
+type DecisionLogSinkConfigs map[string]interface{}
+
+// DecisionLogConfig holds the configuration of the decision audit log. Decisions are buffered in memory
+// and written to the sink in batches, they are dropped when the buffer is full
+type DecisionLogConfig struct {
+	BufferSize    int           `json:"bufferSize"`
+	BatchSize     int           `json:"batchSize"`
+	FlushInterval time.Duration `json:"flushInterval"`
+	// AttributeHashKey is the HMAC key used to hash attribute values, they are hashed with plain SHA-256 when empty
+	AttributeHashKey string                 `json:"attributeHashKey"`
+	Sink             DecisionLogSinkConfigs `json:"sink"`
 }
 
 // OdpConfig holds the odp configuration
@@ -325,9 +510,71 @@ type APIConfig struct {
 	Auth                ServiceAuthConfig `json:"-"`
 	CORS                CORSConfig        `json:"cors"`
 	MaxConns            int               `json:"maxConns"`
+	RateLimit           RateLimitConfig   `json:"rateLimit"`
 	Port                string            `json:"port"`
 	EnableNotifications bool              `json:"enableNotifications"`
 	EnableOverrides     bool              `json:"enableOverrides"`
+	// EnableProfileManagement mounts the endpoints that export, import and delete user profiles
+	EnableProfileManagement bool `json:"enableProfileManagement"`
+	// NotificationWebhooks posts notifications to HTTP endpoints, independently of EnableNotifications
+	NotificationWebhooks NotificationWebhooksConfig `json:"notificationWebhooks"`
+	DecideBulk           DecideBulkConfig           `json:"decideBulk"`
+}
+
+// DecideBulkConfig holds the configuration of the bulk decide endpoint
+type DecideBulkConfig struct {
+	// MaxConcurrency is the number of user contexts decided at once for a request
+	MaxConcurrency int `json:"maxConcurrency"`
+}
+
+// NotificationWebhooksConfig holds the outbound HTTP subscriptions to notifications. Notifications are
+// queued per subscription, posted in batches and retried with exponential backoff. Batches that can not be
+// delivered are appended to the dead-letter file.
+type NotificationWebhooksConfig struct {
+	BatchSize      int           `json:"batchSize"`
+	FlushInterval  time.Duration `json:"flushInterval"`
+	QueueSize      int           `json:"queueSize"`
+	Timeout        time.Duration `json:"timeout"`
+	MaxRetries     int           `json:"maxRetries"`
+	InitialBackoff time.Duration `json:"initialBackoff"`
+	MaxBackoff     time.Duration `json:"maxBackoff"`
+	// DeadLetterFile receives undelivered batches as newline delimited JSON, they are dropped when empty
+	DeadLetterFile string `json:"deadLetterFile"`
+	// Subscriptions are keyed by name
+	Subscriptions map[string]NotificationWebhookSubscription `json:"subscriptions"`
+}
+
+// NotificationWebhookSubscription posts the notifications of an SDK key to a URL
+type NotificationWebhookSubscription struct {
+	SDKKey string `json:"sdkKey"`
+	URL    string `json:"url"`
+	// Secret signs the payload in the X-Hub-Signature header, the header is omitted when empty
+	Secret string `json:"-"`
+	// Filter selects the notification types like the filter parameter of the event stream, every type when empty
+	Filter  []string          `json:"filter"`
+	Headers map[string]string `json:"headers"`
+}
+
+// RateLimitStoreConfigs defines the generic mapping of rate limiter plugins
+type RateLimitStoreConfigs map[string]interface{}
+
+// RateLimitConfig holds the token bucket rate limits applied to the API
+type RateLimitConfig struct {
+	// Rate is the number of requests per second allowed for each key, 0 disables rate limiting
+	Rate float64 `json:"rate"`
+	// Burst is the number of requests that can be made at once, it defaults to the rate
+	Burst int `json:"burst"`
+	// Key selects how requests are grouped: "sdkKey", "clientID" or "ip"
+	Key string `json:"key"`
+	// TrustedProxies are the IPs or CIDR ranges of the proxies whose X-Forwarded-For header is used as the
+	// remote IP of the requests
+	TrustedProxies []string              `json:"trustedProxies"`
+	Store          RateLimitStoreConfigs `json:"store"`
+}
+
+// GRPCConfig holds the gRPC API configuration. Authorization follows the REST API configuration.
+type GRPCConfig struct {
+	Port string `json:"port"`
 }
 
 // BatchRequestsConfig holds the configuration for batching
@@ -58,6 +58,13 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, time.Duration(0), conf.Admin.Auth.JwksUpdateInterval)
 
 	assert.Equal(t, 0, conf.API.MaxConns)
+	assert.Equal(t, 0.0, conf.API.RateLimit.Rate)
+	assert.Equal(t, 0, conf.API.RateLimit.Burst)
+	assert.Equal(t, "sdkKey", conf.API.RateLimit.Key)
+	assert.Equal(t, RateLimitStoreConfigs{
+		"default":  "in-memory",
+		"services": map[string]interface{}{},
+	}, conf.API.RateLimit.Store)
 	assert.Equal(t, "8080", conf.API.Port)
 	assert.Equal(t, make([]OAuthClientCredentials, 0), conf.API.Auth.Clients)
 	assert.Equal(t, make([]string, 0), conf.API.Auth.HMACSecrets)
@@ -65,7 +72,21 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, "", conf.API.Auth.JwksURL)
 	assert.Equal(t, time.Duration(0), conf.API.Auth.JwksUpdateInterval)
 	assert.Equal(t, false, conf.API.EnableOverrides)
+	assert.Equal(t, false, conf.API.EnableProfileManagement)
 	assert.Equal(t, false, conf.API.EnableNotifications)
+	assert.Equal(t, 10, conf.API.NotificationWebhooks.BatchSize)
+	assert.Equal(t, 1*time.Second, conf.API.NotificationWebhooks.FlushInterval)
+	assert.Equal(t, 1000, conf.API.NotificationWebhooks.QueueSize)
+	assert.Equal(t, 10*time.Second, conf.API.NotificationWebhooks.Timeout)
+	assert.Equal(t, 3, conf.API.NotificationWebhooks.MaxRetries)
+	assert.Equal(t, 1*time.Second, conf.API.NotificationWebhooks.InitialBackoff)
+	assert.Equal(t, 30*time.Second, conf.API.NotificationWebhooks.MaxBackoff)
+	assert.Equal(t, "", conf.API.NotificationWebhooks.DeadLetterFile)
+	assert.Empty(t, conf.API.NotificationWebhooks.Subscriptions)
+	assert.Equal(t, 10, conf.API.DecideBulk.MaxConcurrency)
+
+	assert.Equal(t, "0", conf.GRPC.Port)
+
 	assert.Equal(t, []string(nil), conf.API.CORS.AllowedOrigins)
 	assert.Equal(t, []string(nil), conf.API.CORS.AllowedMethods)
 	assert.Equal(t, make([]string, 0), conf.API.CORS.AllowedHeaders)
@@ -83,12 +104,41 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, "https://cdn.optimizely.com/datafiles/%s.json", conf.Client.DatafileURLTemplate)
 	assert.Equal(t, "https://logx.optimizely.com/v1/events", conf.Client.EventURL)
 	assert.Equal(t, "^[a-zA-Z0-9+/=_]+(:[a-zA-Z0-9+/=_]+)?$", conf.Client.SdkKeyRegex)
+	assert.Equal(t, []string{}, conf.Client.SdkKeyFilter.Allow)
+	assert.Equal(t, "", conf.Client.SdkKeyFilter.AllowFile)
+	assert.Equal(t, []string{}, conf.Client.SdkKeyFilter.Deny)
+	assert.Equal(t, "", conf.Client.SdkKeyFilter.DenyFile)
+	assert.Equal(t, 0, conf.Client.MaxClients)
+	assert.Equal(t, time.Duration(0), conf.Client.IdleTimeout)
 	assert.Equal(t, "", conf.Client.UserProfileService["default"])
 	assert.Equal(t, false, conf.Client.ODP.Disable)
 	assert.Equal(t, 1*time.Second, conf.Client.ODP.EventsFlushInterval)
 	assert.Equal(t, 10*time.Second, conf.Client.ODP.EventsRequestTimeout)
 	assert.Equal(t, 10*time.Second, conf.Client.ODP.SegmentsRequestTimeout)
 	assert.Equal(t, map[string]interface{}{}, conf.Client.UserProfileService["services"])
+	assert.Equal(t, "", conf.Client.DatafileStore["default"])
+	assert.Equal(t, map[string]interface{}{}, conf.Client.DatafileStore["services"])
+	assert.Equal(t, "", conf.Client.EventQueue["default"])
+	assert.Equal(t, map[string]interface{}{}, conf.Client.EventQueue["services"])
+	assert.Equal(t, 3, conf.Client.DeadLetter.MaxAttempts)
+	assert.Equal(t, 1*time.Second, conf.Client.DeadLetter.RetryInterval)
+	assert.Equal(t, "", conf.Client.DeadLetter.Store["default"])
+	assert.Equal(t, map[string]interface{}{}, conf.Client.DeadLetter.Store["services"])
+
+	assert.Equal(t, 1000, conf.Client.EventForwarding.QueueSize)
+	assert.Equal(t, 3, conf.Client.EventForwarding.MaxRetries)
+	assert.Equal(t, 1*time.Second, conf.Client.EventForwarding.RetryInterval)
+	assert.Equal(t, EventForwarderConfigs{}, conf.Client.EventForwarding.Destinations)
+	assert.Equal(t, []EventPipelineConfig{}, conf.Client.EventPipelines)
+	assert.False(t, conf.Client.Offline.Enable)
+	assert.Equal(t, "", conf.Client.Offline.DatafileDir)
+	assert.Equal(t, "", conf.Client.Offline.EventsFile)
+	assert.Equal(t, 10000, conf.Client.DecisionLog.BufferSize)
+	assert.Equal(t, 100, conf.Client.DecisionLog.BatchSize)
+	assert.Equal(t, 1*time.Second, conf.Client.DecisionLog.FlushInterval)
+	assert.Equal(t, "", conf.Client.DecisionLog.AttributeHashKey)
+	assert.Equal(t, "", conf.Client.DecisionLog.Sink["default"])
+	assert.Equal(t, map[string]interface{}{}, conf.Client.DecisionLog.Sink["services"])
 	assert.Equal(t, "in-memory", conf.Client.ODP.SegmentsCache["default"])
 	assert.Equal(t, map[string]interface{}{
 		"in-memory": map[string]interface{}{
@@ -0,0 +1,242 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package grpcapi //
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/optimizely/agent/pkg/metrics"
+	"github.com/optimizely/agent/pkg/middleware"
+	"github.com/optimizely/agent/pkg/optimizely"
+)
+
+// interceptor applies the request pipeline of the REST API to every RPC:
+// request ID, metrics, tracing, authorization and OptlyClient lookup.
+type interceptor struct {
+	cache   optimizely.Cache
+	auth    *middleware.Auth
+	sdkKeys *middleware.SDKKeyFilter
+	limiter *middleware.RateLimiter
+	timers  map[string]*metrics.Timer
+}
+
+func (i *interceptor) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	err = i.intercept(ctx, info.FullMethod, grpc.SetHeader, func(ctx context.Context) error {
+		resp, err = handler(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (i *interceptor) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	setHeader := func(_ context.Context, md metadata.MD) error {
+		return ss.SetHeader(md)
+	}
+	return i.intercept(ss.Context(), info.FullMethod, setHeader, func(ctx context.Context) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	})
+}
+
+func (i *interceptor) intercept(ctx context.Context, method string, setHeader func(context.Context, metadata.MD) error, call func(context.Context) error) error {
+	if timer, ok := i.timers[method]; ok {
+		startTime := time.Now()
+		defer func() {
+			timer.Update(time.Since(startTime).Seconds() * 1000.0) // display time in milliseconds
+		}()
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	sdkKey := firstValue(md, sdkKeyMD)
+
+	reqID := firstValue(md, requestIDMD)
+	if reqID == "" {
+		reqID = uuid.New().String()
+	}
+	_ = setHeader(ctx, metadata.Pairs(requestIDMD, reqID))
+
+	r, ok := rpcs[method]
+	if !ok {
+		r = rpc{tracerName: "grpcHandler", spanName: method}
+	}
+	propCtx := otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	ctx, span := otel.Tracer(r.tracerName).Start(propCtx, r.spanName)
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.RPCSystemKey.String("grpc"),
+		semconv.RPCMethodKey.String(method),
+		attribute.String(middleware.OptlySDKHeader, sdkKey),
+	)
+
+	logger := log.With().Str("requestId", reqID).Logger()
+	if span.SpanContext().TraceID().IsValid() {
+		logger = logger.With().Str("traceId", span.SpanContext().TraceID().String()).Logger()
+		logger = logger.With().Str("spanId", span.SpanContext().SpanID().String()).Logger()
+	}
+	if optimizely.ShouldIncludeSDKKey {
+		logger = logger.With().Str("sdkKey", strings.Split(sdkKey, ":")[0]).Logger()
+	}
+	ctx = context.WithValue(ctx, loggerKey, &logger)
+	ctx = context.WithValue(ctx, requestIDKey, reqID)
+
+	clientID, err := i.authorize(md, sdkKey)
+	if err == nil {
+		err = i.rateLimit(ctx, setHeader, md, sdkKey, clientID)
+	}
+	if err == nil {
+		ctx, err = i.clientCtx(ctx, md, sdkKey)
+	}
+	if err == nil {
+		err = call(ctx)
+	}
+
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(status.Code(err))))
+	return err
+}
+
+// authorize mirrors Auth.AuthorizeAPI, reading the token from the request metadata.
+// It returns the OAuth client ID of the token.
+func (i *interceptor) authorize(md metadata.MD, sdkKey string) (string, error) {
+	var token string
+	for _, key := range []string{"auth", "jwt"} {
+		if value := firstValue(md, key); value != "" {
+			token = value
+		}
+	}
+	if value := firstValue(md, authorizationMD); value != "" {
+		token = strings.TrimSpace(value)
+		for _, prefix := range []string{"JWT ", "Bearer "} {
+			token = strings.TrimSpace(strings.TrimPrefix(token, prefix))
+		}
+	}
+
+	tk, err := i.auth.CheckToken(token)
+	if err != nil {
+		return "", status.Errorf(codes.Unauthenticated, "unauthorized: %v", err)
+	}
+
+	if err := i.auth.ValidateAPIClaims(tk, sdkKey); err != nil {
+		return "", status.Error(codes.Unauthenticated, err.Error())
+	}
+	return i.auth.ClientID(tk), nil
+}
+
+// rateLimit mirrors RateLimiter.Limit, setting the retry-after header of rejected calls
+func (i *interceptor) rateLimit(ctx context.Context, setHeader func(context.Context, metadata.MD) error, md metadata.MD, sdkKey, clientID string) error {
+	var remoteAddr string
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddr = p.Addr.String()
+	}
+
+	remoteIP := i.limiter.ClientIP(remoteAddr, md.Get("x-forwarded-for"))
+	allowed, retryAfter := i.limiter.Allow(sdkKey, clientID, remoteIP)
+	if !allowed {
+		_ = setHeader(ctx, metadata.Pairs(retryAfterMD, strconv.Itoa(middleware.RetryAfterSeconds(retryAfter))))
+		return status.Error(codes.ResourceExhausted, middleware.ErrRateLimited.Error())
+	}
+	return nil
+}
+
+// clientCtx mirrors CachedOptlyMiddleware.ClientCtx, adding the OptlyClient for the SDK key to the context
+func (i *interceptor) clientCtx(ctx context.Context, md metadata.MD, sdkKey string) (context.Context, error) {
+	if sdkKey == "" {
+		return ctx, status.Errorf(codes.InvalidArgument, "missing required %s metadata", sdkKeyMD)
+	}
+
+	if !i.sdkKeys.Allowed(sdkKey) {
+		getLogger(ctx).Warn().Msg("Rejected SDK key")
+		return ctx, status.Error(codes.PermissionDenied, middleware.ErrSDKKeyNotAllowed.Error())
+	}
+
+	if upsKey := firstValue(md, upsMD); upsKey != "" {
+		i.cache.SetUserProfileService(sdkKey, upsKey)
+	}
+
+	if odpCacheKey := firstValue(md, odpCacheMD); odpCacheKey != "" {
+		i.cache.SetODPCache(sdkKey, odpCacheKey)
+	}
+
+	optlyClient, err := i.cache.GetClient(sdkKey)
+	if err != nil {
+		getLogger(ctx).Error().Err(err).Msg("Initializing OptimizelyClient")
+
+		switch {
+		// Check if error indicates a 403 from the CDN.
+		case strings.Contains(err.Error(), "403"):
+			return ctx, status.Error(codes.PermissionDenied, err.Error())
+		case errors.Is(err, optimizely.ErrValidationFailure):
+			return ctx, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			return ctx, status.Errorf(codes.Internal, "failed to instantiate Optimizely for SDK Key: %s", sdkKey)
+		}
+	}
+
+	ctx = context.WithValue(ctx, sdkKeyKey, sdkKey)
+	return context.WithValue(ctx, optlyClientKey, optlyClient), nil
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// serverStream overrides the context of a grpc.ServerStream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// metadataCarrier adapts metadata.MD to a propagation.TextMapCarrier
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	return firstValue(metadata.MD(c), key)
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
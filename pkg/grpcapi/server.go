@@ -0,0 +1,131 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package grpcapi provides the gRPC counterpart of the /v1 REST API
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+
+	"github.com/optimizely/agent/config"
+	"github.com/optimizely/agent/pkg/grpcapi/agentpb"
+	"github.com/optimizely/agent/pkg/handlers"
+	"github.com/optimizely/agent/pkg/metrics"
+	"github.com/optimizely/agent/pkg/middleware"
+	"github.com/optimizely/agent/pkg/optimizely"
+)
+
+type contextKey string
+
+const (
+	optlyClientKey = contextKey("optlyClient")
+	loggerKey      = contextKey("logger")
+	sdkKeyKey      = contextKey("sdkKey")
+	requestIDKey   = contextKey("requestId")
+)
+
+// Metadata keys mirror the REST API headers. gRPC metadata keys are always lower case.
+var (
+	sdkKeyMD        = strings.ToLower(middleware.OptlySDKHeader)
+	upsMD           = strings.ToLower(middleware.OptlyUPSHeader)
+	odpCacheMD      = strings.ToLower(middleware.OptlyODPCacheHeader)
+	requestIDMD     = strings.ToLower(middleware.OptlyRequestHeader)
+	authorizationMD = "authorization"
+	retryAfterMD    = "retry-after"
+)
+
+// rpc describes how each method is measured and traced, using the same names as the REST routes
+type rpc struct {
+	metric     string
+	tracerName string
+	spanName   string
+}
+
+var rpcs = map[string]rpc{
+	agentpb.Agent_GetConfig_FullMethodName:     {"grpc-get-config", "configHandler", "OptimizelyConfig"},
+	agentpb.Agent_GetDatafile_FullMethodName:   {"grpc-get-datafile", "datafileHandler", "OptimizelyDatafile"},
+	agentpb.Agent_Decide_FullMethodName:        {"grpc-decide", "decideHandler", "Decide"},
+	agentpb.Agent_DecideAll_FullMethodName:     {"grpc-decide-all", "decideHandler", "DecideAll"},
+	agentpb.Agent_Activate_FullMethodName:      {"grpc-activate", "activateHandler", "Activate"},
+	agentpb.Agent_Track_FullMethodName:         {"grpc-track-event", "trackHandler", "Track"},
+	agentpb.Agent_SendOdpEvent_FullMethodName:  {"grpc-send-odp-event", "sendOdpEventHandler", "SendOdpEvent"},
+	agentpb.Agent_Notifications_FullMethodName: {"grpc-notifications", "notificationHandler", "SendNotificationEvent"},
+}
+
+// NewServer creates a grpc.Server exposing the Agent service. Requests share the OptlyCache,
+// authorization, metrics and tracing of the REST API. Notification streams are closed once ctx is done
+// so that the server can stop gracefully. The SDK key filter and the rate limiter are shared with the REST API,
+// so that both APIs accept the same SDK keys and take from the same token buckets.
+func NewServer(ctx context.Context, optlyCache optimizely.Cache, conf config.AgentConfig, metricsRegistry *metrics.Registry, sdkKeys *middleware.SDKKeyFilter, limiter *middleware.RateLimiter) (*grpc.Server, error) {
+	authProvider := middleware.NewAuth(&conf.API.Auth)
+	if authProvider == nil {
+		return nil, errors.New("unable to initialize api auth")
+	}
+
+	timers := make(map[string]*metrics.Timer, len(rpcs))
+	for method, r := range rpcs {
+		timers[method] = metricsRegistry.NewTimer(r.metric)
+	}
+
+	nReceiver := handlers.NotificationReceiver(conf.Synchronization)
+
+	icp := &interceptor{
+		cache:   optlyCache,
+		auth:    authProvider,
+		sdkKeys: sdkKeys,
+		limiter: limiter,
+		timers:  timers,
+	}
+	svc := &service{
+		ctx:                  ctx,
+		enableNotifications:  conf.API.EnableNotifications,
+		notificationReceiver: nReceiver,
+	}
+
+	return newGRPCServer(icp, svc), nil
+}
+
+func newGRPCServer(icp *interceptor, svc agentpb.AgentServer) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(icp.unary),
+		grpc.StreamInterceptor(icp.stream),
+	)
+	agentpb.RegisterAgentServer(srv, svc)
+	return srv
+}
+
+func getOptlyClient(ctx context.Context) *optimizely.OptlyClient {
+	optlyClient, _ := ctx.Value(optlyClientKey).(*optimizely.OptlyClient)
+	return optlyClient
+}
+
+func getRequestID(ctx context.Context) string {
+	reqID, _ := ctx.Value(requestIDKey).(string)
+	return reqID
+}
+
+func getLogger(ctx context.Context) *zerolog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*zerolog.Logger); ok {
+		return logger
+	}
+	return &log.Logger
+}
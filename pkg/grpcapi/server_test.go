@@ -0,0 +1,474 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package grpcapi //
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/optimizely/agent/config"
+	"github.com/optimizely/agent/pkg/grpcapi/agentpb"
+	"github.com/optimizely/agent/pkg/handlers"
+	"github.com/optimizely/agent/pkg/metrics"
+	"github.com/optimizely/agent/pkg/middleware"
+	"github.com/optimizely/agent/pkg/optimizely"
+	"github.com/optimizely/agent/pkg/optimizely/optimizelytest"
+	"github.com/optimizely/agent/pkg/syncer"
+	_ "github.com/optimizely/agent/plugins/ratelimiter/services"
+	sdkconfig "github.com/optimizely/go-sdk/v2/pkg/config"
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+	"github.com/optimizely/go-sdk/v2/pkg/notification"
+)
+
+var metricsRegistry = metrics.NewRegistry("")
+
+type MockCache struct {
+	mock.Mock
+}
+
+func (m *MockCache) GetClient(key string) (*optimizely.OptlyClient, error) {
+	args := m.Called(key)
+	return args.Get(0).(*optimizely.OptlyClient), args.Error(1)
+}
+
+func (m *MockCache) UpdateConfigs(_ string) {
+}
+
+func (m *MockCache) SetUserProfileService(sdkKey, userProfileService string) {
+	m.Called(sdkKey, userProfileService)
+}
+
+func (m *MockCache) SetODPCache(sdkKey, odpCache string) {
+	m.Called(sdkKey, odpCache)
+}
+
+type staticConfigManager struct {
+	*sdkconfig.StaticProjectConfigManager
+}
+
+func (staticConfigManager) SyncConfig() {}
+
+type ServerTestSuite struct {
+	suite.Suite
+	tc     *optimizelytest.TestClient
+	cache  *MockCache
+	conf   config.AgentConfig
+	events chan syncer.Event
+	ctx    context.Context
+	cancel context.CancelFunc
+	conn   *grpc.ClientConn
+	client agentpb.AgentClient
+}
+
+func (suite *ServerTestSuite) SetupTest() {
+	suite.tc = optimizelytest.NewClient()
+	optlyClient := &optimizely.OptlyClient{
+		OptimizelyClient: suite.tc.OptimizelyClient,
+		ConfigManager:    staticConfigManager{sdkconfig.NewStaticProjectConfigManager(suite.tc.ProjectConfig, logging.GetLogger("test", "test"))},
+		ForcedVariations: suite.tc.ForcedVariations,
+	}
+
+	suite.cache = new(MockCache)
+	suite.cache.On("GetClient", "EXPECTED").Return(optlyClient, nil)
+	suite.cache.On("GetClient", "403").Return(new(optimizely.OptlyClient), errors.New("403 forbidden"))
+	suite.cache.On("GetClient", "INVALID").Return(new(optimizely.OptlyClient), optimizely.ErrValidationFailure)
+	suite.cache.On("GetClient", "ERROR").Return(new(optimizely.OptlyClient), errors.New("error"))
+
+	suite.conf = *config.NewDefaultConfig()
+	suite.conf.API.EnableNotifications = true
+	suite.events = make(chan syncer.Event)
+	suite.ctx, suite.cancel = context.WithCancel(context.Background())
+}
+
+func (suite *ServerTestSuite) TearDownTest() {
+	suite.cancel()
+	if suite.conn != nil {
+		suite.conn.Close()
+		suite.conn = nil
+	}
+}
+
+func (suite *ServerTestSuite) start() {
+	sdkKeys, err := middleware.NewSDKKeyFilter(suite.conf.Client.SdkKeyFilter, nil)
+	suite.Require().NoError(err)
+	suite.T().Cleanup(sdkKeys.Close)
+	limiter, err := middleware.NewRateLimiter(suite.conf.API.RateLimit, nil)
+	suite.Require().NoError(err)
+	srv, err := NewServer(suite.ctx, suite.cache, suite.conf, metricsRegistry, sdkKeys, limiter)
+	suite.Require().NoError(err)
+	suite.serve(srv)
+}
+
+func (suite *ServerTestSuite) serve(srv *grpc.Server) {
+	lis := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	go func() {
+		<-suite.ctx.Done()
+		srv.Stop()
+	}()
+
+	conn, err := grpc.DialContext(suite.ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	suite.Require().NoError(err)
+	suite.conn = conn
+	suite.client = agentpb.NewAgentClient(conn)
+}
+
+func (suite *ServerTestSuite) withSDKKey(sdkKey string) context.Context {
+	return metadata.AppendToOutgoingContext(suite.ctx, sdkKeyMD, sdkKey)
+}
+
+func (suite *ServerTestSuite) assertCode(err error, code codes.Code) {
+	suite.Error(err)
+	suite.Equal(code, status.Code(err), err.Error())
+}
+
+func (suite *ServerTestSuite) TestMissingSDKKey() {
+	suite.start()
+	_, err := suite.client.GetConfig(suite.ctx, &agentpb.GetConfigRequest{})
+	suite.assertCode(err, codes.InvalidArgument)
+}
+
+func (suite *ServerTestSuite) TestClientErrors() {
+	suite.start()
+	scenarios := map[string]codes.Code{
+		"403":     codes.PermissionDenied,
+		"INVALID": codes.InvalidArgument,
+		"ERROR":   codes.Internal,
+	}
+
+	for sdkKey, code := range scenarios {
+		_, err := suite.client.GetConfig(suite.withSDKKey(sdkKey), &agentpb.GetConfigRequest{})
+		suite.assertCode(err, code)
+	}
+}
+
+func (suite *ServerTestSuite) TestSDKKeyFilter() {
+	suite.conf.Client.SdkKeyFilter.Deny = []string{"EXPECTED"}
+	suite.start()
+
+	_, err := suite.client.GetConfig(suite.withSDKKey("EXPECTED:token"), &agentpb.GetConfigRequest{})
+	suite.assertCode(err, codes.PermissionDenied)
+	suite.cache.AssertNotCalled(suite.T(), "GetClient", "EXPECTED:token")
+}
+
+func (suite *ServerTestSuite) TestRateLimit() {
+	suite.conf.API.RateLimit.Rate = 0.1
+	suite.conf.API.RateLimit.Burst = 1
+	suite.start()
+
+	_, err := suite.client.GetConfig(suite.withSDKKey("EXPECTED"), &agentpb.GetConfigRequest{})
+	suite.NoError(err)
+
+	var header metadata.MD
+	_, err = suite.client.GetConfig(suite.withSDKKey("EXPECTED:token"), &agentpb.GetConfigRequest{}, grpc.Header(&header))
+	suite.assertCode(err, codes.ResourceExhausted)
+	suite.Equal([]string{"10"}, header.Get(retryAfterMD))
+}
+
+func (suite *ServerTestSuite) TestRateLimitByForwardedIP() {
+	suite.conf.API.RateLimit.Rate = 0.1
+	suite.conf.API.RateLimit.Burst = 1
+	suite.conf.API.RateLimit.Key = "ip"
+	suite.conf.API.RateLimit.TrustedProxies = []string{"127.0.0.1"}
+	limiter, err := middleware.NewRateLimiter(suite.conf.API.RateLimit, nil)
+	suite.Require().NoError(err)
+	icp := &interceptor{limiter: limiter}
+
+	// The calls forwarded by a trusted proxy are limited by the IP of their client
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1234}})
+	setHeader := func(context.Context, metadata.MD) error { return nil }
+	for _, ip := range []string{"1.1.1.1", "2.2.2.2"} {
+		suite.NoError(icp.rateLimit(ctx, setHeader, metadata.Pairs("x-forwarded-for", ip), "EXPECTED", ""))
+	}
+	err = icp.rateLimit(ctx, setHeader, metadata.Pairs("x-forwarded-for", "1.1.1.1"), "EXPECTED", "")
+	suite.assertCode(err, codes.ResourceExhausted)
+}
+
+func (suite *ServerTestSuite) TestUPSAndODPCacheMetadata() {
+	suite.cache.On("SetUserProfileService", "EXPECTED", "in-memory")
+	suite.cache.On("SetODPCache", "EXPECTED", "redis")
+	suite.start()
+
+	ctx := metadata.AppendToOutgoingContext(suite.withSDKKey("EXPECTED"), upsMD, "in-memory", odpCacheMD, "redis")
+	_, err := suite.client.GetConfig(ctx, &agentpb.GetConfigRequest{})
+	suite.NoError(err)
+	suite.cache.AssertCalled(suite.T(), "SetUserProfileService", "EXPECTED", "in-memory")
+	suite.cache.AssertCalled(suite.T(), "SetODPCache", "EXPECTED", "redis")
+}
+
+func (suite *ServerTestSuite) TestRequestID() {
+	suite.start()
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(suite.withSDKKey("EXPECTED"), requestIDMD, "request-1")
+	_, err := suite.client.GetConfig(ctx, &agentpb.GetConfigRequest{}, grpc.Header(&header))
+	suite.NoError(err)
+	suite.Equal([]string{"request-1"}, header.Get(requestIDMD))
+
+	_, err = suite.client.GetConfig(suite.withSDKKey("EXPECTED"), &agentpb.GetConfigRequest{}, grpc.Header(&header))
+	suite.NoError(err)
+	suite.Len(header.Get(requestIDMD), 1)
+	suite.NotEmpty(header.Get(requestIDMD)[0])
+}
+
+func (suite *ServerTestSuite) TestAuthorization() {
+	suite.conf.API.Auth.HMACSecrets = []string{"c2VjcmV0"}
+	suite.start()
+
+	_, err := suite.client.GetConfig(suite.withSDKKey("EXPECTED"), &agentpb.GetConfigRequest{})
+	suite.assertCode(err, codes.Unauthenticated)
+
+	sign := func(claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+		suite.Require().NoError(err)
+		return token
+	}
+
+	expired := sign(jwt.MapClaims{"sdk_keys": []string{"EXPECTED"}, "exp": time.Now().Add(-time.Hour).Unix()})
+	ctx := metadata.AppendToOutgoingContext(suite.withSDKKey("EXPECTED"), authorizationMD, "Bearer "+expired)
+	_, err = suite.client.GetConfig(ctx, &agentpb.GetConfigRequest{})
+	suite.assertCode(err, codes.Unauthenticated)
+
+	otherKey := sign(jwt.MapClaims{"sdk_keys": []string{"OTHER"}, "exp": time.Now().Add(time.Hour).Unix()})
+	ctx = metadata.AppendToOutgoingContext(suite.withSDKKey("EXPECTED"), authorizationMD, "Bearer "+otherKey)
+	_, err = suite.client.GetConfig(ctx, &agentpb.GetConfigRequest{})
+	suite.assertCode(err, codes.Unauthenticated)
+
+	valid := sign(jwt.MapClaims{"sdk_keys": []string{"EXPECTED"}, "exp": time.Now().Add(time.Hour).Unix()})
+	ctx = metadata.AppendToOutgoingContext(suite.withSDKKey("EXPECTED"), authorizationMD, "Bearer "+valid)
+	_, err = suite.client.GetConfig(ctx, &agentpb.GetConfigRequest{})
+	suite.NoError(err)
+}
+
+func (suite *ServerTestSuite) TestGetConfigAndDatafile() {
+	suite.tc.AddFeature(entities.Feature{Key: "one"})
+	suite.start()
+
+	conf, err := suite.client.GetConfig(suite.withSDKKey("EXPECTED"), &agentpb.GetConfigRequest{})
+	suite.NoError(err)
+	var actual sdkconfig.OptimizelyConfig
+	suite.NoError(json.Unmarshal(conf.GetConfig(), &actual))
+	suite.Contains(actual.FeaturesMap, "one")
+
+	datafile, err := suite.client.GetDatafile(suite.withSDKKey("EXPECTED"), &agentpb.GetDatafileRequest{})
+	suite.NoError(err)
+	suite.Equal(suite.tc.ProjectConfig.GetDatafile(), string(datafile.GetDatafile()))
+	suite.Equal(suite.tc.ProjectConfig.GetRevision(), datafile.GetRevision())
+}
+
+func (suite *ServerTestSuite) TestDecide() {
+	feature := entities.Feature{Key: "one"}
+	suite.tc.AddFeatureTest(feature)
+	suite.tc.AddFeatureTest(entities.Feature{Key: "two"})
+	suite.start()
+
+	_, err := suite.client.Decide(suite.withSDKKey("EXPECTED"), &agentpb.DecideRequest{UserId: "testUser"})
+	suite.assertCode(err, codes.InvalidArgument)
+
+	_, err = suite.client.Decide(suite.withSDKKey("EXPECTED"), &agentpb.DecideRequest{Keys: []string{"one"}})
+	suite.assertCode(err, codes.InvalidArgument)
+
+	attributes, err := structpb.NewStruct(map[string]interface{}{"country": "us"})
+	suite.NoError(err)
+	resp, err := suite.client.Decide(suite.withSDKKey("EXPECTED"), &agentpb.DecideRequest{
+		UserId:         "testUser",
+		UserAttributes: attributes,
+		DecideOptions:  []string{"DISABLE_DECISION_EVENT"},
+		Keys:           []string{"one"},
+	})
+	suite.NoError(err)
+	suite.Require().Len(resp.GetDecisions(), 1)
+
+	d := resp.GetDecisions()[0]
+	suite.Equal("one", d.GetFlagKey())
+	suite.Equal("testUser", d.GetUserContext().GetUserId())
+	suite.Equal("us", d.GetUserContext().GetAttributes().AsMap()["country"])
+	suite.True(d.GetEnabled())
+	suite.Empty(suite.tc.GetProcessedEvents())
+
+	resp, err = suite.client.DecideAll(suite.withSDKKey("EXPECTED"), &agentpb.DecideRequest{UserId: "testUser"})
+	suite.NoError(err)
+	suite.Len(resp.GetDecisions(), 2)
+	suite.Len(suite.tc.GetProcessedEvents(), 2)
+}
+
+func (suite *ServerTestSuite) TestDecideInvalidOption() {
+	suite.start()
+	_, err := suite.client.DecideAll(suite.withSDKKey("EXPECTED"), &agentpb.DecideRequest{
+		UserId:        "testUser",
+		DecideOptions: []string{"INVALID"},
+	})
+	suite.assertCode(err, codes.InvalidArgument)
+}
+
+func (suite *ServerTestSuite) TestActivate() {
+	suite.tc.AddFeatureTest(entities.Feature{Key: "one"})
+	suite.tc.AddExperiment("exp", []entities.Variation{suite.tc.ProjectConfig.CreateVariation("variation_1")})
+	suite.start()
+
+	resp, err := suite.client.Activate(suite.withSDKKey("EXPECTED"), &agentpb.ActivateRequest{
+		UserId:          "testUser",
+		FeatureKeys:     []string{"one", "missing"},
+		ExperimentKeys:  []string{"exp"},
+		DisableTracking: true,
+	})
+	suite.NoError(err)
+	suite.Len(resp.GetDecisions(), 3)
+
+	decisions := make(map[string]*agentpb.ActivateDecision)
+	for _, d := range resp.GetDecisions() {
+		if d.GetFeatureKey() != "" {
+			decisions[d.GetFeatureKey()] = d
+		} else {
+			decisions[d.GetExperimentKey()] = d
+		}
+	}
+	suite.True(decisions["one"].GetEnabled())
+	suite.Equal("featureKey not found", decisions["missing"].GetError())
+	suite.Equal("variation_1", decisions["exp"].GetVariationKey())
+	suite.Empty(suite.tc.GetProcessedEvents())
+
+	enabled := false
+	resp, err = suite.client.Activate(suite.withSDKKey("EXPECTED"), &agentpb.ActivateRequest{
+		UserId:          "testUser",
+		Types:           []string{"feature"},
+		Enabled:         &enabled,
+		DisableTracking: true,
+	})
+	suite.NoError(err)
+	suite.Empty(resp.GetDecisions())
+
+	_, err = suite.client.Activate(suite.withSDKKey("EXPECTED"), &agentpb.ActivateRequest{
+		UserId: "testUser",
+		Types:  []string{"invalid"},
+	})
+	suite.assertCode(err, codes.InvalidArgument)
+}
+
+func (suite *ServerTestSuite) TestTrack() {
+	suite.tc.AddEvent(entities.Event{Key: "test-event"})
+	suite.start()
+
+	tags, err := structpb.NewStruct(map[string]interface{}{"revenue": 100})
+	suite.NoError(err)
+	resp, err := suite.client.Track(suite.withSDKKey("EXPECTED"), &agentpb.TrackRequest{
+		EventKey:  "test-event",
+		UserId:    "testUser",
+		EventTags: tags,
+	})
+	suite.NoError(err)
+	suite.Equal("testUser", resp.GetUserId())
+	suite.Equal("test-event", resp.GetEventKey())
+	suite.Empty(resp.GetError())
+	suite.Len(suite.tc.GetProcessedEvents(), 1)
+
+	resp, err = suite.client.Track(suite.withSDKKey("EXPECTED"), &agentpb.TrackRequest{EventKey: "missing", UserId: "testUser"})
+	suite.NoError(err)
+	suite.NotEmpty(resp.GetError())
+
+	_, err = suite.client.Track(suite.withSDKKey("EXPECTED"), &agentpb.TrackRequest{UserId: "testUser"})
+	suite.assertCode(err, codes.InvalidArgument)
+}
+
+func (suite *ServerTestSuite) TestSendOdpEvent() {
+	suite.start()
+
+	_, err := suite.client.SendOdpEvent(suite.withSDKKey("EXPECTED"), &agentpb.SendOdpEventRequest{Identifiers: map[string]string{"fs_user_id": "testUser"}})
+	suite.assertCode(err, codes.InvalidArgument)
+
+	_, err = suite.client.SendOdpEvent(suite.withSDKKey("EXPECTED"), &agentpb.SendOdpEventRequest{Action: "any"})
+	suite.assertCode(err, codes.InvalidArgument)
+}
+
+func (suite *ServerTestSuite) TestNotificationsDisabled() {
+	suite.conf.API.EnableNotifications = false
+	suite.start()
+
+	stream, err := suite.client.Notifications(suite.withSDKKey("EXPECTED"), &agentpb.NotificationsRequest{})
+	suite.NoError(err)
+	_, err = stream.Recv()
+	suite.assertCode(err, codes.PermissionDenied)
+}
+
+func (suite *ServerTestSuite) TestNotifications() {
+	optlyClient, _ := suite.cache.GetClient("EXPECTED")
+	suite.cache.On("GetClient", "EXPECTED:token").Return(optlyClient, nil)
+
+	var receivedSDKKey string
+	icp := &interceptor{
+		cache:  suite.cache,
+		auth:   middleware.NewAuth(&suite.conf.API.Auth),
+		timers: map[string]*metrics.Timer{},
+	}
+	svc := &service{
+		ctx:                 suite.ctx,
+		enableNotifications: true,
+		notificationReceiver: func(ctx context.Context) (<-chan syncer.Event, error) {
+			receivedSDKKey, _ = ctx.Value(handlers.SDKKey).(string)
+			return suite.events, nil
+		},
+	}
+	suite.serve(newGRPCServer(icp, svc))
+
+	stream, err := suite.client.Notifications(suite.withSDKKey("EXPECTED:token"), &agentpb.NotificationsRequest{Filter: []string{"track"}})
+	suite.NoError(err)
+
+	go func() {
+		suite.events <- syncer.Event{Type: notification.Decision, Message: map[string]string{"type": "decision"}}
+		suite.events <- syncer.Event{Type: notification.Track, Message: map[string]string{"type": "track"}}
+	}()
+
+	n, err := stream.Recv()
+	suite.NoError(err)
+	suite.Equal("track", n.GetType())
+	suite.JSONEq(`{"type":"track"}`, string(n.GetMessage()))
+	suite.Equal("EXPECTED", receivedSDKKey)
+
+	// Streams are closed when the server context is done
+	suite.cancel()
+	_, err = stream.Recv()
+	suite.Error(err)
+}
+
+func TestServerTestSuite(t *testing.T) {
+	suite.Run(t, new(ServerTestSuite))
+}
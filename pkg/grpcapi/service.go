@@ -0,0 +1,344 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package grpcapi //
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/optimizely/agent/pkg/grpcapi/agentpb"
+	"github.com/optimizely/agent/pkg/handlers"
+	"github.com/optimizely/agent/pkg/optimizely"
+	"github.com/optimizely/go-sdk/v2/pkg/client"
+	"github.com/optimizely/go-sdk/v2/pkg/config"
+	"github.com/optimizely/go-sdk/v2/pkg/decide"
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/odp/segment"
+)
+
+// service implements agentpb.AgentServer on top of the OptlyClient added by the interceptor
+type service struct {
+	agentpb.UnimplementedAgentServer
+
+	ctx                  context.Context
+	enableNotifications  bool
+	notificationReceiver handlers.NotificationReceiverFunc
+}
+
+// GetConfig returns the OptimizelyConfig as JSON
+func (s *service) GetConfig(ctx context.Context, _ *agentpb.GetConfigRequest) (*agentpb.GetConfigResponse, error) {
+	optlyClient := getOptlyClient(ctx)
+	conf, err := json.Marshal(optlyClient.WithTraceContext(ctx).GetOptimizelyConfig())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	getLogger(ctx).Info().Msg("Successfully returned OptimizelyConfig")
+	return &agentpb.GetConfigResponse{Config: conf}, nil
+}
+
+// GetDatafile returns the datafile directly from the SDK
+func (s *service) GetDatafile(ctx context.Context, _ *agentpb.GetDatafileRequest) (*agentpb.GetDatafileResponse, error) {
+	optlyClient := getOptlyClient(ctx)
+	oConf := optlyClient.WithTraceContext(ctx).GetOptimizelyConfig()
+
+	getLogger(ctx).Info().Msg("Successfully returned datafile")
+	return &agentpb.GetDatafileResponse{Datafile: []byte(oConf.GetDatafile()), Revision: oConf.Revision}, nil
+}
+
+// Decide makes feature decisions for the requested keys
+func (s *service) Decide(ctx context.Context, req *agentpb.DecideRequest) (*agentpb.DecideResponse, error) {
+	if len(req.GetKeys()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, `missing "keys" in request`)
+	}
+	return s.decide(ctx, req, req.GetKeys())
+}
+
+// DecideAll makes feature decisions for every flag in the datafile
+func (s *service) DecideAll(ctx context.Context, req *agentpb.DecideRequest) (*agentpb.DecideResponse, error) {
+	return s.decide(ctx, req, nil)
+}
+
+func (s *service) decide(ctx context.Context, req *agentpb.DecideRequest, keys []string) (*agentpb.DecideResponse, error) {
+	optlyClient := getOptlyClient(ctx)
+	logger := getLogger(ctx)
+
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, handlers.ErrEmptyUserID.Error())
+	}
+
+	decideOptions, err := decide.TranslateOptions(req.GetDecideOptions())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	defer optlyClient.BindRequest(req.GetUserId(), getRequestID(ctx))()
+	optimizelyUserContext := optlyClient.WithTraceContext(ctx).CreateUserContext(req.GetUserId(), req.GetUserAttributes().AsMap())
+
+	if req.GetFetchSegments() {
+		options := make([]segment.OptimizelySegmentOption, 0, len(req.GetFetchSegmentsOptions()))
+		for _, option := range req.GetFetchSegmentsOptions() {
+			options = append(options, segment.OptimizelySegmentOption(option))
+		}
+		if success := optimizelyUserContext.FetchQualifiedSegments(options); !success {
+			return nil, status.Error(codes.Internal, "failed to fetch qualified segments")
+		}
+	}
+
+	for _, fd := range req.GetForcedDecisions() {
+		context := decision.OptimizelyDecisionContext{FlagKey: fd.GetFlagKey(), RuleKey: fd.GetRuleKey()}
+		forcedDecision := decision.OptimizelyForcedDecision{VariationKey: fd.GetVariationKey()}
+		optimizelyUserContext.SetForcedDecision(context, forcedDecision)
+	}
+
+	featureMap := make(map[string]config.OptimizelyFeature)
+	if cfg := optlyClient.GetOptimizelyConfig(); cfg != nil {
+		featureMap = cfg.FeaturesMap
+	}
+
+	var decides map[string]client.OptimizelyDecision
+	if len(keys) == 0 {
+		decides = optimizelyUserContext.DecideAll(decideOptions)
+	} else {
+		decides = optimizelyUserContext.DecideForKeys(keys, decideOptions)
+	}
+
+	resp := &agentpb.DecideResponse{Decisions: make([]*agentpb.Decision, 0, len(decides))}
+	for _, d := range decides {
+		decisionOut, err := toDecision(handlers.NewDecideOut(d, featureMap))
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		resp.Decisions = append(resp.Decisions, decisionOut)
+		logger.Debug().Msgf("Feature %q is enabled for user %s? %t", d.FlagKey, d.UserContext.UserID, d.Enabled)
+	}
+
+	return resp, nil
+}
+
+// Activate makes feature and experiment decisions for the requested keys and types
+func (s *service) Activate(ctx context.Context, req *agentpb.ActivateRequest) (*agentpb.ActivateResponse, error) {
+	optlyClient := getOptlyClient(ctx)
+	logger := getLogger(ctx)
+
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, handlers.ErrEmptyUserID.Error())
+	}
+	uc := entities.UserContext{ID: req.GetUserId(), Attributes: req.GetUserAttributes().AsMap()}
+	defer optlyClient.BindRequest(uc.ID, getRequestID(ctx))()
+
+	oConf := optlyClient.WithTraceContext(ctx).GetOptimizelyConfig()
+	kmap, err := handlers.ActivateKeys(oConf, req.GetTypes(), req.GetExperimentKeys(), req.GetFeatureKeys())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	resp := &agentpb.ActivateResponse{Decisions: make([]*agentpb.ActivateDecision, 0, len(kmap))}
+	for key, value := range kmap {
+		var d *optimizely.Decision
+
+		switch value {
+		case "experiment":
+			logger.Debug().Str("experimentKey", key).Msg("fetching experiment decision")
+			d, err = optlyClient.ActivateExperiment(ctx, key, uc, req.GetDisableTracking())
+		case "feature":
+			logger.Debug().Str("featureKey", key).Msg("fetching feature decision")
+			d, err = optlyClient.ActivateFeature(ctx, key, uc, req.GetDisableTracking())
+		case "experimentKey-not-found":
+			d = &optimizely.Decision{UserID: uc.ID, ExperimentKey: key, Error: "experimentKey not found"}
+		case "featureKey-not-found":
+			d = &optimizely.Decision{UserID: uc.ID, FeatureKey: key, Error: "featureKey not found"}
+		default:
+			err = fmt.Errorf(`type %q not supported`, value)
+		}
+
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		if req.Enabled != nil && req.GetEnabled() != d.Enabled {
+			continue
+		}
+
+		decisionOut, err := toActivateDecision(d)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		resp.Decisions = append(resp.Decisions, decisionOut)
+	}
+
+	logger.Info().Msgf("Made activate decisions for user %s", uc.ID)
+	return resp, nil
+}
+
+// Track tracks a conversion event for the user
+func (s *service) Track(ctx context.Context, req *agentpb.TrackRequest) (*agentpb.TrackResponse, error) {
+	if req.GetEventKey() == "" {
+		return nil, status.Error(codes.InvalidArgument, `missing "event_key" in request`)
+	}
+
+	uc := entities.UserContext{ID: req.GetUserId(), Attributes: req.GetUserAttributes().AsMap()}
+	track, err := getOptlyClient(ctx).TrackEvent(ctx, req.GetEventKey(), uc, req.GetEventTags().AsMap())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	getLogger(ctx).Info().Str("eventKey", req.GetEventKey()).Msg("tracked event")
+	return &agentpb.TrackResponse{UserId: track.UserID, EventKey: track.EventKey, Error: track.Error}, nil
+}
+
+// SendOdpEvent sends an event to the ODP platform
+func (s *service) SendOdpEvent(ctx context.Context, req *agentpb.SendOdpEventRequest) (*agentpb.SendOdpEventResponse, error) {
+	if req.GetAction() == "" {
+		return nil, status.Error(codes.InvalidArgument, `missing "action" in request`)
+	}
+	if len(req.GetIdentifiers()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, `missing or empty "identifiers" in request`)
+	}
+
+	optlyClient := getOptlyClient(ctx)
+	err := optlyClient.WithTraceContext(ctx).SendOdpEvent(req.GetType(), req.GetAction(), req.GetIdentifiers(), req.GetData().AsMap())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	getLogger(ctx).Info().Msg("Successfully sent event to ODP platform")
+	return &agentpb.SendOdpEventResponse{Success: true}, nil
+}
+
+// Notifications streams the notifications of the SDK key, in the same JSON encoding as the SSE stream
+func (s *service) Notifications(req *agentpb.NotificationsRequest, stream agentpb.Agent_NotificationsServer) error {
+	if !s.enableNotifications {
+		return status.Error(codes.PermissionDenied, "Notification stream not enabled")
+	}
+
+	ctx := stream.Context()
+	logger := getLogger(ctx)
+	notificationsToAdd := handlers.NotificationFilter(req.GetFilter())
+
+	sdkKey, _ := ctx.Value(sdkKeyKey).(string)
+	// Parse out the SDK key if it includes a secure token (format: sdkKey:apiKey)
+	if idx := strings.Index(sdkKey, ":"); idx != -1 {
+		sdkKey = sdkKey[:idx]
+	}
+
+	rctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	rctx = context.WithValue(rctx, handlers.SDKKey, sdkKey)
+	dataChan, err := s.notificationReceiver(context.WithValue(rctx, handlers.LoggerKey, logger))
+	if err != nil {
+		logger.Err(err).Msg("error from receiver")
+		return status.Error(codes.Internal, "Error from data receiver!")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Debug().Msg("received close on the stream.  So, we are shutting down this handler")
+			return nil
+		case <-s.ctx.Done():
+			logger.Debug().Msg("server is shutting down, closing the notification stream")
+			return nil
+		case event, ok := <-dataChan:
+			if !ok {
+				return nil
+			}
+			notificationType, found := notificationsToAdd[event.Type]
+			if !found {
+				continue
+			}
+
+			jsonEvent, err := json.Marshal(event.Message)
+			if err != nil {
+				logger.Err(err).Msg("failed to marshal notification into json")
+				continue
+			}
+
+			if err := stream.Send(&agentpb.Notification{Type: notificationType, Message: jsonEvent}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toDecision(d handlers.DecideOut) (*agentpb.Decision, error) {
+	variables, err := toStruct(d.Variables)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := toStruct(d.UserContext.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &agentpb.Decision{
+		FlagKey:                 d.FlagKey,
+		RuleKey:                 d.RuleKey,
+		VariationKey:            d.VariationKey,
+		Enabled:                 d.Enabled,
+		Variables:               variables,
+		UserContext:             &agentpb.UserContext{UserId: d.UserContext.UserID, Attributes: attributes},
+		Reasons:                 d.Reasons,
+		IsEveryoneElseVariation: d.IsEveryoneElseVariation,
+	}, nil
+}
+
+func toActivateDecision(d *optimizely.Decision) (*agentpb.ActivateDecision, error) {
+	variables, err := toStruct(d.Variables)
+	if err != nil {
+		return nil, err
+	}
+
+	return &agentpb.ActivateDecision{
+		UserId:        d.UserID,
+		ExperimentKey: d.ExperimentKey,
+		FeatureKey:    d.FeatureKey,
+		VariationKey:  d.VariationKey,
+		Type:          d.Type,
+		Variables:     variables,
+		Enabled:       d.Enabled,
+		Error:         d.Error,
+	}, nil
+}
+
+// toStruct converts through JSON so that values are encoded exactly as in the REST API responses
+func toStruct(m map[string]interface{}) (*structpb.Struct, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &structpb.Struct{}
+	if err := protojson.Unmarshal(raw, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
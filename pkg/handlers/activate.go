@@ -54,22 +54,18 @@ func Activate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	defer optlyClient.BindRequest(uc.ID, r.Header.Get(middleware.OptlyRequestHeader))()
 	query := r.URL.Query()
 	oConf := optlyClient.WithTraceContext(r.Context()).GetOptimizelyConfig()
 	decisions := make([]*optimizely.Decision, 0, len(oConf.ExperimentsMap)+len(oConf.FeaturesMap))
 	disableTracking := query.Get("disableTracking") == "true"
 
-	kmap := make(keyMap)
-	err = parseTypeParameter(query["type"], oConf, kmap)
+	kmap, err := ActivateKeys(oConf, query["type"], query["experimentKey"], query["featureKey"])
 	if err != nil {
 		RenderError(err, http.StatusBadRequest, w, r)
 		return
 	}
 
-	parseExperimentKeys(query["experimentKey"], oConf, kmap)
-
-	parseFeatureKeys(query["featureKey"], oConf, kmap)
-
 	for key, value := range kmap {
 		var d *optimizely.Decision
 
@@ -112,6 +108,20 @@ func Activate(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, decisions)
 }
 
+// ActivateKeys resolves the requested types, experiment keys and feature keys into a map of
+// entity key to the kind of activation ("experiment", "feature" or "<kind>Key-not-found")
+func ActivateKeys(oConf *config.OptimizelyConfig, types, experimentKeys, featureKeys []string) (map[string]string, error) {
+	kmap := make(keyMap)
+	if err := parseTypeParameter(types, oConf, kmap); err != nil {
+		return nil, err
+	}
+
+	parseExperimentKeys(experimentKeys, oConf, kmap)
+	parseFeatureKeys(featureKeys, oConf, kmap)
+
+	return kmap, nil
+}
+
 func parseExperimentKeys(keys []string, oConf *config.OptimizelyConfig, kmap keyMap) {
 	for _, key := range keys {
 		_, ok := oConf.ExperimentsMap[key]
@@ -179,6 +189,9 @@ func filterDecisions(r *http.Request, decisions []*optimizely.Decision) []*optim
 // ErrEmptyUserID is a constant error if userId is omitted from the request
 var ErrEmptyUserID = errors.New(`missing "userId" in request payload`)
 
+// ErrEmptyUserIDQuery is a constant error if userId is omitted from the query string
+var ErrEmptyUserIDQuery = errors.New(`missing "userId" query parameter`)
+
 func getUserContext(r *http.Request) (entities.UserContext, error) {
 	var body ActivateBody
 	err := ParseRequestBody(r, &body)
@@ -25,6 +25,7 @@ import (
 	"github.com/go-chi/render"
 	"github.com/optimizely/agent/config"
 	"github.com/optimizely/agent/pkg/metrics"
+	"github.com/optimizely/agent/pkg/optimizely"
 )
 
 var startTime = time.Now()
@@ -39,12 +40,21 @@ type Info struct {
 	AppName string `json:"app_name,omitempty"`
 	Uptime  string `json:"uptime"`
 	Host    string `json:"host,omitempty"`
+
+	Datafiles map[string]optimizely.DatafileInfo `json:"datafiles,omitempty"`
+}
+
+// DatafileInfoProvider reports the datafile source and revision of every loaded SDK key
+type DatafileInfoProvider interface {
+	DatafileInfo() map[string]optimizely.DatafileInfo
 }
 
 // Admin is holding info to pass to admin handlers
 type Admin struct {
-	Config config.AgentConfig
-	Info   Info
+	Config      config.AgentConfig
+	Info        Info
+	Datafiles   DatafileInfoProvider
+	DeadLetters DeadLetterManager
 }
 
 // NewAdmin initializes admin
@@ -61,6 +71,9 @@ func NewAdmin(conf config.AgentConfig) *Admin {
 // AppInfo returns custom app-info
 func (a Admin) AppInfo(w http.ResponseWriter, r *http.Request) {
 	a.Info.Uptime = time.Since(startTime).String()
+	if a.Datafiles != nil {
+		a.Info.Datafiles = a.Datafiles.DatafileInfo()
+	}
 	render.JSON(w, r, a.Info)
 }
 
@@ -26,6 +26,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/optimizely/agent/config"
+	"github.com/optimizely/agent/pkg/optimizely"
 )
 
 var testConfig = config.AgentConfig{
@@ -54,6 +55,33 @@ func TestAppInfoHandler(t *testing.T) {
 	assert.NotEmpty(t, actual.Uptime)
 }
 
+type mockDatafileInfoProvider map[string]optimizely.DatafileInfo
+
+func (m mockDatafileInfoProvider) DatafileInfo() map[string]optimizely.DatafileInfo {
+	return m
+}
+
+func TestAppInfoHandlerWithDatafiles(t *testing.T) {
+
+	req := httptest.NewRequest("GET", "/info", nil)
+	rec := httptest.NewRecorder()
+
+	datafiles := mockDatafileInfoProvider{
+		"sdkKey": {Source: optimizely.DatafileSourceSnapshot, Revision: "42"},
+	}
+	a := NewAdmin(testConfig)
+	a.Datafiles = datafiles
+	a.AppInfo(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code, "Status code differs")
+
+	actual := &Info{}
+	err := json.Unmarshal(rec.Body.Bytes(), actual)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]optimizely.DatafileInfo(datafiles), actual.Datafiles)
+}
+
 func TestAppConfigHandler(t *testing.T) {
 
 	req := httptest.NewRequest("GET", "/config", nil)
@@ -0,0 +1,108 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package handlers //
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// bulkDecoder decodes the items of a bulk request body, given as a JSON array or as newline delimited JSON
+type bulkDecoder struct {
+	decoder *json.Decoder
+	isArray bool
+	index   int
+}
+
+// newBulkDecoder returns io.EOF when the body is empty
+func newBulkDecoder(body io.Reader) (*bulkDecoder, error) {
+	reader := bufio.NewReader(body)
+	first, err := peekNonSpace(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &bulkDecoder{decoder: json.NewDecoder(reader), isArray: first == '['}
+	if d.isArray {
+		// Consume the opening bracket, the elements are decoded one by one
+		if _, err := d.decoder.Token(); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// next decodes the next item into v and returns its index, or io.EOF once every item is decoded
+func (d *bulkDecoder) next(v interface{}) (int, error) {
+	if d.isArray && !d.decoder.More() {
+		return d.index, io.EOF
+	}
+
+	index := d.index
+	if err := d.decoder.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) && d.isArray {
+			err = io.ErrUnexpectedEOF
+		}
+		return index, err
+	}
+	d.index++
+	return index, nil
+}
+
+// peekNonSpace skips leading whitespace and returns the next byte without consuming it
+func peekNonSpace(reader *bufio.Reader) (byte, error) {
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			_, _ = reader.ReadByte()
+		default:
+			return b[0], nil
+		}
+	}
+}
+
+// ndjsonWriter streams a newline delimited JSON response while the request body is still being read
+type ndjsonWriter struct {
+	rc      *http.ResponseController
+	encoder *json.Encoder
+}
+
+func newNDJSONWriter(w http.ResponseWriter) *ndjsonWriter {
+	// Without full duplex, the request body can not be read anymore once the response is flushed over HTTP/1.x
+	rc := http.NewResponseController(w)
+	_ = rc.EnableFullDuplex()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	return &ndjsonWriter{rc: rc, encoder: json.NewEncoder(w)}
+}
+
+func (n *ndjsonWriter) write(v interface{}) {
+	_ = n.encoder.Encode(v)
+}
+
+func (n *ndjsonWriter) flush() {
+	_ = n.rc.Flush()
+}
@@ -0,0 +1,197 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package handlers //
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"github.com/optimizely/agent/pkg/optimizely"
+	"github.com/optimizely/agent/plugins/deadletter"
+)
+
+// ErrDeadLettersDisabled is returned by the dead-letter endpoints when no dead-letter store is configured
+var ErrDeadLettersDisabled = errors.New("dead-letter store is not configured")
+
+// DeadLetterManager lists, replays and purges the event batches of an SDK key that could not be dispatched
+type DeadLetterManager interface {
+	ListDeadLetters(sdkKey string) ([]deadletter.Batch, error)
+	GetDeadLetter(sdkKey, id string) (deadletter.Batch, error)
+	ReplayDeadLetter(sdkKey, id string) error
+	RemoveDeadLetter(sdkKey, id string) error
+	PurgeDeadLetters(sdkKey string) (int, error)
+}
+
+// DeadLetterSummary describes a dead-lettered batch without its events
+type DeadLetterSummary struct {
+	ID        string    `json:"id"`
+	EndPoint  string    `json:"endPoint"`
+	Error     string    `json:"error"`
+	Attempts  int       `json:"attempts"`
+	Timestamp time.Time `json:"timestamp"`
+	Events    int       `json:"events"`
+}
+
+// DeadLetterReplay is the outcome of replaying the dead-lettered batches of an SDK key
+type DeadLetterReplay struct {
+	Replayed int `json:"replayed"`
+	Failed   int `json:"failed"`
+}
+
+// DeadLetterPurge is the outcome of purging the dead-lettered batches of an SDK key
+type DeadLetterPurge struct {
+	Purged int `json:"purged"`
+}
+
+// ListDeadLetters returns a summary of the dead-lettered batches of the SDK key, oldest first
+func (a Admin) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if a.DeadLetters == nil {
+		RenderError(ErrDeadLettersDisabled, http.StatusNotFound, w, r)
+		return
+	}
+
+	batches, err := a.DeadLetters.ListDeadLetters(chi.URLParam(r, "sdkKey"))
+	if err != nil {
+		renderDeadLetterError(err, w, r)
+		return
+	}
+
+	summaries := make([]DeadLetterSummary, 0, len(batches))
+	for _, batch := range batches {
+		summary := DeadLetterSummary{
+			ID:        batch.ID,
+			EndPoint:  batch.EndPoint,
+			Error:     batch.Error,
+			Attempts:  batch.Attempts,
+			Timestamp: batch.Timestamp,
+		}
+		for _, visitor := range batch.Event.Visitors {
+			for _, snapshot := range visitor.Snapshots {
+				summary.Events += len(snapshot.Events)
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	render.JSON(w, r, summaries)
+}
+
+// GetDeadLetter returns a dead-lettered batch of the SDK key with its events
+func (a Admin) GetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if a.DeadLetters == nil {
+		RenderError(ErrDeadLettersDisabled, http.StatusNotFound, w, r)
+		return
+	}
+
+	batch, err := a.DeadLetters.GetDeadLetter(chi.URLParam(r, "sdkKey"), chi.URLParam(r, "batchID"))
+	if err != nil {
+		renderDeadLetterError(err, w, r)
+		return
+	}
+	render.JSON(w, r, batch)
+}
+
+// ReplayDeadLetters dispatches every dead-lettered batch of the SDK key once, the batches that are accepted
+// are removed from the store
+func (a Admin) ReplayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if a.DeadLetters == nil {
+		RenderError(ErrDeadLettersDisabled, http.StatusNotFound, w, r)
+		return
+	}
+
+	sdkKey := chi.URLParam(r, "sdkKey")
+	batches, err := a.DeadLetters.ListDeadLetters(sdkKey)
+	if err != nil {
+		renderDeadLetterError(err, w, r)
+		return
+	}
+
+	var replay DeadLetterReplay
+	for _, batch := range batches {
+		err := a.DeadLetters.ReplayDeadLetter(sdkKey, batch.ID)
+		switch {
+		case err == nil:
+			replay.Replayed++
+		case errors.Is(err, deadletter.ErrNotFound):
+			// replayed or removed concurrently
+		case errors.Is(err, optimizely.ErrReplayFailed):
+			replay.Failed++
+		default:
+			renderDeadLetterError(err, w, r)
+			return
+		}
+	}
+	render.JSON(w, r, replay)
+}
+
+// ReplayDeadLetter dispatches a dead-lettered batch of the SDK key once and removes it when it is accepted
+func (a Admin) ReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if a.DeadLetters == nil {
+		RenderError(ErrDeadLettersDisabled, http.StatusNotFound, w, r)
+		return
+	}
+
+	if err := a.DeadLetters.ReplayDeadLetter(chi.URLParam(r, "sdkKey"), chi.URLParam(r, "batchID")); err != nil {
+		renderDeadLetterError(err, w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PurgeDeadLetters deletes every dead-lettered batch of the SDK key
+func (a Admin) PurgeDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if a.DeadLetters == nil {
+		RenderError(ErrDeadLettersDisabled, http.StatusNotFound, w, r)
+		return
+	}
+
+	purged, err := a.DeadLetters.PurgeDeadLetters(chi.URLParam(r, "sdkKey"))
+	if err != nil {
+		renderDeadLetterError(err, w, r)
+		return
+	}
+	render.JSON(w, r, DeadLetterPurge{Purged: purged})
+}
+
+// RemoveDeadLetter deletes a dead-lettered batch of the SDK key
+func (a Admin) RemoveDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if a.DeadLetters == nil {
+		RenderError(ErrDeadLettersDisabled, http.StatusNotFound, w, r)
+		return
+	}
+
+	if err := a.DeadLetters.RemoveDeadLetter(chi.URLParam(r, "sdkKey"), chi.URLParam(r, "batchID")); err != nil {
+		renderDeadLetterError(err, w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func renderDeadLetterError(err error, w http.ResponseWriter, r *http.Request) {
+	switch {
+	case errors.Is(err, deadletter.ErrNotFound):
+		RenderError(err, http.StatusNotFound, w, r)
+	case errors.Is(err, optimizely.ErrReplayFailed):
+		RenderError(err, http.StatusBadGateway, w, r)
+	default:
+		RenderError(err, http.StatusInternalServerError, w, r)
+	}
+}
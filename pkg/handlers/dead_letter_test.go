@@ -0,0 +1,223 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package handlers //
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/agent/pkg/optimizely"
+	"github.com/optimizely/agent/plugins/deadletter"
+)
+
+type mockDeadLetterManager struct {
+	batches   map[string]deadletter.Batch
+	order     []string
+	replayErr map[string]error
+	err       error
+}
+
+func (m *mockDeadLetterManager) ListDeadLetters(sdkKey string) ([]deadletter.Batch, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var batches []deadletter.Batch
+	for _, id := range m.order {
+		if batch, ok := m.batches[id]; ok {
+			batches = append(batches, batch)
+		}
+	}
+	return batches, nil
+}
+
+func (m *mockDeadLetterManager) GetDeadLetter(sdkKey, id string) (deadletter.Batch, error) {
+	batch, ok := m.batches[id]
+	if !ok {
+		return batch, deadletter.ErrNotFound
+	}
+	return batch, nil
+}
+
+func (m *mockDeadLetterManager) ReplayDeadLetter(sdkKey, id string) error {
+	if _, ok := m.batches[id]; !ok {
+		return deadletter.ErrNotFound
+	}
+	if err := m.replayErr[id]; err != nil {
+		return err
+	}
+	delete(m.batches, id)
+	return nil
+}
+
+func (m *mockDeadLetterManager) RemoveDeadLetter(sdkKey, id string) error {
+	if _, ok := m.batches[id]; !ok {
+		return deadletter.ErrNotFound
+	}
+	delete(m.batches, id)
+	return nil
+}
+
+func (m *mockDeadLetterManager) PurgeDeadLetters(sdkKey string) (int, error) {
+	purged := len(m.batches)
+	m.batches = map[string]deadletter.Batch{}
+	return purged, nil
+}
+
+type DeadLetterTestSuite struct {
+	suite.Suite
+	manager *mockDeadLetterManager
+	admin   *Admin
+	mux     *chi.Mux
+}
+
+func (suite *DeadLetterTestSuite) SetupTest() {
+	suite.manager = &mockDeadLetterManager{
+		batches: map[string]deadletter.Batch{
+			"1": {
+				ID:       "1",
+				EndPoint: "https://logx.optimizely.com/v1/events",
+				Event: event.Batch{Visitors: []event.Visitor{
+					{VisitorID: "user1", Snapshots: []event.Snapshot{{Events: []event.SnapshotEvent{{Key: "a"}, {Key: "b"}}}}},
+					{VisitorID: "user2", Snapshots: []event.Snapshot{{Events: []event.SnapshotEvent{{Key: "c"}}}}},
+				}},
+				Error:     "event dispatch failed",
+				Attempts:  3,
+				Timestamp: time.Unix(100, 0).UTC(),
+			},
+			"2": {ID: "2", Attempts: 1, Timestamp: time.Unix(200, 0).UTC()},
+		},
+		order:     []string{"1", "2"},
+		replayErr: map[string]error{},
+	}
+	suite.admin = NewAdmin(testConfig)
+	suite.admin.DeadLetters = suite.manager
+
+	mux := chi.NewMux()
+	mux.Get("/dead-letters/{sdkKey}", suite.admin.ListDeadLetters)
+	mux.Delete("/dead-letters/{sdkKey}", suite.admin.PurgeDeadLetters)
+	mux.Post("/dead-letters/{sdkKey}/replay", suite.admin.ReplayDeadLetters)
+	mux.Get("/dead-letters/{sdkKey}/{batchID}", suite.admin.GetDeadLetter)
+	mux.Delete("/dead-letters/{sdkKey}/{batchID}", suite.admin.RemoveDeadLetter)
+	mux.Post("/dead-letters/{sdkKey}/{batchID}/replay", suite.admin.ReplayDeadLetter)
+	suite.mux = mux
+}
+
+func (suite *DeadLetterTestSuite) serve(method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	rec := httptest.NewRecorder()
+	suite.mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func (suite *DeadLetterTestSuite) assertError(rec *httptest.ResponseRecorder, status int, message string) {
+	suite.Equal(status, rec.Code)
+	var actual ErrorResponse
+	suite.NoError(json.Unmarshal(rec.Body.Bytes(), &actual))
+	suite.Equal(message, actual.Error)
+}
+
+func (suite *DeadLetterTestSuite) TestList() {
+	rec := suite.serve("GET", "/dead-letters/sdkKey")
+	suite.Equal(http.StatusOK, rec.Code)
+
+	var actual []DeadLetterSummary
+	suite.NoError(json.Unmarshal(rec.Body.Bytes(), &actual))
+	suite.Equal([]DeadLetterSummary{
+		{ID: "1", EndPoint: "https://logx.optimizely.com/v1/events", Error: "event dispatch failed", Attempts: 3, Timestamp: time.Unix(100, 0).UTC(), Events: 3},
+		{ID: "2", Attempts: 1, Timestamp: time.Unix(200, 0).UTC()},
+	}, actual)
+}
+
+func (suite *DeadLetterTestSuite) TestListError() {
+	suite.manager.err = errors.New("unavailable")
+	suite.assertError(suite.serve("GET", "/dead-letters/sdkKey"), http.StatusInternalServerError, "unavailable")
+}
+
+func (suite *DeadLetterTestSuite) TestGet() {
+	rec := suite.serve("GET", "/dead-letters/sdkKey/1")
+	suite.Equal(http.StatusOK, rec.Code)
+
+	var actual deadletter.Batch
+	suite.NoError(json.Unmarshal(rec.Body.Bytes(), &actual))
+	suite.Equal(suite.manager.batches["1"], actual)
+
+	suite.assertError(suite.serve("GET", "/dead-letters/sdkKey/3"), http.StatusNotFound, "dead-lettered batch not found")
+}
+
+func (suite *DeadLetterTestSuite) TestReplayAll() {
+	suite.manager.replayErr["2"] = fmt.Errorf("%w: timeout", optimizely.ErrReplayFailed)
+	rec := suite.serve("POST", "/dead-letters/sdkKey/replay")
+	suite.Equal(http.StatusOK, rec.Code)
+
+	var actual DeadLetterReplay
+	suite.NoError(json.Unmarshal(rec.Body.Bytes(), &actual))
+	suite.Equal(DeadLetterReplay{Replayed: 1, Failed: 1}, actual)
+	suite.NotContains(suite.manager.batches, "1")
+	suite.Contains(suite.manager.batches, "2")
+}
+
+func (suite *DeadLetterTestSuite) TestReplayOne() {
+	rec := suite.serve("POST", "/dead-letters/sdkKey/1/replay")
+	suite.Equal(http.StatusNoContent, rec.Code)
+	suite.NotContains(suite.manager.batches, "1")
+
+	suite.manager.replayErr["2"] = fmt.Errorf("%w: timeout", optimizely.ErrReplayFailed)
+	suite.assertError(suite.serve("POST", "/dead-letters/sdkKey/2/replay"), http.StatusBadGateway, "replay failed: timeout")
+	suite.assertError(suite.serve("POST", "/dead-letters/sdkKey/1/replay"), http.StatusNotFound, "dead-lettered batch not found")
+}
+
+func (suite *DeadLetterTestSuite) TestRemove() {
+	rec := suite.serve("DELETE", "/dead-letters/sdkKey/1")
+	suite.Equal(http.StatusNoContent, rec.Code)
+	suite.NotContains(suite.manager.batches, "1")
+
+	suite.assertError(suite.serve("DELETE", "/dead-letters/sdkKey/1"), http.StatusNotFound, "dead-lettered batch not found")
+}
+
+func (suite *DeadLetterTestSuite) TestPurge() {
+	rec := suite.serve("DELETE", "/dead-letters/sdkKey")
+	suite.Equal(http.StatusOK, rec.Code)
+
+	var actual DeadLetterPurge
+	suite.NoError(json.Unmarshal(rec.Body.Bytes(), &actual))
+	suite.Equal(2, actual.Purged)
+	suite.Empty(suite.manager.batches)
+}
+
+func (suite *DeadLetterTestSuite) TestDisabled() {
+	suite.admin.DeadLetters = nil
+	mux := chi.NewMux()
+	mux.Get("/dead-letters/{sdkKey}", suite.admin.ListDeadLetters)
+	req := httptest.NewRequest("GET", "/dead-letters/sdkKey", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	suite.assertError(rec, http.StatusNotFound, "dead-letter store is not configured")
+}
+
+func TestDeadLetterTestSuite(t *testing.T) {
+	suite.Run(t, new(DeadLetterTestSuite))
+}
@@ -18,13 +18,19 @@
 package handlers
 
 import (
+	"crypto/sha256"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
 
 	"github.com/go-chi/render"
+	"github.com/rs/zerolog"
 
 	"github.com/optimizely/agent/pkg/middleware"
+	"github.com/optimizely/agent/pkg/optimizely"
 	"github.com/optimizely/go-sdk/v2/pkg/client"
 	"github.com/optimizely/go-sdk/v2/pkg/config"
 	"github.com/optimizely/go-sdk/v2/pkg/decide"
@@ -58,6 +64,16 @@ type DecideOut struct {
 	IsEveryoneElseVariation bool                   `json:"isEveryoneElseVariation"`
 }
 
+// NewDecideOut builds the DecideOut response for a decision, using the feature map
+// of the current OptimizelyConfig to flag "Everyone Else" rollout variations
+func NewDecideOut(d client.OptimizelyDecision, featureMap map[string]config.OptimizelyFeature) DecideOut {
+	return DecideOut{
+		OptimizelyDecision:      d,
+		Variables:               d.Variables.ToMap(),
+		IsEveryoneElseVariation: isEveryoneElseVariation(featureMap[d.FlagKey].DeliveryRules, d.RuleKey),
+	}
+}
+
 // Decide makes feature decisions for the selected query parameters
 func Decide(w http.ResponseWriter, r *http.Request) {
 	optlyClient, err := middleware.GetOptlyClient(r)
@@ -79,14 +95,100 @@ func Decide(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	optimizelyUserContext := optlyClient.WithTraceContext(r.Context()).CreateUserContext(db.UserID, db.UserAttributes)
+	keys := []string{}
+	if err := r.ParseForm(); err == nil {
+		keys = r.Form["keys"]
+	}
+
+	renderDecisions(w, r, optlyClient, logger, db, keys, decideOptions)
+}
+
+// DecideGet makes feature decisions for the user context given in the query string. Attributes are passed as
+// userAttributes[name]=value and typed with CoerceType. When the decisions can be cached, the response carries a
+// weak ETag derived from the SDK key, datafile revision, user inputs and overrides of the user, and a request
+// matching If-None-Match is answered with 304 without deciding. Decisions can only be cached when deciding again
+// would neither send an impression nor read or save a user profile, that is with the DISABLE_DECISION_EVENT
+// option, and with IGNORE_USER_PROFILE_SERVICE when a user profile service is configured. ODP segments are not
+// fetched by this endpoint, so they do not affect the decisions.
+func DecideGet(w http.ResponseWriter, r *http.Request) {
+	optlyClient, err := middleware.GetOptlyClient(r)
+	logger := middleware.GetLogger(r)
+	if err != nil {
+		RenderError(err, http.StatusInternalServerError, w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	db := DecideBody{
+		UserID:         query.Get("userId"),
+		UserAttributes: getQueryAttributes(query),
+		DecideOptions:  query["decideOptions"],
+	}
+	if db.UserID == "" {
+		RenderError(ErrEmptyUserIDQuery, http.StatusBadRequest, w, r)
+		return
+	}
+
+	decideOptions, err := decide.TranslateOptions(db.DecideOptions)
+	if err != nil {
+		RenderError(err, http.StatusBadRequest, w, r)
+		return
+	}
+
+	revision := ""
+	if cfg := optlyClient.GetOptimizelyConfig(); cfg != nil {
+		revision = cfg.Revision
+	}
+
+	keys := query["keys"]
+	// Decisions depend on the SDK key and may only be visible to the client that requested them
+	w.Header().Add("Vary", middleware.OptlySDKHeader)
+	w.Header().Add("Vary", "Authorization")
+	if !cacheableDecisions(optlyClient, decideOptions) {
+		w.Header().Set("Cache-Control", "no-store")
+		renderDecisions(w, r, optlyClient, logger, db, keys, decideOptions)
+		return
+	}
+
+	etag := decideETag(r.Header.Get(middleware.OptlySDKHeader), revision, query, userOverrides(optlyClient, db.UserID))
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	renderDecisions(w, r, optlyClient, logger, db, keys, decideOptions)
+}
+
+// renderDecisions decides the given flag keys, or every flag when no key is given, for the user context
+func renderDecisions(w http.ResponseWriter, r *http.Request, optlyClient *optimizely.OptlyClient, logger *zerolog.Logger,
+	db DecideBody, keys []string, decideOptions []decide.OptimizelyDecideOptions) {
+	optlyClient.WithTraceContext(r.Context())
+	decideOuts, err := decideUser(r, optlyClient, logger, db, keys, decideOptions)
+	if err != nil {
+		RenderError(err, http.StatusInternalServerError, w, r)
+		return
+	}
+
+	if len(keys) == 1 {
+		render.JSON(w, r, decideOuts[0])
+		return
+	}
+	render.JSON(w, r, decideOuts)
+}
+
+// decideUser returns the decisions of the given flag keys, or of every flag when no key is given, for the user context.
+// The caller is expected to have set the trace context of the client.
+func decideUser(r *http.Request, optlyClient *optimizely.OptlyClient, logger *zerolog.Logger,
+	db DecideBody, keys []string, decideOptions []decide.OptimizelyDecideOptions) ([]DecideOut, error) {
+	defer optlyClient.BindRequest(db.UserID, r.Header.Get(middleware.OptlyRequestHeader))()
+	optimizelyUserContext := optlyClient.CreateUserContext(db.UserID, db.UserAttributes)
 
 	if db.FetchSegments {
 		success := optimizelyUserContext.FetchQualifiedSegments(db.FetchSegmentsOptions)
 		if !success {
-			err := errors.New("failed to fetch qualified segments")
-			RenderError(err, http.StatusInternalServerError, w, r)
-			return
+			return nil, errors.New("failed to fetch qualified segments")
 		}
 	}
 
@@ -97,61 +199,35 @@ func Decide(w http.ResponseWriter, r *http.Request) {
 		optimizelyUserContext.SetForcedDecision(context, forcedDecision)
 	}
 
-	keys := []string{}
-	if err := r.ParseForm(); err == nil {
-		keys = r.Form["keys"]
-	}
-
 	featureMap := make(map[string]config.OptimizelyFeature)
 	cfg := optlyClient.GetOptimizelyConfig()
 	if cfg != nil {
 		featureMap = cfg.FeaturesMap
 	}
 
+	var decides map[string]client.OptimizelyDecision
 	switch len(keys) {
 	case 0:
 		// Decide All
-		decides := optimizelyUserContext.DecideAll(decideOptions)
-		decideOuts := []DecideOut{}
-		for _, d := range decides {
-			decideOut := DecideOut{
-				OptimizelyDecision:      d,
-				Variables:               d.Variables.ToMap(),
-				IsEveryoneElseVariation: isEveryoneElseVariation(featureMap[d.FlagKey].DeliveryRules, d.RuleKey),
-			}
-			decideOuts = append(decideOuts, decideOut)
-			logger.Debug().Msgf("Feature %q is enabled for user %s? %t", d.FlagKey, d.UserContext.UserID, d.Enabled)
-		}
-		render.JSON(w, r, decideOuts)
-		return
+		decides = optimizelyUserContext.DecideAll(decideOptions)
 	case 1:
 		// Decide single key
 		key := keys[0]
 		logger.Debug().Str("featureKey", key).Msg("fetching feature decision")
 		d := optimizelyUserContext.Decide(key, decideOptions)
-		decideOut := DecideOut{
-			OptimizelyDecision:      d,
-			Variables:               d.Variables.ToMap(),
-			IsEveryoneElseVariation: isEveryoneElseVariation(featureMap[d.FlagKey].DeliveryRules, d.RuleKey),
-		}
-		render.JSON(w, r, decideOut)
-		return
+		return []DecideOut{NewDecideOut(d, featureMap)}, nil
 	default:
 		// Decide for multiple keys
-		decides := optimizelyUserContext.DecideForKeys(keys, decideOptions)
-		decideOuts := []DecideOut{}
-		for _, d := range decides {
-			decideOut := DecideOut{
-				OptimizelyDecision:      d,
-				Variables:               d.Variables.ToMap(),
-				IsEveryoneElseVariation: isEveryoneElseVariation(featureMap[d.FlagKey].DeliveryRules, d.RuleKey),
-			}
-			decideOuts = append(decideOuts, decideOut)
-			logger.Debug().Msgf("Feature %q is enabled for user %s? %t", d.FlagKey, d.UserContext.UserID, d.Enabled)
-		}
-		render.JSON(w, r, decideOuts)
-		return
+		decides = optimizelyUserContext.DecideForKeys(keys, decideOptions)
 	}
+
+	decideOuts := []DecideOut{}
+	for _, d := range decides {
+		decideOut := NewDecideOut(d, featureMap)
+		decideOuts = append(decideOuts, decideOut)
+		logger.Debug().Msgf("Feature %q is enabled for user %s? %t", d.FlagKey, d.UserContext.UserID, d.Enabled)
+	}
+	return decideOuts, nil
 }
 
 func getUserContextWithOptions(r *http.Request) (DecideBody, error) {
@@ -168,6 +244,90 @@ func getUserContextWithOptions(r *http.Request) (DecideBody, error) {
 	return body, nil
 }
 
+// getQueryAttributes returns the userAttributes[name]=value query parameters as typed attributes
+func getQueryAttributes(query url.Values) map[string]interface{} {
+	attributes := map[string]interface{}{}
+	for key, values := range query {
+		if !strings.HasPrefix(key, "userAttributes[") || !strings.HasSuffix(key, "]") || len(values) == 0 {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "userAttributes["), "]")
+		attributes[name] = middleware.CoerceType(values[0])
+	}
+	return attributes
+}
+
+// cacheableDecisions reports whether deciding again for the same inputs has no side effect and returns the
+// same decisions: no impression is sent, and no user profile, which may change between requests, is used
+func cacheableDecisions(optlyClient *optimizely.OptlyClient, decideOptions []decide.OptimizelyDecideOptions) bool {
+	disableDecisionEvent, ignoreUserProfileService := false, false
+	for _, option := range decideOptions {
+		switch option {
+		case decide.DisableDecisionEvent:
+			disableDecisionEvent = true
+		case decide.IgnoreUserProfileService:
+			ignoreUserProfileService = true
+		}
+	}
+	return disableDecisionEvent && (ignoreUserProfileService || optlyClient.UserProfileService == nil)
+}
+
+// userOverrides returns the forced variations set for the user through the override endpoint
+func userOverrides(optlyClient *optimizely.OptlyClient, userID string) []string {
+	cfg := optlyClient.GetOptimizelyConfig()
+	if optlyClient.ForcedVariations == nil || cfg == nil {
+		return nil
+	}
+
+	overrides := []string{}
+	for experimentKey := range cfg.ExperimentsMap {
+		key := decision.ExperimentOverrideKey{ExperimentKey: experimentKey, UserID: userID}
+		if variationKey, ok := optlyClient.ForcedVariations.GetVariation(key); ok {
+			overrides = append(overrides, experimentKey+"="+variationKey)
+		}
+	}
+	sort.Strings(overrides)
+	return overrides
+}
+
+// decideETag hashes the inputs of a decision. The ETag is weak since the order of the decisions of
+// several flags is not stable.
+func decideETag(sdkKey, revision string, query url.Values, overrides []string) string {
+	inputs := []string{}
+	for key, values := range query {
+		switch {
+		case key == "keys" || key == "decideOptions":
+			sorted := append([]string{}, values...)
+			sort.Strings(sorted)
+			inputs = append(inputs, key+"="+strings.Join(sorted, ","))
+		case key == "userId" || strings.HasPrefix(key, "userAttributes["):
+			inputs = append(inputs, key+"="+values[0])
+		}
+	}
+	sort.Strings(inputs)
+	for _, override := range overrides {
+		inputs = append(inputs, "override:"+override)
+	}
+
+	hash := sha256.New()
+	for _, input := range append([]string{sdkKey, revision}, inputs...) {
+		hash.Write([]byte(input))
+		hash.Write([]byte{0})
+	}
+	return fmt.Sprintf(`W/"%x"`, hash.Sum(nil)[:16])
+}
+
+// etagMatches reports whether an If-None-Match header matches the ETag, using weak comparison
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
 func isEveryoneElseVariation(rules []config.OptimizelyExperiment, ruleKey string) bool {
 	for _, r := range rules {
 		if r.Key == ruleKey {
@@ -0,0 +1,145 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package handlers //
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decide"
+
+	"github.com/optimizely/agent/pkg/middleware"
+)
+
+// ErrEmptyBulkPayload is a constant error if no user context is given to the bulk decide API
+var ErrEmptyBulkPayload = errors.New("missing user contexts in request payload")
+
+// DecideBulkError is streamed in place of the decisions of a user context that can not be decided
+type DecideBulkError struct {
+	Index  int    `json:"index"`
+	UserID string `json:"userId,omitempty"`
+	Error  string `json:"error"`
+}
+
+type decideBulkJob struct {
+	index int
+	body  DecideBody
+}
+
+type decideBulkResult struct {
+	decideOuts []DecideOut
+	err        *DecideBulkError
+}
+
+// DecideBulk makes feature decisions for a list of user contexts, given as a JSON array or as newline delimited
+// JSON, with the flag keys of the query string. User contexts are decided by at most maxConcurrency workers and
+// the decisions are streamed as newline delimited DecideOut objects as soon as they are made, in no particular
+// order. A user context that can not be decided is answered with a DecideBulkError.
+func DecideBulk(maxConcurrency int) http.HandlerFunc {
+	if maxConcurrency <= 0 {
+		maxConcurrency = 1
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		optlyClient, err := middleware.GetOptlyClient(r)
+		logger := middleware.GetLogger(r)
+		if err != nil {
+			RenderError(err, http.StatusInternalServerError, w, r)
+			return
+		}
+
+		decoder, err := newBulkDecoder(r.Body)
+		if err != nil {
+			RenderError(ErrEmptyBulkPayload, http.StatusBadRequest, w, r)
+			return
+		}
+
+		keys := r.URL.Query()["keys"]
+		jobs := make(chan decideBulkJob)
+		results := make(chan decideBulkResult, maxConcurrency)
+
+		go func() {
+			defer close(jobs)
+			for {
+				var body DecideBody
+				index, err := decoder.next(&body)
+				if errors.Is(err, io.EOF) {
+					return
+				}
+				if err != nil {
+					logger.Err(err).Int("index", index).Msg("error parsing bulk decide request body")
+					results <- decideBulkResult{err: &DecideBulkError{Index: index, Error: "error parsing request body"}}
+					return
+				}
+
+				select {
+				case jobs <- decideBulkJob{index: index, body: body}:
+				case <-r.Context().Done():
+					return
+				}
+			}
+		}()
+
+		// The trace context is set on the shared client once, before the workers start
+		optlyClient.WithTraceContext(r.Context())
+		var wg sync.WaitGroup
+		for i := 0; i < maxConcurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for job := range jobs {
+					decideOptions, err := decide.TranslateOptions(job.body.DecideOptions)
+					if err == nil && job.body.UserID == "" {
+						err = ErrEmptyUserID
+					}
+
+					var decideOuts []DecideOut
+					if err == nil {
+						decideOuts, err = decideUser(r, optlyClient, logger, job.body, keys, decideOptions)
+					}
+
+					if err != nil {
+						results <- decideBulkResult{err: &DecideBulkError{Index: job.index, UserID: job.body.UserID, Error: err.Error()}}
+						continue
+					}
+					results <- decideBulkResult{decideOuts: decideOuts}
+				}
+			}()
+		}
+
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		writer := newNDJSONWriter(w)
+		for result := range results {
+			if result.err != nil {
+				writer.write(result.err)
+			}
+			for _, decideOut := range result.decideOuts {
+				writer.write(decideOut)
+			}
+			if len(results) == 0 {
+				writer.flush()
+			}
+		}
+	}
+}
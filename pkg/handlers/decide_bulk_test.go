@@ -0,0 +1,217 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package handlers //
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/agent/pkg/middleware"
+	"github.com/optimizely/agent/pkg/optimizely"
+	"github.com/optimizely/agent/pkg/optimizely/optimizelytest"
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+)
+
+type DecideBulkTestSuite struct {
+	suite.Suite
+	oc  *optimizely.OptlyClient
+	tc  *optimizelytest.TestClient
+	mux *chi.Mux
+}
+
+func (suite *DecideBulkTestSuite) ClientCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), middleware.OptlyClientKey, suite.oc)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (suite *DecideBulkTestSuite) SetupTest() {
+	testClient := optimizelytest.NewClient()
+	suite.tc = testClient
+	suite.oc = &optimizely.OptlyClient{
+		OptimizelyClient: testClient.OptimizelyClient,
+		ForcedVariations: testClient.ForcedVariations,
+	}
+
+	suite.mux = chi.NewMux()
+	suite.mux.With(suite.ClientCtx).Post("/decide/bulk", DecideBulk(4))
+
+	feature := entities.Feature{Key: "one"}
+	suite.tc.AddFeatureTest(feature)
+	suite.tc.AddFeatureRollout(entities.Feature{Key: "two"})
+	suite.tc.AddFlagVariation(feature, entities.Variation{Key: "4", FeatureEnabled: true})
+}
+
+// decodeLines splits the NDJSON response into decisions and errors
+func (suite *DecideBulkTestSuite) decodeLines(body io.Reader) ([]DecideOut, []DecideBulkError) {
+	decideOuts := []DecideOut{}
+	errs := []DecideBulkError{}
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if bytes.Contains(line, []byte(`"error"`)) {
+			var bulkErr DecideBulkError
+			suite.NoError(json.Unmarshal(line, &bulkErr))
+			errs = append(errs, bulkErr)
+			continue
+		}
+		var decideOut DecideOut
+		suite.NoError(json.Unmarshal(line, &decideOut))
+		decideOuts = append(decideOuts, decideOut)
+	}
+	suite.NoError(scanner.Err())
+
+	sort.Slice(decideOuts, func(i, j int) bool {
+		if decideOuts[i].UserContext.UserID == decideOuts[j].UserContext.UserID {
+			return decideOuts[i].FlagKey < decideOuts[j].FlagKey
+		}
+		return decideOuts[i].UserContext.UserID < decideOuts[j].UserContext.UserID
+	})
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Index < errs[j].Index })
+	return decideOuts, errs
+}
+
+func (suite *DecideBulkTestSuite) post(path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	suite.mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func (suite *DecideBulkTestSuite) TestJSONArray() {
+	rec := suite.post("/decide/bulk?keys=one", `[
+		{"userId": "user1", "userAttributes": {"plan": "gold"}, "decideOptions": ["DISABLE_DECISION_EVENT"]},
+		{"userId": "user2", "forcedDecisions": [{"flagKey": "one", "ruleKey": "1", "variationKey": "4"}]},
+		{"userId": "user3", "decideOptions": ["INCLUDE_REASONS"]}
+	]`)
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Equal("application/x-ndjson", rec.Header().Get("Content-Type"))
+
+	decideOuts, errs := suite.decodeLines(rec.Body)
+	suite.Empty(errs)
+	suite.Require().Len(decideOuts, 3)
+
+	suite.Equal("user1", decideOuts[0].UserContext.UserID)
+	suite.Equal(map[string]interface{}{"plan": "gold"}, decideOuts[0].UserContext.Attributes)
+	suite.Equal("one", decideOuts[0].FlagKey)
+	suite.Equal("2", decideOuts[0].VariationKey)
+
+	// Forced decisions only apply to the user context they are given with
+	suite.Equal("user2", decideOuts[1].UserContext.UserID)
+	suite.Equal("4", decideOuts[1].VariationKey)
+
+	suite.Equal("user3", decideOuts[2].UserContext.UserID)
+	suite.NotEmpty(decideOuts[2].Reasons)
+
+	// Decision events are sent for user2 and user3 only
+	suite.Equal(2, len(suite.tc.GetProcessedEvents()))
+}
+
+func (suite *DecideBulkTestSuite) TestNDJSONAllFlags() {
+	rec := suite.post("/decide/bulk", `{"userId": "user1", "decideOptions": ["DISABLE_DECISION_EVENT"]}
+{"userId": "user2", "decideOptions": ["DISABLE_DECISION_EVENT"]}
+`)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	decideOuts, errs := suite.decodeLines(rec.Body)
+	suite.Empty(errs)
+	suite.Require().Len(decideOuts, 4)
+	suite.Equal([]string{"user1", "user1", "user2", "user2"}, []string{
+		decideOuts[0].UserContext.UserID, decideOuts[1].UserContext.UserID,
+		decideOuts[2].UserContext.UserID, decideOuts[3].UserContext.UserID,
+	})
+	suite.Equal([]string{"one", "two"}, []string{decideOuts[0].FlagKey, decideOuts[1].FlagKey})
+}
+
+func (suite *DecideBulkTestSuite) TestInvalidUserContexts() {
+	rec := suite.post("/decide/bulk?keys=one", `[
+		{"userId": "user1"},
+		{"userAttributes": {"plan": "gold"}},
+		{"userId": "user3", "decideOptions": ["INVALID"]}
+	]`)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	decideOuts, errs := suite.decodeLines(rec.Body)
+	suite.Len(decideOuts, 1)
+	suite.Equal([]DecideBulkError{
+		{Index: 1, Error: `missing "userId" in request payload`},
+		{Index: 2, UserID: "user3", Error: "invalid option: INVALID"},
+	}, errs)
+}
+
+func (suite *DecideBulkTestSuite) TestMalformedPayload() {
+	rec := suite.post("/decide/bulk?keys=one", `{"userId": "user1"}
+{"userId": `)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	decideOuts, errs := suite.decodeLines(rec.Body)
+	suite.Len(decideOuts, 1)
+	suite.Equal([]DecideBulkError{{Index: 1, Error: "error parsing request body"}}, errs)
+}
+
+func (suite *DecideBulkTestSuite) TestEmptyPayload() {
+	rec := suite.post("/decide/bulk", "  \n")
+	assertError(suite.T(), rec, "missing user contexts in request payload", http.StatusBadRequest)
+
+	// An empty list has no decision
+	rec = suite.post("/decide/bulk", "[]")
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Empty(rec.Body.String())
+}
+
+func (suite *DecideBulkTestSuite) TestStreamsOverHTTP() {
+	server := httptest.NewServer(suite.mux)
+	defer server.Close()
+
+	users := 2000
+	body, writer := io.Pipe()
+	go func() {
+		encoder := json.NewEncoder(writer)
+		for i := 0; i < users; i++ {
+			_ = encoder.Encode(DecideBody{UserID: fmt.Sprintf("user%d", i), DecideOptions: []string{"DISABLE_DECISION_EVENT"}})
+		}
+		_ = writer.Close()
+	}()
+
+	resp, err := http.Post(server.URL+"/decide/bulk?keys=one", "application/x-ndjson", body)
+	suite.Require().NoError(err)
+	defer resp.Body.Close()
+	suite.Equal(http.StatusOK, resp.StatusCode)
+
+	decideOuts, errs := suite.decodeLines(resp.Body)
+	suite.Empty(errs)
+	suite.Len(decideOuts, users)
+}
+
+func TestDecideBulkTestSuite(t *testing.T) {
+	suite.Run(t, new(DecideBulkTestSuite))
+}
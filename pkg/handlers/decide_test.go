@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
@@ -35,6 +36,7 @@ import (
 	"github.com/optimizely/agent/pkg/optimizely/optimizelytest"
 	"github.com/optimizely/go-sdk/v2/pkg/client"
 	"github.com/optimizely/go-sdk/v2/pkg/decide"
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
 	"github.com/optimizely/go-sdk/v2/pkg/entities"
 	"github.com/optimizely/go-sdk/v2/pkg/odp/segment"
 )
@@ -86,6 +88,7 @@ func (suite *DecideTestSuite) SetupTest() {
 
 	mux := chi.NewMux()
 	mux.With(suite.ClientCtx).Post("/decide", Decide)
+	mux.With(suite.ClientCtx).Get("/decide", DecideGet)
 
 	db := DecideBody{
 		UserID:         "testUser",
@@ -850,6 +853,133 @@ func (suite *DecideTestSuite) TestFetchQualifiedSegmentsFailure() {
 	suite.assertError(rec, `failed to fetch qualified segments`, http.StatusInternalServerError)
 }
 
+func (suite *DecideTestSuite) getDecide(query url.Values, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/decide?"+query.Encode(), nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	suite.mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func (suite *DecideTestSuite) TestDecideGet() {
+	feature := entities.Feature{Key: "one"}
+	suite.tc.AddFeatureTest(feature)
+
+	rec := suite.getDecide(url.Values{
+		"userId":                []string{"testUser"},
+		"keys":                  []string{"one"},
+		"decideOptions":         []string{"DISABLE_DECISION_EVENT"},
+		"userAttributes[age]":   []string{"30"},
+		"userAttributes[vip]":   []string{"true"},
+		"userAttributes[name]":  []string{"bob"},
+		"userAttributes[zip]":   []string{`"02134"`},
+		"userAttributes[score]": []string{"1.5"},
+	}, nil)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	var actual DecideOut
+	err := json.Unmarshal(rec.Body.Bytes(), &actual)
+	suite.NoError(err)
+
+	expected := DecideOut{
+		OptimizelyDecision: client.OptimizelyDecision{
+			UserContext: client.OptimizelyUserContext{UserID: "testUser", Attributes: map[string]interface{}{
+				"age":   float64(30),
+				"vip":   true,
+				"name":  "bob",
+				"zip":   "02134",
+				"score": 1.5,
+			}},
+			FlagKey:      "one",
+			RuleKey:      "1",
+			Enabled:      true,
+			VariationKey: "2",
+			Reasons:      []string{},
+		},
+	}
+
+	suite.Equal(0, len(suite.tc.GetProcessedEvents()))
+	suite.Equal(expected, actual)
+	suite.Regexp(`^W/"[0-9a-f]{32}"$`, rec.Header().Get("ETag"))
+	suite.Equal([]string{middleware.OptlySDKHeader, "Authorization"}, rec.Header().Values("Vary"))
+	suite.Equal("private, no-cache", rec.Header().Get("Cache-Control"))
+}
+
+func (suite *DecideTestSuite) TestDecideGetAllFlags() {
+	suite.tc.AddFeatureRollout(entities.Feature{Key: "featureA"})
+	suite.tc.AddFeatureTest(entities.Feature{Key: "featureB"})
+
+	rec := suite.getDecide(url.Values{"userId": []string{"testUser"}, "decideOptions": []string{"DISABLE_DECISION_EVENT"}}, nil)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	var actual []DecideOut
+	err := json.Unmarshal(rec.Body.Bytes(), &actual)
+	suite.NoError(err)
+	suite.Len(actual, 2)
+}
+
+func (suite *DecideTestSuite) TestDecideGetNotModified() {
+	suite.tc.AddFeatureTest(entities.Feature{Key: "one"})
+	query := url.Values{"userId": []string{"testUser"}, "keys": []string{"one"}, "decideOptions": []string{"DISABLE_DECISION_EVENT"}}
+
+	rec := suite.getDecide(query, nil)
+	suite.Equal(http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+
+	rec = suite.getDecide(query, http.Header{"If-None-Match": []string{`"other", ` + etag}})
+	suite.Equal(http.StatusNotModified, rec.Code)
+	suite.Empty(rec.Body.String())
+	suite.Equal(etag, rec.Header().Get("ETag"))
+
+	rec = suite.getDecide(query, http.Header{"If-None-Match": []string{`"other"`}})
+	suite.Equal(http.StatusOK, rec.Code)
+
+	// An override of the user changes the decision, and so the ETag
+	suite.oc.ForcedVariations.SetVariation(decision.ExperimentOverrideKey{ExperimentKey: "1", UserID: "testUser"}, "2")
+	rec = suite.getDecide(query, http.Header{"If-None-Match": []string{etag}})
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.NotEqual(etag, rec.Header().Get("ETag"))
+	suite.Equal(0, len(suite.tc.GetProcessedEvents()))
+}
+
+func (suite *DecideTestSuite) TestDecideGetWithImpressionIsNotCached() {
+	suite.tc.AddFeatureTest(entities.Feature{Key: "one"})
+	query := url.Values{"userId": []string{"testUser"}, "keys": []string{"one"}}
+
+	for i := 1; i <= 2; i++ {
+		rec := suite.getDecide(query, http.Header{"If-None-Match": []string{"*"}})
+		suite.Equal(http.StatusOK, rec.Code)
+		suite.Empty(rec.Header().Get("ETag"))
+		suite.Equal("no-store", rec.Header().Get("Cache-Control"))
+		suite.Equal(i, len(suite.tc.GetProcessedEvents()))
+	}
+}
+
+func (suite *DecideTestSuite) TestDecideGetWithUserProfileService() {
+	suite.tc.AddFeatureTest(entities.Feature{Key: "one"})
+	suite.oc.UserProfileService = noopUserProfileService{}
+
+	rec := suite.getDecide(url.Values{"userId": []string{"testUser"}, "decideOptions": []string{"DISABLE_DECISION_EVENT"}}, nil)
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Empty(rec.Header().Get("ETag"))
+
+	rec = suite.getDecide(url.Values{"userId": []string{"testUser"}, "decideOptions": []string{"DISABLE_DECISION_EVENT", "IGNORE_USER_PROFILE_SERVICE"}}, nil)
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.NotEmpty(rec.Header().Get("ETag"))
+}
+
+func (suite *DecideTestSuite) TestDecideGetMissingUserID() {
+	rec := suite.getDecide(url.Values{"keys": []string{"one"}}, nil)
+	suite.assertError(rec, `missing "userId" query parameter`, http.StatusBadRequest)
+}
+
+func (suite *DecideTestSuite) TestDecideGetInvalidOption() {
+	rec := suite.getDecide(url.Values{"userId": []string{"testUser"}, "decideOptions": []string{"INVALID"}}, nil)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
 func TestDecideTestSuite(t *testing.T) {
 	suite.Run(t, new(DecideTestSuite))
 }
@@ -899,3 +1029,76 @@ func TestTranslateOptions(t *testing.T) {
 	assert.Equal(t, "invalid option: invalid", err.Error())
 	assert.Equal(t, []decide.OptimizelyDecideOptions{}, decideOptions)
 }
+
+func TestGetQueryAttributes(t *testing.T) {
+	query := url.Values{
+		"userId":             []string{"user"},
+		"userAttributes[a]":  []string{"1", "2"},
+		"userAttributes[b]":  []string{"false"},
+		"userAttributes[c]":  []string{""},
+		"userAttributes[[d]": []string{"x"},
+		"userAttributes":     []string{"ignored"},
+	}
+
+	expected := map[string]interface{}{
+		"a":  int64(1),
+		"b":  false,
+		"c":  nil,
+		"[d": "x",
+	}
+	assert.Equal(t, expected, getQueryAttributes(query))
+}
+
+func TestDecideETag(t *testing.T) {
+	query := url.Values{
+		"userId":            []string{"user"},
+		"keys":              []string{"a", "b"},
+		"userAttributes[a]": []string{"1"},
+	}
+	etag := decideETag("sdkKey", "1", query, nil)
+
+	// Key order and unrelated parameters do not change the ETag
+	assert.Equal(t, etag, decideETag("sdkKey", "1", url.Values{
+		"userId":            []string{"user"},
+		"keys":              []string{"b", "a"},
+		"userAttributes[a]": []string{"1"},
+		"other":             []string{"value"},
+	}, nil))
+
+	assert.NotEqual(t, etag, decideETag("otherSdkKey", "1", query, nil))
+	assert.NotEqual(t, etag, decideETag("sdkKey", "2", query, nil))
+	assert.NotEqual(t, etag, decideETag("sdkKey", "1", url.Values{
+		"userId":            []string{"user"},
+		"keys":              []string{"a", "b"},
+		"userAttributes[a]": []string{"2"},
+	}, nil))
+	assert.NotEqual(t, etag, decideETag("sdkKey", "1", url.Values{
+		"userId":            []string{"other"},
+		"keys":              []string{"a", "b"},
+		"userAttributes[a]": []string{"1"},
+	}, nil))
+	assert.NotEqual(t, etag, decideETag("sdkKey", "1", url.Values{
+		"userId":            []string{"user"},
+		"keys":              []string{"a", "b"},
+		"userAttributes[a]": []string{"1"},
+		"decideOptions":     []string{"INCLUDE_REASONS"},
+	}, nil))
+	assert.NotEqual(t, etag, decideETag("sdkKey", "1", query, []string{"experiment=variation"}))
+}
+
+type noopUserProfileService struct{}
+
+func (noopUserProfileService) Lookup(string) decision.UserProfile {
+	return decision.UserProfile{}
+}
+
+func (noopUserProfileService) Save(decision.UserProfile) {}
+
+func TestETagMatches(t *testing.T) {
+	assert.True(t, etagMatches(`W/"abc"`, `W/"abc"`))
+	assert.True(t, etagMatches(`"abc"`, `W/"abc"`))
+	assert.True(t, etagMatches(`"xyz", W/"abc"`, `W/"abc"`))
+	assert.True(t, etagMatches(`*`, `W/"abc"`))
+	assert.False(t, etagMatches(``, `W/"abc"`))
+	assert.False(t, etagMatches(`W/"xyz"`, `W/"abc"`))
+}
@@ -71,12 +71,7 @@ func Lookup(w http.ResponseWriter, r *http.Request) {
 		UserID: body.UserID,
 	}
 	savedProfile := optlyClient.UserProfileService.Lookup(body.UserID)
-	// Converting to map
-	experimentBucketMap := map[string]interface{}{}
-	for k, v := range savedProfile.ExperimentBucketMap {
-		experimentBucketMap[k.ExperimentID] = map[string]interface{}{k.Field: v}
-	}
-	lookupResponse.ExperimentBucketMap = experimentBucketMap
+	lookupResponse.ExperimentBucketMap = experimentBucketMapOut(savedProfile)
 	logger.Info().Msgf("Looked up user profile for user %s", body.UserID)
 	render.JSON(w, r, lookupResponse)
 }
@@ -51,7 +51,9 @@ var types = map[notification.Type]string{
 	notification.ProjectConfigUpdate: string(notification.ProjectConfigUpdate),
 }
 
-func getFilter(filters []string) map[notification.Type]string {
+// NotificationFilter returns the notification types selected by the given filters.
+// Each filter may be a comma separated list, and no filters selects every supported type.
+func NotificationFilter(filters []string) map[notification.Type]string {
 	notificationsToAdd := make(map[notification.Type]string)
 	// Parse out the any filters that were added
 	if len(filters) == 0 {
@@ -104,7 +106,7 @@ func NotificationEventStreamHandler(notificationReceiverFn NotificationReceiverF
 		filters := r.Form["filter"]
 
 		// Parse out the any filters that were added
-		notificationsToAdd := getFilter(filters)
+		notificationsToAdd := NotificationFilter(filters)
 
 		// Listen to connection close and un-register messageChan
 		notify := r.Context().Done()
@@ -210,6 +212,15 @@ func DefaultNotificationReceiver(ctx context.Context) (<-chan syncer.Event, erro
 	return messageChan, nil
 }
 
+// NotificationReceiver returns the receiver of the notifications of this Agent, or of every Agent sharing
+// the pubsub when notification synchronization is enabled
+func NotificationReceiver(conf config.SyncConfig) NotificationReceiverFunc {
+	if conf.Notification.Enable {
+		return SyncedNotificationReceiver(conf)
+	}
+	return DefaultNotificationReceiver
+}
+
 func SyncedNotificationReceiver(conf config.SyncConfig) NotificationReceiverFunc {
 	return func(ctx context.Context) (<-chan syncer.Event, error) {
 		sdkKey, ok := ctx.Value(SDKKey).(string)
@@ -114,7 +114,7 @@ func (suite *NotificationTestSuite) TestFeatureTestFilter() {
 func (suite *NotificationTestSuite) TestFilter() {
 	filter := []string{"decision", "track"}
 
-	notifications := getFilter(filter)
+	notifications := NotificationFilter(filter)
 
 	suite.True(len(notifications) == 2)
 	suite.EqualValues(notification.Track, notifications["track"])
@@ -122,7 +122,7 @@ func (suite *NotificationTestSuite) TestFilter() {
 
 	filter = []string{"decision,track", "track"}
 
-	notifications = getFilter(filter)
+	notifications = NotificationFilter(filter)
 
 	suite.True(len(notifications) == 2)
 	suite.EqualValues(notification.Track, notifications["track"])
@@ -627,3 +627,46 @@ func (suite *NotificationTestSuite) TestSecureTokenParsingIntegration() {
 	response := rec.Body.String()
 	suite.Contains(response, `data: {"test":"event"}`, "Should receive the test event")
 }
+
+func TestNotificationReceiver(t *testing.T) {
+	defaultReceiver := reflect.ValueOf(DefaultNotificationReceiver).Pointer()
+
+	receiver := NotificationReceiver(config.SyncConfig{})
+	if reflect.ValueOf(receiver).Pointer() != defaultReceiver {
+		t.Error("expected the default notification receiver when synchronization is disabled")
+	}
+
+	receiver = NotificationReceiver(config.SyncConfig{Notification: config.FeatureSyncConfig{Enable: true, Default: "redis"}})
+	if reflect.ValueOf(receiver).Pointer() == defaultReceiver {
+		t.Error("expected the synced notification receiver when synchronization is enabled")
+	}
+}
+
+func TestMemoryNotificationReceiver(t *testing.T) {
+	conf := config.SyncConfig{
+		Notification: config.FeatureSyncConfig{
+			Enable:  true,
+			Default: "memory",
+		},
+	}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), SDKKey, "memory-sdk-key"))
+	defer cancel()
+
+	events, err := SyncedNotificationReceiver(conf)(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	nc, err := syncer.NewSyncedNotificationCenter(ctx, "memory-sdk-key", conf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := nc.Send(notification.Decision, map[string]interface{}{"userId": "user"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := syncer.Event{Type: notification.Decision, Message: map[string]interface{}{"userId": "user"}}
+	if event := <-events; !reflect.DeepEqual(event, expected) {
+		t.Errorf("SyncedNotificationReceiver() event = %v, want %v", event, expected)
+	}
+}
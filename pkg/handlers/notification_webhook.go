@@ -0,0 +1,299 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package handlers //
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	go_kit_metrics "github.com/go-kit/kit/metrics"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/optimizely/agent/config"
+	"github.com/optimizely/agent/pkg/metrics"
+	"github.com/optimizely/agent/pkg/syncer"
+)
+
+// Notification webhook metrics, counted in notifications
+const (
+	NotificationWebhookDeliveredMetric = "notification-webhook.delivered"
+	NotificationWebhookFailedMetric    = "notification-webhook.failed"
+	NotificationWebhookDroppedMetric   = "notification-webhook.dropped"
+)
+
+// NotificationWebhookPayload is the body posted to a notification webhook
+type NotificationWebhookPayload struct {
+	Subscription  string         `json:"subscription"`
+	SDKKey        string         `json:"sdkKey"`
+	Notifications []syncer.Event `json:"notifications"`
+}
+
+// NotificationWebhookDeadLetter is the line appended to the dead-letter file for a batch that could not be delivered
+type NotificationWebhookDeadLetter struct {
+	Timestamp time.Time `json:"timestamp"`
+	URL       string    `json:"url"`
+	Error     string    `json:"error"`
+	NotificationWebhookPayload
+}
+
+// NotificationWebhookDispatcher posts the notifications of every configured subscription to its URL. It is the
+// outbound counterpart of NotificationEventStreamHandler for consumers that can not hold a connection open.
+type NotificationWebhookDispatcher struct {
+	conf        config.NotificationWebhooksConfig
+	receiverFn  NotificationReceiverFunc
+	client      *http.Client
+	deadLetters *deadLetterFile
+	wg          sync.WaitGroup
+
+	delivered go_kit_metrics.Counter
+	failed    go_kit_metrics.Counter
+	dropped   go_kit_metrics.Counter
+}
+
+// NewNotificationWebhookDispatcher returns a dispatcher receiving notifications from receiverFn
+func NewNotificationWebhookDispatcher(conf config.NotificationWebhooksConfig, receiverFn NotificationReceiverFunc, metricsRegistry *metrics.Registry) *NotificationWebhookDispatcher {
+	if conf.BatchSize <= 0 {
+		conf.BatchSize = 1
+	}
+	if conf.FlushInterval <= 0 {
+		conf.FlushInterval = time.Second
+	}
+	if conf.QueueSize < 0 {
+		conf.QueueSize = 0
+	}
+
+	return &NotificationWebhookDispatcher{
+		conf:        conf,
+		receiverFn:  receiverFn,
+		client:      &http.Client{Timeout: conf.Timeout},
+		deadLetters: &deadLetterFile{path: conf.DeadLetterFile},
+		delivered:   metricsRegistry.GetCounter(NotificationWebhookDeliveredMetric),
+		failed:      metricsRegistry.GetCounter(NotificationWebhookFailedMetric),
+		dropped:     metricsRegistry.GetCounter(NotificationWebhookDroppedMetric),
+	}
+}
+
+// Start subscribes to the notifications of every subscription and posts them until ctx is done.
+// Subscriptions that fail to start are reported in the returned error without affecting the others.
+func (d *NotificationWebhookDispatcher) Start(ctx context.Context) error {
+	var errs []error
+	for name, sub := range d.conf.Subscriptions {
+		if err := d.subscribe(ctx, name, sub); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Wait blocks until the notifications queued when ctx is done have been delivered
+func (d *NotificationWebhookDispatcher) Wait() {
+	d.wg.Wait()
+}
+
+func (d *NotificationWebhookDispatcher) subscribe(ctx context.Context, name string, sub config.NotificationWebhookSubscription) error {
+	if sub.SDKKey == "" || sub.URL == "" {
+		return fmt.Errorf("notification webhook %q requires an sdkKey and a url", name)
+	}
+
+	// Notifications are published under the SDK key without the datafile access token
+	sdkKey := strings.Split(sub.SDKKey, ":")[0]
+	logger := log.With().Str("subscription", name).Logger()
+	receiverCtx := context.WithValue(context.WithValue(ctx, SDKKey, sdkKey), LoggerKey, &logger)
+	events, err := d.receiverFn(receiverCtx)
+	if err != nil {
+		return fmt.Errorf("notification webhook %q: %w", name, err)
+	}
+
+	// The receiver blocks the notification center until its events are read, so they are moved to a
+	// bounded queue right away and dropped when the queue is full
+	filter := NotificationFilter(sub.Filter)
+	queue := make(chan syncer.Event, d.conf.QueueSize)
+	d.wg.Add(2)
+	go func() {
+		defer d.wg.Done()
+		defer close(queue)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if _, found := filter[event.Type]; !found {
+					continue
+				}
+				select {
+				case queue <- event:
+				default:
+					d.dropped.Add(1)
+				}
+			}
+		}
+	}()
+	go func() {
+		defer d.wg.Done()
+		d.run(ctx, &logger, name, sdkKey, sub, queue)
+	}()
+
+	logger.Info().Str("url", sub.URL).Msg("Notification webhook started")
+	return nil
+}
+
+// run posts the queued notifications in batches until the queue is closed
+func (d *NotificationWebhookDispatcher) run(ctx context.Context, logger *zerolog.Logger, name, sdkKey string, sub config.NotificationWebhookSubscription, queue <-chan syncer.Event) {
+	ticker := time.NewTicker(d.conf.FlushInterval)
+	defer ticker.Stop()
+
+	var batch []syncer.Event
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		d.deliver(ctx, logger, sub, NotificationWebhookPayload{Subscription: name, SDKKey: sdkKey, Notifications: batch})
+		batch = nil
+	}
+
+	for {
+		select {
+		case event, ok := <-queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= d.conf.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// deliver posts the payload, retrying with exponential backoff, and appends it to the dead-letter file
+// once the retries are exhausted. Retries stop when ctx is done.
+func (d *NotificationWebhookDispatcher) deliver(ctx context.Context, logger *zerolog.Logger, sub config.NotificationWebhookSubscription, payload NotificationWebhookPayload) {
+	count := float64(len(payload.Notifications))
+	body, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to marshal notifications")
+		d.failed.Add(count)
+		return
+	}
+
+	backoff := d.conf.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		if retry, err = d.post(sub, body); err == nil {
+			d.delivered.Add(count)
+			return
+		}
+		if !retry || attempt >= d.conf.MaxRetries || ctx.Err() != nil {
+			break
+		}
+
+		logger.Warn().Err(err).Int("attempt", attempt+1).Msg("Failed to post notifications, retrying")
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; d.conf.MaxBackoff > 0 && backoff > d.conf.MaxBackoff {
+			backoff = d.conf.MaxBackoff
+		}
+	}
+
+	logger.Error().Err(err).Msg("Failed to post notifications")
+	d.failed.Add(count)
+	deadLetter := NotificationWebhookDeadLetter{
+		Timestamp:                  time.Now(),
+		URL:                        sub.URL,
+		Error:                      err.Error(),
+		NotificationWebhookPayload: payload,
+	}
+	if err := d.deadLetters.write(deadLetter); err != nil {
+		logger.Error().Err(err).Msg("Failed to write notifications to the dead-letter file")
+	}
+}
+
+// post sends the body to the subscription URL and reports whether a failed request can be retried
+func (d *NotificationWebhookDispatcher) post(sub config.NotificationWebhookSubscription, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range sub.Headers {
+		req.Header.Set(name, value)
+	}
+	if sub.Secret != "" {
+		req.Header.Set(signatureHeader, computeSignature(body, sub.Secret))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+	return retry, fmt.Errorf("notification webhook responded with status %d", resp.StatusCode)
+}
+
+// deadLetterFile appends undelivered batches to a file as newline delimited JSON
+type deadLetterFile struct {
+	path string
+	lock sync.Mutex
+}
+
+func (f *deadLetterFile) write(deadLetter NotificationWebhookDeadLetter) error {
+	if f.path == "" {
+		return nil
+	}
+
+	line, err := json.Marshal(deadLetter)
+	if err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,289 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package handlers //
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics/generic"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/agent/config"
+	"github.com/optimizely/agent/pkg/metrics"
+	"github.com/optimizely/agent/pkg/syncer"
+	"github.com/optimizely/go-sdk/v2/pkg/notification"
+)
+
+// The expvar counters can only be created once per process
+var notificationWebhookMetrics = metrics.NewRegistry("")
+
+type NotificationWebhookTestSuite struct {
+	suite.Suite
+	events   chan syncer.Event
+	sdkKeys  chan string
+	conf     config.NotificationWebhooksConfig
+	server   *httptest.Server
+	lock     sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	statuses []int
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+func (s *NotificationWebhookTestSuite) SetupTest() {
+	s.events = make(chan syncer.Event)
+	s.sdkKeys = make(chan string, 1)
+	s.requests = nil
+	s.bodies = nil
+	s.statuses = nil
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.requests = append(s.requests, r)
+		s.bodies = append(s.bodies, body)
+		status := http.StatusNoContent
+		if len(s.statuses) > 0 {
+			status, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	s.conf = config.NotificationWebhooksConfig{
+		BatchSize:      2,
+		FlushInterval:  time.Hour,
+		QueueSize:      10,
+		Timeout:        time.Second,
+		MaxRetries:     2,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		Subscriptions: map[string]config.NotificationWebhookSubscription{
+			"hook": {SDKKey: "sdkKey:token", URL: s.server.URL},
+		},
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+}
+
+func (s *NotificationWebhookTestSuite) TearDownTest() {
+	s.cancel()
+	s.server.Close()
+}
+
+func (s *NotificationWebhookTestSuite) receiver(ctx context.Context) (<-chan syncer.Event, error) {
+	s.sdkKeys <- ctx.Value(SDKKey).(string)
+	return s.events, nil
+}
+
+func (s *NotificationWebhookTestSuite) start() *NotificationWebhookDispatcher {
+	dispatcher := NewNotificationWebhookDispatcher(s.conf, s.receiver, notificationWebhookMetrics)
+	dispatcher.delivered = generic.NewCounter("delivered")
+	dispatcher.failed = generic.NewCounter("failed")
+	dispatcher.dropped = generic.NewCounter("dropped")
+	s.Require().NoError(dispatcher.Start(s.ctx))
+	return dispatcher
+}
+
+func (s *NotificationWebhookTestSuite) stop(dispatcher *NotificationWebhookDispatcher) {
+	s.cancel()
+	dispatcher.Wait()
+}
+
+func (s *NotificationWebhookTestSuite) payloads() []NotificationWebhookPayload {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	payloads := []NotificationWebhookPayload{}
+	for _, body := range s.bodies {
+		var payload NotificationWebhookPayload
+		s.Require().NoError(json.Unmarshal(body, &payload))
+		payloads = append(payloads, payload)
+	}
+	return payloads
+}
+
+func counterValue(counter interface{}) float64 {
+	return counter.(*generic.Counter).Value()
+}
+
+func (s *NotificationWebhookTestSuite) TestDeliversFilteredBatches() {
+	sub := s.conf.Subscriptions["hook"]
+	sub.Secret = "secret"
+	sub.Filter = []string{"decision,track"}
+	sub.Headers = map[string]string{"X-Custom": "value"}
+	s.conf.Subscriptions["hook"] = sub
+	dispatcher := s.start()
+	s.Equal("sdkKey", <-s.sdkKeys)
+
+	s.events <- syncer.Event{Type: notification.Decision, Message: map[string]interface{}{"userId": "1"}}
+	s.events <- syncer.Event{Type: notification.ProjectConfigUpdate, Message: map[string]interface{}{"revision": "2"}}
+	s.events <- syncer.Event{Type: notification.Track, Message: map[string]interface{}{"eventKey": "event"}}
+	s.stop(dispatcher)
+
+	payloads := s.payloads()
+	s.Require().Len(payloads, 1)
+	s.Equal("hook", payloads[0].Subscription)
+	s.Equal("sdkKey", payloads[0].SDKKey)
+	s.Equal([]syncer.Event{
+		{Type: notification.Decision, Message: map[string]interface{}{"userId": "1"}},
+		{Type: notification.Track, Message: map[string]interface{}{"eventKey": "event"}},
+	}, payloads[0].Notifications)
+
+	request := s.requests[0]
+	s.Equal(http.MethodPost, request.Method)
+	s.Equal("application/json", request.Header.Get("Content-Type"))
+	s.Equal("value", request.Header.Get("X-Custom"))
+	s.Equal(computeSignature(s.bodies[0], "secret"), request.Header.Get(signatureHeader))
+	s.Equal(2.0, counterValue(dispatcher.delivered))
+}
+
+func (s *NotificationWebhookTestSuite) TestNoSignatureWithoutSecret() {
+	s.conf.BatchSize = 1
+	dispatcher := s.start()
+	<-s.sdkKeys
+
+	s.events <- syncer.Event{Type: notification.Decision, Message: "decision"}
+	s.stop(dispatcher)
+
+	s.Require().Len(s.requests, 1)
+	s.Empty(s.requests[0].Header.Get(signatureHeader))
+}
+
+func (s *NotificationWebhookTestSuite) TestFlushesOnShutdown() {
+	s.conf.BatchSize = 10
+	dispatcher := s.start()
+	<-s.sdkKeys
+
+	s.events <- syncer.Event{Type: notification.Decision, Message: "decision"}
+	s.Empty(s.payloads())
+	s.stop(dispatcher)
+
+	s.Len(s.payloads(), 1)
+}
+
+func (s *NotificationWebhookTestSuite) TestFlushesOnInterval() {
+	s.conf.BatchSize = 10
+	s.conf.FlushInterval = 10 * time.Millisecond
+	dispatcher := s.start()
+	<-s.sdkKeys
+
+	s.events <- syncer.Event{Type: notification.Decision, Message: "decision"}
+	s.Eventually(func() bool { return len(s.payloads()) == 1 }, time.Second, 5*time.Millisecond)
+	s.stop(dispatcher)
+}
+
+func (s *NotificationWebhookTestSuite) TestRetriesServerErrors() {
+	s.conf.BatchSize = 1
+	s.statuses = []int{http.StatusInternalServerError, http.StatusTooManyRequests}
+	dispatcher := s.start()
+	<-s.sdkKeys
+
+	s.events <- syncer.Event{Type: notification.Decision, Message: "decision"}
+	s.Eventually(func() bool { return counterValue(dispatcher.delivered) == 1 }, time.Second, 5*time.Millisecond)
+	s.stop(dispatcher)
+
+	s.Len(s.payloads(), 3)
+	s.Equal(0.0, counterValue(dispatcher.failed))
+}
+
+func (s *NotificationWebhookTestSuite) TestDeadLetters() {
+	s.conf.BatchSize = 1
+	s.conf.DeadLetterFile = filepath.Join(s.T().TempDir(), "dead-letters.ndjson")
+	s.statuses = []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusBadRequest}
+	dispatcher := s.start()
+	<-s.sdkKeys
+
+	s.events <- syncer.Event{Type: notification.Decision, Message: "first"}
+	s.Eventually(func() bool { return counterValue(dispatcher.failed) == 1 }, time.Second, 5*time.Millisecond)
+	// Client errors are not retried
+	s.events <- syncer.Event{Type: notification.Decision, Message: "second"}
+	s.stop(dispatcher)
+
+	s.Len(s.payloads(), 4)
+	s.Equal(2.0, counterValue(dispatcher.failed))
+
+	file, err := os.Open(s.conf.DeadLetterFile)
+	s.Require().NoError(err)
+	defer file.Close()
+	deadLetters := []NotificationWebhookDeadLetter{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var deadLetter NotificationWebhookDeadLetter
+		s.Require().NoError(json.Unmarshal(scanner.Bytes(), &deadLetter))
+		deadLetters = append(deadLetters, deadLetter)
+	}
+	s.Require().Len(deadLetters, 2)
+	s.Equal(s.server.URL, deadLetters[0].URL)
+	s.Equal("notification webhook responded with status 500", deadLetters[0].Error)
+	s.Equal("hook", deadLetters[0].Subscription)
+	s.Equal([]syncer.Event{{Type: notification.Decision, Message: "first"}}, deadLetters[0].Notifications)
+	s.Equal("notification webhook responded with status 400", deadLetters[1].Error)
+}
+
+func (s *NotificationWebhookTestSuite) TestDropsWhenQueueIsFull() {
+	s.conf.BatchSize = 1
+	s.conf.QueueSize = 1
+	received := make(chan struct{})
+	release := make(chan struct{})
+	s.server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-release
+	})
+	dispatcher := s.start()
+	<-s.sdkKeys
+
+	s.events <- syncer.Event{Type: notification.Decision, Message: "sending"}
+	<-received
+	s.events <- syncer.Event{Type: notification.Decision, Message: "queued"}
+	s.events <- syncer.Event{Type: notification.Decision, Message: "dropped"}
+	s.Eventually(func() bool { return counterValue(dispatcher.dropped) == 1 }, time.Second, 5*time.Millisecond)
+
+	close(release)
+	<-received
+	s.stop(dispatcher)
+	s.Equal(2.0, counterValue(dispatcher.delivered))
+}
+
+func (s *NotificationWebhookTestSuite) TestStartErrors() {
+	s.conf.Subscriptions["invalid"] = config.NotificationWebhookSubscription{SDKKey: "sdkKey"}
+	dispatcher := NewNotificationWebhookDispatcher(s.conf, s.receiver, notificationWebhookMetrics)
+	s.EqualError(dispatcher.Start(s.ctx), `notification webhook "invalid" requires an sdkKey and a url`)
+	// The valid subscription is started regardless
+	s.Equal("sdkKey", <-s.sdkKeys)
+
+	failing := func(context.Context) (<-chan syncer.Event, error) {
+		return nil, errors.New("unavailable")
+	}
+	dispatcher = NewNotificationWebhookDispatcher(config.NotificationWebhooksConfig{
+		Subscriptions: map[string]config.NotificationWebhookSubscription{"hook": {SDKKey: "sdkKey", URL: s.server.URL}},
+	}, failing, notificationWebhookMetrics)
+	s.EqualError(dispatcher.Start(s.ctx), `notification webhook "hook": unavailable`)
+}
+
+func TestNotificationWebhookTestSuite(t *testing.T) {
+	suite.Run(t, new(NotificationWebhookTestSuite))
+}
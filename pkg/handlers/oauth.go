@@ -202,7 +202,7 @@ func (h *OAuthHandler) CreateAPIAccessToken(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	accessToken, err := jwtauth.BuildAPIAccessToken(clientCreds.SDKKeys, clientCreds.TTL, h.hmacSecret)
+	accessToken, err := jwtauth.BuildAPIAccessToken(clientCreds.ID, clientCreds.SDKKeys, clientCreds.TTL, h.hmacSecret)
 	if err != nil {
 		middleware.GetLogger(r).Error().Err(err).Msg("Calling jwt BuildAPIAccessToken")
 		RenderError(err, http.StatusInternalServerError, w, r)
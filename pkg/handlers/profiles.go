@@ -0,0 +1,270 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package handlers //
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+
+	"github.com/optimizely/agent/pkg/middleware"
+	"github.com/optimizely/agent/plugins/userprofileservice"
+)
+
+// ErrProfilesNotSupported is a constant error if the user profile service can not delete or export profiles
+var ErrProfilesNotSupported = errors.New("user profile service does not support deleting or exporting profiles")
+
+// ErrEmptyProfilesPayload is a constant error if no profile is given to the profile import API
+var ErrEmptyProfilesPayload = errors.New("missing profiles in request payload")
+
+// ErrInvalidExperimentBucketMap is a constant error if an imported profile has an invalid experimentBucketMap
+var ErrInvalidExperimentBucketMap = errors.New(`invalid "experimentBucketMap" in request payload`)
+
+// ErrProfileExperimentNotFound is a constant error if a profile holds no bucketing for the experiment
+var ErrProfileExperimentNotFound = errors.New("experiment not found in user profile")
+
+// ProfileImportError is streamed for each profile of an import request that is not saved
+type ProfileImportError struct {
+	Index  int    `json:"index"`
+	UserID string `json:"userId,omitempty"`
+	Error  string `json:"error"`
+}
+
+// ProfileImportSummary is streamed once every profile of an import request is processed
+type ProfileImportSummary struct {
+	Imported int `json:"imported"`
+	Failed   int `json:"failed"`
+}
+
+// DeleteProfile deletes the profile of the userId path parameter, for instance to erase the data of a user
+func DeleteProfile(w http.ResponseWriter, r *http.Request) {
+	optlyClient, err := middleware.GetOptlyClient(r)
+	if err != nil {
+		RenderError(err, http.StatusInternalServerError, w, r)
+		return
+	}
+	logger := middleware.GetLogger(r)
+
+	if optlyClient.UserProfileService == nil {
+		RenderError(ErrNoUPS, http.StatusInternalServerError, w, r)
+		return
+	}
+	manager, ok := optlyClient.UserProfileService.(userprofileservice.Manager)
+	if !ok {
+		RenderError(ErrProfilesNotSupported, http.StatusNotImplemented, w, r)
+		return
+	}
+
+	userID, err := url.PathUnescape(chi.URLParam(r, "userId"))
+	if err != nil || userID == "" {
+		RenderError(ErrEmptyUserID, http.StatusBadRequest, w, r)
+		return
+	}
+
+	if err := manager.Delete(userID); err != nil {
+		renderProfilesError(err, w, r)
+		return
+	}
+	logger.Info().Msgf("Deleted user profile for user %s", userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteProfileExperiment removes the bucketing of the experimentId path parameter from the profile of the userId
+// path parameter, so that the user is bucketed again in the experiment
+func DeleteProfileExperiment(w http.ResponseWriter, r *http.Request) {
+	optlyClient, err := middleware.GetOptlyClient(r)
+	if err != nil {
+		RenderError(err, http.StatusInternalServerError, w, r)
+		return
+	}
+	logger := middleware.GetLogger(r)
+
+	if optlyClient.UserProfileService == nil {
+		RenderError(ErrNoUPS, http.StatusInternalServerError, w, r)
+		return
+	}
+
+	userID, err := url.PathUnescape(chi.URLParam(r, "userId"))
+	if err != nil || userID == "" {
+		RenderError(ErrEmptyUserID, http.StatusBadRequest, w, r)
+		return
+	}
+	experimentID, err := url.PathUnescape(chi.URLParam(r, "experimentId"))
+	if err != nil {
+		RenderError(err, http.StatusBadRequest, w, r)
+		return
+	}
+
+	profile := optlyClient.UserProfileService.Lookup(userID)
+	found := false
+	for key := range profile.ExperimentBucketMap {
+		if key.ExperimentID == experimentID {
+			delete(profile.ExperimentBucketMap, key)
+			found = true
+		}
+	}
+	if !found {
+		RenderError(ErrProfileExperimentNotFound, http.StatusNotFound, w, r)
+		return
+	}
+
+	profile.ID = userID
+	optlyClient.UserProfileService.Save(profile)
+	logger.Info().Msgf("Deleted experiment %s from user profile for user %s", experimentID, userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ExportProfiles streams every saved profile as newline delimited JSON, in the format of the Lookup response
+func ExportProfiles(w http.ResponseWriter, r *http.Request) {
+	optlyClient, err := middleware.GetOptlyClient(r)
+	if err != nil {
+		RenderError(err, http.StatusInternalServerError, w, r)
+		return
+	}
+	logger := middleware.GetLogger(r)
+
+	if optlyClient.UserProfileService == nil {
+		RenderError(ErrNoUPS, http.StatusInternalServerError, w, r)
+		return
+	}
+	manager, ok := optlyClient.UserProfileService.(userprofileservice.Manager)
+	if !ok {
+		RenderError(ErrProfilesNotSupported, http.StatusNotImplemented, w, r)
+		return
+	}
+
+	// The response is started with the first profile so that an error of the service can still be rendered
+	var writer *ndjsonWriter
+	exported := 0
+	err = manager.Range(func(profile decision.UserProfile) error {
+		if writer == nil {
+			writer = newNDJSONWriter(w)
+		}
+		writer.write(UPSResponseOut{UserID: profile.ID, ExperimentBucketMap: experimentBucketMapOut(profile)})
+		exported++
+		return r.Context().Err()
+	})
+	if err != nil && writer == nil {
+		renderProfilesError(err, w, r)
+		return
+	}
+	if err != nil {
+		logger.Err(err).Int("exported", exported).Msg("error exporting user profiles")
+		return
+	}
+	if writer == nil {
+		newNDJSONWriter(w)
+	}
+	logger.Info().Int("exported", exported).Msg("exported user profiles")
+}
+
+// ImportProfiles saves a list of profiles, given as a JSON array or as newline delimited JSON in the format of the
+// Save request. The profiles that are not saved are streamed back as newline delimited ProfileImportError objects,
+// followed by a ProfileImportSummary.
+func ImportProfiles(w http.ResponseWriter, r *http.Request) {
+	optlyClient, err := middleware.GetOptlyClient(r)
+	if err != nil {
+		RenderError(err, http.StatusInternalServerError, w, r)
+		return
+	}
+	logger := middleware.GetLogger(r)
+
+	if optlyClient.UserProfileService == nil {
+		RenderError(ErrNoUPS, http.StatusInternalServerError, w, r)
+		return
+	}
+
+	decoder, err := newBulkDecoder(r.Body)
+	if err != nil {
+		RenderError(ErrEmptyProfilesPayload, http.StatusBadRequest, w, r)
+		return
+	}
+
+	writer := newNDJSONWriter(w)
+	summary := ProfileImportSummary{}
+	fail := func(importErr ProfileImportError) {
+		summary.Failed++
+		writer.write(importErr)
+		writer.flush()
+	}
+
+	for {
+		var body saveBody
+		index, err := decoder.next(&body)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			logger.Err(err).Int("index", index).Msg("error parsing profile import request body")
+			fail(ProfileImportError{Index: index, Error: "error parsing request body"})
+			break
+		}
+
+		switch {
+		case body.UserID == "":
+			err = ErrEmptyUserID
+		case !validExperimentBucketMap(body.ExperimentBucketMap):
+			err = ErrInvalidExperimentBucketMap
+		}
+		if err != nil {
+			fail(ProfileImportError{Index: index, UserID: body.UserID, Error: err.Error()})
+			continue
+		}
+
+		optlyClient.UserProfileService.Save(convertToUserProfile(body))
+		summary.Imported++
+	}
+
+	logger.Info().Int("imported", summary.Imported).Int("failed", summary.Failed).Msg("imported user profiles")
+	writer.write(summary)
+}
+
+// experimentBucketMapOut converts the bucketing of a profile to the experimentBucketMap of the UPS API
+func experimentBucketMapOut(profile decision.UserProfile) map[string]interface{} {
+	experimentBucketMap := map[string]interface{}{}
+	for k, v := range profile.ExperimentBucketMap {
+		experimentBucketMap[k.ExperimentID] = map[string]interface{}{k.Field: v}
+	}
+	return experimentBucketMap
+}
+
+// validExperimentBucketMap checks that every experiment holds the variation ID string expected by convertToUserProfile
+func validExperimentBucketMap(experimentBucketMap map[string]interface{}) bool {
+	for experimentID, v := range experimentBucketMap {
+		bucketMap, ok := v.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if _, ok := bucketMap[decision.NewUserDecisionKey(experimentID).Field].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func renderProfilesError(err error, w http.ResponseWriter, r *http.Request) {
+	if errors.Is(err, userprofileservice.ErrNotSupported) {
+		RenderError(ErrProfilesNotSupported, http.StatusNotImplemented, w, r)
+		return
+	}
+	RenderError(err, http.StatusInternalServerError, w, r)
+}
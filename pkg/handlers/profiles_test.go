@@ -0,0 +1,230 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package handlers //
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/agent/pkg/middleware"
+	"github.com/optimizely/agent/pkg/optimizely"
+	"github.com/optimizely/agent/pkg/optimizely/optimizelytest"
+	userprofileservices "github.com/optimizely/agent/plugins/userprofileservice/services"
+)
+
+// lookupOnlyUPS does not implement the userprofileservice.Manager interface
+type lookupOnlyUPS struct {
+	decision.UserProfileService
+}
+
+type ProfilesTestSuite struct {
+	suite.Suite
+	oc  *optimizely.OptlyClient
+	ups *userprofileservices.InMemoryUserProfileService
+	mux *chi.Mux
+}
+
+func (suite *ProfilesTestSuite) ClientCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), middleware.OptlyClientKey, suite.oc)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (suite *ProfilesTestSuite) SetupTest() {
+	testClient := optimizelytest.NewClient()
+	suite.ups = &userprofileservices.InMemoryUserProfileService{}
+	suite.ups.Save(decision.UserProfile{
+		ID: "testUser",
+		ExperimentBucketMap: map[decision.UserDecisionKey]string{
+			decision.NewUserDecisionKey("1"): "2",
+			decision.NewUserDecisionKey("2"): "3",
+		},
+	})
+	suite.oc = &optimizely.OptlyClient{
+		OptimizelyClient:   testClient.OptimizelyClient,
+		ForcedVariations:   testClient.ForcedVariations,
+		UserProfileService: suite.ups,
+	}
+
+	suite.mux = chi.NewMux()
+	suite.mux.With(suite.ClientCtx).Get("/profiles", ExportProfiles)
+	suite.mux.With(suite.ClientCtx).Post("/profiles/import", ImportProfiles)
+	suite.mux.With(suite.ClientCtx).Delete("/profiles/{userId}", DeleteProfile)
+	suite.mux.With(suite.ClientCtx).Delete("/profiles/{userId}/experiments/{experimentId}", DeleteProfileExperiment)
+}
+
+func (suite *ProfilesTestSuite) serve(method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	suite.mux.ServeHTTP(rec, req)
+	return rec
+}
+
+// lines returns the newline delimited JSON objects of the response
+func (suite *ProfilesTestSuite) lines(rec *httptest.ResponseRecorder) []map[string]interface{} {
+	suite.Equal("application/x-ndjson", rec.Header().Get("Content-Type"))
+	lines := []map[string]interface{}{}
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		suite.NoError(json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (suite *ProfilesTestSuite) TestDeleteProfile() {
+	rec := suite.serve("DELETE", "/profiles/testUser", "")
+	suite.Equal(http.StatusNoContent, rec.Code)
+	suite.Empty(suite.ups.ProfilesMap)
+
+	// Deleting a profile that does not exist is not an error
+	rec = suite.serve("DELETE", "/profiles/testUser", "")
+	suite.Equal(http.StatusNoContent, rec.Code)
+}
+
+func (suite *ProfilesTestSuite) TestDeleteProfileEscapedUserID() {
+	suite.ups.Save(decision.UserProfile{ID: "user/1", ExperimentBucketMap: map[decision.UserDecisionKey]string{}})
+
+	rec := suite.serve("DELETE", "/profiles/user%2F1", "")
+	suite.Equal(http.StatusNoContent, rec.Code)
+	suite.NotContains(suite.ups.ProfilesMap, "user/1")
+	suite.Contains(suite.ups.ProfilesMap, "testUser")
+}
+
+func (suite *ProfilesTestSuite) TestDeleteProfileExperiment() {
+	rec := suite.serve("DELETE", "/profiles/testUser/experiments/1", "")
+	suite.Equal(http.StatusNoContent, rec.Code)
+	suite.Equal(map[decision.UserDecisionKey]string{decision.NewUserDecisionKey("2"): "3"}, suite.ups.Lookup("testUser").ExperimentBucketMap)
+
+	rec = suite.serve("DELETE", "/profiles/testUser/experiments/1", "")
+	assertError(suite.T(), rec, ErrProfileExperimentNotFound.Error(), http.StatusNotFound)
+
+	rec = suite.serve("DELETE", "/profiles/unknownUser/experiments/2", "")
+	assertError(suite.T(), rec, ErrProfileExperimentNotFound.Error(), http.StatusNotFound)
+}
+
+func (suite *ProfilesTestSuite) TestExportProfiles() {
+	suite.ups.Save(decision.UserProfile{
+		ID:                  "otherUser",
+		ExperimentBucketMap: map[decision.UserDecisionKey]string{decision.NewUserDecisionKey("1"): "4"},
+	})
+
+	rec := suite.serve("GET", "/profiles", "")
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.ElementsMatch([]map[string]interface{}{
+		{
+			"userId": "testUser",
+			"experimentBucketMap": map[string]interface{}{
+				"1": map[string]interface{}{"variation_id": "2"},
+				"2": map[string]interface{}{"variation_id": "3"},
+			},
+		},
+		{
+			"userId":              "otherUser",
+			"experimentBucketMap": map[string]interface{}{"1": map[string]interface{}{"variation_id": "4"}},
+		},
+	}, suite.lines(rec))
+}
+
+func (suite *ProfilesTestSuite) TestExportNoProfiles() {
+	suite.NoError(suite.ups.Delete("testUser"))
+
+	rec := suite.serve("GET", "/profiles", "")
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Empty(suite.lines(rec))
+}
+
+func (suite *ProfilesTestSuite) TestImportProfiles() {
+	rec := suite.serve("POST", "/profiles/import", `{"userId": "user1", "experimentBucketMap": {"1": {"variation_id": "5"}}}
+{"experimentBucketMap": {}}
+{"userId": "user3", "experimentBucketMap": {"1": {"variation_id": 5}}}
+{"userId": "testUser", "experimentBucketMap": {"3": {"variation_id": "6"}}}
+`)
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Equal([]map[string]interface{}{
+		{"index": float64(1), "error": ErrEmptyUserID.Error()},
+		{"index": float64(2), "userId": "user3", "error": ErrInvalidExperimentBucketMap.Error()},
+		{"imported": float64(2), "failed": float64(2)},
+	}, suite.lines(rec))
+
+	suite.Equal(map[decision.UserDecisionKey]string{decision.NewUserDecisionKey("1"): "5"}, suite.ups.Lookup("user1").ExperimentBucketMap)
+	// Imported profiles replace the saved ones
+	suite.Equal(map[decision.UserDecisionKey]string{decision.NewUserDecisionKey("3"): "6"}, suite.ups.Lookup("testUser").ExperimentBucketMap)
+	suite.NotContains(suite.ups.ProfilesMap, "user3")
+}
+
+func (suite *ProfilesTestSuite) TestImportJSONArray() {
+	rec := suite.serve("POST", "/profiles/import", `[{"userId": "user1", "experimentBucketMap": {"1": {"variation_id": "5"}}}]`)
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Equal([]map[string]interface{}{{"imported": float64(1), "failed": float64(0)}}, suite.lines(rec))
+}
+
+func (suite *ProfilesTestSuite) TestImportMalformedPayload() {
+	rec := suite.serve("POST", "/profiles/import", `{"userId": "user1", "experimentBucketMap": {}}
+{"userId": `)
+	suite.Equal([]map[string]interface{}{
+		{"index": float64(1), "error": "error parsing request body"},
+		{"imported": float64(1), "failed": float64(1)},
+	}, suite.lines(rec))
+}
+
+func (suite *ProfilesTestSuite) TestImportEmptyPayload() {
+	rec := suite.serve("POST", "/profiles/import", "")
+	assertError(suite.T(), rec, ErrEmptyProfilesPayload.Error(), http.StatusBadRequest)
+}
+
+func (suite *ProfilesTestSuite) TestNoUserProfileService() {
+	suite.oc.UserProfileService = nil
+	for _, route := range [][2]string{
+		{"GET", "/profiles"},
+		{"POST", "/profiles/import"},
+		{"DELETE", "/profiles/testUser"},
+		{"DELETE", "/profiles/testUser/experiments/1"},
+	} {
+		rec := suite.serve(route[0], route[1], "")
+		assertError(suite.T(), rec, ErrNoUPS.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (suite *ProfilesTestSuite) TestProfilesNotSupported() {
+	suite.oc.UserProfileService = lookupOnlyUPS{suite.ups}
+
+	rec := suite.serve("GET", "/profiles", "")
+	assertError(suite.T(), rec, ErrProfilesNotSupported.Error(), http.StatusNotImplemented)
+	rec = suite.serve("DELETE", "/profiles/testUser", "")
+	assertError(suite.T(), rec, ErrProfilesNotSupported.Error(), http.StatusNotImplemented)
+
+	// Experiments are removed with Lookup and Save, which every user profile service supports
+	rec = suite.serve("DELETE", "/profiles/testUser/experiments/1", "")
+	suite.Equal(http.StatusNoContent, rec.Code)
+}
+
+func TestProfilesTestSuite(t *testing.T) {
+	suite.Run(t, new(ProfilesTestSuite))
+}
@@ -0,0 +1,126 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package handlers //
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+
+	"github.com/optimizely/agent/pkg/middleware"
+)
+
+// ErrEmptyTrackBulkPayload is a constant error if no event is given to the bulk track API
+var ErrEmptyTrackBulkPayload = errors.New("missing events in request payload")
+
+// ErrEmptyEventKey is a constant error if an event of the bulk track API has no event key
+var ErrEmptyEventKey = errors.New(`missing "eventKey" in request payload`)
+
+type trackBulkBody struct {
+	EventKey string `json:"eventKey"`
+	trackBody
+}
+
+// TrackBulkError is streamed for each event of a bulk track request that is not tracked
+type TrackBulkError struct {
+	Index    int    `json:"index"`
+	UserID   string `json:"userId,omitempty"`
+	EventKey string `json:"eventKey,omitempty"`
+	Error    string `json:"error"`
+}
+
+// TrackBulkSummary is streamed once every event of a bulk track request is processed
+type TrackBulkSummary struct {
+	Tracked int `json:"tracked"`
+	Failed  int `json:"failed"`
+}
+
+// TrackBulk tracks a list of events, given as a JSON array or as newline delimited JSON, in the order of the
+// request. Events are validated against the project config like TrackEvent does and the events that are not
+// tracked are streamed back as newline delimited TrackBulkError objects, followed by a TrackBulkSummary.
+// Reading the request waits for room in the event queue of the client instead of discarding events.
+func TrackBulk(w http.ResponseWriter, r *http.Request) {
+	optlyClient, err := middleware.GetOptlyClient(r)
+	if err != nil {
+		RenderError(err, http.StatusInternalServerError, w, r)
+		return
+	}
+	logger := middleware.GetLogger(r)
+
+	decoder, err := newBulkDecoder(r.Body)
+	if err != nil {
+		RenderError(ErrEmptyTrackBulkPayload, http.StatusBadRequest, w, r)
+		return
+	}
+
+	writer := newNDJSONWriter(w)
+	summary := TrackBulkSummary{}
+	fail := func(bulkErr TrackBulkError) {
+		summary.Failed++
+		writer.write(bulkErr)
+		writer.flush()
+	}
+
+	for {
+		var body trackBulkBody
+		index, err := decoder.next(&body)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			logger.Err(err).Int("index", index).Msg("error parsing bulk track request body")
+			fail(TrackBulkError{Index: index, Error: "error parsing request body"})
+			break
+		}
+
+		switch {
+		case body.EventKey == "":
+			err = ErrEmptyEventKey
+		case body.UserID == "":
+			err = ErrEmptyUserID
+		}
+		if err != nil {
+			fail(TrackBulkError{Index: index, UserID: body.UserID, EventKey: body.EventKey, Error: err.Error()})
+			continue
+		}
+
+		if err := optlyClient.WaitForEventQueue(r.Context()); err != nil {
+			logger.Warn().Err(err).Int("index", index).Msg("bulk track request canceled")
+			return
+		}
+
+		uc := entities.UserContext{
+			ID:         body.UserID,
+			Attributes: body.UserAttributes,
+		}
+		track, err := optlyClient.TrackEvent(r.Context(), body.EventKey, uc, body.EventTags)
+		if err == nil && track.Error != "" {
+			err = errors.New(track.Error)
+		}
+		if err != nil {
+			fail(TrackBulkError{Index: index, UserID: body.UserID, EventKey: body.EventKey, Error: err.Error()})
+			continue
+		}
+		summary.Tracked++
+	}
+
+	logger.Info().Int("tracked", summary.Tracked).Int("failed", summary.Failed).Msg("tracked bulk events")
+	writer.write(summary)
+}
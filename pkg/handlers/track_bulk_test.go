@@ -0,0 +1,191 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package handlers //
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/optimizely/go-sdk/v2/pkg/client"
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/agent/pkg/middleware"
+	"github.com/optimizely/agent/pkg/optimizely"
+	"github.com/optimizely/agent/pkg/optimizely/optimizelytest"
+)
+
+type TrackBulkTestSuite struct {
+	suite.Suite
+	oc  *optimizely.OptlyClient
+	tc  *optimizelytest.TestClient
+	mux *chi.Mux
+}
+
+func (suite *TrackBulkTestSuite) ClientCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), middleware.OptlyClientKey, suite.oc)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (suite *TrackBulkTestSuite) SetupTest() {
+	testClient := optimizelytest.NewClient()
+	testClient.AddEvent(entities.Event{Key: "purchase"})
+	testClient.AddEvent(entities.Event{Key: "signup"})
+
+	suite.tc = testClient
+	suite.oc = &optimizely.OptlyClient{
+		OptimizelyClient: testClient.OptimizelyClient,
+		ConfigManager:    MockConfigManager{config: testClient.ProjectConfig},
+		ForcedVariations: testClient.ForcedVariations,
+	}
+
+	suite.mux = chi.NewMux()
+	suite.mux.With(suite.ClientCtx).Post("/track/bulk", TrackBulk)
+}
+
+// post returns the error lines and the summary of the response
+func (suite *TrackBulkTestSuite) post(body string) ([]TrackBulkError, TrackBulkSummary) {
+	req := httptest.NewRequest("POST", "/track/bulk", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	suite.mux.ServeHTTP(rec, req)
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Equal("application/x-ndjson", rec.Header().Get("Content-Type"))
+
+	errs := []TrackBulkError{}
+	var summary TrackBulkSummary
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		if strings.Contains(scanner.Text(), `"error"`) {
+			var bulkErr TrackBulkError
+			suite.NoError(json.Unmarshal(scanner.Bytes(), &bulkErr))
+			errs = append(errs, bulkErr)
+			continue
+		}
+		suite.NoError(json.Unmarshal(scanner.Bytes(), &summary))
+	}
+	return errs, summary
+}
+
+func (suite *TrackBulkTestSuite) TestJSONArray() {
+	errs, summary := suite.post(`[
+		{"eventKey": "purchase", "userId": "user1", "userAttributes": {"plan": "gold"}, "eventTags": {"revenue": 1000}},
+		{"eventKey": "signup", "userId": "user2"}
+	]`)
+	suite.Empty(errs)
+	suite.Equal(TrackBulkSummary{Tracked: 2}, summary)
+
+	events := suite.tc.GetProcessedEvents()
+	suite.Require().Len(events, 2)
+	suite.Equal("purchase", events[0].Conversion.Key)
+	suite.Equal("user1", events[0].VisitorID)
+	suite.Equal(map[string]interface{}{"revenue": float64(1000)}, events[0].Conversion.Tags)
+	suite.Equal("signup", events[1].Conversion.Key)
+	suite.Equal("user2", events[1].VisitorID)
+}
+
+func (suite *TrackBulkTestSuite) TestInvalidEvents() {
+	errs, summary := suite.post(`{"eventKey": "purchase", "userId": "user1"}
+{"userId": "user2"}
+{"eventKey": "purchase"}
+{"eventKey": "unknown", "userId": "user4"}
+{"eventKey": "signup", "userId": "user5"}
+`)
+	suite.Equal([]TrackBulkError{
+		{Index: 1, UserID: "user2", Error: `missing "eventKey" in request payload`},
+		{Index: 2, EventKey: "purchase", Error: `missing "userId" in request payload`},
+		{Index: 3, UserID: "user4", EventKey: "unknown", Error: "Event with key unknown not found"},
+	}, errs)
+	suite.Equal(TrackBulkSummary{Tracked: 2, Failed: 3}, summary)
+	suite.Len(suite.tc.GetProcessedEvents(), 2)
+}
+
+func (suite *TrackBulkTestSuite) TestMalformedPayload() {
+	errs, summary := suite.post(`{"eventKey": "purchase", "userId": "user1"}
+{"eventKey": `)
+	suite.Equal([]TrackBulkError{{Index: 1, Error: "error parsing request body"}}, errs)
+	suite.Equal(TrackBulkSummary{Tracked: 1, Failed: 1}, summary)
+}
+
+func (suite *TrackBulkTestSuite) TestEmptyPayload() {
+	req := httptest.NewRequest("POST", "/track/bulk", strings.NewReader(""))
+	rec := httptest.NewRecorder()
+	suite.mux.ServeHTTP(rec, req)
+	assertError(suite.T(), rec, "missing events in request payload", http.StatusBadRequest)
+}
+
+// slowDispatcher dispatches events one at a time, slower than they are tracked
+type slowDispatcher struct {
+	dispatched int32
+}
+
+func (d *slowDispatcher) DispatchEvent(logEvent event.LogEvent) (bool, error) {
+	time.Sleep(2 * time.Millisecond)
+	atomic.AddInt32(&d.dispatched, int32(len(logEvent.Event.Visitors)))
+	return true, nil
+}
+
+func (suite *TrackBulkTestSuite) TestWaitsForEventQueue() {
+	dispatcher := &slowDispatcher{}
+	ep := event.NewBatchEventProcessor(
+		event.WithBatchSize(1),
+		event.WithQueueSize(2),
+		event.WithFlushInterval(10*time.Millisecond),
+		event.WithEventDispatcher(dispatcher),
+	)
+	factory := client.OptimizelyFactory{}
+	optimizelyClient, err := factory.Client(
+		client.WithConfigManager(MockConfigManager{config: suite.tc.ProjectConfig}),
+		client.WithEventProcessor(ep),
+		client.WithOdpDisabled(true),
+	)
+	suite.Require().NoError(err)
+	defer optimizelyClient.Close()
+	suite.oc = &optimizely.OptlyClient{
+		OptimizelyClient: optimizelyClient,
+		ConfigManager:    MockConfigManager{config: suite.tc.ProjectConfig},
+	}
+
+	lines := make([]string, 50)
+	for i := range lines {
+		lines[i] = fmt.Sprintf(`{"eventKey": "purchase", "userId": "user%d"}`, i)
+	}
+	errs, summary := suite.post(strings.Join(lines, "\n"))
+	suite.Empty(errs)
+	suite.Equal(TrackBulkSummary{Tracked: 50}, summary)
+
+	// No event was discarded by the event processor
+	suite.Eventually(func() bool {
+		return atomic.LoadInt32(&dispatcher.dispatched) == 50
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestTrackBulkTestSuite(t *testing.T) {
+	suite.Run(t, new(TrackBulkTestSuite))
+}
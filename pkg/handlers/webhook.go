@@ -77,7 +77,7 @@ func NewWebhookHandler(optlyCache optimizely.Cache, projectMap map[int64]config.
 }
 
 // computeSignature computes signature based on payload
-func (h *OptlyWebhookHandler) computeSignature(payload []byte, secretKey string) string {
+func computeSignature(payload []byte, secretKey string) string {
 	mac := hmac.New(sha1.New, []byte(secretKey))
 	_, err := mac.Write(payload)
 
@@ -97,7 +97,7 @@ func (h *OptlyWebhookHandler) validateSignature(requestSignature string, payload
 		return false
 	}
 
-	computedSignature := h.computeSignature(payload, webhookConfig.Secret)
+	computedSignature := computeSignature(payload, webhookConfig.Secret)
 	return subtle.ConstantTimeCompare([]byte(computedSignature), []byte(requestSignature)) == 1
 }
 
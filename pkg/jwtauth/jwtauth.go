@@ -28,14 +28,15 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 )
 
-// BuildAPIAccessToken returns a token for accessing the API service using the argument SDK keys and TTL. It also returns the expiration timestamp.
-func BuildAPIAccessToken(sdkKeys []string, ttl time.Duration, key []byte) (tokenString string, err error) {
+// BuildAPIAccessToken returns a token for accessing the API service using the argument client ID, SDK keys and TTL. It also returns the expiration timestamp.
+func BuildAPIAccessToken(clientID string, sdkKeys []string, ttl time.Duration, key []byte) (tokenString string, err error) {
 	expires := time.Now().Add(ttl).Unix()
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"iss":      "Optimizely",
-		"sdk_keys": sdkKeys,
-		"exp":      expires,
+		"iss":       "Optimizely",
+		"client_id": clientID,
+		"sdk_keys":  sdkKeys,
+		"exp":       expires,
 	})
 	tokenString, err = token.SignedString(key)
 	if err != nil {
@@ -33,7 +33,7 @@ type JWTAuthTestSuite struct {
 func (s *JWTAuthTestSuite) TestBuildAPIAccessTokenSuccess() {
 	tokenTtl := 10 * time.Minute
 	secretKey := []byte("seekrit")
-	tokenString, err := BuildAPIAccessToken([]string{"123"}, tokenTtl, secretKey)
+	tokenString, err := BuildAPIAccessToken("clientid1", []string{"123"}, tokenTtl, secretKey)
 	s.NoError(err)
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (i interface{}, err error) {
 		return secretKey, nil
@@ -48,6 +48,7 @@ func (s *JWTAuthTestSuite) TestBuildAPIAccessTokenSuccess() {
 	sdkKey, ok := sdkKeys[0].(string)
 	s.True(ok)
 	s.Equal("123", sdkKey)
+	s.Equal("clientid1", claims["client_id"])
 	claimsExpFloat, ok := claims["exp"].(float64)
 	s.True(ok)
 	expectedExpiresIn := time.Now().Add(tokenTtl).Unix()
@@ -57,7 +58,7 @@ func (s *JWTAuthTestSuite) TestBuildAPIAccessTokenSuccess() {
 func (s *JWTAuthTestSuite) TestBuildAPIAccessTokenMultipleSDKKeysSuccess() {
 	tokenTtl := 10 * time.Minute
 	secretKey := []byte("seekrit")
-	tokenString, err := BuildAPIAccessToken([]string{"456", "789"}, tokenTtl, secretKey)
+	tokenString, err := BuildAPIAccessToken("clientid1", []string{"456", "789"}, tokenTtl, secretKey)
 	s.NoError(err)
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (i interface{}, err error) {
 		return secretKey, nil
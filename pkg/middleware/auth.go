@@ -271,35 +271,13 @@ func (a Auth) AuthorizeAPI(next http.Handler) http.Handler {
 			return
 		}
 
-		if a.enabled() {
-			claims := tk.Claims.(jwt.MapClaims)
-			if expired := (getNumberFromJSON(claims["exp"]) - time.Now().Unix()) <= 0; expired {
-				RenderError(errors.New("token expired"), http.StatusUnauthorized, w, r)
-				return
-			}
+		if err := a.ValidateAPIClaims(tk, r.Header.Get(OptlySDKHeader)); err != nil {
+			RenderError(err, http.StatusUnauthorized, w, r)
+			return
+		}
 
-			rawClaimsSdkKeys, ok := claims["sdk_keys"].([]interface{})
-			if !ok {
-				RenderError(errors.New("invalid claims: sdk_keys not found, or have the wrong type"), http.StatusUnauthorized, w, r)
-				return
-			}
-			sdkKeyFromHeader := r.Header.Get(OptlySDKHeader)
-			foundMatchingSdkKey := false
-			for _, rawSdkKey := range rawClaimsSdkKeys {
-				sdkKey, ok := rawSdkKey.(string)
-				if !ok {
-					GetLogger(r).Warn().Msgf("Non-string value in token claims sdk_keys: %v", sdkKey)
-					continue
-				}
-				if sdkKey == sdkKeyFromHeader {
-					foundMatchingSdkKey = true
-					break
-				}
-			}
-			if !foundMatchingSdkKey {
-				RenderError(errors.New("SDK key given in X-Optimizely-Sdk-Key header was not found in the SDK keys in this token's claims"), http.StatusUnauthorized, w, r)
-				return
-			}
+		if clientID := a.ClientID(tk); clientID != "" {
+			r = r.WithContext(context.WithValue(r.Context(), OptlyAPIClientIDKey, clientID))
 		}
 
 		next.ServeHTTP(w, r)
@@ -307,6 +285,55 @@ func (a Auth) AuthorizeAPI(next http.Handler) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
+// ValidateAPIClaims checks that the verified token has not expired and that its claims
+// grant access to the given SDK key. It is a no-op when authorization is disabled.
+func (a Auth) ValidateAPIClaims(tk *jwt.Token, sdkKey string) error {
+	if !a.enabled() {
+		return nil
+	}
+
+	claims := tk.Claims.(jwt.MapClaims)
+	if expired := (getNumberFromJSON(claims["exp"]) - time.Now().Unix()) <= 0; expired {
+		return errors.New("token expired")
+	}
+
+	rawClaimsSdkKeys, ok := claims["sdk_keys"].([]interface{})
+	if !ok {
+		return errors.New("invalid claims: sdk_keys not found, or have the wrong type")
+	}
+	for _, rawSdkKey := range rawClaimsSdkKeys {
+		claimsSdkKey, ok := rawSdkKey.(string)
+		if !ok {
+			log.Warn().Msgf("Non-string value in token claims sdk_keys: %v", rawSdkKey)
+			continue
+		}
+		if claimsSdkKey == sdkKey {
+			return nil
+		}
+	}
+
+	return errors.New("SDK key given in X-Optimizely-Sdk-Key header was not found in the SDK keys in this token's claims")
+}
+
+// ClientID returns the OAuth client ID of a verified token, read from the "client_id" claim or else the
+// "sub" claim. It is empty when authorization is disabled.
+func (a Auth) ClientID(tk *jwt.Token) string {
+	if !a.enabled() {
+		return ""
+	}
+
+	claims, ok := tk.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	for _, claim := range []string{"client_id", "sub"} {
+		if clientID, ok := claims[claim].(string); ok && clientID != "" {
+			return clientID
+		}
+	}
+	return ""
+}
+
 // NewAuth makes Auth middleware
 func NewAuth(authConfig *config.ServiceAuthConfig) *Auth {
 
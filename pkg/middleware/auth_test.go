@@ -37,6 +37,8 @@ type OptlyClaims struct {
 	Issuer    string   `json:"iss,omitempty"`
 	SdkKeys   []string `json:"sdk_keys,omitempty"`
 	Admin     bool     `json:"admin,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
 }
 
 func (c OptlyClaims) Valid() error {
@@ -304,6 +306,32 @@ func (suite *AuthTestSuite) TestAuthAuthorizeAPITokenAuthorizationValidClaims()
 	suite.Equal(http.StatusOK, rec.Code)
 }
 
+func (suite *AuthTestSuite) TestAuthAuthorizeAPISetsClientID() {
+	decoded, _ := base64.StdEncoding.DecodeString(suite.signatures[0])
+	auth := NewAuth(suite.authConfig)
+
+	scenarios := map[string]OptlyClaims{
+		"client1": {ExpiresAt: 12313123123213, SdkKeys: []string{"SDK_KEY"}, ClientID: "client1", Subject: "subject"},
+		"subject": {ExpiresAt: 12313123123213, SdkKeys: []string{"SDK_KEY"}, Subject: "subject"},
+		"":        {ExpiresAt: 12313123123213, SdkKeys: []string{"SDK_KEY"}},
+	}
+
+	for expected, claims := range scenarios {
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(decoded)
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/some_url", nil)
+		req.Header.Add("Authorization", "Bearer "+token)
+		req.Header.Add(OptlySDKHeader, "SDK_KEY")
+
+		var clientID string
+		auth.AuthorizeAPI(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clientID = GetAPIClientID(r)
+		})).ServeHTTP(rec, req)
+		suite.Equal(http.StatusOK, rec.Code)
+		suite.Equal(expected, clientID)
+	}
+}
+
 func (suite *AuthTestSuite) TestAuthAuthorizeAPITokenAuthorizationValidClaimsOtherSig() {
 
 	auth := NewAuth(suite.authConfig)
@@ -44,6 +44,9 @@ const OptlyExperimentKey = contextKey("experimentKey")
 // OptlyCacheKey is the context key for the OptlyCache
 const OptlyCacheKey = contextKey("optlyCache")
 
+// OptlyAPIClientIDKey is the context key for the OAuth client ID of the API access token
+const OptlyAPIClientIDKey = contextKey("apiClientID")
+
 // OptlySDKHeader is the header key for an ad-hoc SDK key
 const OptlySDKHeader = "X-Optimizely-SDK-Key"
 
@@ -56,6 +59,8 @@ const OptlyODPCacheHeader = "X-Optimizely-ODP-Cache-Name"
 // CachedOptlyMiddleware implements OptlyMiddleware backed by a cache
 type CachedOptlyMiddleware struct {
 	Cache optimizely.Cache
+	// SDKKeys optionally restricts the SDK keys that can be used, nil accepts every key
+	SDKKeys *SDKKeyFilter
 }
 
 // ClientCtx adds a pointer to an OptlyClient to the request context.
@@ -69,6 +74,12 @@ func (mw *CachedOptlyMiddleware) ClientCtx(next http.Handler) http.Handler {
 			return
 		}
 
+		if !mw.SDKKeys.Allowed(sdkKey) {
+			GetLogger(r).Warn().Msg("Rejected SDK key")
+			RenderError(ErrSDKKeyNotAllowed, http.StatusForbidden, w, r)
+			return
+		}
+
 		upsKey := r.Header.Get(OptlyUPSHeader)
 		// Storing Provided UserProfileService Key in cache, to be used for requests with the given sdkKey.
 		// This UserProfileService Key will override the default UserProfileService provided in Client Config.
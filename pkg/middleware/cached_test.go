@@ -23,11 +23,13 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/go-kit/kit/metrics/generic"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/optimizely/go-sdk/v2/pkg/entities"
 
+	"github.com/optimizely/agent/config"
 	"github.com/optimizely/agent/pkg/optimizely"
 	"github.com/optimizely/agent/pkg/optimizely/optimizelytest"
 )
@@ -67,7 +69,7 @@ func (suite *OptlyMiddlewareTestSuite) SetupTest() {
 	mockCache.On("GetClient", "403").Return(new(optimizely.OptlyClient), fmt.Errorf("403 forbidden"))
 	mockCache.On("GetClient", "INVALID").Return(new(optimizely.OptlyClient), optimizely.ErrValidationFailure)
 	mockCache.On("GetClient", "EXPECTED").Return(&expectedClient, nil)
-	suite.mw = &CachedOptlyMiddleware{mockCache}
+	suite.mw = &CachedOptlyMiddleware{Cache: mockCache}
 
 	suite.tc = optimizelytest.NewClient()
 	suite.tc.AddFeature(entities.Feature{
@@ -122,6 +124,32 @@ func (suite *OptlyMiddlewareTestSuite) TestGetClientMissingHeader() {
 	suite.Equal(http.StatusBadRequest, rec.Code)
 }
 
+func (suite *OptlyMiddlewareTestSuite) TestGetClientRejectedSDKKey() {
+	rejected := generic.NewCounter("rejected")
+	sdkKeys, err := NewSDKKeyFilter(config.SDKKeyFilterConfig{Allow: []string{"EXPECTED"}}, rejected)
+	suite.NoError(err)
+	suite.mw.SDKKeys = sdkKeys
+
+	handler := suite.mw.ClientCtx(AssertOptlyClientHandler(suite, &expectedClient))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add(OptlySDKHeader, "WITH_TEST_CLIENT")
+	req.Header.Add(OptlyUPSHeader, "in-memory")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assertError(suite.T(), rec, ErrSDKKeyNotAllowed.Error(), http.StatusForbidden)
+	suite.Equal(float64(1), rejected.Value())
+	suite.mw.Cache.(*MockCache).AssertNotCalled(suite.T(), "GetClient", "WITH_TEST_CLIENT")
+	suite.mw.Cache.(*MockCache).AssertNotCalled(suite.T(), "SetUserProfileService", "WITH_TEST_CLIENT", "in-memory")
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Add(OptlySDKHeader, "EXPECTED")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Equal(float64(1), rejected.Value())
+}
+
 func (suite *OptlyMiddlewareTestSuite) TestGetClientWithUserProfileService() {
 	handler := suite.mw.ClientCtx(AssertOptlyClientHandler(suite, &expectedClient))
 	req := httptest.NewRequest("GET", "/", nil)
@@ -0,0 +1,255 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package middleware //
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	go_kit_metrics "github.com/go-kit/kit/metrics"
+	"github.com/rs/zerolog/log"
+
+	"github.com/optimizely/agent/config"
+	"github.com/optimizely/agent/plugins/ratelimiter"
+)
+
+// RateLimitRejectedMetric is the name of the counter of requests rejected by the rate limiter
+const RateLimitRejectedMetric = "rate-limit.rejected"
+
+// Rate limit keys
+const (
+	RateLimitBySDKKey   = "sdkKey"
+	RateLimitByClientID = "clientID"
+	RateLimitByIP       = "ip"
+)
+
+// ErrRateLimited is returned when a request exceeds the configured rate limit
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RateLimiter applies token bucket rate limits to requests grouped by SDK key, OAuth client ID or remote IP.
+// A nil RateLimiter does not limit requests.
+type RateLimiter struct {
+	limiter        ratelimiter.Limiter
+	rate           float64
+	burst          int
+	key            string
+	trustedProxies []*net.IPNet
+	rejected       go_kit_metrics.Counter
+}
+
+// NewRateLimiter returns a RateLimiter for the given configuration, or nil if rate limiting is disabled.
+// Rejected requests are counted by the optional rejected counter.
+func NewRateLimiter(conf config.RateLimitConfig, rejected go_kit_metrics.Counter) (*RateLimiter, error) {
+	if conf.Rate <= 0 {
+		return nil, nil
+	}
+
+	key := conf.Key
+	switch key {
+	case "":
+		key = RateLimitBySDKKey
+	case RateLimitBySDKKey, RateLimitByClientID, RateLimitByIP:
+	default:
+		return nil, fmt.Errorf("invalid rate limit key %q", conf.Key)
+	}
+
+	burst := conf.Burst
+	if burst <= 0 {
+		burst = int(math.Ceil(conf.Rate))
+	}
+
+	trustedProxies, err := parseTrustedProxies(conf.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter, err := newLimiter(conf.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RateLimiter{
+		limiter:        limiter,
+		rate:           conf.Rate,
+		burst:          burst,
+		key:            key,
+		trustedProxies: trustedProxies,
+		rejected:       rejected,
+	}, nil
+}
+
+// parseTrustedProxies parses IPs and CIDR ranges, a single IP is a range of its own
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if ip := net.ParseIP(proxy); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// newLimiter creates the default rate limiter plugin and maps its service config onto it
+func newLimiter(storeConf map[string]interface{}) (ratelimiter.Limiter, error) {
+	name, _ := storeConf["default"].(string)
+	creator, ok := ratelimiter.Creators[name]
+	if !ok {
+		return nil, fmt.Errorf("rate limiter %q not found", name)
+	}
+
+	limiter := creator()
+	if services, ok := storeConf["services"].(map[string]interface{}); ok {
+		if serviceConf, ok := services[name]; ok {
+			data, err := json.Marshal(serviceConf)
+			if err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(data, limiter); err != nil {
+				return nil, fmt.Errorf("unable to configure rate limiter %q: %w", name, err)
+			}
+		}
+	}
+
+	log.Info().Msgf("Rate Limiter of type: %q created", name)
+	return limiter, nil
+}
+
+// Allow takes a token from the bucket of the request and returns whether the request may proceed and,
+// when it may not, how long to wait before retrying. Requests are allowed if the rate limiter fails.
+func (l *RateLimiter) Allow(sdkKey, clientID, remoteAddr string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+
+	key := l.bucketKey(sdkKey, clientID, remoteAddr)
+	allowed, retryAfter, err := l.limiter.Take(key, l.rate, l.burst)
+	if err != nil {
+		log.Warn().Err(err).Msg("Unable to apply rate limit")
+		return true, 0
+	}
+
+	if !allowed && l.rejected != nil {
+		l.rejected.Add(1)
+	}
+	return allowed, retryAfter
+}
+
+// ClientIP returns the IP of the client of a request received from remoteAddr. When remoteAddr is a trusted
+// proxy, the X-Forwarded-For values are read from the right and the first address that is not a trusted proxy
+// is returned. Without trusted proxies every client behind a load balancer shares the IP of the load balancer.
+func (l *RateLimiter) ClientIP(remoteAddr string, forwardedFor []string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	if l == nil || !l.trusted(host) {
+		return host
+	}
+
+	var hops []string
+	for _, value := range forwardedFor {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		host = hops[i]
+		if !l.trusted(host) {
+			break
+		}
+	}
+	return host
+}
+
+func (l *RateLimiter) trusted(host string) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, proxy := range l.trustedProxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// bucketKey returns the key identifying the token bucket of a request. Requests without an OAuth client ID
+// are grouped by remote IP.
+func (l *RateLimiter) bucketKey(sdkKey, clientID, remoteAddr string) string {
+	switch {
+	case l.key == RateLimitBySDKKey:
+		return RateLimitBySDKKey + ":" + strings.SplitN(sdkKey, ":", 2)[0]
+	case l.key == RateLimitByClientID && clientID != "":
+		return RateLimitByClientID + ":" + clientID
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return RateLimitByIP + ":" + host
+}
+
+// Limit is middleware rejecting the requests that exceed the rate limit with a 429 response
+func (l *RateLimiter) Limit(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remoteIP := l.ClientIP(r.RemoteAddr, r.Header.Values("X-Forwarded-For"))
+		allowed, retryAfter := l.Allow(r.Header.Get(OptlySDKHeader), GetAPIClientID(r), remoteIP)
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(RetryAfterSeconds(retryAfter)))
+			RenderError(ErrRateLimited, http.StatusTooManyRequests, w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RetryAfterSeconds rounds a retry delay up to whole seconds, as expected by the Retry-After header
+func RetryAfterSeconds(retryAfter time.Duration) int {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
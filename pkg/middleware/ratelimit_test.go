@@ -0,0 +1,204 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package middleware //
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics/generic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/agent/config"
+	"github.com/optimizely/agent/plugins/ratelimiter"
+)
+
+// testLimiter allows the first `allow` requests of every key
+type testLimiter struct {
+	Allow int `json:"allow"`
+	taken map[string]int
+	rate  float64
+	burst int
+	err   error
+}
+
+func (l *testLimiter) Take(key string, rate float64, burst int) (bool, time.Duration, error) {
+	if l.err != nil {
+		return false, 0, l.err
+	}
+	l.rate, l.burst = rate, burst
+	l.taken[key]++
+	return l.taken[key] <= l.Allow, 1500 * time.Millisecond, nil
+}
+
+func init() {
+	ratelimiter.Add("test", func() ratelimiter.Limiter {
+		return &testLimiter{taken: map[string]int{}}
+	})
+}
+
+type RateLimiterTestSuite struct {
+	suite.Suite
+	conf     config.RateLimitConfig
+	rejected *generic.Counter
+}
+
+func (s *RateLimiterTestSuite) SetupTest() {
+	s.conf = config.RateLimitConfig{
+		Rate: 2.5,
+		Key:  RateLimitBySDKKey,
+		Store: config.RateLimitStoreConfigs{
+			"default": "test",
+			"services": map[string]interface{}{
+				"test": map[string]interface{}{"allow": 1},
+			},
+		},
+	}
+	s.rejected = generic.NewCounter("rejected")
+}
+
+func (s *RateLimiterTestSuite) newRateLimiter() (*RateLimiter, *testLimiter) {
+	rl, err := NewRateLimiter(s.conf, s.rejected)
+	s.Require().NoError(err)
+	return rl, rl.limiter.(*testLimiter)
+}
+
+func (s *RateLimiterTestSuite) serve(rl *RateLimiter, sdkKey, clientID, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add(OptlySDKHeader, sdkKey)
+	req.RemoteAddr = remoteAddr
+	if clientID != "" {
+		req = req.WithContext(context.WithValue(req.Context(), OptlyAPIClientIDKey, clientID))
+	}
+
+	rec := httptest.NewRecorder()
+	rl.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(rec, req)
+	return rec
+}
+
+func (s *RateLimiterTestSuite) TestDisabled() {
+	s.conf.Rate = 0
+	rl, err := NewRateLimiter(s.conf, s.rejected)
+	s.NoError(err)
+	s.Nil(rl)
+
+	for i := 0; i < 3; i++ {
+		s.Equal(http.StatusOK, s.serve(rl, "sdkKey", "", "").Code)
+	}
+}
+
+func (s *RateLimiterTestSuite) TestInvalidConfig() {
+	s.conf.Key = "header"
+	_, err := NewRateLimiter(s.conf, s.rejected)
+	s.EqualError(err, `invalid rate limit key "header"`)
+
+	s.conf.Key = RateLimitBySDKKey
+	s.conf.Store = config.RateLimitStoreConfigs{"default": "DNE"}
+	_, err = NewRateLimiter(s.conf, s.rejected)
+	s.EqualError(err, `rate limiter "DNE" not found`)
+}
+
+func (s *RateLimiterTestSuite) TestDefaultBurst() {
+	rl, limiter := s.newRateLimiter()
+	s.serve(rl, "sdkKey", "", "")
+	s.Equal(2.5, limiter.rate)
+	s.Equal(3, limiter.burst)
+
+	s.conf.Burst = 10
+	rl, limiter = s.newRateLimiter()
+	s.serve(rl, "sdkKey", "", "")
+	s.Equal(10, limiter.burst)
+}
+
+func (s *RateLimiterTestSuite) TestLimitBySDKKey() {
+	rl, limiter := s.newRateLimiter()
+	s.Equal(http.StatusOK, s.serve(rl, "sdkKey", "", "").Code)
+
+	rec := s.serve(rl, "sdkKey:token", "", "")
+	assertError(s.T(), rec, ErrRateLimited.Error(), http.StatusTooManyRequests)
+	s.Equal("2", rec.Header().Get("Retry-After"))
+	s.Equal(float64(1), s.rejected.Value())
+
+	s.Equal(http.StatusOK, s.serve(rl, "other", "", "").Code)
+	s.Equal(map[string]int{"sdkKey:sdkKey": 2, "sdkKey:other": 1}, limiter.taken)
+}
+
+func (s *RateLimiterTestSuite) TestLimitByClientID() {
+	s.conf.Key = RateLimitByClientID
+	rl, limiter := s.newRateLimiter()
+	s.serve(rl, "sdkKey", "client1", "10.0.0.1:1234")
+	s.serve(rl, "sdkKey", "", "10.0.0.1:1234")
+	s.Equal(map[string]int{"clientID:client1": 1, "ip:10.0.0.1": 1}, limiter.taken)
+}
+
+func (s *RateLimiterTestSuite) TestLimitByIP() {
+	s.conf.Key = RateLimitByIP
+	rl, limiter := s.newRateLimiter()
+	s.serve(rl, "sdkKey", "client1", "10.0.0.1:1234")
+	s.serve(rl, "other", "", "10.0.0.1:5678")
+	s.Equal(map[string]int{"ip:10.0.0.1": 2}, limiter.taken)
+}
+
+func (s *RateLimiterTestSuite) TestLimitByForwardedIP() {
+	s.conf.Key = RateLimitByIP
+	s.conf.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.1"}
+	rl, limiter := s.newRateLimiter()
+
+	serve := func(remoteAddr string, forwardedFor ...string) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		for _, value := range forwardedFor {
+			req.Header.Add("X-Forwarded-For", value)
+		}
+		rl.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(httptest.NewRecorder(), req)
+	}
+	serve("10.0.0.1:1234", "1.1.1.1")
+	serve("10.0.0.2:1234", "9.9.9.9, 2.2.2.2", "192.168.1.1")
+	serve("10.0.0.3:1234", "10.0.0.4")
+	// The header of untrusted clients is ignored
+	serve("3.3.3.3:1234", "1.1.1.1")
+	s.Equal(map[string]int{"ip:1.1.1.1": 1, "ip:2.2.2.2": 1, "ip:10.0.0.4": 1, "ip:3.3.3.3": 1}, limiter.taken)
+
+	s.conf.TrustedProxies = []string{"proxy"}
+	_, err := NewRateLimiter(s.conf, s.rejected)
+	s.EqualError(err, `invalid trusted proxy "proxy"`)
+}
+
+func (s *RateLimiterTestSuite) TestLimiterErrorAllowsRequests() {
+	rl, limiter := s.newRateLimiter()
+	limiter.err = errors.New("unavailable")
+	for i := 0; i < 3; i++ {
+		s.Equal(http.StatusOK, s.serve(rl, "sdkKey", "", "").Code)
+	}
+	s.Equal(float64(0), s.rejected.Value())
+}
+
+func TestRateLimiterTestSuite(t *testing.T) {
+	suite.Run(t, new(RateLimiterTestSuite))
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	assert.Equal(t, 1, RetryAfterSeconds(0))
+	assert.Equal(t, 1, RetryAfterSeconds(400*time.Millisecond))
+	assert.Equal(t, 2, RetryAfterSeconds(1001*time.Millisecond))
+}
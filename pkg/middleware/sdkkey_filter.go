@@ -0,0 +1,244 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package middleware //
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	go_kit_metrics "github.com/go-kit/kit/metrics"
+	"github.com/rs/zerolog/log"
+
+	"github.com/optimizely/agent/config"
+)
+
+// SDKKeyRejectedMetric is the name of the counter of requests rejected by the SDK key filter
+const SDKKeyRejectedMetric = "sdk-key.rejected"
+
+// ErrSDKKeyNotAllowed is returned when an SDK key is rejected by the SDK key filter
+var ErrSDKKeyNotAllowed = errors.New("SDK key is not allowed by this Agent")
+
+// SDKKeyFilter restricts the SDK keys that Agent will load using static and file based
+// allow and deny lists. A nil SDKKeyFilter accepts every SDK key.
+type SDKKeyFilter struct {
+	allow     map[string]struct{}
+	deny      map[string]struct{}
+	allowFile *keyFile
+	denyFile  *keyFile
+	rejected  go_kit_metrics.Counter
+}
+
+// NewSDKKeyFilter returns an SDKKeyFilter for the given configuration, or nil if no restriction is configured.
+// Allow and deny files are watched until the filter is closed and reloaded on change.
+// Rejected SDK keys are counted by the optional rejected counter.
+// An error is returned when the deny file can not be read, since the keys it lists would be accepted, while an
+// allow file that can not be read rejects every key.
+func NewSDKKeyFilter(conf config.SDKKeyFilterConfig, rejected go_kit_metrics.Counter) (*SDKKeyFilter, error) {
+	if len(conf.Allow) == 0 && conf.AllowFile == "" && len(conf.Deny) == 0 && conf.DenyFile == "" {
+		return nil, nil
+	}
+
+	denyFile, err := newKeyFile(conf.DenyFile)
+	if err != nil {
+		denyFile.close()
+		return nil, fmt.Errorf("unable to read SDK key deny file: %w", err)
+	}
+	allowFile, _ := newKeyFile(conf.AllowFile)
+
+	return &SDKKeyFilter{
+		allow:     toKeySet(conf.Allow),
+		deny:      toKeySet(conf.Deny),
+		allowFile: allowFile,
+		denyFile:  denyFile,
+		rejected:  rejected,
+	}, nil
+}
+
+// Allowed returns whether the SDK key may be used. Only the SDK key part of a "<sdkKey>:<token>" value
+// is matched. Denied keys are always rejected, and when an allow list or file is configured only the
+// keys it contains are accepted.
+func (f *SDKKeyFilter) Allowed(sdkKey string) bool {
+	if f == nil {
+		return true
+	}
+
+	key := strings.SplitN(sdkKey, ":", 2)[0]
+	if f.allowed(key) {
+		return true
+	}
+
+	if f.rejected != nil {
+		f.rejected.Add(1)
+	}
+	return false
+}
+
+// Close stops watching the allow and deny files
+func (f *SDKKeyFilter) Close() {
+	if f == nil {
+		return
+	}
+	f.allowFile.close()
+	f.denyFile.close()
+}
+
+func (f *SDKKeyFilter) allowed(key string) bool {
+	if _, ok := f.deny[key]; ok || f.denyFile.contains(key) {
+		return false
+	}
+
+	if len(f.allow) == 0 && f.allowFile == nil {
+		return true
+	}
+
+	_, ok := f.allow[key]
+	return ok || f.allowFile.contains(key)
+}
+
+// keyFile holds the SDK keys listed in a file, one per line
+type keyFile struct {
+	path    string
+	lock    sync.RWMutex
+	keys    map[string]struct{}
+	watcher *fsnotify.Watcher
+	// target is the file that path resolved to when it was last loaded
+	target string
+}
+
+// newKeyFile loads the file and watches it, the returned error is the one of the first load
+func newKeyFile(path string) (*keyFile, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	kf := &keyFile{path: filepath.Clean(path)}
+	loadErr := kf.load()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Error().Err(err).Str("file", kf.path).Msg("Unable to watch SDK key file")
+		return kf, loadErr
+	}
+	// Watch the directory rather than the file so that files replaced by a rename are still picked up
+	if err := watcher.Add(filepath.Dir(kf.path)); err != nil {
+		log.Error().Err(err).Str("file", kf.path).Msg("Unable to watch SDK key file")
+		_ = watcher.Close()
+		return kf, loadErr
+	}
+
+	kf.watcher = watcher
+	go kf.watch(watcher)
+	return kf, loadErr
+}
+
+// load reads the keys from the file. Blank lines and lines starting with # are ignored.
+// The previous keys are kept if the file can not be read.
+func (kf *keyFile) load() error {
+	if target, err := filepath.EvalSymlinks(kf.path); err == nil {
+		kf.target = target
+	}
+
+	file, err := os.Open(kf.path)
+	if err != nil {
+		log.Error().Err(err).Str("file", kf.path).Msg("Unable to read SDK key file")
+		return err
+	}
+	defer file.Close()
+
+	keys := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys[line] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Error().Err(err).Str("file", kf.path).Msg("Unable to read SDK key file")
+		return err
+	}
+
+	kf.lock.Lock()
+	kf.keys = keys
+	kf.lock.Unlock()
+	log.Info().Str("file", kf.path).Int("keys", len(keys)).Msg("Loaded SDK key file")
+	return nil
+}
+
+// retargeted returns whether the path resolves to another file than when it was last loaded, as when the
+// ..data symlink of a mounted Kubernetes ConfigMap or Secret is swapped
+func (kf *keyFile) retargeted() bool {
+	target, err := filepath.EvalSymlinks(kf.path)
+	return err == nil && target != kf.target
+}
+
+func (kf *keyFile) watch(watcher *fsnotify.Watcher) {
+	defer watcher.Close()
+	for {
+		select {
+		case e, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(e.Name) == kf.path && e.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 || kf.retargeted() {
+				_ = kf.load()
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Error().Err(err).Str("file", kf.path).Msg("Error watching SDK key file")
+		}
+	}
+}
+
+// close stops the watch goroutine, which returns once the event channels of the watcher are closed
+func (kf *keyFile) close() {
+	if kf == nil || kf.watcher == nil {
+		return
+	}
+	if err := kf.watcher.Close(); err != nil {
+		log.Warn().Err(err).Str("file", kf.path).Msg("Unable to stop watching SDK key file")
+	}
+}
+
+func (kf *keyFile) contains(key string) bool {
+	if kf == nil {
+		return false
+	}
+
+	kf.lock.RLock()
+	defer kf.lock.RUnlock()
+	_, ok := kf.keys[key]
+	return ok
+}
+
+func toKeySet(keys []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		set[strings.TrimSpace(key)] = struct{}{}
+	}
+	return set
+}
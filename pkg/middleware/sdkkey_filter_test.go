@@ -0,0 +1,137 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package middleware //
+package middleware
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics/generic"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/optimizely/agent/config"
+)
+
+func TestSDKKeyFilterNotConfigured(t *testing.T) {
+	filter, err := NewSDKKeyFilter(config.SDKKeyFilterConfig{Allow: []string{}, Deny: []string{}}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, filter)
+	assert.True(t, filter.Allowed("any"))
+}
+
+func TestSDKKeyFilterAllow(t *testing.T) {
+	rejected := generic.NewCounter("rejected")
+	filter, err := NewSDKKeyFilter(config.SDKKeyFilterConfig{Allow: []string{"one", "two"}}, rejected)
+	assert.NoError(t, err)
+
+	assert.True(t, filter.Allowed("one"))
+	assert.True(t, filter.Allowed("two:token"))
+	assert.False(t, filter.Allowed("three"))
+	assert.Equal(t, float64(1), rejected.Value())
+}
+
+func TestSDKKeyFilterDeny(t *testing.T) {
+	filter, err := NewSDKKeyFilter(config.SDKKeyFilterConfig{Allow: []string{"one", "two"}, Deny: []string{"two"}}, nil)
+	assert.NoError(t, err)
+
+	assert.True(t, filter.Allowed("one"))
+	assert.False(t, filter.Allowed("two"))
+	assert.False(t, filter.Allowed("two:token"))
+
+	filter, err = NewSDKKeyFilter(config.SDKKeyFilterConfig{Deny: []string{"two"}}, nil)
+	assert.NoError(t, err)
+	assert.True(t, filter.Allowed("one"))
+	assert.False(t, filter.Allowed("two"))
+}
+
+func TestSDKKeyFilterMissingAllowFile(t *testing.T) {
+	filter, err := NewSDKKeyFilter(config.SDKKeyFilterConfig{AllowFile: filepath.Join(t.TempDir(), "missing")}, nil)
+	assert.NoError(t, err)
+	assert.False(t, filter.Allowed("one"))
+}
+
+func TestSDKKeyFilterMissingDenyFile(t *testing.T) {
+	filter, err := NewSDKKeyFilter(config.SDKKeyFilterConfig{DenyFile: filepath.Join(t.TempDir(), "missing")}, nil)
+	assert.Error(t, err)
+	assert.Nil(t, filter)
+}
+
+func TestSDKKeyFilterReloadsFiles(t *testing.T) {
+	dir := t.TempDir()
+	allowFile := filepath.Join(dir, "allow.txt")
+	denyFile := filepath.Join(dir, "deny.txt")
+	assert.NoError(t, os.WriteFile(allowFile, []byte("# allowed keys\none\n\ntwo\n"), 0600))
+	assert.NoError(t, os.WriteFile(denyFile, []byte(""), 0600))
+
+	filter, err := NewSDKKeyFilter(config.SDKKeyFilterConfig{AllowFile: allowFile, DenyFile: denyFile}, nil)
+	assert.NoError(t, err)
+	assert.True(t, filter.Allowed("one"))
+	assert.True(t, filter.Allowed("two"))
+	assert.False(t, filter.Allowed("three"))
+	assert.False(t, filter.Allowed("# allowed keys"))
+
+	assert.NoError(t, os.WriteFile(allowFile, []byte("one\nthree\n"), 0600))
+	assert.NoError(t, os.WriteFile(denyFile, []byte("one\n"), 0600))
+	assert.Eventually(t, func() bool {
+		return !filter.Allowed("one") && !filter.Allowed("two") && filter.Allowed("three")
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSDKKeyFilterReloadsSwappedSymlink(t *testing.T) {
+	// Kubernetes mounts ConfigMaps and Secrets as symlinks to a ..data symlink, which is swapped on update
+	dir := t.TempDir()
+	for version, keys := range map[string]string{"v1": "one\n", "v2": "two\n"} {
+		assert.NoError(t, os.Mkdir(filepath.Join(dir, version), 0700))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, version, "allow.txt"), []byte(keys), 0600))
+	}
+	assert.NoError(t, os.Symlink("v1", filepath.Join(dir, "..data")))
+	assert.NoError(t, os.Symlink(filepath.Join("..data", "allow.txt"), filepath.Join(dir, "allow.txt")))
+
+	filter, err := NewSDKKeyFilter(config.SDKKeyFilterConfig{AllowFile: filepath.Join(dir, "allow.txt")}, nil)
+	assert.NoError(t, err)
+	defer filter.Close()
+	assert.True(t, filter.Allowed("one"))
+	assert.False(t, filter.Allowed("two"))
+
+	assert.NoError(t, os.Symlink("v2", filepath.Join(dir, "..data_tmp")))
+	assert.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+	assert.Eventually(t, func() bool {
+		return !filter.Allowed("one") && filter.Allowed("two")
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSDKKeyFilterClose(t *testing.T) {
+	dir := t.TempDir()
+	allowFile := filepath.Join(dir, "allow.txt")
+	assert.NoError(t, os.WriteFile(allowFile, []byte("one\n"), 0600))
+
+	filter, err := NewSDKKeyFilter(config.SDKKeyFilterConfig{AllowFile: allowFile}, nil)
+	assert.NoError(t, err)
+	filter.Close()
+
+	// The file is no longer reloaded once the filter is closed
+	assert.NoError(t, os.WriteFile(allowFile, []byte("two\n"), 0600))
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, filter.Allowed("one"))
+	assert.False(t, filter.Allowed("two"))
+
+	var closed *SDKKeyFilter
+	closed.Close()
+}
@@ -47,6 +47,12 @@ func GetOptlyClient(r *http.Request) (*optimizely.OptlyClient, error) {
 	return optlyClient, nil
 }
 
+// GetAPIClientID returns the OAuth client ID of the API access token, or an empty string when API auth is disabled
+func GetAPIClientID(r *http.Request) string {
+	clientID, _ := r.Context().Value(OptlyAPIClientIDKey).(string)
+	return clientID
+}
+
 // GetOptlyCache is a utility to extract the OptlyCache from the http request context.
 func GetOptlyCache(r *http.Request) (optimizely.Cache, error) {
 	cache, ok := r.Context().Value(OptlyCacheKey).(optimizely.Cache)
@@ -21,10 +21,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
 	"os"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	cmap "github.com/orcaman/concurrent-map"
 	"github.com/rs/zerolog/log"
@@ -33,6 +36,11 @@ import (
 	"github.com/optimizely/agent/config"
 	"github.com/optimizely/agent/pkg/syncer"
 	"github.com/optimizely/agent/plugins/cmabcache"
+	"github.com/optimizely/agent/plugins/datafilestore"
+	"github.com/optimizely/agent/plugins/deadletter"
+	"github.com/optimizely/agent/plugins/decisionlog"
+	"github.com/optimizely/agent/plugins/eventforwarder"
+	"github.com/optimizely/agent/plugins/eventqueue"
 	"github.com/optimizely/agent/plugins/odpcache"
 	"github.com/optimizely/agent/plugins/userprofileservice"
 	cachePkg "github.com/optimizely/go-sdk/v2/pkg/cache"
@@ -41,6 +49,8 @@ import (
 	"github.com/optimizely/go-sdk/v2/pkg/decision"
 	"github.com/optimizely/go-sdk/v2/pkg/event"
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
+	go_sdk_metrics "github.com/optimizely/go-sdk/v2/pkg/metrics"
+	"github.com/optimizely/go-sdk/v2/pkg/notification"
 	"github.com/optimizely/go-sdk/v2/pkg/odp"
 	odpEventPkg "github.com/optimizely/go-sdk/v2/pkg/odp/event"
 	odpSegmentPkg "github.com/optimizely/go-sdk/v2/pkg/odp/segment"
@@ -53,6 +63,11 @@ const (
 	userProfileServicePlugin = "UserProfileService"
 	odpCachePlugin           = "ODP Cache"
 	cmabCachePlugin          = "CMAB Cache"
+	datafileStorePlugin      = "Datafile Store"
+	decisionLogSinkPlugin    = "Decision Log Sink"
+	eventQueuePlugin         = "Event Queue"
+	deadLetterStorePlugin    = "Dead Letter Store"
+	eventForwarderPlugin     = "Event Forwarder"
 )
 
 // OptlyCache implements the Cache interface backed by a concurrent map.
@@ -63,8 +78,15 @@ type OptlyCache struct {
 	userProfileServiceMap cmap.ConcurrentMap
 	odpCacheMap           cmap.ConcurrentMap
 	cmabCacheMap          cmap.ConcurrentMap
+	deadLetters           *DeadLetters
 	ctx                   context.Context
 	wg                    sync.WaitGroup
+
+	usage          *clientUsage
+	maxClients     int
+	clientsCreated go_sdk_metrics.Counter
+	clientsEvicted go_sdk_metrics.Counter
+	activeClients  go_sdk_metrics.Gauge
 }
 
 // NewCache returns a new implementation of OptlyCache interface backed by a concurrent map.
@@ -74,18 +96,80 @@ func NewCache(ctx context.Context, conf config.AgentConfig, metricsRegistry *Met
 	cmLoader := func(sdkkey string, options ...sdkconfig.OptionFunc) SyncedConfigManager {
 		return sdkconfig.NewPollingProjectConfigManager(sdkkey, options...)
 	}
+	if conf.Client.Offline.Enable {
+		log.Info().Str("datafileDir", conf.Client.Offline.DatafileDir).Msg("Offline mode enabled, datafiles are read from disk")
+		cmLoader = func(sdkkey string, _ ...sdkconfig.OptionFunc) SyncedConfigManager {
+			return NewFileConfigManager(ctx, sdkkey, conf.Client.Offline.DatafileDir)
+		}
+	}
 
 	userProfileServiceMap := cmap.New()
 	odpCacheMap := cmap.New()
 	cmabCacheMap := cmap.New()
+	decisionLogger := NewDecisionLogger(conf.Client.DecisionLog, metricsRegistry)
+	eventForwarder := NewEventForwarder(conf.Client.EventForwarding, metricsRegistry)
+	deadLetters := NewDeadLetters(conf.Client.DeadLetter, eventForwarder, metricsRegistry)
+	eventPipelines, err := NewEventPipelines(conf.Client.EventPipelines)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid eventPipelines configuration")
+	}
+	services := clientServices{
+		metricsRegistry:       metricsRegistry,
+		tracer:                tracer,
+		userProfileServiceMap: userProfileServiceMap,
+		odpCacheMap:           odpCacheMap,
+		cmabCacheMap:          cmabCacheMap,
+		decisionLogger:        decisionLogger,
+		deadLetters:           deadLetters,
+		eventForwarder:        eventForwarder,
+		eventPipelines:        eventPipelines,
+	}
 	cache := &OptlyCache{
 		ctx:                   ctx,
 		wg:                    sync.WaitGroup{},
-		loader:                defaultLoader(conf, metricsRegistry, tracer, userProfileServiceMap, odpCacheMap, cmabCacheMap, cmLoader, event.NewBatchEventProcessor),
+		loader:                defaultLoader(conf, services, cmLoader, event.NewBatchEventProcessor),
 		optlyMap:              cmap.New(),
 		userProfileServiceMap: userProfileServiceMap,
 		odpCacheMap:           odpCacheMap,
 		cmabCacheMap:          cmabCacheMap,
+		deadLetters:           deadLetters,
+		usage:                 newClientUsage(),
+		maxClients:            conf.Client.MaxClients,
+		clientsCreated:        metricsRegistry.GetCounter("clients.created"),
+		clientsEvicted:        metricsRegistry.GetCounter("clients.evicted"),
+		activeClients:         metricsRegistry.GetGauge("clients.active"),
+	}
+
+	if decisionLogger != nil {
+		cache.wg.Add(1)
+		go func() {
+			defer cache.wg.Done()
+			decisionLogger.Run(ctx)
+		}()
+	}
+
+	if deadLetters != nil {
+		cache.wg.Add(1)
+		go func() {
+			defer cache.wg.Done()
+			deadLetters.Run(ctx)
+		}()
+	}
+
+	if eventForwarder != nil {
+		cache.wg.Add(1)
+		go func() {
+			defer cache.wg.Done()
+			eventForwarder.Run(ctx)
+		}()
+	}
+
+	if conf.Client.IdleTimeout > 0 {
+		cache.wg.Add(1)
+		go func() {
+			defer cache.wg.Done()
+			cache.evictIdleClients(conf.Client.IdleTimeout)
+		}()
 	}
 
 	return cache
@@ -109,6 +193,7 @@ func (c *OptlyCache) Init(sdkKeys []string) {
 func (c *OptlyCache) GetClient(sdkKey string) (*OptlyClient, error) {
 	val, ok := c.optlyMap.Get(sdkKey)
 	if ok {
+		c.usage.touch(sdkKey)
 		return val.(*OptlyClient), nil
 	}
 
@@ -117,14 +202,27 @@ func (c *OptlyCache) GetClient(sdkKey string) (*OptlyClient, error) {
 		return oc, err
 	}
 
-	set := c.optlyMap.SetIfAbsent(sdkKey, oc)
+	evicted, set := c.usage.add(sdkKey, func() bool { return c.optlyMap.SetIfAbsent(sdkKey, oc) })
 	if set {
+		c.clientsCreated.Add(1)
 		c.wg.Add(1)
 		go func() {
 			defer c.wg.Done()
-			<-c.ctx.Done()
+			select {
+			case <-c.ctx.Done():
+			case <-evicted:
+			}
+			// Closing the client stops its poller and flushes the pending events
 			oc.Close()
 		}()
+
+		// Make room for the new client by evicting the least recently used ones
+		for c.maxClients > 0 && c.usage.len() > c.maxClients {
+			if key, ok := c.usage.leastRecentlyUsed(); ok {
+				c.evict(key, "maxClients reached")
+			}
+		}
+		c.activeClients.Set(float64(c.usage.len()))
 		return oc, err
 	}
 
@@ -164,11 +262,79 @@ func (c *OptlyCache) SetCMABCache(sdkKey, cmabCache string) {
 	c.cmabCacheMap.SetIfAbsent(sdkKey, cmabCache)
 }
 
+// DatafileInfo returns the source and revision of the datafile used by each cached client, keyed by SDK key
+func (c *OptlyCache) DatafileInfo() map[string]DatafileInfo {
+	info := make(map[string]DatafileInfo)
+	for clientInfo := range c.optlyMap.IterBuffered() {
+		if optlyClient, ok := clientInfo.Val.(*OptlyClient); ok {
+			// Never expose the datafile access token
+			info[strings.Split(clientInfo.Key, ":")[0]] = optlyClient.DatafileInfo()
+		}
+	}
+	return info
+}
+
+// DeadLetters returns the dead-letter store of the event batches that could not be dispatched, or nil when no
+// store is configured
+func (c *OptlyCache) DeadLetters() *DeadLetters {
+	return c.deadLetters
+}
+
+// evictIdleClients periodically evicts the clients which have not been used for the idle timeout
+func (c *OptlyCache) evictIdleClients(idleTimeout time.Duration) {
+	interval := idleTimeout / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, key := range c.usage.idleSince(time.Now().Add(-idleTimeout)) {
+				c.evict(key, "idle timeout")
+			}
+			c.activeClients.Set(float64(c.usage.len()))
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// evict removes the client from the cache, the client is closed asynchronously
+func (c *OptlyCache) evict(sdkKey, reason string) {
+	if !c.usage.remove(sdkKey, func() { c.optlyMap.Remove(sdkKey) }) {
+		return
+	}
+	c.clientsEvicted.Add(1)
+
+	message := "Evicted Optimizely client"
+	if ShouldIncludeSDKKey {
+		log.Info().Str("sdkKey", sdkKey).Str("reason", reason).Msg(message)
+	} else {
+		log.Info().Str("reason", reason).Msg(message)
+	}
+}
+
 // Wait for all optimizely clients to gracefully shutdown
 func (c *OptlyCache) Wait() {
 	c.wg.Wait()
 }
 
+// clientServices holds the collaborators shared by every client created by defaultLoader, nil when not configured
+type clientServices struct {
+	metricsRegistry       *MetricsRegistry
+	tracer                trace.Tracer
+	userProfileServiceMap cmap.ConcurrentMap
+	odpCacheMap           cmap.ConcurrentMap
+	cmabCacheMap          cmap.ConcurrentMap
+	decisionLogger        *DecisionLogger
+	deadLetters           *DeadLetters
+	eventForwarder        *EventForwarder
+	eventPipelines        *EventPipelines
+}
+
 // ErrValidationFailure is returned when the provided SDK key fails initial validation
 var ErrValidationFailure = errors.New("sdkKey failed validation")
 
@@ -183,15 +349,40 @@ func regexValidator(sdkKeyRegex string) func(string) bool {
 
 func defaultLoader(
 	agentConf config.AgentConfig,
-	metricsRegistry *MetricsRegistry,
-	tracer trace.Tracer,
-	userProfileServiceMap cmap.ConcurrentMap,
-	odpCacheMap cmap.ConcurrentMap,
-	cmabCacheMap cmap.ConcurrentMap,
+	services clientServices,
 	pcFactory func(sdkKey string, options ...sdkconfig.OptionFunc) SyncedConfigManager,
 	bpFactory func(options ...event.BPOptionConfig) *event.BatchEventProcessor) func(clientKey string) (*OptlyClient, error) {
 	clientConf := agentConf.Client
 	validator := regexValidator(clientConf.SdkKeyRegex)
+	// Datafile stores and event queues can not be selected through request headers, so every client shares the
+	// same store and its connections
+	getDatafileStore := sync.OnceValue(func() interface{} {
+		return getServiceWithType(datafileStorePlugin, "", cmap.New(), clientConf.DatafileStore)
+	})
+	getEventQueueStore := sync.OnceValue(func() interface{} {
+		return getServiceWithType(eventQueuePlugin, "", cmap.New(), clientConf.EventQueue)
+	})
+	// The gauges are only registered once a persistent event queue is used
+	getEventQueueMetrics := sync.OnceValue(func() *eventQueueMetrics {
+		return newEventQueueMetrics(services.metricsRegistry)
+	})
+	// The gauges are only registered once a user profile service with a circuit breaker is used
+	getUserProfileCircuitMetrics := sync.OnceValue(func() *userProfileCircuitMetrics {
+		return newUserProfileCircuitMetrics(services.metricsRegistry)
+	})
+
+	// In offline mode events never leave the host, they are either written to a file or dropped
+	var offlineDispatcher event.Dispatcher
+	if clientConf.Offline.Enable {
+		offlineDispatcher = discardEventDispatcher{}
+		if clientConf.Offline.EventsFile != "" {
+			if fileDispatcher, err := NewFileEventDispatcher(clientConf.Offline.EventsFile); err != nil {
+				log.Error().Err(err).Msg("Unable to open events file, events will be dropped")
+			} else {
+				offlineDispatcher = fileDispatcher
+			}
+		}
+	}
 
 	return func(clientKey string) (*OptlyClient, error) {
 		var sdkKey string
@@ -225,35 +416,145 @@ func defaultLoader(
 			log.Info().Msg(message)
 		}
 
+		configOptions := []sdkconfig.OptionFunc{
+			sdkconfig.WithPollingInterval(clientConf.PollingInterval),
+			sdkconfig.WithDatafileURLTemplate(clientConf.DatafileURLTemplate),
+		}
 		if datafileAccessToken != "" {
-			configManager = pcFactory(
-				sdkKey,
-				sdkconfig.WithPollingInterval(clientConf.PollingInterval),
-				sdkconfig.WithDatafileURLTemplate(clientConf.DatafileURLTemplate),
-				sdkconfig.WithDatafileAccessToken(datafileAccessToken),
-			)
-		} else {
-			configManager = pcFactory(
-				sdkKey,
+			configOptions = append(configOptions, sdkconfig.WithDatafileAccessToken(datafileAccessToken))
+		}
+		configManager = pcFactory(sdkKey, configOptions...)
+
+		var clientDatafileStore datafilestore.Store
+		var rawDatafileStore = getDatafileStore()
+		// Check if datafile store was provided by user
+		if rawDatafileStore != nil {
+			// convert datafileStore to Store interface
+			if convertedDatafileStore, ok := rawDatafileStore.(datafilestore.Store); ok && convertedDatafileStore != nil {
+				clientDatafileStore = convertedDatafileStore
+			}
+		}
+
+		var onClose []func()
+		if closer, ok := configManager.(interface{ Close() }); ok {
+			onClose = append(onClose, closer.Close)
+		}
+
+		// Release the config managers created so far when the client can not be created
+		fail := func(err error) (*OptlyClient, error) {
+			for _, fn := range onClose {
+				fn()
+			}
+			return &OptlyClient{}, err
+		}
+
+		datafileState := &datafileState{source: DatafileSourceCDN}
+		if _, err := configManager.GetConfig(); err != nil {
+			// A 403 means the sdkKey or access token is invalid, in which case the snapshot must not be served
+			if clientDatafileStore == nil || errors.Is(err, sdkconfig.Err403Forbidden) {
+				return fail(err)
+			}
+
+			datafile, loadErr := clientDatafileStore.Load(sdkKey)
+			if loadErr != nil {
+				log.Error().Err(loadErr).Msg("Failed to load datafile snapshot")
+			}
+			if len(datafile) == 0 {
+				return fail(err)
+			}
+
+			log.Warn().Err(err).Msg("Unable to fetch datafile, falling back to the last saved snapshot")
+			// The requester marks the datafile as served from the CDN on the next successful poll, even when the
+			// revision did not change. The access token is set on the requester, since the config manager replaces
+			// the requester of an authenticated datafile.
+			datafileURLTemplate := clientConf.DatafileURLTemplate
+			if datafileURLTemplate == "" {
+				datafileURLTemplate = sdkconfig.DatafileURLTemplate
+				if datafileAccessToken != "" {
+					datafileURLTemplate = sdkconfig.AuthDatafileURLTemplate
+				}
+			}
+			configManager = pcFactory(sdkKey,
 				sdkconfig.WithPollingInterval(clientConf.PollingInterval),
-				sdkconfig.WithDatafileURLTemplate(clientConf.DatafileURLTemplate),
+				sdkconfig.WithDatafileURLTemplate(datafileURLTemplate),
+				sdkconfig.WithRequester(newCDNRequester(sdkKey, datafileAccessToken, datafileState)),
+				sdkconfig.WithInitialDatafile(datafile),
 			)
+			if closer, ok := configManager.(interface{ Close() }); ok {
+				onClose = append(onClose, closer.Close)
+			}
+			if _, err := configManager.GetConfig(); err != nil {
+				return fail(err)
+			}
+			datafileState.set(DatafileSourceSnapshot)
+		} else if clientDatafileStore != nil {
+			saveDatafileSnapshot(clientDatafileStore, sdkKey, configManager)
 		}
 
-		if _, err := configManager.GetConfig(); err != nil {
-			return &OptlyClient{}, err
+		if clientDatafileStore != nil {
+			// Snapshot every new revision fetched from the CDN
+			id, err := configManager.OnProjectConfigUpdate(func(notification.ProjectConfigUpdateNotification) {
+				datafileState.set(DatafileSourceCDN)
+				saveDatafileSnapshot(clientDatafileStore, sdkKey, configManager)
+			})
+			if err != nil {
+				log.Warn().Err(err).Msg("Unable to subscribe to datafile updates, new revisions will not be saved")
+			} else {
+				onClose = append(onClose, func() {
+					_ = configManager.RemoveOnProjectConfigUpdate(id)
+				})
+			}
 		}
 
 		q := event.NewInMemoryQueue(clientConf.QueueSize)
-		ep := bpFactory(
+		persistentQueue := false
+		var rawEventQueueStore = getEventQueueStore()
+		// Check if event queue store was provided by user
+		if eventQueueStore, ok := rawEventQueueStore.(eventqueue.Store); ok && eventQueueStore != nil {
+			if clientQueue, err := eventQueueStore.Open(sdkKey, clientConf.QueueSize); err != nil {
+				log.Error().Err(err).Msg("Unable to open the event queue, events are queued in memory")
+			} else {
+				metered := getEventQueueMetrics().wrap(clientQueue)
+				onClose = append(onClose, func() {
+					if err := metered.Close(); err != nil {
+						log.Warn().Err(err).Msg("Unable to close the event queue")
+					}
+				})
+				q = metered
+				persistentQueue = true
+			}
+		}
+
+		// Attributes and tags are scrubbed before the events are queued, so that they are never persisted
+		q = services.eventPipelines.wrap(sdkKey, q)
+
+		bpOptions := []event.BPOptionConfig{
 			event.WithSDKKey(sdkKey),
 			event.WithQueueSize(clientConf.QueueSize),
 			event.WithBatchSize(clientConf.BatchSize),
 			event.WithEventEndPoint(clientConf.EventURL),
 			event.WithFlushInterval(clientConf.FlushInterval),
 			event.WithQueue(q),
-			event.WithEventDispatcherMetrics(metricsRegistry),
-		)
+			event.WithEventDispatcherMetrics(services.metricsRegistry),
+		}
+		if offlineDispatcher != nil {
+			bpOptions = append(bpOptions, event.WithEventDispatcher(offlineDispatcher))
+		} else if persistentQueue || services.deadLetters != nil || services.eventForwarder != nil {
+			// Batches are only forwarded once sent, the dead-letter dispatcher retries them through the forwarder
+			var dispatcher = services.eventForwarder.wrap(sdkKey, event.NewHTTPEventDispatcher(sdkKey, nil, nil))
+			if services.deadLetters != nil {
+				dispatcher = services.deadLetters.wrap(sdkKey, dispatcher)
+			}
+			// The default dispatcher moves events to a queue of its own before sending them. Send them from the
+			// persistent queue instead, so that they are only removed from it once delivered
+			if !persistentQueue {
+				queueDispatcher := event.NewQueueEventDispatcher(sdkKey, services.metricsRegistry)
+				queueDispatcher.Dispatcher = dispatcher
+				dispatcher = queueDispatcher
+			}
+			bpOptions = append(bpOptions, event.WithEventDispatcher(dispatcher))
+		}
+		ep := bpFactory(bpOptions...)
 
 		forcedVariations := decision.NewMapExperimentOverridesStore()
 		optimizelyFactory := &client.OptimizelyFactory{SDKKey: sdkKey}
@@ -263,31 +564,62 @@ func defaultLoader(
 			client.WithExperimentOverrides(forcedVariations),
 			client.WithEventProcessor(ep),
 			client.WithOdpDisabled(clientConf.ODP.Disable),
-			client.WithTracer(tracing.NewOtelTracer(tracer)),
+			client.WithTracer(tracing.NewOtelTracer(services.tracer)),
+		}
+
+		revision := func() string {
+			if projectConfig, err := configManager.GetConfig(); err == nil && projectConfig != nil {
+				return projectConfig.GetRevision()
+			}
+			return ""
 		}
 
 		if agentConf.Synchronization.Notification.Enable {
 			syncedNC, err := syncer.NewSyncedNotificationCenter(context.Background(), sdkKey, agentConf.Synchronization)
 			if err != nil {
 				log.Error().Err(err).Msgf("Failed to create SyncedNotificationCenter, reason: %s", err.Error())
+			} else if services.decisionLogger != nil {
+				clientOptions = append(clientOptions, client.WithNotificationCenter(services.decisionLogger.wrap(sdkKey, syncedNC, revision)))
 			} else {
 				clientOptions = append(clientOptions, client.WithNotificationCenter(syncedNC))
 			}
 		}
 
+		if services.decisionLogger != nil {
+			// Experiment decisions are always sent through the notification center of the SDK key
+			unsubscribe, err := services.decisionLogger.subscribe(sdkKey, revision)
+			if err != nil {
+				log.Warn().Err(err).Msg("Unable to subscribe to decisions, they will not be logged")
+			} else {
+				onClose = append(onClose, unsubscribe)
+			}
+		}
+
 		var clientUserProfileService decision.UserProfileService
-		var rawUPS = getServiceWithType(userProfileServicePlugin, sdkKey, userProfileServiceMap, clientConf.UserProfileService)
+		var rawUPS = getServiceWithType(userProfileServicePlugin, sdkKey, services.userProfileServiceMap, clientConf.UserProfileService)
 		// Check if ups was provided by user
 		if rawUPS != nil {
+			if composite, ok := rawUPS.(userprofileservice.Composite); ok {
+				if err := composeUserProfileService(composite, sdkKey, clientConf.UserProfileService); err != nil {
+					log.Error().Err(err).Msg("Unable to create the user profile service")
+					rawUPS = nil
+				}
+			}
+			if closer, ok := rawUPS.(interface{ Close() }); ok {
+				onClose = append(onClose, closer.Close)
+			}
 			// convert ups to UserProfileService interface
 			if convertedUPS, ok := rawUPS.(decision.UserProfileService); ok && convertedUPS != nil {
 				clientUserProfileService = convertedUPS
-				clientOptions = append(clientOptions, client.WithUserProfileService(clientUserProfileService))
+				// only the lookups and saves of the SDK are metered
+				metered := newMeteredUserProfileService(clientUserProfileService, services.metricsRegistry, getUserProfileCircuitMetrics)
+				onClose = append(onClose, metered.release)
+				clientOptions = append(clientOptions, client.WithUserProfileService(metered))
 			}
 		}
 
 		var clientODPCache cachePkg.Cache
-		var rawODPCache = getServiceWithType(odpCachePlugin, sdkKey, odpCacheMap, clientConf.ODP.SegmentsCache)
+		var rawODPCache = getServiceWithType(odpCachePlugin, sdkKey, services.odpCacheMap, clientConf.ODP.SegmentsCache)
 		// Check if odp cache was provided by user
 		if rawODPCache != nil {
 			// convert odpCache to Cache interface
@@ -326,7 +658,7 @@ func defaultLoader(
 
 		// Get CMAB cache from service configuration
 		var clientCMABCache cachePkg.CacheWithRemove
-		var rawCMABCache = getServiceWithType(cmabCachePlugin, sdkKey, cmabCacheMap, clientConf.CMAB.Cache)
+		var rawCMABCache = getServiceWithType(cmabCachePlugin, sdkKey, services.cmabCacheMap, clientConf.CMAB.Cache)
 		// Check if CMAB cache was provided by user
 		if rawCMABCache != nil {
 			// convert cmabCache to CacheWithRemove interface
@@ -360,7 +692,75 @@ func defaultLoader(
 		optimizelyClient, err := optimizelyFactory.Client(
 			clientOptions...,
 		)
-		return &OptlyClient{optimizelyClient, configManager, forcedVariations, clientUserProfileService, clientODPCache}, err
+		return &OptlyClient{
+			OptimizelyClient:   optimizelyClient,
+			ConfigManager:      configManager,
+			ForcedVariations:   forcedVariations,
+			UserProfileService: clientUserProfileService,
+			odpCache:           clientODPCache,
+			datafile:           datafileState,
+			sdkKey:             sdkKey,
+			decisionLog:        services.decisionLogger,
+			onClose:            onClose,
+		}, err
+	}
+}
+
+// composeUserProfileService creates the user profile services, named in the same configuration, that a composite
+// user profile service is built on
+func composeUserProfileService(composite userprofileservice.Composite, sdkKey string, upsConf map[string]interface{}) error {
+	names := composite.ServiceNames()
+	services := make([]decision.UserProfileService, 0, len(names))
+	for _, name := range names {
+		conf := map[string]interface{}{"default": name, "services": upsConf["services"]}
+		ups, ok := getServiceWithType(userProfileServicePlugin, sdkKey, cmap.New(), conf).(decision.UserProfileService)
+		if !ok || ups == nil {
+			return fmt.Errorf("user profile service %q is not configured", name)
+		}
+		if _, ok := ups.(userprofileservice.Composite); ok {
+			return fmt.Errorf("user profile service %q can not be composed", name)
+		}
+		services = append(services, ups)
+	}
+	return composite.SetServices(services)
+}
+
+// cdnRequester fetches datafiles from the CDN and marks the datafile state as served from the CDN whenever a fetch
+// succeeds, including when the datafile was not modified
+type cdnRequester struct {
+	utils.Requester
+	state *datafileState
+}
+
+func newCDNRequester(sdkKey, datafileAccessToken string, state *datafileState) cdnRequester {
+	logger := logging.GetLogger(sdkKey, "HTTPRequester")
+	if datafileAccessToken == "" {
+		return cdnRequester{Requester: utils.NewHTTPRequester(logger), state: state}
+	}
+	headers := utils.Headers(
+		utils.Header{Name: utils.HeaderContentType, Value: utils.ContentTypeJSON},
+		utils.Header{Name: utils.HeaderAccept, Value: utils.ContentTypeJSON},
+		utils.Header{Name: utils.HeaderAuthorization, Value: "Bearer " + datafileAccessToken},
+	)
+	return cdnRequester{Requester: utils.NewHTTPRequester(logger, headers), state: state}
+}
+
+// Get fetches a datafile
+func (r cdnRequester) Get(uri string, headers ...utils.Header) (response []byte, responseHeaders http.Header, code int, err error) {
+	response, responseHeaders, code, err = r.Requester.Get(uri, headers...)
+	if err == nil {
+		r.state.set(DatafileSourceCDN)
+	}
+	return response, responseHeaders, code, err
+}
+
+func saveDatafileSnapshot(store datafilestore.Store, sdkKey string, configManager SyncedConfigManager) {
+	projectConfig, err := configManager.GetConfig()
+	if err != nil || projectConfig == nil {
+		return
+	}
+	if err := store.Save(sdkKey, []byte(projectConfig.GetDatafile())); err != nil {
+		log.Error().Err(err).Msg("Failed to save datafile snapshot")
 	}
 }
 
@@ -384,6 +784,26 @@ func getServiceWithType(serviceType, sdkKey string, serviceMap cmap.ConcurrentMa
 					if cmabCreator, ok := cmabcache.Creators[serviceName]; ok && cmabCreator != nil {
 						serviceInstance = cmabCreator()
 					}
+				case datafileStorePlugin:
+					if datafileStoreCreator, ok := datafilestore.Creators[serviceName]; ok {
+						serviceInstance = datafileStoreCreator()
+					}
+				case decisionLogSinkPlugin:
+					if sinkCreator, ok := decisionlog.Creators[serviceName]; ok {
+						serviceInstance = sinkCreator()
+					}
+				case deadLetterStorePlugin:
+					if deadLetterStoreCreator, ok := deadletter.Creators[serviceName]; ok {
+						serviceInstance = deadLetterStoreCreator()
+					}
+				case eventForwarderPlugin:
+					if eventForwarderCreator, ok := eventforwarder.Creators[serviceName]; ok {
+						serviceInstance = eventForwarderCreator()
+					}
+				case eventQueuePlugin:
+					if eventQueueCreator, ok := eventqueue.Creators[serviceName]; ok {
+						serviceInstance = eventQueueCreator()
+					}
 				default:
 				}
 
@@ -423,10 +843,10 @@ func getServiceWithType(serviceType, sdkKey string, serviceMap cmap.ConcurrentMa
 func (c *OptlyCache) ResetClient(sdkKey string) {
 	// Remove the client from the cache
 	if val, exists := c.optlyMap.Get(sdkKey); exists {
-		c.optlyMap.Remove(sdkKey)
-
 		// Close the client to clean up resources
-		if client, ok := val.(*OptlyClient); ok {
+		if c.usage.remove(sdkKey, func() { c.optlyMap.Remove(sdkKey) }) {
+			c.activeClients.Set(float64(c.usage.len()))
+		} else if client, ok := val.(*OptlyClient); ok {
 			client.Close()
 		}
 
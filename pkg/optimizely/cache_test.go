@@ -19,12 +19,18 @@ package optimizely
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/go-redis/redis/v8"
 	cmap "github.com/orcaman/concurrent-map"
 	"github.com/stretchr/testify/suite"
 
@@ -33,6 +39,8 @@ import (
 	"github.com/optimizely/agent/pkg/optimizely/optimizelytest"
 	"github.com/optimizely/agent/plugins/cmabcache"
 	cmabCacheServices "github.com/optimizely/agent/plugins/cmabcache/services"
+	"github.com/optimizely/agent/plugins/datafilestore"
+	"github.com/optimizely/agent/plugins/eventqueue"
 	"github.com/optimizely/agent/plugins/odpcache"
 	odpCacheServices "github.com/optimizely/agent/plugins/odpcache/services"
 	"github.com/optimizely/agent/plugins/userprofileservice"
@@ -40,6 +48,7 @@ import (
 	"github.com/optimizely/go-sdk/v2/pkg/cache"
 	sdkconfig "github.com/optimizely/go-sdk/v2/pkg/config"
 	"github.com/optimizely/go-sdk/v2/pkg/decision"
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
 	"github.com/optimizely/go-sdk/v2/pkg/event"
 )
 
@@ -61,6 +70,10 @@ func (suite *CacheTestSuite) SetupTest() {
 		odpCacheMap:           cmap.New(),
 		cmabCacheMap:          cmap.New(),
 		ctx:                   ctx,
+		usage:                 newClientUsage(),
+		clientsCreated:        &testCounter{},
+		clientsEvicted:        &testCounter{},
+		activeClients:         &testGauge{},
 	}
 
 	suite.cancel = cancel
@@ -333,6 +346,110 @@ func (suite *CacheTestSuite) TestResetClient() {
 	suite.False(exists)
 }
 
+func (suite *CacheTestSuite) TestMaxClientsEvictsLeastRecentlyUsed() {
+	suite.cache.maxClients = 2
+
+	one, _ := suite.cache.GetClient("one")
+	_, _ = suite.cache.GetClient("two")
+	_, _ = suite.cache.GetClient("one")
+	_, _ = suite.cache.GetClient("three")
+
+	suite.True(suite.cache.optlyMap.Has("one"))
+	suite.False(suite.cache.optlyMap.Has("two"))
+	suite.True(suite.cache.optlyMap.Has("three"))
+	suite.Equal(3.0, suite.cache.clientsCreated.(*testCounter).value)
+	suite.Equal(1.0, suite.cache.clientsEvicted.(*testCounter).value)
+	suite.Equal(2.0, suite.cache.activeClients.(*testGauge).value)
+
+	// A new client is created once an evicted key is requested again
+	_, _ = suite.cache.GetClient("two")
+	suite.False(suite.cache.optlyMap.Has("one"))
+	suite.Equal(4.0, suite.cache.clientsCreated.(*testCounter).value)
+	suite.Equal(2.0, suite.cache.clientsEvicted.(*testCounter).value)
+
+	again, _ := suite.cache.GetClient("one")
+	suite.NotSame(one, again)
+}
+
+func (suite *CacheTestSuite) TestEvictIdleClients() {
+	_, _ = suite.cache.GetClient("one")
+	_, _ = suite.cache.GetClient("two")
+
+	go suite.cache.evictIdleClients(50 * time.Millisecond)
+
+	// Keep "two" in use while "one" goes idle
+	suite.Eventually(func() bool {
+		_, _ = suite.cache.GetClient("two")
+		return !suite.cache.optlyMap.Has("one")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	suite.True(suite.cache.optlyMap.Has("two"))
+	suite.Equal(1.0, suite.cache.clientsEvicted.(*testCounter).value)
+}
+
+func (suite *CacheTestSuite) TestEvictClosesClient() {
+	closed := make(chan struct{})
+	suite.cache.loader = func(sdkKey string) (*OptlyClient, error) {
+		oc, err := mockLoader(sdkKey)
+		oc.onClose = []func(){func() { close(closed) }}
+		return oc, err
+	}
+
+	_, _ = suite.cache.GetClient("one")
+	suite.cache.evict("one", "test")
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		suite.Fail("evicted client was not closed")
+	}
+	suite.False(suite.cache.optlyMap.Has("one"))
+}
+
+func (suite *CacheTestSuite) TestEvictIsAtomic() {
+	_, _ = suite.cache.GetClient("one")
+
+	// A client cached while the key is evicted is either removed or still tracked once the eviction is done
+	removed := make(chan struct{})
+	go func() {
+		defer close(removed)
+		suite.cache.evict("one", "test")
+	}()
+	_, _ = suite.cache.GetClient("one")
+	<-removed
+	if suite.cache.optlyMap.Has("one") {
+		suite.Equal(1, suite.cache.usage.len())
+	} else {
+		suite.Equal(0, suite.cache.usage.len())
+	}
+}
+
+func (suite *CacheTestSuite) TestClientUsageAddMovesTrackedKey() {
+	usage := newClientUsage()
+	set := func() bool { return true }
+	first, _ := usage.add("one", set)
+	_, _ = usage.add("two", set)
+	second, ok := usage.add("one", set)
+	suite.True(ok)
+
+	// The previous client of the key is signaled and the key is only tracked once
+	_, open := <-first
+	suite.False(open)
+	suite.Equal(2, usage.len())
+	lru, _ := usage.leastRecentlyUsed()
+	suite.Equal("two", lru)
+
+	suite.True(usage.remove("one", func() {}))
+	suite.True(usage.remove("two", func() {}))
+	suite.Equal(0, usage.len())
+	_, open = <-second
+	suite.False(open)
+
+	_, ok = usage.add("three", func() bool { return false })
+	suite.False(ok)
+	suite.Equal(0, usage.len())
+}
+
 func (suite *CacheTestSuite) TestResetClientNonExistent() {
 	// Reset a client that doesn't exist - should not panic
 	suite.cache.ResetClient("non-existent-key")
@@ -356,7 +473,7 @@ func mockLoader(sdkKey string) (*OptlyClient, error) {
 	tc := optimizelytest.NewClient()
 	tc.ProjectConfig.ProjectID = sdkKey
 
-	return &OptlyClient{tc.OptimizelyClient, nil, tc.ForcedVariations, nil, nil}, nil
+	return &OptlyClient{OptimizelyClient: tc.OptimizelyClient, ForcedVariations: tc.ForcedVariations}, nil
 }
 
 type MockUserProfileService struct {
@@ -404,6 +521,15 @@ type DefaultLoaderTestSuite struct {
 	pcFactory                         func(sdkKey string, options ...sdkconfig.OptionFunc) SyncedConfigManager
 }
 
+func (s *DefaultLoaderTestSuite) services() clientServices {
+	return clientServices{
+		metricsRegistry:       s.registry,
+		userProfileServiceMap: s.upsMap,
+		odpCacheMap:           s.odpCacheMap,
+		cmabCacheMap:          s.cmabCacheMap,
+	}
+}
+
 func (s *DefaultLoaderTestSuite) SetupTest() {
 	// Need the registry to be created only once since it panics if we create gauges with the same name again and again
 	doOnce.Do(func() {
@@ -450,7 +576,7 @@ func (s *DefaultLoaderTestSuite) TestDefaultLoader() {
 		},
 	}
 
-	loader := defaultLoader(config.AgentConfig{Client: conf}, s.registry, nil, s.upsMap, s.odpCacheMap, s.cmabCacheMap, s.pcFactory, s.bpFactory)
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
 	client, err := loader("sdkkey")
 	s.NoError(err)
 
@@ -504,7 +630,10 @@ func (s *DefaultLoaderTestSuite) TestUPSAndODPCacheHeaderOverridesDefaultKey() {
 	tmpOdpCacheMap := cmap.New()
 	tmpOdpCacheMap.Set("sdkkey", "in-memory")
 
-	loader := defaultLoader(config.AgentConfig{Client: conf}, s.registry, nil, tmpUPSMap, tmpOdpCacheMap, s.cmabCacheMap, s.pcFactory, s.bpFactory)
+	shared := s.services()
+	shared.userProfileServiceMap = tmpUPSMap
+	shared.odpCacheMap = tmpOdpCacheMap
+	loader := defaultLoader(config.AgentConfig{Client: conf}, shared, s.pcFactory, s.bpFactory)
 	client, err := loader("sdkkey")
 	s.NoError(err)
 
@@ -568,7 +697,7 @@ func (s *DefaultLoaderTestSuite) TestFirstSaveConfiguresClientForRedisUPSAndODPC
 			}},
 		},
 	}
-	loader := defaultLoader(config.AgentConfig{Client: conf}, s.registry, nil, s.upsMap, s.odpCacheMap, s.cmabCacheMap, s.pcFactory, s.bpFactory)
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
 	client, err := loader("sdkkey")
 	s.NoError(err)
 	s.NotNil(client.UserProfileService)
@@ -587,12 +716,13 @@ func (s *DefaultLoaderTestSuite) TestFirstSaveConfiguresClientForRedisUPSAndODPC
 		s.Equal("100", testRedisUPS.Address)
 		s.Equal("10", testRedisUPS.Password)
 		s.Equal(1, testRedisUPS.Database)
+		s.Empty(testRedisUPS.Prefix)
 
 		// Check if redis client was instantiated with updated config
 		s.NotNil(testRedisUPS.Client)
-		s.Equal(testRedisUPS.Address, testRedisUPS.Client.Options().Addr)
-		s.Equal(testRedisUPS.Password, testRedisUPS.Client.Options().Password)
-		s.Equal(testRedisUPS.Database, testRedisUPS.Client.Options().DB)
+		s.Equal(testRedisUPS.Address, testRedisUPS.Client.(*redis.Client).Options().Addr)
+		s.Equal(testRedisUPS.Password, testRedisUPS.Client.(*redis.Client).Options().Password)
+		s.Equal(testRedisUPS.Database, testRedisUPS.Client.(*redis.Client).Options().DB)
 		return
 	} else {
 		s.Failf("UserProfileService not registered", "%s DNE in registry", "redis")
@@ -606,9 +736,9 @@ func (s *DefaultLoaderTestSuite) TestFirstSaveConfiguresClientForRedisUPSAndODPC
 
 		// Check if redis client was instantiated with updated config
 		s.NotNil(testRedisODPCache.Client)
-		s.Equal(testRedisODPCache.Address, testRedisODPCache.Client.Options().Addr)
-		s.Equal(testRedisODPCache.Password, testRedisODPCache.Client.Options().Password)
-		s.Equal(testRedisODPCache.Database, testRedisODPCache.Client.Options().DB)
+		s.Equal(testRedisODPCache.Address, testRedisODPCache.Client.(*redis.Client).Options().Addr)
+		s.Equal(testRedisODPCache.Password, testRedisODPCache.Client.(*redis.Client).Options().Password)
+		s.Equal(testRedisODPCache.Database, testRedisODPCache.Client.(*redis.Client).Options().DB)
 		return
 	} else {
 		s.Failf("ODPCache not registered", "%s DNE in registry", "redis")
@@ -626,7 +756,7 @@ func (s *DefaultLoaderTestSuite) TestFirstSaveConfiguresLRUCacheForInMemoryCache
 			}},
 		},
 	}
-	loader := defaultLoader(config.AgentConfig{Client: conf}, s.registry, nil, s.upsMap, s.odpCacheMap, s.cmabCacheMap, s.pcFactory, s.bpFactory)
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
 	client, err := loader("sdkkey")
 	s.NoError(err)
 	s.NotNil(client.odpCache)
@@ -657,7 +787,7 @@ func (s *DefaultLoaderTestSuite) TestHttpClientInitializesByDefaultRestUPS() {
 			"rest": map[string]interface{}{},
 		}},
 	}
-	loader := defaultLoader(config.AgentConfig{Client: conf}, s.registry, nil, s.upsMap, s.odpCacheMap, s.cmabCacheMap, s.pcFactory, s.bpFactory)
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
 	client, err := loader("sdkkey")
 	s.NoError(err)
 	s.NotNil(client.UserProfileService)
@@ -685,7 +815,7 @@ func (s *DefaultLoaderTestSuite) TestLoaderWithValidUserProfileServices() {
 			},
 		}},
 	}
-	loader := defaultLoader(config.AgentConfig{Client: conf}, s.registry, nil, s.upsMap, s.odpCacheMap, s.cmabCacheMap, s.pcFactory, s.bpFactory)
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
 	client, err := loader("sdkkey")
 	s.NoError(err)
 
@@ -716,7 +846,7 @@ func (s *DefaultLoaderTestSuite) TestLoaderWithValidODPCache() {
 			}},
 		},
 	}
-	loader := defaultLoader(config.AgentConfig{Client: conf}, s.registry, nil, s.upsMap, s.odpCacheMap, s.cmabCacheMap, s.pcFactory, s.bpFactory)
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
 	client, err := loader("sdkkey")
 	s.NoError(err)
 
@@ -739,9 +869,51 @@ func (s *DefaultLoaderTestSuite) TestLoaderWithEmptyUserProfileServices() {
 	conf := config.ClientConfig{
 		UserProfileService: map[string]interface{}{},
 	}
-	loader := defaultLoader(config.AgentConfig{Client: conf}, s.registry, nil, s.upsMap, s.odpCacheMap, s.cmabCacheMap, s.pcFactory, s.bpFactory)
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
+	client, err := loader("sdkkey")
+	s.NoError(err)
+	s.Nil(client.UserProfileService)
+}
+
+func (s *DefaultLoaderTestSuite) TestLoaderWithTieredUserProfileService() {
+	userprofileservice.Add("tiered-mock", func() decision.UserProfileService {
+		return &MockUserProfileService{}
+	})
+
+	conf := config.ClientConfig{
+		UserProfileService: map[string]interface{}{"default": "tiered", "services": map[string]interface{}{
+			"tiered": map[string]interface{}{
+				"store":           "tiered-mock",
+				"negativeCaching": true,
+			},
+			"in-memory": map[string]interface{}{
+				"capacity": 10,
+			},
+			"tiered-mock": map[string]interface{}{},
+		}},
+	}
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
 	client, err := loader("sdkkey")
 	s.NoError(err)
+
+	tieredUPS, ok := client.UserProfileService.(*services.TieredUserProfileService)
+	s.Require().True(ok)
+	s.True(tieredUPS.NegativeCaching)
+
+	// Profiles are cached by the in-memory service, the mock store can not delete them
+	profile := decision.UserProfile{ID: "user", ExperimentBucketMap: map[decision.UserDecisionKey]string{}}
+	tieredUPS.Save(profile)
+	s.Equal(profile, tieredUPS.Lookup("user"))
+	s.ErrorIs(tieredUPS.Delete("user"), userprofileservice.ErrNotSupported)
+
+	// The store must be configured
+	conf.UserProfileService["services"] = map[string]interface{}{
+		"tiered":    map[string]interface{}{"store": "redis"},
+		"in-memory": map[string]interface{}{},
+	}
+	loader = defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
+	client, err = loader("sdkkey")
+	s.NoError(err)
 	s.Nil(client.UserProfileService)
 }
 
@@ -756,7 +928,7 @@ func (s *DefaultLoaderTestSuite) TestLoaderWithEmptyODPCache() {
 			SegmentsCache: map[string]interface{}{},
 		},
 	}
-	loader := defaultLoader(config.AgentConfig{Client: conf}, s.registry, nil, s.upsMap, s.odpCacheMap, s.cmabCacheMap, s.pcFactory, s.bpFactory)
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
 	client, err := loader("sdkkey")
 	s.NoError(err)
 	s.Nil(client.odpCache)
@@ -773,7 +945,7 @@ func (s *DefaultLoaderTestSuite) TestLoaderWithNoDefaultUserProfileServices() {
 			"mock3": map[string]interface{}{},
 		}},
 	}
-	loader := defaultLoader(config.AgentConfig{Client: conf}, s.registry, nil, s.upsMap, s.odpCacheMap, s.cmabCacheMap, s.pcFactory, s.bpFactory)
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
 	client, err := loader("sdkkey")
 	s.NoError(err)
 	s.Nil(client.UserProfileService)
@@ -792,7 +964,7 @@ func (s *DefaultLoaderTestSuite) TestLoaderWithNoDefaultODPCache() {
 			}},
 		},
 	}
-	loader := defaultLoader(config.AgentConfig{Client: conf}, s.registry, nil, s.upsMap, s.odpCacheMap, s.cmabCacheMap, s.pcFactory, s.bpFactory)
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
 	client, err := loader("sdkkey")
 	s.NoError(err)
 	s.Nil(client.odpCache)
@@ -859,7 +1031,7 @@ func (s *DefaultLoaderTestSuite) TestCMABEndpointEnvironmentVariable() {
 		},
 	}
 
-	loader := defaultLoader(config.AgentConfig{Client: conf}, s.registry, nil, s.upsMap, s.odpCacheMap, s.cmabCacheMap, s.pcFactory, s.bpFactory)
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
 	client, err := loader("sdkkey")
 
 	s.NoError(err)
@@ -896,7 +1068,7 @@ func (s *DefaultLoaderTestSuite) TestCMABEndpointFromConfig() {
 		},
 	}
 
-	loader := defaultLoader(config.AgentConfig{Client: conf}, s.registry, nil, s.upsMap, s.odpCacheMap, s.cmabCacheMap, s.pcFactory, s.bpFactory)
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
 	client, err := loader("sdkkey")
 
 	s.NoError(err)
@@ -939,7 +1111,7 @@ func (s *DefaultLoaderTestSuite) TestCMABEndpointEnvironmentOverridesConfig() {
 		},
 	}
 
-	loader := defaultLoader(config.AgentConfig{Client: conf}, s.registry, nil, s.upsMap, s.odpCacheMap, s.cmabCacheMap, s.pcFactory, s.bpFactory)
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
 	client, err := loader("sdkkey")
 
 	s.NoError(err)
@@ -948,6 +1120,337 @@ func (s *DefaultLoaderTestSuite) TestCMABEndpointEnvironmentOverridesConfig() {
 	// Environment variable priority is handled in cache.go lines 341-348
 }
 
+func (s *DefaultLoaderTestSuite) TestLoaderFallsBackToDatafileSnapshot() {
+	testDatafileStore.datafiles = map[string][]byte{"sdkkey": []byte(testDatafile)}
+	conf := config.ClientConfig{
+		SdkKeyRegex:   "sdkkey",
+		DatafileStore: mockDatafileStoreConfig,
+	}
+
+	var pcOptions [][]sdkconfig.OptionFunc
+	pcFactory := func(sdkKey string, options ...sdkconfig.OptionFunc) SyncedConfigManager {
+		pcOptions = append(pcOptions, options)
+		if len(pcOptions) == 1 {
+			return NewErrorConfigManager("cdn unreachable")
+		}
+		return sdkconfig.NewAsyncPollingProjectConfigManager(sdkKey, options...)
+	}
+
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), pcFactory, s.bpFactory)
+	client, err := loader("sdkkey")
+	s.NoError(err)
+	s.Len(pcOptions, 2)
+	s.Len(pcOptions[1], len(pcOptions[0])+2)
+	s.Equal(DatafileInfo{Source: DatafileSourceSnapshot, Revision: "42"}, client.DatafileInfo())
+}
+
+func (s *DefaultLoaderTestSuite) TestLoaderMarksDatafileFromCDNOnSuccessfulPoll() {
+	testDatafileStore.datafiles = map[string][]byte{"sdkkey": []byte(testDatafile)}
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testDatafile))
+	}))
+	defer cdn.Close()
+	conf := config.ClientConfig{
+		SdkKeyRegex:         "sdkkey",
+		DatafileURLTemplate: cdn.URL + "/%s.json",
+		DatafileStore:       mockDatafileStoreConfig,
+	}
+
+	first := true
+	pcFactory := func(sdkKey string, options ...sdkconfig.OptionFunc) SyncedConfigManager {
+		if first {
+			first = false
+			return NewErrorConfigManager("cdn unreachable")
+		}
+		return sdkconfig.NewAsyncPollingProjectConfigManager(sdkKey, options...)
+	}
+
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), pcFactory, s.bpFactory)
+	client, err := loader("sdkkey")
+	s.NoError(err)
+	s.Equal(DatafileSourceSnapshot, client.DatafileInfo().Source)
+
+	// The CDN serves the revision of the snapshot, so no config update notification is sent
+	client.ConfigManager.SyncConfig()
+	s.Equal(DatafileInfo{Source: DatafileSourceCDN, Revision: "42"}, client.DatafileInfo())
+}
+
+func (s *DefaultLoaderTestSuite) TestLoaderWithoutDatafileSnapshot() {
+	testDatafileStore.datafiles = map[string][]byte{}
+	conf := config.ClientConfig{
+		SdkKeyRegex:   "sdkkey",
+		DatafileStore: mockDatafileStoreConfig,
+	}
+
+	pcFactory := func(sdkKey string, options ...sdkconfig.OptionFunc) SyncedConfigManager {
+		return NewErrorConfigManager("cdn unreachable")
+	}
+
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), pcFactory, s.bpFactory)
+	_, err := loader("sdkkey")
+	s.EqualError(err, "config error")
+}
+
+func (s *DefaultLoaderTestSuite) TestLoaderIgnoresDatafileSnapshotWhenForbidden() {
+	testDatafileStore.datafiles = map[string][]byte{"sdkkey": []byte(testDatafile)}
+	conf := config.ClientConfig{
+		SdkKeyRegex:   "sdkkey",
+		DatafileStore: mockDatafileStoreConfig,
+	}
+
+	pcFactory := func(sdkKey string, options ...sdkconfig.OptionFunc) SyncedConfigManager {
+		return forbiddenConfigManager{}
+	}
+
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), pcFactory, s.bpFactory)
+	_, err := loader("sdkkey")
+	s.ErrorIs(err, sdkconfig.Err403Forbidden)
+}
+
+func (s *DefaultLoaderTestSuite) TestLoaderSavesDatafileSnapshot() {
+	testDatafileStore.datafiles = map[string][]byte{}
+	conf := config.ClientConfig{
+		SdkKeyRegex:   "sdkkey",
+		DatafileStore: mockDatafileStoreConfig,
+	}
+
+	pcFactory := func(sdkKey string, options ...sdkconfig.OptionFunc) SyncedConfigManager {
+		return sdkconfig.NewAsyncPollingProjectConfigManager(sdkKey, sdkconfig.WithInitialDatafile([]byte(testDatafile)))
+	}
+
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), pcFactory, s.bpFactory)
+	client, err := loader("sdkkey")
+	s.NoError(err)
+	s.JSONEq(testDatafile, string(testDatafileStore.datafiles["sdkkey"]))
+	s.Equal(DatafileInfo{Source: DatafileSourceCDN, Revision: "42"}, client.DatafileInfo())
+}
+
+func (s *DefaultLoaderTestSuite) TestLoaderWithPersistentEventQueue() {
+	testEventQueueStore.err = nil
+	conf := config.ClientConfig{
+		SdkKeyRegex: "sdkkey",
+		QueueSize:   100,
+		EventQueue:  mockEventQueueConfig,
+	}
+
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
+	client, err := loader("sdkkey")
+	s.NoError(err)
+
+	s.Equal("sdkkey", testEventQueueStore.sdkKey)
+	s.Equal(100, testEventQueueStore.maxSize)
+	metered, ok := s.bp.Q.(*meteredQueue)
+	s.Require().True(ok)
+	s.Equal(testEventQueueStore.queue, metered.Queue)
+
+	// Events are only removed from the persistent queue once they are sent
+	_, ok = s.bp.EventDispatcher.(*event.QueueEventDispatcher)
+	s.False(ok)
+
+	client.Close()
+	s.True(testEventQueueStore.queue.closed)
+}
+
+func (s *DefaultLoaderTestSuite) TestLoaderSharesEventQueueStore() {
+	testEventQueueStore.err = nil
+	conf := config.ClientConfig{
+		SdkKeyRegex: "sdkkey",
+		QueueSize:   100,
+		EventQueue:  mockEventQueueConfig,
+	}
+	created := testEventQueueStoresCreated.Load()
+
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
+	for i := 0; i < 2; i++ {
+		client, err := loader("sdkkey")
+		s.NoError(err)
+		client.Close()
+	}
+	s.Equal(created+1, testEventQueueStoresCreated.Load())
+}
+
+func (s *DefaultLoaderTestSuite) TestLoaderWithFailingEventQueue() {
+	testEventQueueStore.err = errors.New("open error")
+	conf := config.ClientConfig{
+		SdkKeyRegex: "sdkkey",
+		EventQueue:  mockEventQueueConfig,
+	}
+
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
+	_, err := loader("sdkkey")
+	s.NoError(err)
+
+	// Events are queued in memory instead
+	_, ok := s.bp.Q.(*event.InMemoryQueue)
+	s.True(ok)
+	_, ok = s.bp.EventDispatcher.(*event.QueueEventDispatcher)
+	s.True(ok)
+}
+
+func (s *DefaultLoaderTestSuite) TestLoaderWithDeadLetters() {
+	deadLetters := &DeadLetters{}
+	conf := config.ClientConfig{SdkKeyRegex: "sdkkey"}
+
+	shared := s.services()
+	shared.deadLetters = deadLetters
+	loader := defaultLoader(config.AgentConfig{Client: conf}, shared, s.pcFactory, s.bpFactory)
+	_, err := loader("sdkkey")
+	s.NoError(err)
+
+	queueDispatcher, ok := s.bp.EventDispatcher.(*event.QueueEventDispatcher)
+	s.Require().True(ok)
+	dispatcher, ok := queueDispatcher.Dispatcher.(*deadLetterDispatcher)
+	s.Require().True(ok)
+	s.Equal("sdkkey", dispatcher.sdkKey)
+	s.Equal(deadLetters, dispatcher.deadLetters)
+
+	// Persistent queues dispatch through the dead-letter dispatcher directly
+	testEventQueueStore.err = nil
+	conf.EventQueue = mockEventQueueConfig
+	shared = s.services()
+	shared.deadLetters = deadLetters
+	loader = defaultLoader(config.AgentConfig{Client: conf}, shared, s.pcFactory, s.bpFactory)
+	client, err := loader("sdkkey")
+	s.NoError(err)
+	_, ok = s.bp.EventDispatcher.(*deadLetterDispatcher)
+	s.True(ok)
+	client.Close()
+}
+
+func (s *DefaultLoaderTestSuite) TestLoaderWithEventForwarder() {
+	eventForwarder := &EventForwarder{}
+	deadLetters := &DeadLetters{}
+	conf := config.ClientConfig{SdkKeyRegex: "sdkkey"}
+
+	shared := s.services()
+	shared.eventForwarder = eventForwarder
+	loader := defaultLoader(config.AgentConfig{Client: conf}, shared, s.pcFactory, s.bpFactory)
+	_, err := loader("sdkkey")
+	s.NoError(err)
+
+	queueDispatcher, ok := s.bp.EventDispatcher.(*event.QueueEventDispatcher)
+	s.Require().True(ok)
+	dispatcher, ok := queueDispatcher.Dispatcher.(*forwardingDispatcher)
+	s.Require().True(ok)
+	s.Equal("sdkkey", dispatcher.sdkKey)
+	s.Equal(eventForwarder, dispatcher.forwarder)
+
+	// Dead-lettered batches are retried through the forwarding dispatcher
+	shared = s.services()
+	shared.deadLetters = deadLetters
+	shared.eventForwarder = eventForwarder
+	loader = defaultLoader(config.AgentConfig{Client: conf}, shared, s.pcFactory, s.bpFactory)
+	_, err = loader("sdkkey")
+	s.NoError(err)
+
+	queueDispatcher, ok = s.bp.EventDispatcher.(*event.QueueEventDispatcher)
+	s.Require().True(ok)
+	deadLetterDispatcher, ok := queueDispatcher.Dispatcher.(*deadLetterDispatcher)
+	s.Require().True(ok)
+	s.IsType(&forwardingDispatcher{}, deadLetterDispatcher.dispatcher)
+}
+
+func (s *DefaultLoaderTestSuite) TestLoaderWithEventPipelines() {
+	eventPipelines, err := NewEventPipelines([]config.EventPipelineConfig{{
+		SDKKeys: []string{"sdkkey"},
+		Steps:   []config.EventPipelineStep{{Type: "deny", Keys: []string{"email"}}},
+	}})
+	s.Require().NoError(err)
+	conf := config.ClientConfig{SdkKeyRegex: "sdkkey"}
+
+	shared := s.services()
+	shared.eventPipelines = eventPipelines
+	loader := defaultLoader(config.AgentConfig{Client: conf}, shared, s.pcFactory, s.bpFactory)
+	_, err = loader("sdkkey")
+	s.NoError(err)
+
+	q, ok := s.bp.Q.(*pipelineQueue)
+	s.Require().True(ok)
+	s.IsType(event.NewInMemoryQueue(1), q.Queue)
+	s.Len(q.steps, 1)
+
+	// Other SDK keys queue events as is
+	shared = s.services()
+	shared.eventPipelines = eventPipelines
+	loader = defaultLoader(config.AgentConfig{Client: config.ClientConfig{SdkKeyRegex: "other"}}, shared, s.pcFactory, s.bpFactory)
+	_, err = loader("other")
+	s.NoError(err)
+	s.IsType(event.NewInMemoryQueue(1), s.bp.Q)
+}
+
+func (s *DefaultLoaderTestSuite) TestOfflineLoaderDispatchesEventsToFile() {
+	conf := config.ClientConfig{
+		SdkKeyRegex: "sdkkey",
+		Offline: config.OfflineConfig{
+			Enable:     true,
+			EventsFile: filepath.Join(s.T().TempDir(), "events.ndjson"),
+		},
+	}
+
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
+	_, err := loader("sdkkey")
+	s.NoError(err)
+	s.IsType(&FileEventDispatcher{}, s.bp.EventDispatcher)
+}
+
+func (s *DefaultLoaderTestSuite) TestOfflineLoaderDropsEventsWithoutFile() {
+	conf := config.ClientConfig{
+		SdkKeyRegex: "sdkkey",
+		Offline:     config.OfflineConfig{Enable: true},
+	}
+
+	loader := defaultLoader(config.AgentConfig{Client: conf}, s.services(), s.pcFactory, s.bpFactory)
+	_, err := loader("sdkkey")
+	s.NoError(err)
+	s.IsType(discardEventDispatcher{}, s.bp.EventDispatcher)
+}
+
+func (s *DefaultLoaderTestSuite) TestNewDecisionLoggerWithoutSink() {
+	s.Nil(NewDecisionLogger(config.DecisionLogConfig{Sink: map[string]interface{}{"default": "", "services": map[string]interface{}{}}}, s.registry))
+	s.Nil(NewDecisionLogger(config.DecisionLogConfig{Sink: map[string]interface{}{"default": "dne", "services": map[string]interface{}{}}}, s.registry))
+}
+
+func (s *DefaultLoaderTestSuite) TestDecisionLogLoader() {
+	testClient := optimizelytest.NewClient()
+	testClient.ProjectConfig.Revision = "7"
+	testClient.AddExperiment("experiment", []entities.Variation{testClient.ProjectConfig.CreateVariation("variation")})
+	pcFactory := func(sdkKey string, options ...sdkconfig.OptionFunc) SyncedConfigManager {
+		return MockConfigManager{config: testClient.ProjectConfig}
+	}
+
+	decisionLogger := NewDecisionLogger(config.DecisionLogConfig{
+		BufferSize: 10,
+		BatchSize:  1,
+		Sink: map[string]interface{}{"default": "test", "services": map[string]interface{}{
+			"test": map[string]interface{}{},
+		}},
+	}, s.registry)
+	s.Require().NotNil(decisionLogger)
+	s.IsType(&testSink{}, decisionLogger.sink)
+
+	conf := config.ClientConfig{SdkKeyRegex: "sdkkey"}
+	shared := s.services()
+	shared.decisionLogger = decisionLogger
+	loader := defaultLoader(config.AgentConfig{Client: conf}, shared, pcFactory, s.bpFactory)
+	client, err := loader("sdkkey")
+	s.Require().NoError(err)
+	defer client.Close()
+
+	unbind := client.BindRequest("user", "request")
+	_, err = client.ActivateExperiment(context.Background(), "experiment", entities.UserContext{ID: "user"}, true)
+	unbind()
+	s.NoError(err)
+
+	s.Require().Len(decisionLogger.records, 1)
+	record := <-decisionLogger.records
+	s.Equal("sdkkey", record.SDKKey)
+	s.Equal("request", record.RequestID)
+	s.Equal("user", record.UserID)
+	s.Equal("experiment", record.RuleKey)
+	s.Equal("variation", record.VariationKey)
+	s.Equal("7", record.Revision)
+}
+
 func TestDefaultLoaderTestSuite(t *testing.T) {
 	suite.Run(t, new(DefaultLoaderTestSuite))
 }
@@ -1117,3 +1620,112 @@ func (suite *CacheTestSuite) TestCMABRedisCacheCreator() {
 	suite.Equal(2, redisCache.Database)
 	suite.Equal(300*time.Second, redisCache.Timeout.Duration)
 }
+
+func (suite *CacheTestSuite) TestDatafileInfo() {
+	_, _ = suite.cache.GetClient("one")
+	_, _ = suite.cache.GetClient("two:token")
+
+	suite.Equal(map[string]DatafileInfo{
+		"one": {Source: DatafileSourceCDN},
+		"two": {Source: DatafileSourceCDN},
+	}, suite.cache.DatafileInfo())
+}
+
+const testDatafile = `{"version":"4","revision":"42","projectId":"1","accountId":"1"}`
+
+var testDatafileStore = &MockDatafileStore{}
+
+func init() {
+	datafilestore.Add("mock", func() datafilestore.Store {
+		return testDatafileStore
+	})
+}
+
+var mockDatafileStoreConfig = config.DatafileStoreConfigs{"default": "mock", "services": map[string]interface{}{
+	"mock": map[string]interface{}{},
+}}
+
+type MockDatafileStore struct {
+	datafiles map[string][]byte
+}
+
+func (m *MockDatafileStore) Save(sdkKey string, datafile []byte) error {
+	m.datafiles[sdkKey] = datafile
+	return nil
+}
+
+func (m *MockDatafileStore) Load(sdkKey string) ([]byte, error) {
+	return m.datafiles[sdkKey], nil
+}
+
+var testEventQueueStore = &MockEventQueueStore{}
+
+// testEventQueueStoresCreated counts the event queue stores created by the mock creator
+var testEventQueueStoresCreated atomic.Int32
+
+func init() {
+	eventqueue.Add("mock", func() eventqueue.Store {
+		testEventQueueStoresCreated.Add(1)
+		return testEventQueueStore
+	})
+}
+
+var mockEventQueueConfig = config.EventQueueConfigs{"default": "mock", "services": map[string]interface{}{
+	"mock": map[string]interface{}{},
+}}
+
+type MockEventQueueStore struct {
+	sdkKey  string
+	maxSize int
+	queue   *MockEventQueue
+	err     error
+}
+
+func (m *MockEventQueueStore) Open(sdkKey string, maxSize int) (eventqueue.Queue, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.sdkKey, m.maxSize = sdkKey, maxSize
+	m.queue = &MockEventQueue{Queue: event.NewInMemoryQueue(maxSize)}
+	return m.queue, nil
+}
+
+type MockEventQueue struct {
+	event.Queue
+	closed bool
+}
+
+func (m *MockEventQueue) Close() error {
+	m.closed = true
+	return nil
+}
+
+type forbiddenConfigManager struct {
+	ErrorConfigManager
+}
+
+func (f forbiddenConfigManager) GetConfig() (sdkconfig.ProjectConfig, error) {
+	return nil, sdkconfig.Err403Forbidden
+}
+
+type testCounter struct {
+	lock  sync.Mutex
+	value float64
+}
+
+func (c *testCounter) Add(delta float64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.value += delta
+}
+
+type testGauge struct {
+	lock  sync.Mutex
+	value float64
+}
+
+func (g *testGauge) Set(value float64) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.value = value
+}
@@ -20,6 +20,8 @@ package optimizely
 import (
 	"context"
 	"errors"
+	"sync"
+	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -28,11 +30,15 @@ import (
 	optimizelyclient "github.com/optimizely/go-sdk/v2/pkg/client"
 	"github.com/optimizely/go-sdk/v2/pkg/decision"
 	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/event"
 )
 
 // ErrEntityNotFound is returned when no entity exists with a given key
 var ErrEntityNotFound = errors.New("not found")
 
+// eventQueuePollInterval is how often WaitForEventQueue checks for room in a full event queue
+var eventQueuePollInterval = 10 * time.Millisecond
+
 // ErrForcedVariationsUninitialized is returned from SetForcedVariation and GetForcedVariation when the forced variations store is not initialized
 var ErrForcedVariationsUninitialized = errors.New("client forced variations store not initialized")
 
@@ -43,6 +49,41 @@ type OptlyClient struct {
 	ForcedVariations   *decision.MapExperimentOverridesStore
 	UserProfileService decision.UserProfileService
 	odpCache           cache.Cache
+	datafile           *datafileState
+	sdkKey             string
+	decisionLog        *DecisionLogger
+	// onClose releases the resources that the SDK client does not own
+	onClose []func()
+}
+
+// Datafile sources reported by DatafileInfo
+const (
+	DatafileSourceCDN      = "cdn"
+	DatafileSourceSnapshot = "snapshot"
+)
+
+// DatafileInfo describes the datafile currently used by a client
+type DatafileInfo struct {
+	Source   string `json:"source"`
+	Revision string `json:"revision"`
+}
+
+// datafileState tracks where the current datafile was loaded from
+type datafileState struct {
+	lock   sync.RWMutex
+	source string
+}
+
+func (d *datafileState) set(source string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.source = source
+}
+
+func (d *datafileState) get() string {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.source
 }
 
 // Decision Model
@@ -79,6 +120,25 @@ type SendOdpEventResponseModel struct {
 	Error   string `json:"error,omitempty"`
 }
 
+// Close stops the client, flushing its pending events, and releases the resources held for it
+func (c *OptlyClient) Close() {
+	if c.OptimizelyClient != nil {
+		c.OptimizelyClient.Close()
+	}
+	for _, fn := range c.onClose {
+		fn()
+	}
+}
+
+// BindRequest attributes the decisions logged for userID to requestID until the returned function is called.
+// Decisions made while several requests are bound to the same user are logged without a request ID.
+func (c *OptlyClient) BindRequest(userID, requestID string) func() {
+	if c.decisionLog == nil || requestID == "" {
+		return func() {}
+	}
+	return c.decisionLog.bind(c.sdkKey, userID, requestID)
+}
+
 // UpdateConfig uses config manager to sync and set project config
 func (c *OptlyClient) UpdateConfig() {
 	if c.ConfigManager != nil {
@@ -86,6 +146,20 @@ func (c *OptlyClient) UpdateConfig() {
 	}
 }
 
+// DatafileInfo returns the source and revision of the datafile currently used by the client
+func (c *OptlyClient) DatafileInfo() DatafileInfo {
+	info := DatafileInfo{Source: DatafileSourceCDN}
+	if c.datafile != nil {
+		info.Source = c.datafile.get()
+	}
+	if c.ConfigManager != nil {
+		if projectConfig, err := c.ConfigManager.GetConfig(); err == nil && projectConfig != nil {
+			info.Revision = projectConfig.GetRevision()
+		}
+	}
+	return info
+}
+
 // TrackEvent checks for the existence of the event before calling the OptimizelyClient Track method
 func (c *OptlyClient) TrackEvent(ctx context.Context, eventKey string, uc entities.UserContext, eventTags map[string]interface{}) (*Track, error) {
 	_, span := otel.Tracer("trackHandler").Start(ctx, "TrackEvent")
@@ -111,6 +185,29 @@ func (c *OptlyClient) TrackEvent(ctx context.Context, eventKey string, uc entiti
 	return tr, nil
 }
 
+// WaitForEventQueue blocks while the event queue of the client is full, since the events processed meanwhile
+// would be discarded. It returns the error of ctx if ctx is done first.
+func (c *OptlyClient) WaitForEventQueue(ctx context.Context) error {
+	if c.OptimizelyClient == nil {
+		return nil
+	}
+	bp, ok := c.EventProcessor.(*event.BatchEventProcessor)
+	if !ok || bp.Q == nil || bp.Q.Size() < bp.MaxQueueSize {
+		return nil
+	}
+
+	ticker := time.NewTicker(eventQueuePollInterval)
+	defer ticker.Stop()
+	for bp.Q.Size() >= bp.MaxQueueSize {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
 // SetForcedVariation sets a forced variation for the argument experiment key and user ID
 // Returns false if the same forced variation was already set for the argument experiment and user, true otherwise
 // Returns an error when forced variations are not available on this OptlyClient instance
@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -30,6 +31,7 @@ import (
 	"github.com/optimizely/go-sdk/v2/pkg/config"
 	"github.com/optimizely/go-sdk/v2/pkg/decision"
 	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/event"
 	"github.com/optimizely/go-sdk/v2/pkg/notification"
 )
 
@@ -352,3 +354,28 @@ func TestTrackErrorClient(t *testing.T) {
 
 	assert.Equal(t, expected, actual)
 }
+
+func TestWaitForEventQueue(t *testing.T) {
+	q := event.NewInMemoryQueue(2)
+	ep := event.NewBatchEventProcessor(event.WithQueueSize(2), event.WithBatchSize(2), event.WithQueue(q))
+	optlyClient := &OptlyClient{OptimizelyClient: &client.OptimizelyClient{EventProcessor: ep}}
+
+	// Returns right away while there is room in the queue
+	q.Add(event.UserEvent{})
+	assert.NoError(t, optlyClient.WaitForEventQueue(context.Background()))
+
+	q.Add(event.UserEvent{})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, optlyClient.WaitForEventQueue(ctx))
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		q.Remove(1)
+	}()
+	assert.NoError(t, optlyClient.WaitForEventQueue(context.Background()))
+
+	// Event processors without a queue are not waited for
+	optlyClient = &OptlyClient{OptimizelyClient: &client.OptimizelyClient{EventProcessor: &optimizelytest.TestEventProcessor{}}}
+	assert.NoError(t, optlyClient.WaitForEventQueue(ctx))
+}
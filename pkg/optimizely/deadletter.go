@@ -0,0 +1,277 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package optimizely //
+package optimizely
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	cmap "github.com/orcaman/concurrent-map"
+	"github.com/rs/zerolog/log"
+
+	"github.com/optimizely/agent/config"
+	"github.com/optimizely/agent/plugins/deadletter"
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	go_sdk_metrics "github.com/optimizely/go-sdk/v2/pkg/metrics"
+)
+
+// Dead-letter metrics
+const (
+	DeadLetterAddedMetric    = "dead-letter.added"
+	DeadLetterFailedMetric   = "dead-letter.failed"
+	DeadLetterReplayedMetric = "dead-letter.replayed"
+)
+
+// ErrReplayFailed is returned when a dead-lettered batch is rejected again by the event endpoint
+var ErrReplayFailed = errors.New("replay failed")
+
+// errDispatchFailed is recorded for the batches rejected by the event endpoint without an error
+var errDispatchFailed = errors.New("event dispatch failed")
+
+// Bounds of the background retries: deadLetterRetryQueueSize batches wait for one of the deadLetterRetryWorkers,
+// the batches that fail once the queue is full are kept in the dead-letter store without being retried
+const (
+	deadLetterRetryQueueSize = 1000
+	deadLetterRetryWorkers   = 10
+)
+
+// DeadLetters writes the event batches that could not be dispatched to the configured dead-letter store, from
+// which they can be listed, replayed or purged. Batches are written before they are removed from the event queue
+// and then retried in the background, so that the event processor is not blocked while they wait for their next
+// attempt. Batches that are accepted by a retry are removed from the store.
+type DeadLetters struct {
+	store         deadletter.Store
+	maxAttempts   int
+	retryInterval time.Duration
+	now           func() time.Time
+	// sleep waits before a retry and reports false when ctx is done first
+	sleep   func(ctx context.Context, d time.Duration) bool
+	retries chan deadLetterRetry
+	// newDispatcher returns the dispatcher used to replay the batches of an SDK key
+	newDispatcher func(sdkKey string) event.Dispatcher
+
+	added    go_sdk_metrics.Counter
+	failed   go_sdk_metrics.Counter
+	replayed go_sdk_metrics.Counter
+}
+
+// NewDeadLetters creates the store configured in conf, it returns nil when no store is configured. Replayed
+// batches are forwarded by eventForwarder, which may be nil, like the batches dispatched by the event processor.
+func NewDeadLetters(conf config.DeadLetterConfig, eventForwarder *EventForwarder, metricsRegistry *MetricsRegistry) *DeadLetters {
+	rawStore := getServiceWithType(deadLetterStorePlugin, "", cmap.New(), conf.Store)
+	if rawStore == nil {
+		return nil
+	}
+	store, ok := rawStore.(deadletter.Store)
+	if !ok || store == nil {
+		return nil
+	}
+
+	maxAttempts := conf.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	return &DeadLetters{
+		store:         store,
+		maxAttempts:   maxAttempts,
+		retryInterval: conf.RetryInterval,
+		now:           time.Now,
+		sleep:         sleepContext,
+		retries:       make(chan deadLetterRetry, deadLetterRetryQueueSize),
+		newDispatcher: func(sdkKey string) event.Dispatcher {
+			return eventForwarder.wrap(sdkKey, event.NewHTTPEventDispatcher(sdkKey, nil, nil))
+		},
+		added:    metricsRegistry.GetCounter(DeadLetterAddedMetric),
+		failed:   metricsRegistry.GetCounter(DeadLetterFailedMetric),
+		replayed: metricsRegistry.GetCounter(DeadLetterReplayedMetric),
+	}
+}
+
+// ListDeadLetters returns the dead-lettered batches of sdkKey, oldest first
+func (d *DeadLetters) ListDeadLetters(sdkKey string) ([]deadletter.Batch, error) {
+	return d.store.List(sdkKey)
+}
+
+// GetDeadLetter returns the dead-lettered batch of sdkKey with the given ID
+func (d *DeadLetters) GetDeadLetter(sdkKey, id string) (deadletter.Batch, error) {
+	return d.store.Get(sdkKey, id)
+}
+
+// ReplayDeadLetter dispatches the dead-lettered batch once and removes it from the store when it is accepted.
+// A rejected batch is kept with its attempts and error updated, and ErrReplayFailed is returned.
+func (d *DeadLetters) ReplayDeadLetter(sdkKey, id string) error {
+	batch, err := d.store.Get(sdkKey, id)
+	if err != nil {
+		return err
+	}
+
+	logEvent := event.LogEvent{EndPoint: batch.EndPoint, Event: batch.Event}
+	if err := dispatch(d.newDispatcher(sdkKey), logEvent); err != nil {
+		batch.Attempts++
+		batch.Error = err.Error()
+		if storeErr := d.store.Add(sdkKey, batch); storeErr != nil {
+			log.Warn().Err(storeErr).Str("id", id).Msg("Unable to update dead-lettered batch")
+		}
+		return fmt.Errorf("%w: %v", ErrReplayFailed, err)
+	}
+
+	d.replayed.Add(1)
+	return d.store.Remove(sdkKey, id)
+}
+
+// RemoveDeadLetter deletes the dead-lettered batch of sdkKey with the given ID
+func (d *DeadLetters) RemoveDeadLetter(sdkKey, id string) error {
+	return d.store.Remove(sdkKey, id)
+}
+
+// PurgeDeadLetters deletes every dead-lettered batch of sdkKey and returns how many were deleted
+func (d *DeadLetters) PurgeDeadLetters(sdkKey string) (int, error) {
+	return d.store.Purge(sdkKey)
+}
+
+// Run retries the stored batches with deadLetterRetryWorkers workers until ctx is done. The batches waiting to be
+// retried are already in the store, so they are kept there.
+func (d *DeadLetters) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < deadLetterRetryWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case retry := <-d.retries:
+					d.retry(ctx, retry)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// deadLetterRetry is a stored batch waiting for its next dispatch attempt
+type deadLetterRetry struct {
+	sdkKey     string
+	dispatcher event.Dispatcher
+	batch      deadletter.Batch
+}
+
+// retry dispatches the stored batch until maxAttempts attempts were made, waiting retryInterval before the second
+// attempt and doubling it after every failed attempt. The batch is removed from the store once it is accepted and
+// its attempts and error are updated otherwise. Retries stop when ctx is done, or when the batch is no longer
+// stored because it was replayed or purged in the meantime.
+func (d *DeadLetters) retry(ctx context.Context, retry deadLetterRetry) {
+	interval := d.retryInterval
+	batch := retry.batch
+	for batch.Attempts < d.maxAttempts {
+		if !d.sleep(ctx, interval) {
+			return
+		}
+		interval *= 2
+		if _, err := d.store.Get(retry.sdkKey, batch.ID); err != nil {
+			return
+		}
+
+		batch.Attempts++
+		err := dispatch(retry.dispatcher, event.LogEvent{EndPoint: batch.EndPoint, Event: batch.Event})
+		if err == nil {
+			if err := d.store.Remove(retry.sdkKey, batch.ID); err != nil && !errors.Is(err, deadletter.ErrNotFound) {
+				log.Warn().Err(err).Str("id", batch.ID).Msg("Unable to remove retried dead-lettered batch")
+			}
+			d.replayed.Add(1)
+			return
+		}
+		batch.Error = err.Error()
+		if err := d.store.Add(retry.sdkKey, batch); err != nil {
+			log.Warn().Err(err).Str("id", batch.ID).Msg("Unable to update dead-lettered batch")
+		}
+	}
+}
+
+// add writes the batch to the dead-letter store and returns it
+func (d *DeadLetters) add(sdkKey string, logEvent event.LogEvent, err error) (deadletter.Batch, error) {
+	batch := deadletter.Batch{
+		ID:        uuid.NewString(),
+		EndPoint:  logEvent.EndPoint,
+		Event:     logEvent.Event,
+		Error:     err.Error(),
+		Attempts:  1,
+		Timestamp: d.now().UTC(),
+	}
+	if storeErr := d.store.Add(sdkKey, batch); storeErr != nil {
+		log.Error().Err(storeErr).AnErr("dispatchError", err).Msg("Unable to write event batch to the dead-letter store")
+		d.failed.Add(1)
+		return batch, storeErr
+	}
+
+	log.Warn().Err(err).Str("id", batch.ID).Msg("Event batch could not be dispatched, it was written to the dead-letter store")
+	d.added.Add(1)
+	return batch, nil
+}
+
+// wrap returns a dispatcher that dead-letters the batches dispatcher fails to send
+func (d *DeadLetters) wrap(sdkKey string, dispatcher event.Dispatcher) event.Dispatcher {
+	return &deadLetterDispatcher{sdkKey: sdkKey, dispatcher: dispatcher, deadLetters: d}
+}
+
+// deadLetterDispatcher writes the batches that fail their first attempt to the dead-letter store and hands them
+// to the retries of DeadLetters
+type deadLetterDispatcher struct {
+	sdkKey      string
+	dispatcher  event.Dispatcher
+	deadLetters *DeadLetters
+}
+
+// DispatchEvent reports a batch that could not be dispatched as sent once it is written to the dead-letter store,
+// so that it is removed from the event queue. It only fails when the batch can not be written to the store.
+func (d *deadLetterDispatcher) DispatchEvent(logEvent event.LogEvent) (bool, error) {
+	err := dispatch(d.dispatcher, logEvent)
+	if err == nil {
+		return true, nil
+	}
+
+	batch, storeErr := d.deadLetters.add(d.sdkKey, logEvent, err)
+	if storeErr != nil {
+		return false, storeErr
+	}
+	if d.deadLetters.maxAttempts > 1 {
+		select {
+		case d.deadLetters.retries <- deadLetterRetry{sdkKey: d.sdkKey, dispatcher: d.dispatcher, batch: batch}:
+		default:
+			log.Warn().Str("id", batch.ID).Msg("Dead-letter retry queue is full, the event batch is kept in the dead-letter store without retries")
+		}
+	}
+	return true, nil
+}
+
+func dispatch(dispatcher event.Dispatcher, logEvent event.LogEvent) error {
+	success, err := dispatcher.DispatchEvent(logEvent)
+	if err != nil {
+		return err
+	}
+	if !success {
+		return errDispatchFailed
+	}
+	return nil
+}
@@ -0,0 +1,383 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package optimizely //
+package optimizely
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/agent/config"
+	"github.com/optimizely/agent/plugins/deadletter"
+)
+
+// memoryDeadLetterStore keeps the batches in a map
+type memoryDeadLetterStore struct {
+	lock    sync.Mutex
+	batches map[string]deadletter.Batch
+	err     error
+}
+
+func (m *memoryDeadLetterStore) Add(sdkKey string, batch deadletter.Batch) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.batches[sdkKey+"/"+batch.ID] = batch
+	return nil
+}
+
+func (m *memoryDeadLetterStore) List(sdkKey string) ([]deadletter.Batch, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	var batches []deadletter.Batch
+	for key, batch := range m.batches {
+		if key == sdkKey+"/"+batch.ID {
+			batches = append(batches, batch)
+		}
+	}
+	sort.Slice(batches, func(i, j int) bool { return batches[i].ID < batches[j].ID })
+	return batches, nil
+}
+
+func (m *memoryDeadLetterStore) Get(sdkKey, id string) (deadletter.Batch, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	batch, ok := m.batches[sdkKey+"/"+id]
+	if !ok {
+		return batch, deadletter.ErrNotFound
+	}
+	return batch, nil
+}
+
+func (m *memoryDeadLetterStore) Remove(sdkKey, id string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.batches[sdkKey+"/"+id]; !ok {
+		return deadletter.ErrNotFound
+	}
+	delete(m.batches, sdkKey+"/"+id)
+	return nil
+}
+
+func (m *memoryDeadLetterStore) Purge(sdkKey string) (int, error) {
+	batches, _ := m.List(sdkKey)
+	for _, batch := range batches {
+		_ = m.Remove(sdkKey, batch.ID)
+	}
+	return len(batches), nil
+}
+
+// scriptedDispatcher returns the given results in order and then succeeds
+type scriptedDispatcher struct {
+	results []error
+	events  []event.LogEvent
+}
+
+func (d *scriptedDispatcher) DispatchEvent(logEvent event.LogEvent) (bool, error) {
+	d.events = append(d.events, logEvent)
+	if len(d.results) == 0 {
+		return true, nil
+	}
+	err := d.results[0]
+	d.results = d.results[1:]
+	if errors.Is(err, errDispatchFailed) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func init() {
+	deadletter.Add("test", func() deadletter.Store {
+		return &memoryDeadLetterStore{batches: map[string]deadletter.Batch{}}
+	})
+}
+
+type DeadLettersTestSuite struct {
+	suite.Suite
+	store       *memoryDeadLetterStore
+	deadLetters *DeadLetters
+	dispatcher  *scriptedDispatcher
+	sleeps      []time.Duration
+	logEvent    event.LogEvent
+}
+
+func (s *DeadLettersTestSuite) SetupTest() {
+	s.store = &memoryDeadLetterStore{batches: map[string]deadletter.Batch{}}
+	s.dispatcher = &scriptedDispatcher{}
+	s.sleeps = nil
+	s.deadLetters = &DeadLetters{
+		store:         s.store,
+		maxAttempts:   3,
+		retryInterval: time.Second,
+		now:           func() time.Time { return time.Unix(100, 0) },
+		sleep: func(ctx context.Context, d time.Duration) bool {
+			s.sleeps = append(s.sleeps, d)
+			return ctx.Err() == nil
+		},
+		retries:       make(chan deadLetterRetry, 1),
+		newDispatcher: func(string) event.Dispatcher { return s.dispatcher },
+		added:         &testCounter{},
+		failed:        &testCounter{},
+		replayed:      &testCounter{},
+	}
+	s.logEvent = event.LogEvent{
+		EndPoint: "https://logx.optimizely.com/v1/events",
+		Event:    event.Batch{AccountID: "1", Revision: "2", Visitors: []event.Visitor{{VisitorID: "user"}}},
+	}
+}
+
+func (s *DeadLettersTestSuite) counter(c interface{}) float64 {
+	return c.(*testCounter).value
+}
+
+// dispatch dispatches the batch and then runs its retry, if any
+func (s *DeadLettersTestSuite) dispatch(ctx context.Context) (bool, error) {
+	success, err := s.deadLetters.wrap("sdkKey", s.dispatcher).DispatchEvent(s.logEvent)
+	s.Empty(s.sleeps, "the dispatch must not wait for the retries")
+	select {
+	case retry := <-s.deadLetters.retries:
+		s.deadLetters.retry(ctx, retry)
+	default:
+	}
+	return success, err
+}
+
+func (s *DeadLettersTestSuite) TestDispatchRetries() {
+	s.dispatcher.results = []error{errors.New("timeout"), errDispatchFailed}
+	success, err := s.dispatch(context.Background())
+	s.True(success)
+	s.NoError(err)
+	s.Len(s.dispatcher.events, 3)
+	s.Equal([]time.Duration{time.Second, 2 * time.Second}, s.sleeps)
+
+	// The batch was stored until a retry was accepted
+	batches, err := s.deadLetters.ListDeadLetters("sdkKey")
+	s.NoError(err)
+	s.Empty(batches)
+	s.Equal(1.0, s.counter(s.deadLetters.added))
+	s.Equal(1.0, s.counter(s.deadLetters.replayed))
+}
+
+func (s *DeadLettersTestSuite) TestDispatchFailureIsDeadLettered() {
+	s.dispatcher.results = []error{errors.New("timeout"), errors.New("timeout"), errDispatchFailed}
+	success, err := s.dispatch(context.Background())
+	s.True(success)
+	s.NoError(err)
+	s.Len(s.dispatcher.events, 3)
+
+	batches, err := s.deadLetters.ListDeadLetters("sdkKey")
+	s.NoError(err)
+	s.Require().Len(batches, 1)
+	s.NotEmpty(batches[0].ID)
+	s.Equal(s.logEvent.EndPoint, batches[0].EndPoint)
+	s.Equal(s.logEvent.Event, batches[0].Event)
+	s.Equal("event dispatch failed", batches[0].Error)
+	s.Equal(3, batches[0].Attempts)
+	s.Equal(time.Unix(100, 0).UTC(), batches[0].Timestamp)
+	s.Equal(1.0, s.counter(s.deadLetters.added))
+}
+
+func (s *DeadLettersTestSuite) TestDispatchFailsWhenStoreFails() {
+	s.deadLetters.maxAttempts = 1
+	s.store.err = errors.New("disk full")
+	s.dispatcher.results = []error{errors.New("timeout")}
+	success, err := s.deadLetters.wrap("sdkKey", s.dispatcher).DispatchEvent(s.logEvent)
+	s.False(success)
+	s.EqualError(err, "disk full")
+	s.Empty(s.deadLetters.retries)
+	s.Equal(1.0, s.counter(s.deadLetters.failed))
+}
+
+func (s *DeadLettersTestSuite) TestDispatchWithFullRetryQueue() {
+	s.deadLetters.retries = make(chan deadLetterRetry)
+	s.dispatcher.results = []error{errors.New("timeout")}
+	success, err := s.dispatch(context.Background())
+	s.True(success)
+	s.NoError(err)
+	s.Len(s.dispatcher.events, 1)
+
+	batches, err := s.deadLetters.ListDeadLetters("sdkKey")
+	s.NoError(err)
+	s.Require().Len(batches, 1)
+	s.Equal(1, batches[0].Attempts)
+	s.Equal("timeout", batches[0].Error)
+}
+
+func (s *DeadLettersTestSuite) TestBatchIsStoredBeforeItIsRetried() {
+	s.dispatcher.results = []error{errors.New("timeout")}
+	success, err := s.deadLetters.wrap("sdkKey", s.dispatcher).DispatchEvent(s.logEvent)
+	s.True(success)
+	s.NoError(err)
+
+	batches, err := s.deadLetters.ListDeadLetters("sdkKey")
+	s.NoError(err)
+	s.Require().Len(batches, 1)
+	s.Equal(1, batches[0].Attempts)
+	s.Equal(1.0, s.counter(s.deadLetters.added))
+
+	// Retries stop on shutdown and the batch is kept in the store
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.deadLetters.retry(ctx, <-s.deadLetters.retries)
+	s.Len(s.dispatcher.events, 1)
+	batches, err = s.deadLetters.ListDeadLetters("sdkKey")
+	s.NoError(err)
+	s.Len(batches, 1)
+}
+
+func (s *DeadLettersTestSuite) TestRetryStopsWhenBatchWasPurged() {
+	s.dispatcher.results = []error{errors.New("timeout")}
+	_, err := s.deadLetters.wrap("sdkKey", s.dispatcher).DispatchEvent(s.logEvent)
+	s.NoError(err)
+
+	purged, err := s.deadLetters.PurgeDeadLetters("sdkKey")
+	s.NoError(err)
+	s.Equal(1, purged)
+	s.deadLetters.retry(context.Background(), <-s.deadLetters.retries)
+	s.Len(s.dispatcher.events, 1)
+	batches, err := s.deadLetters.ListDeadLetters("sdkKey")
+	s.NoError(err)
+	s.Empty(batches)
+}
+
+func (s *DeadLettersTestSuite) TestRunBoundsTheRetries() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.deadLetters.sleep = func(ctx context.Context, _ time.Duration) bool {
+		<-ctx.Done()
+		return false
+	}
+	retries := make(chan deadLetterRetry, deadLetterRetryWorkers+5)
+	for i := 0; i < cap(retries); i++ {
+		retries <- deadLetterRetry{sdkKey: "sdkKey", dispatcher: s.dispatcher}
+	}
+	s.deadLetters.retries = retries
+	done := make(chan struct{})
+	go func() {
+		s.deadLetters.Run(ctx)
+		close(done)
+	}()
+
+	// Every worker waits for the retry of a batch, the other batches stay queued
+	s.Eventually(func() bool { return len(retries) == 5 }, time.Second, time.Millisecond)
+	s.Never(func() bool { return len(retries) < 5 }, 50*time.Millisecond, time.Millisecond)
+	cancel()
+	<-done
+	s.Empty(s.dispatcher.events)
+}
+
+func (s *DeadLettersTestSuite) TestRunRetries() {
+	s.deadLetters.sleep = func(context.Context, time.Duration) bool { return true }
+	s.dispatcher.results = []error{errors.New("timeout")}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.deadLetters.Run(ctx)
+		close(done)
+	}()
+
+	success, err := s.deadLetters.wrap("sdkKey", s.dispatcher).DispatchEvent(s.logEvent)
+	s.True(success)
+	s.NoError(err)
+	s.Eventually(func() bool { return len(s.deadLetters.retries) == 0 }, time.Second, time.Millisecond)
+	cancel()
+	<-done
+
+	s.Len(s.dispatcher.events, 2)
+	batches, err := s.deadLetters.ListDeadLetters("sdkKey")
+	s.NoError(err)
+	s.Empty(batches)
+}
+
+func (s *DeadLettersTestSuite) TestReplay() {
+	s.NoError(s.store.Add("sdkKey", deadletter.Batch{ID: "1", EndPoint: s.logEvent.EndPoint, Event: s.logEvent.Event, Attempts: 3}))
+
+	s.NoError(s.deadLetters.ReplayDeadLetter("sdkKey", "1"))
+	s.Equal([]event.LogEvent{s.logEvent}, s.dispatcher.events)
+	_, err := s.deadLetters.GetDeadLetter("sdkKey", "1")
+	s.Equal(deadletter.ErrNotFound, err)
+	s.Equal(1.0, s.counter(s.deadLetters.replayed))
+
+	s.Equal(deadletter.ErrNotFound, s.deadLetters.ReplayDeadLetter("sdkKey", "1"))
+}
+
+func (s *DeadLettersTestSuite) TestReplayFailureKeepsBatch() {
+	s.NoError(s.store.Add("sdkKey", deadletter.Batch{ID: "1", EndPoint: s.logEvent.EndPoint, Event: s.logEvent.Event, Attempts: 3}))
+	s.dispatcher.results = []error{errors.New("timeout")}
+
+	err := s.deadLetters.ReplayDeadLetter("sdkKey", "1")
+	s.True(errors.Is(err, ErrReplayFailed))
+	s.EqualError(err, "replay failed: timeout")
+
+	batch, err := s.deadLetters.GetDeadLetter("sdkKey", "1")
+	s.NoError(err)
+	s.Equal(4, batch.Attempts)
+	s.Equal("timeout", batch.Error)
+	s.Equal(0.0, s.counter(s.deadLetters.replayed))
+}
+
+func (s *DeadLettersTestSuite) TestRemoveAndPurge() {
+	s.NoError(s.store.Add("sdkKey", deadletter.Batch{ID: "1"}))
+	s.NoError(s.store.Add("sdkKey", deadletter.Batch{ID: "2"}))
+	s.NoError(s.store.Add("sdkKey", deadletter.Batch{ID: "3"}))
+
+	s.NoError(s.deadLetters.RemoveDeadLetter("sdkKey", "1"))
+	purged, err := s.deadLetters.PurgeDeadLetters("sdkKey")
+	s.NoError(err)
+	s.Equal(2, purged)
+}
+
+func TestDeadLettersTestSuite(t *testing.T) {
+	suite.Run(t, new(DeadLettersTestSuite))
+}
+
+func TestNewDeadLettersWithoutStore(t *testing.T) {
+	conf := config.NewDefaultConfig().Client.DeadLetter
+	if NewDeadLetters(conf, nil, nil) != nil {
+		t.Error("expected no dead letters without a store")
+	}
+}
+
+func TestNewDeadLettersReplaysThroughEventForwarder(t *testing.T) {
+	conf := config.NewDefaultConfig().Client.DeadLetter
+	conf.Store = config.DeadLetterStoreConfigs{
+		"default":  "test",
+		"services": map[string]interface{}{"test": map[string]interface{}{}},
+	}
+	eventForwarder := &EventForwarder{}
+	deadLetters := NewDeadLetters(conf, eventForwarder, eventForwarderRegistry())
+	if deadLetters == nil {
+		t.Fatal("expected dead letters with the test store")
+	}
+	dispatcher, ok := deadLetters.newDispatcher("sdkKey").(*forwardingDispatcher)
+	if !ok || dispatcher.forwarder != eventForwarder || dispatcher.sdkKey != "sdkKey" {
+		t.Error("expected the replayed batches to be forwarded")
+	}
+
+	deadLetters = NewDeadLetters(conf, nil, eventForwarderRegistry())
+	if _, ok := deadLetters.newDispatcher("sdkKey").(*forwardingDispatcher); ok {
+		t.Error("expected the replayed batches to be dispatched directly without event forwarding")
+	}
+}
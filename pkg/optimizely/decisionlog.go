@@ -0,0 +1,357 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package optimizely //
+package optimizely
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"sync"
+	"time"
+
+	cmap "github.com/orcaman/concurrent-map"
+	"github.com/rs/zerolog/log"
+
+	"github.com/optimizely/agent/config"
+	"github.com/optimizely/agent/plugins/decisionlog"
+	go_sdk_metrics "github.com/optimizely/go-sdk/v2/pkg/metrics"
+	"github.com/optimizely/go-sdk/v2/pkg/notification"
+	"github.com/optimizely/go-sdk/v2/pkg/registry"
+)
+
+// Decision log metrics
+const (
+	DecisionLogWrittenMetric = "decision-log.written"
+	DecisionLogDroppedMetric = "decision-log.dropped"
+	DecisionLogFailedMetric  = "decision-log.failed"
+)
+
+// DecisionLogger writes the decisions served by every client to the configured decision log sink.
+// Decisions are queued in a bounded buffer and written in batches by a single goroutine so that serving
+// a decision never waits on the sink, they are dropped when the buffer is full.
+type DecisionLogger struct {
+	sink          decisionlog.Sink
+	records       chan decisionlog.Record
+	batchSize     int
+	flushInterval time.Duration
+	hashKey       []byte
+	now           func() time.Time
+
+	lock          sync.Mutex
+	subscriptions map[string]*decisionSubscription
+	requests      map[decisionRequestKey][]string
+
+	written go_sdk_metrics.Counter
+	dropped go_sdk_metrics.Counter
+	failed  go_sdk_metrics.Counter
+}
+
+// decisionSubscription is the decision handler registered on the notification center of an SDK key,
+// it is shared by the clients of that SDK key
+type decisionSubscription struct {
+	id       int
+	refs     int
+	revision func() string
+}
+
+type decisionRequestKey struct {
+	sdkKey string
+	userID string
+}
+
+// NewDecisionLogger creates the sink configured in conf, it returns nil when no sink is configured
+func NewDecisionLogger(conf config.DecisionLogConfig, metricsRegistry *MetricsRegistry) *DecisionLogger {
+	rawSink := getServiceWithType(decisionLogSinkPlugin, "", cmap.New(), conf.Sink)
+	if rawSink == nil {
+		return nil
+	}
+	sink, ok := rawSink.(decisionlog.Sink)
+	if !ok || sink == nil {
+		return nil
+	}
+
+	batchSize := conf.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	flushInterval := conf.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
+	bufferSize := conf.BufferSize
+	if bufferSize < 0 {
+		bufferSize = 0
+	}
+
+	return &DecisionLogger{
+		sink:          sink,
+		records:       make(chan decisionlog.Record, bufferSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		hashKey:       []byte(conf.AttributeHashKey),
+		now:           time.Now,
+		subscriptions: make(map[string]*decisionSubscription),
+		requests:      make(map[decisionRequestKey][]string),
+		written:       metricsRegistry.GetCounter(DecisionLogWrittenMetric),
+		dropped:       metricsRegistry.GetCounter(DecisionLogDroppedMetric),
+		failed:        metricsRegistry.GetCounter(DecisionLogFailedMetric),
+	}
+}
+
+// Run writes the queued decisions to the sink until ctx is done, then writes the decisions left in
+// the buffer and closes the sink
+func (l *DecisionLogger) Run(ctx context.Context) {
+	ticker := time.NewTicker(l.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]decisionlog.Record, 0, l.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := l.sink.Write(batch); err != nil {
+			log.Error().Err(err).Int("decisions", len(batch)).Msg("Failed to write decision log")
+			l.failed.Add(float64(len(batch)))
+		} else {
+			l.written.Add(float64(len(batch)))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case record := <-l.records:
+			batch = append(batch, record)
+			if len(batch) >= l.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-ctx.Done():
+			// Run is the only reader, so the buffered decisions can be received without blocking
+			for len(l.records) > 0 {
+				batch = append(batch, <-l.records)
+				if len(batch) >= l.batchSize {
+					flush()
+				}
+			}
+			flush()
+			if closer, ok := l.sink.(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					log.Error().Err(err).Msg("Failed to close decision log")
+				}
+			}
+			return
+		}
+	}
+}
+
+// subscribe logs the decisions sent through the notification center of sdkKey, the subscription is
+// removed once every client of the SDK key has called the returned function
+func (l *DecisionLogger) subscribe(sdkKey string, revision func() string) (func(), error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	sub, ok := l.subscriptions[sdkKey]
+	if !ok {
+		nc := registry.GetNotificationCenter(sdkKey)
+		sub = &decisionSubscription{}
+		id, err := nc.AddHandler(notification.Decision, func(payload interface{}) {
+			if n, ok := payload.(notification.DecisionNotification); ok {
+				l.lock.Lock()
+				current := sub.revision
+				l.lock.Unlock()
+				l.log(sdkKey, current(), n)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		sub.id = id
+		l.subscriptions[sdkKey] = sub
+	}
+	sub.refs++
+	sub.revision = revision
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.lock.Lock()
+			defer l.lock.Unlock()
+			if sub.refs--; sub.refs > 0 {
+				return
+			}
+			delete(l.subscriptions, sdkKey)
+			if err := registry.GetNotificationCenter(sdkKey).RemoveHandler(sub.id, notification.Decision); err != nil {
+				log.Warn().Err(err).Msg("Unable to remove decision log handler")
+			}
+		})
+	}, nil
+}
+
+// wrap returns a notification center that logs the decisions sent through nc. It is used for the
+// synchronized notification center, which forwards decisions to redis instead of local handlers.
+func (l *DecisionLogger) wrap(sdkKey string, nc notification.Center, revision func() string) notification.Center {
+	return &decisionLogNotificationCenter{
+		Center: nc,
+		onDecision: func(n notification.DecisionNotification) {
+			l.log(sdkKey, revision(), n)
+		},
+	}
+}
+
+// bind attributes the decisions made for the user to requestID until the returned function is called
+func (l *DecisionLogger) bind(sdkKey, userID, requestID string) func() {
+	key := decisionRequestKey{sdkKey: sdkKey, userID: userID}
+	l.lock.Lock()
+	l.requests[key] = append(l.requests[key], requestID)
+	l.lock.Unlock()
+
+	return func() {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		ids := l.requests[key]
+		for i, id := range ids {
+			if id == requestID {
+				ids = append(ids[:i], ids[i+1:]...)
+				break
+			}
+		}
+		if len(ids) == 0 {
+			delete(l.requests, key)
+			return
+		}
+		l.requests[key] = ids
+	}
+}
+
+// requestID returns the request bound to the user, or an empty string when the decision can not be
+// attributed to a single request
+func (l *DecisionLogger) requestID(sdkKey, userID string) string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if ids := l.requests[decisionRequestKey{sdkKey: sdkKey, userID: userID}]; len(ids) == 1 {
+		return ids[0]
+	}
+	return ""
+}
+
+// log queues the decision without blocking, it is dropped when the buffer is full
+func (l *DecisionLogger) log(sdkKey, revision string, n notification.DecisionNotification) {
+	record := l.newRecord(sdkKey, revision, n)
+	select {
+	case l.records <- record:
+	default:
+		l.dropped.Add(1)
+	}
+}
+
+func (l *DecisionLogger) newRecord(sdkKey, revision string, n notification.DecisionNotification) decisionlog.Record {
+	record := decisionlog.Record{
+		Timestamp:  l.now(),
+		SDKKey:     sdkKey,
+		RequestID:  l.requestID(sdkKey, n.UserContext.ID),
+		UserID:     n.UserContext.ID,
+		Attributes: l.hashAttributes(n.UserContext.Attributes),
+		Type:       string(n.Type),
+		Revision:   revision,
+	}
+
+	// Feature notifications nest their details under "feature", flag notifications use the flag
+	// keys and experiment notifications the experiment keys
+	info := n.DecisionInfo
+	if feature, ok := info["feature"].(map[string]interface{}); ok {
+		info = feature
+	}
+	record.FlagKey = stringValue(info, "flagKey", "featureKey")
+	record.RuleKey = stringValue(info, "ruleKey", "experimentKey")
+	record.VariationKey = stringValue(info, "variationKey")
+	if sourceInfo, ok := info["sourceInfo"].(map[string]string); ok {
+		if record.RuleKey == "" {
+			record.RuleKey = sourceInfo["experimentKey"]
+		}
+		if record.VariationKey == "" {
+			record.VariationKey = sourceInfo["variationKey"]
+		}
+	}
+	if enabled, ok := info["enabled"].(bool); ok {
+		record.Enabled = enabled
+	} else if enabled, ok := info["featureEnabled"].(bool); ok {
+		record.Enabled = enabled
+	}
+	return record
+}
+
+// hashAttributes replaces the attribute values with their hex encoded HMAC-SHA256, or SHA-256 when
+// no hash key is configured, so that decisions can be audited without storing user data
+func (l *DecisionLogger) hashAttributes(attributes map[string]interface{}) map[string]string {
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	hashed := make(map[string]string, len(attributes))
+	for name, value := range attributes {
+		hashed[name] = hashValue(l.hashKey, value)
+	}
+	return hashed
+}
+
+// hashValue returns the hex encoded HMAC-SHA256 of the JSON encoded value, or its SHA-256 when key is empty
+func hashValue(key []byte, value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		encoded = []byte(fmt.Sprint(value))
+	}
+	var h hash.Hash
+	if len(key) > 0 {
+		h = hmac.New(sha256.New, key)
+	} else {
+		h = sha256.New()
+	}
+	h.Write(encoded)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func stringValue(info map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := info[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// decisionLogNotificationCenter passes the decision notifications to the decision log before sending
+// them through the wrapped notification center
+type decisionLogNotificationCenter struct {
+	notification.Center
+	onDecision func(notification.DecisionNotification)
+}
+
+// Send logs decision notifications and forwards every notification to the wrapped center
+func (c *decisionLogNotificationCenter) Send(notificationType notification.Type, payload interface{}) error {
+	if n, ok := payload.(notification.DecisionNotification); ok && notificationType == notification.Decision {
+		c.onDecision(n)
+	}
+	return c.Center.Send(notificationType, payload)
+}
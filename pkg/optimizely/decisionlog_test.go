@@ -0,0 +1,297 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package optimizely //
+package optimizely
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics/generic"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/optimizely/agent/plugins/decisionlog"
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/notification"
+	"github.com/optimizely/go-sdk/v2/pkg/registry"
+)
+
+// testSink records the batches written to it
+type testSink struct {
+	lock    sync.Mutex
+	batches [][]decisionlog.Record
+	err     error
+	closed  bool
+}
+
+func (s *testSink) Write(records []decisionlog.Record) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.batches = append(s.batches, append([]decisionlog.Record(nil), records...))
+	return nil
+}
+
+func (s *testSink) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *testSink) records() []decisionlog.Record {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	records := []decisionlog.Record{}
+	for _, batch := range s.batches {
+		records = append(records, batch...)
+	}
+	return records
+}
+
+func init() {
+	decisionlog.Add("test", func() decisionlog.Sink {
+		return &testSink{}
+	})
+}
+
+func newTestDecisionLogger(sink decisionlog.Sink, bufferSize, batchSize int) *DecisionLogger {
+	return &DecisionLogger{
+		sink:          sink,
+		records:       make(chan decisionlog.Record, bufferSize),
+		batchSize:     batchSize,
+		flushInterval: time.Hour,
+		now:           func() time.Time { return time.Unix(1700000000, 0).UTC() },
+		subscriptions: make(map[string]*decisionSubscription),
+		requests:      make(map[decisionRequestKey][]string),
+		written:       generic.NewCounter("written"),
+		dropped:       generic.NewCounter("dropped"),
+		failed:        generic.NewCounter("failed"),
+	}
+}
+
+func flagNotification(userID string) notification.DecisionNotification {
+	return notification.DecisionNotification{
+		Type:        notification.Flag,
+		UserContext: entities.UserContext{ID: userID, Attributes: map[string]interface{}{"plan": "pro"}},
+		DecisionInfo: map[string]interface{}{
+			"flagKey":      "flag",
+			"ruleKey":      "rule",
+			"variationKey": "on",
+			"enabled":      true,
+		},
+	}
+}
+
+func sha256Hex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDecisionRecordFromFlagNotification(t *testing.T) {
+	logger := newTestDecisionLogger(&testSink{}, 1, 1)
+	record := logger.newRecord("sdkKey", "42", flagNotification("user"))
+
+	assert.Equal(t, decisionlog.Record{
+		Timestamp:    time.Unix(1700000000, 0).UTC(),
+		SDKKey:       "sdkKey",
+		UserID:       "user",
+		Attributes:   map[string]string{"plan": sha256Hex(`"pro"`)},
+		Type:         "flag",
+		FlagKey:      "flag",
+		RuleKey:      "rule",
+		VariationKey: "on",
+		Enabled:      true,
+		Revision:     "42",
+	}, record)
+}
+
+func TestDecisionRecordFromFeatureNotification(t *testing.T) {
+	logger := newTestDecisionLogger(&testSink{}, 1, 1)
+	record := logger.newRecord("sdkKey", "42", notification.DecisionNotification{
+		Type:        notification.Feature,
+		UserContext: entities.UserContext{ID: "user"},
+		DecisionInfo: map[string]interface{}{
+			"feature": map[string]interface{}{
+				"featureKey":     "feature",
+				"featureEnabled": true,
+				"sourceInfo":     map[string]string{"experimentKey": "experiment", "variationKey": "variation"},
+			},
+		},
+	})
+
+	assert.Equal(t, "feature", record.FlagKey)
+	assert.Equal(t, "experiment", record.RuleKey)
+	assert.Equal(t, "variation", record.VariationKey)
+	assert.True(t, record.Enabled)
+	assert.Nil(t, record.Attributes)
+}
+
+func TestDecisionRecordFromExperimentNotification(t *testing.T) {
+	logger := newTestDecisionLogger(&testSink{}, 1, 1)
+	record := logger.newRecord("sdkKey", "42", notification.DecisionNotification{
+		Type:         notification.ABTest,
+		UserContext:  entities.UserContext{ID: "user"},
+		DecisionInfo: map[string]interface{}{"experimentKey": "experiment", "variationKey": "variation"},
+	})
+
+	assert.Equal(t, "ab-test", record.Type)
+	assert.Equal(t, "", record.FlagKey)
+	assert.Equal(t, "experiment", record.RuleKey)
+	assert.Equal(t, "variation", record.VariationKey)
+	assert.False(t, record.Enabled)
+}
+
+func TestDecisionRecordHashesAttributesWithKey(t *testing.T) {
+	logger := newTestDecisionLogger(&testSink{}, 1, 1)
+	logger.hashKey = []byte("secret")
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(`10`))
+	assert.Equal(t, map[string]string{"age": hex.EncodeToString(mac.Sum(nil))}, logger.hashAttributes(map[string]interface{}{"age": 10}))
+}
+
+func TestDecisionLoggerRequestBinding(t *testing.T) {
+	logger := newTestDecisionLogger(&testSink{}, 1, 1)
+	assert.Equal(t, "", logger.requestID("sdkKey", "user"))
+
+	unbind := logger.bind("sdkKey", "user", "request-1")
+	assert.Equal(t, "request-1", logger.requestID("sdkKey", "user"))
+	assert.Equal(t, "", logger.requestID("other", "user"))
+	assert.Equal(t, "", logger.requestID("sdkKey", "other"))
+
+	// Concurrent requests for the same user can not be told apart
+	unbindOther := logger.bind("sdkKey", "user", "request-2")
+	assert.Equal(t, "", logger.requestID("sdkKey", "user"))
+
+	unbind()
+	assert.Equal(t, "request-2", logger.requestID("sdkKey", "user"))
+	unbindOther()
+	assert.Equal(t, "", logger.requestID("sdkKey", "user"))
+	assert.Empty(t, logger.requests)
+}
+
+func TestDecisionLoggerDropsWhenBufferIsFull(t *testing.T) {
+	logger := newTestDecisionLogger(&testSink{}, 1, 1)
+	logger.log("sdkKey", "42", flagNotification("user"))
+	logger.log("sdkKey", "42", flagNotification("user"))
+
+	assert.Len(t, logger.records, 1)
+	assert.Equal(t, 1.0, logger.dropped.(*generic.Counter).Value())
+}
+
+func TestDecisionLoggerRunWritesBatches(t *testing.T) {
+	sink := &testSink{}
+	logger := newTestDecisionLogger(sink, 10, 2)
+	for i := 0; i < 5; i++ {
+		logger.log("sdkKey", "42", flagNotification("user"))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		logger.Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool { return len(sink.records()) == 4 }, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	// The last decision is written once the logger stops
+	assert.Len(t, sink.records(), 5)
+	assert.Len(t, sink.batches, 3)
+	assert.True(t, sink.closed)
+	assert.Equal(t, 5.0, logger.written.(*generic.Counter).Value())
+}
+
+func TestDecisionLoggerRunCountsFailures(t *testing.T) {
+	sink := &testSink{err: errors.New("unavailable")}
+	logger := newTestDecisionLogger(sink, 10, 10)
+	logger.log("sdkKey", "42", flagNotification("user"))
+	logger.log("sdkKey", "42", flagNotification("user"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	logger.Run(ctx)
+
+	assert.Equal(t, 2.0, logger.failed.(*generic.Counter).Value())
+	assert.Equal(t, 0.0, logger.written.(*generic.Counter).Value())
+}
+
+func TestDecisionLoggerSubscribe(t *testing.T) {
+	logger := newTestDecisionLogger(&testSink{}, 10, 1)
+	nc := registry.GetNotificationCenter("decision-log-sdk-key")
+
+	unsubscribe, err := logger.subscribe("decision-log-sdk-key", func() string { return "1" })
+	assert.NoError(t, err)
+	unsubscribeOther, err := logger.subscribe("decision-log-sdk-key", func() string { return "2" })
+	assert.NoError(t, err)
+
+	defer logger.bind("decision-log-sdk-key", "user", "request")()
+	assert.NoError(t, nc.Send(notification.Decision, flagNotification("user")))
+	assert.Len(t, logger.records, 1)
+	record := <-logger.records
+	assert.Equal(t, "2", record.Revision)
+	assert.Equal(t, "request", record.RequestID)
+	assert.Equal(t, "decision-log-sdk-key", record.SDKKey)
+
+	// The handler is kept until every client has unsubscribed
+	unsubscribe()
+	unsubscribe()
+	assert.NoError(t, nc.Send(notification.Decision, flagNotification("user")))
+	assert.Len(t, logger.records, 1)
+	<-logger.records
+
+	unsubscribeOther()
+	assert.NoError(t, nc.Send(notification.Decision, flagNotification("user")))
+	assert.Len(t, logger.records, 0)
+	assert.Empty(t, logger.subscriptions)
+}
+
+func TestDecisionLoggerWrap(t *testing.T) {
+	logger := newTestDecisionLogger(&testSink{}, 10, 1)
+	nc := notification.NewNotificationCenter()
+	received := 0
+	_, err := nc.AddHandler(notification.Decision, func(interface{}) { received++ })
+	assert.NoError(t, err)
+	_, err = nc.AddHandler(notification.Track, func(interface{}) { received++ })
+	assert.NoError(t, err)
+
+	wrapped := logger.wrap("sdkKey", nc, func() string { return "42" })
+	assert.NoError(t, wrapped.Send(notification.Decision, flagNotification("user")))
+	assert.NoError(t, wrapped.Send(notification.Track, notification.TrackNotification{EventKey: "event"}))
+
+	assert.Equal(t, 2, received)
+	assert.Len(t, logger.records, 1)
+	assert.Equal(t, "42", (<-logger.records).Revision)
+}
+
+func TestBindRequestWithoutDecisionLog(t *testing.T) {
+	optlyClient := &OptlyClient{}
+	assert.NotPanics(t, func() {
+		optlyClient.BindRequest("user", "request")()
+	})
+}
@@ -0,0 +1,223 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package optimizely //
+package optimizely
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	cmap "github.com/orcaman/concurrent-map"
+	"github.com/rs/zerolog/log"
+
+	"github.com/optimizely/agent/config"
+	"github.com/optimizely/agent/plugins/eventforwarder"
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	go_sdk_metrics "github.com/optimizely/go-sdk/v2/pkg/metrics"
+)
+
+// Event forwarding metrics
+const (
+	EventForwardingForwardedMetric = "event-forwarding.forwarded"
+	EventForwardingFailedMetric    = "event-forwarding.failed"
+	EventForwardingDroppedMetric   = "event-forwarding.dropped"
+)
+
+// EventForwarder sends a copy of every dispatched event batch to the configured destinations. Every destination
+// has its own bounded queue and goroutine, so that a slow or failing destination neither delays the event
+// dispatcher nor the other destinations. Batches are dropped when the queue of a destination is full.
+type EventForwarder struct {
+	destinations  []*forwardingDestination
+	maxRetries    int
+	retryInterval time.Duration
+
+	forwarded go_sdk_metrics.Counter
+	failed    go_sdk_metrics.Counter
+	dropped   go_sdk_metrics.Counter
+}
+
+type forwardingDestination struct {
+	name      string
+	forwarder eventforwarder.Forwarder
+	batches   chan forwardedBatch
+}
+
+type forwardedBatch struct {
+	sdkKey   string
+	logEvent event.LogEvent
+}
+
+// NewEventForwarder creates the destinations configured in conf, it returns nil when no destination is configured
+func NewEventForwarder(conf config.EventForwardingConfig, metricsRegistry *MetricsRegistry) *EventForwarder {
+	names := make([]string, 0, len(conf.Destinations))
+	for name := range conf.Destinations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	queueSize := conf.QueueSize
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	destinations := make([]*forwardingDestination, 0, len(names))
+	for _, name := range names {
+		destinationConf, ok := conf.Destinations[name].(map[string]interface{})
+		if !ok {
+			log.Warn().Str("destination", name).Msg("Ignoring event forwarding destination without settings")
+			continue
+		}
+		forwarderType, _ := destinationConf["type"].(string)
+		rawForwarder := getServiceWithType(eventForwarderPlugin, "", cmap.New(), map[string]interface{}{
+			"default":  forwarderType,
+			"services": map[string]interface{}{forwarderType: destinationConf},
+		})
+		forwarder, ok := rawForwarder.(eventforwarder.Forwarder)
+		if !ok || forwarder == nil {
+			log.Warn().Str("destination", name).Str("type", forwarderType).Msg("Ignoring event forwarding destination of unknown type")
+			continue
+		}
+		destinations = append(destinations, &forwardingDestination{
+			name:      name,
+			forwarder: forwarder,
+			batches:   make(chan forwardedBatch, queueSize),
+		})
+	}
+	if len(destinations) == 0 {
+		return nil
+	}
+
+	maxRetries := conf.MaxRetries
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	return &EventForwarder{
+		destinations:  destinations,
+		maxRetries:    maxRetries,
+		retryInterval: conf.RetryInterval,
+		forwarded:     metricsRegistry.GetCounter(EventForwardingForwardedMetric),
+		failed:        metricsRegistry.GetCounter(EventForwardingFailedMetric),
+		dropped:       metricsRegistry.GetCounter(EventForwardingDroppedMetric),
+	}
+}
+
+// Run forwards the queued batches to every destination until ctx is done, then forwards the batches left in
+// the queues without retrying them and closes the destinations
+func (f *EventForwarder) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, destination := range f.destinations {
+		wg.Add(1)
+		go func(destination *forwardingDestination) {
+			defer wg.Done()
+			f.run(ctx, destination)
+		}(destination)
+	}
+	wg.Wait()
+}
+
+func (f *EventForwarder) run(ctx context.Context, destination *forwardingDestination) {
+	for {
+		select {
+		case batch := <-destination.batches:
+			f.deliver(ctx, destination, batch)
+		case <-ctx.Done():
+			// run is the only reader of the queue, so the queued batches can be received without blocking
+			for len(destination.batches) > 0 {
+				f.deliver(ctx, destination, <-destination.batches)
+			}
+			if err := destination.forwarder.Close(); err != nil {
+				log.Error().Err(err).Str("destination", destination.name).Msg("Failed to close event forwarding destination")
+			}
+			return
+		}
+	}
+}
+
+// deliver forwards the batch up to maxRetries+1 times, waiting retryInterval before the first retry and doubling
+// it after every failed retry. Retries stop once ctx is done.
+func (f *EventForwarder) deliver(ctx context.Context, destination *forwardingDestination, batch forwardedBatch) {
+	interval := f.retryInterval
+	for attempt := 0; ; attempt++ {
+		err := destination.forwarder.Forward(batch.sdkKey, batch.logEvent)
+		if err == nil {
+			f.forwarded.Add(1)
+			return
+		}
+		if attempt >= f.maxRetries || !sleepContext(ctx, interval) {
+			log.Error().Err(err).Str("destination", destination.name).Int("attempts", attempt+1).Msg("Failed to forward event batch")
+			f.failed.Add(1)
+			return
+		}
+		interval *= 2
+	}
+}
+
+// sleepContext waits for d and reports false when ctx is done first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// enqueue queues the batch for every destination, it never blocks
+func (f *EventForwarder) enqueue(sdkKey string, logEvent event.LogEvent) {
+	for _, destination := range f.destinations {
+		select {
+		case destination.batches <- forwardedBatch{sdkKey: sdkKey, logEvent: logEvent}:
+		default:
+			log.Warn().Str("destination", destination.name).Msg("Event forwarding queue is full, dropping event batch")
+			f.dropped.Add(1)
+		}
+	}
+}
+
+// wrap returns a dispatcher that forwards the batches dispatcher sends, dispatcher is returned as is when
+// forwarding is not configured
+func (f *EventForwarder) wrap(sdkKey string, dispatcher event.Dispatcher) event.Dispatcher {
+	if f == nil {
+		return dispatcher
+	}
+	return &forwardingDispatcher{sdkKey: sdkKey, dispatcher: dispatcher, forwarder: f}
+}
+
+// forwardingDispatcher queues the batches for the forwarding destinations once dispatcher has sent them, so that
+// a batch that is retried by the event processor is only forwarded once
+type forwardingDispatcher struct {
+	sdkKey     string
+	dispatcher event.Dispatcher
+	forwarder  *EventForwarder
+}
+
+// DispatchEvent dispatches the batch and forwards it when it was sent, the result of dispatcher is returned as is
+func (d *forwardingDispatcher) DispatchEvent(logEvent event.LogEvent) (bool, error) {
+	success, err := d.dispatcher.DispatchEvent(logEvent)
+	if success && err == nil {
+		d.forwarder.enqueue(d.sdkKey, logEvent)
+	}
+	return success, err
+}
@@ -0,0 +1,233 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package optimizely //
+package optimizely
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/agent/config"
+	"github.com/optimizely/agent/pkg/metrics"
+	"github.com/optimizely/agent/plugins/eventforwarder"
+)
+
+// testForwarder returns the given errors in order and then succeeds
+type testForwarder struct {
+	Name string `json:"name"`
+
+	lock    sync.Mutex
+	errs    []error
+	batches []forwardedBatch
+	closed  bool
+}
+
+func (t *testForwarder) Forward(sdkKey string, logEvent event.LogEvent) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if len(t.errs) > 0 {
+		err := t.errs[0]
+		t.errs = t.errs[1:]
+		return err
+	}
+	t.batches = append(t.batches, forwardedBatch{sdkKey: sdkKey, logEvent: logEvent})
+	return nil
+}
+
+func (t *testForwarder) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.closed = true
+	return nil
+}
+
+func (t *testForwarder) forwarded() []forwardedBatch {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]forwardedBatch{}, t.batches...)
+}
+
+func init() {
+	eventforwarder.Add("test", func() eventforwarder.Forwarder {
+		return &testForwarder{}
+	})
+}
+
+type EventForwarderTestSuite struct {
+	suite.Suite
+	first     *testForwarder
+	second    *testForwarder
+	forwarder *EventForwarder
+	logEvent  event.LogEvent
+}
+
+func (s *EventForwarderTestSuite) SetupTest() {
+	s.first = &testForwarder{}
+	s.second = &testForwarder{}
+	s.forwarder = &EventForwarder{
+		destinations: []*forwardingDestination{
+			{name: "first", forwarder: s.first, batches: make(chan forwardedBatch, 2)},
+			{name: "second", forwarder: s.second, batches: make(chan forwardedBatch, 2)},
+		},
+		maxRetries:    2,
+		retryInterval: time.Millisecond,
+		forwarded:     &testCounter{},
+		failed:        &testCounter{},
+		dropped:       &testCounter{},
+	}
+	s.logEvent = event.LogEvent{
+		EndPoint: "https://logx.optimizely.com/v1/events",
+		Event:    event.Batch{AccountID: "1", Revision: "2", Visitors: []event.Visitor{{VisitorID: "user"}}},
+	}
+}
+
+// run forwards the queued batches and stops the forwarder once they were handled
+func (s *EventForwarderTestSuite) run() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.forwarder.Run(ctx)
+}
+
+func (s *EventForwarderTestSuite) TestWrapForwardsDispatchedBatches() {
+	dispatcher := s.forwarder.wrap("sdkKey", &scriptedDispatcher{})
+	success, err := dispatcher.DispatchEvent(s.logEvent)
+	s.True(success)
+	s.NoError(err)
+	s.Len(s.forwarder.destinations[0].batches, 1)
+	s.Len(s.forwarder.destinations[1].batches, 1)
+
+	s.run()
+	s.Equal([]forwardedBatch{{sdkKey: "sdkKey", logEvent: s.logEvent}}, s.first.forwarded())
+	s.Equal([]forwardedBatch{{sdkKey: "sdkKey", logEvent: s.logEvent}}, s.second.forwarded())
+	s.Equal(2.0, s.forwarder.forwarded.(*testCounter).value)
+	s.True(s.first.closed)
+	s.True(s.second.closed)
+}
+
+func (s *EventForwarderTestSuite) TestWrapSkipsFailedBatches() {
+	dispatcher := s.forwarder.wrap("sdkKey", &scriptedDispatcher{results: []error{errDispatchFailed, errors.New("unavailable")}})
+
+	success, err := dispatcher.DispatchEvent(s.logEvent)
+	s.False(success)
+	s.NoError(err)
+
+	success, err = dispatcher.DispatchEvent(s.logEvent)
+	s.False(success)
+	s.EqualError(err, "unavailable")
+
+	s.Empty(s.forwarder.destinations[0].batches)
+	s.Empty(s.forwarder.destinations[1].batches)
+}
+
+func (s *EventForwarderTestSuite) TestEnqueueDropsWhenQueueIsFull() {
+	s.forwarder.destinations[1].batches = make(chan forwardedBatch)
+	s.forwarder.enqueue("sdkKey", s.logEvent)
+
+	s.Len(s.forwarder.destinations[0].batches, 1)
+	s.Equal(1.0, s.forwarder.dropped.(*testCounter).value)
+}
+
+func (s *EventForwarderTestSuite) TestDeliverRetries() {
+	s.first.errs = []error{errors.New("unavailable"), errors.New("unavailable")}
+	s.forwarder.deliver(context.Background(), s.forwarder.destinations[0], forwardedBatch{sdkKey: "sdkKey", logEvent: s.logEvent})
+
+	s.Len(s.first.forwarded(), 1)
+	s.Equal(1.0, s.forwarder.forwarded.(*testCounter).value)
+	s.Equal(0.0, s.forwarder.failed.(*testCounter).value)
+}
+
+func (s *EventForwarderTestSuite) TestDeliverFailsAfterMaxRetries() {
+	s.first.errs = []error{errors.New("unavailable"), errors.New("unavailable"), errors.New("unavailable"), nil}
+	s.forwarder.deliver(context.Background(), s.forwarder.destinations[0], forwardedBatch{sdkKey: "sdkKey", logEvent: s.logEvent})
+
+	s.Empty(s.first.forwarded())
+	s.Equal(1.0, s.forwarder.failed.(*testCounter).value)
+}
+
+func (s *EventForwarderTestSuite) TestFailingDestinationDoesNotAffectOthers() {
+	s.first.errs = []error{errors.New("unavailable"), errors.New("unavailable"), errors.New("unavailable")}
+	s.forwarder.wrap("sdkKey", &scriptedDispatcher{}).DispatchEvent(s.logEvent)
+
+	// Retries are skipped once the forwarder stops
+	s.run()
+	s.Empty(s.first.forwarded())
+	s.Len(s.second.forwarded(), 1)
+	s.Equal(1.0, s.forwarder.failed.(*testCounter).value)
+	s.Equal(1.0, s.forwarder.forwarded.(*testCounter).value)
+}
+
+func (s *EventForwarderTestSuite) TestRunForwardsUntilStopped() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.forwarder.Run(ctx)
+		close(done)
+	}()
+
+	s.forwarder.enqueue("sdkKey", s.logEvent)
+	s.Eventually(func() bool { return len(s.first.forwarded()) == 1 && len(s.second.forwarded()) == 1 }, time.Second, time.Millisecond)
+	cancel()
+	<-done
+	s.True(s.first.closed)
+}
+
+func TestEventForwarderTestSuite(t *testing.T) {
+	suite.Run(t, new(EventForwarderTestSuite))
+}
+
+// eventForwarderRegistry is shared by the tests since expvar metrics can only be published once
+var eventForwarderRegistry = sync.OnceValue(func() *MetricsRegistry {
+	return NewRegistry(metrics.NewRegistry(""))
+})
+
+func TestNewEventForwarder(t *testing.T) {
+	conf := config.NewDefaultConfig().Client.EventForwarding
+	if NewEventForwarder(conf, nil) != nil {
+		t.Error("expected no event forwarder without destinations")
+	}
+
+	conf.Destinations = config.EventForwarderConfigs{
+		"archive": map[string]interface{}{"type": "test", "name": "archive"},
+		"unknown": map[string]interface{}{"type": "missing"},
+		"invalid": "test",
+	}
+	forwarder := NewEventForwarder(conf, eventForwarderRegistry())
+	if forwarder == nil || len(forwarder.destinations) != 1 {
+		t.Fatal("expected a single event forwarding destination")
+	}
+	destination := forwarder.destinations[0]
+	if destination.name != "archive" || destination.forwarder.(*testForwarder).Name != "archive" {
+		t.Errorf("unexpected destination %q", destination.name)
+	}
+	if cap(destination.batches) != conf.QueueSize {
+		t.Errorf("expected a queue of %d batches, got %d", conf.QueueSize, cap(destination.batches))
+	}
+}
+
+func TestWrapWithoutEventForwarder(t *testing.T) {
+	var forwarder *EventForwarder
+	dispatcher := &scriptedDispatcher{}
+	if forwarder.wrap("sdkKey", dispatcher) != dispatcher {
+		t.Error("expected the dispatcher to be returned as is")
+	}
+}
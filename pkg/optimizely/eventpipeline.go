@@ -0,0 +1,281 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package optimizely //
+package optimizely
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+
+	"github.com/optimizely/agent/config"
+)
+
+// Event pipeline step types
+const (
+	eventStepAllow  = "allow"
+	eventStepDeny   = "deny"
+	eventStepRedact = "redact"
+	eventStepHash   = "hash"
+	eventStepInject = "inject"
+)
+
+// reservedAttributePrefix is the prefix of the attributes added by the SDK, like bot filtering, which the
+// event pipeline steps leave as is
+const reservedAttributePrefix = "$opt_"
+
+// customAttributeType is the type of the attributes set by inject steps
+const customAttributeType = "custom"
+
+// EventPipelines applies the configured processing steps to the user attributes and event tags of the impression
+// and conversion events, before they are queued. Scrubbed values are therefore neither written to a persistent
+// event queue nor dispatched, forwarded or dead-lettered.
+type EventPipelines struct {
+	pipelines []eventPipeline
+}
+
+type eventPipeline struct {
+	// sdkKeys is empty when the pipeline applies to every SDK key
+	sdkKeys map[string]bool
+	steps   []*eventPipelineStep
+}
+
+type eventPipelineStep struct {
+	kind        string
+	attributes  bool
+	tags        bool
+	keys        map[string]bool
+	pattern     *regexp.Regexp
+	replacement string
+	hashKey     []byte
+	values      []config.EventPipelineValue
+}
+
+// NewEventPipelines validates the configured pipelines, it returns nil when no pipeline is configured
+func NewEventPipelines(conf []config.EventPipelineConfig) (*EventPipelines, error) {
+	pipelines := make([]eventPipeline, 0, len(conf))
+	for i, pipelineConf := range conf {
+		pipeline := eventPipeline{sdkKeys: make(map[string]bool, len(pipelineConf.SDKKeys))}
+		for _, sdkKey := range pipelineConf.SDKKeys {
+			pipeline.sdkKeys[sdkKey] = true
+		}
+		for j, stepConf := range pipelineConf.Steps {
+			step, err := newEventPipelineStep(stepConf)
+			if err != nil {
+				return nil, fmt.Errorf("eventPipelines[%d].steps[%d]: %w", i, j, err)
+			}
+			pipeline.steps = append(pipeline.steps, step)
+		}
+		if len(pipeline.steps) > 0 {
+			pipelines = append(pipelines, pipeline)
+		}
+	}
+	if len(pipelines) == 0 {
+		return nil, nil
+	}
+	return &EventPipelines{pipelines: pipelines}, nil
+}
+
+func newEventPipelineStep(conf config.EventPipelineStep) (*eventPipelineStep, error) {
+	step := &eventPipelineStep{
+		kind:        conf.Type,
+		keys:        make(map[string]bool, len(conf.Keys)),
+		replacement: conf.Replacement,
+		hashKey:     []byte(conf.HashKey),
+	}
+	for _, key := range conf.Keys {
+		step.keys[key] = true
+	}
+
+	switch conf.Target {
+	case "", "all":
+		step.attributes, step.tags = true, true
+	case "attributes":
+		step.attributes = true
+	case "tags":
+		step.tags = true
+	default:
+		return nil, fmt.Errorf("unknown target %q", conf.Target)
+	}
+
+	switch conf.Type {
+	case eventStepAllow, eventStepDeny:
+		if len(conf.Keys) == 0 {
+			return nil, fmt.Errorf("%s step requires keys", conf.Type)
+		}
+	case eventStepRedact:
+		if conf.Pattern == "" {
+			return nil, fmt.Errorf("redact step requires a pattern")
+		}
+		pattern, err := regexp.Compile(conf.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		step.pattern = pattern
+	case eventStepHash:
+	case eventStepInject:
+		if len(conf.Values) == 0 {
+			return nil, fmt.Errorf("inject step requires values")
+		}
+		for _, value := range conf.Values {
+			if value.Key == "" {
+				return nil, fmt.Errorf("inject step requires a key for every value")
+			}
+			if s, ok := value.Value.(string); ok {
+				value.Value = os.ExpandEnv(s)
+			}
+			step.values = append(step.values, value)
+		}
+	default:
+		return nil, fmt.Errorf("unknown type %q", conf.Type)
+	}
+	return step, nil
+}
+
+// steps returns the steps applied to the events of sdkKey, in order
+func (p *EventPipelines) steps(sdkKey string) []*eventPipelineStep {
+	if p == nil {
+		return nil
+	}
+	var steps []*eventPipelineStep
+	for _, pipeline := range p.pipelines {
+		if len(pipeline.sdkKeys) == 0 || pipeline.sdkKeys[sdkKey] {
+			steps = append(steps, pipeline.steps...)
+		}
+	}
+	return steps
+}
+
+// wrap returns a queue that applies the steps of sdkKey to the events added to q, q is returned as is when
+// no step applies to sdkKey
+func (p *EventPipelines) wrap(sdkKey string, q event.Queue) event.Queue {
+	steps := p.steps(sdkKey)
+	if len(steps) == 0 {
+		return q
+	}
+	return &pipelineQueue{Queue: q, steps: steps}
+}
+
+// pipelineQueue applies the event pipeline steps to the events before adding them to the queue
+type pipelineQueue struct {
+	event.Queue
+	steps []*eventPipelineStep
+}
+
+// Add adds the processed event to the queue
+func (q *pipelineQueue) Add(item interface{}) {
+	if userEvent, ok := item.(event.UserEvent); ok {
+		item = processUserEvent(q.steps, userEvent)
+	}
+	q.Queue.Add(item)
+}
+
+// processUserEvent returns a copy of userEvent with the steps applied to its attributes and tags
+func processUserEvent(steps []*eventPipelineStep, userEvent event.UserEvent) event.UserEvent {
+	if userEvent.Impression != nil {
+		impression := *userEvent.Impression
+		for _, step := range steps {
+			if step.attributes {
+				impression.Attributes = step.processAttributes(impression.Attributes)
+			}
+		}
+		userEvent.Impression = &impression
+	}
+	if userEvent.Conversion != nil {
+		conversion := *userEvent.Conversion
+		for _, step := range steps {
+			if step.attributes {
+				conversion.Attributes = step.processAttributes(conversion.Attributes)
+			}
+			if step.tags {
+				conversion.Tags = step.processTags(conversion.Tags)
+			}
+		}
+		userEvent.Conversion = &conversion
+	}
+	return userEvent
+}
+
+func (s *eventPipelineStep) processAttributes(attributes []event.VisitorAttribute) []event.VisitorAttribute {
+	processed := make([]event.VisitorAttribute, 0, len(attributes)+len(s.values))
+	for _, attribute := range attributes {
+		if strings.HasPrefix(attribute.Key, reservedAttributePrefix) {
+			processed = append(processed, attribute)
+			continue
+		}
+		if value, ok := s.process(attribute.Key, attribute.Value); ok {
+			attribute.Value = value
+			processed = append(processed, attribute)
+		}
+	}
+
+	for _, value := range s.values {
+		injected := event.VisitorAttribute{Key: value.Key, EntityID: value.Key, AttributeType: customAttributeType, Value: value.Value}
+		replaced := false
+		for i := range processed {
+			if processed[i].Key == value.Key {
+				injected.EntityID, injected.AttributeType = processed[i].EntityID, processed[i].AttributeType
+				processed[i] = injected
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			processed = append(processed, injected)
+		}
+	}
+	return processed
+}
+
+func (s *eventPipelineStep) processTags(tags map[string]interface{}) map[string]interface{} {
+	if tags == nil && len(s.values) == 0 {
+		return nil
+	}
+	processed := make(map[string]interface{}, len(tags)+len(s.values))
+	for key, value := range tags {
+		if value, ok := s.process(key, value); ok {
+			processed[key] = value
+		}
+	}
+	for _, value := range s.values {
+		processed[value.Key] = value.Value
+	}
+	return processed
+}
+
+// process returns the value of key after the step, or false when the step removes it
+func (s *eventPipelineStep) process(key string, value interface{}) (interface{}, bool) {
+	selected := len(s.keys) == 0 || s.keys[key]
+	switch s.kind {
+	case eventStepAllow:
+		return value, selected
+	case eventStepDeny:
+		return value, !selected
+	case eventStepRedact:
+		if str, ok := value.(string); ok && selected {
+			return s.pattern.ReplaceAllString(str, s.replacement), true
+		}
+	case eventStepHash:
+		if selected {
+			return hashValue(s.hashKey, value), true
+		}
+	}
+	return value, true
+}
@@ -0,0 +1,194 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package optimizely //
+package optimizely
+
+import (
+	"testing"
+
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optimizely/agent/config"
+)
+
+func newTestEventPipelines(t *testing.T, steps ...config.EventPipelineStep) *EventPipelines {
+	pipelines, err := NewEventPipelines([]config.EventPipelineConfig{{Steps: steps}})
+	require.NoError(t, err)
+	require.NotNil(t, pipelines)
+	return pipelines
+}
+
+func pipelineImpression(attributes ...event.VisitorAttribute) event.UserEvent {
+	attributes = append(attributes, event.VisitorAttribute{Key: "$opt_bot_filtering", EntityID: "$opt_bot_filtering", AttributeType: "custom", Value: true})
+	return event.UserEvent{VisitorID: "user", Impression: &event.ImpressionEvent{Key: "campaign", Attributes: attributes}}
+}
+
+func pipelineAttribute(key string, value interface{}) event.VisitorAttribute {
+	return event.VisitorAttribute{Key: key, EntityID: key + "-id", AttributeType: "custom", Value: value}
+}
+
+func TestNewEventPipelinesWithoutSteps(t *testing.T) {
+	pipelines, err := NewEventPipelines(config.NewDefaultConfig().Client.EventPipelines)
+	assert.NoError(t, err)
+	assert.Nil(t, pipelines)
+
+	pipelines, err = NewEventPipelines([]config.EventPipelineConfig{{SDKKeys: []string{"sdkKey"}}})
+	assert.NoError(t, err)
+	assert.Nil(t, pipelines)
+}
+
+func TestNewEventPipelinesValidatesSteps(t *testing.T) {
+	scenarios := map[string]config.EventPipelineStep{
+		"eventPipelines[0].steps[0]: unknown type \"drop\"":                      {Type: "drop"},
+		"eventPipelines[0].steps[0]: unknown target \"visitors\"":                {Type: "hash", Target: "visitors"},
+		"eventPipelines[0].steps[0]: allow step requires keys":                   {Type: "allow"},
+		"eventPipelines[0].steps[0]: deny step requires keys":                    {Type: "deny"},
+		"eventPipelines[0].steps[0]: redact step requires a pattern":             {Type: "redact"},
+		"eventPipelines[0].steps[0]: inject step requires values":                {Type: "inject"},
+		"eventPipelines[0].steps[0]: inject step requires a key for every value": {Type: "inject", Values: []config.EventPipelineValue{{Value: "x"}}},
+	}
+	for expected, step := range scenarios {
+		_, err := NewEventPipelines([]config.EventPipelineConfig{{Steps: []config.EventPipelineStep{step}}})
+		assert.EqualError(t, err, expected)
+	}
+
+	_, err := NewEventPipelines([]config.EventPipelineConfig{{Steps: []config.EventPipelineStep{{Type: "redact", Pattern: "("}}}})
+	assert.Error(t, err)
+}
+
+func TestEventPipelinesSteps(t *testing.T) {
+	pipelines, err := NewEventPipelines([]config.EventPipelineConfig{
+		{Steps: []config.EventPipelineStep{{Type: "deny", Keys: []string{"email"}}}},
+		{SDKKeys: []string{"sdkKey"}, Steps: []config.EventPipelineStep{{Type: "hash"}}},
+	})
+	require.NoError(t, err)
+
+	steps := pipelines.steps("sdkKey")
+	require.Len(t, steps, 2)
+	assert.Equal(t, eventStepDeny, steps[0].kind)
+	assert.Equal(t, eventStepHash, steps[1].kind)
+	assert.Len(t, pipelines.steps("other"), 1)
+
+	var disabled *EventPipelines
+	assert.Nil(t, disabled.steps("sdkKey"))
+}
+
+func TestEventPipelineAllowAndDeny(t *testing.T) {
+	pipelines := newTestEventPipelines(t,
+		config.EventPipelineStep{Type: "allow", Keys: []string{"plan", "country", "email"}},
+		config.EventPipelineStep{Type: "deny", Keys: []string{"email"}},
+	)
+	processed := processUserEvent(pipelines.steps("sdkKey"), pipelineImpression(
+		pipelineAttribute("plan", "gold"),
+		pipelineAttribute("email", "user@example.com"),
+		pipelineAttribute("age", 42),
+	))
+
+	// Attributes added by the SDK are kept
+	assert.Equal(t, []event.VisitorAttribute{
+		pipelineAttribute("plan", "gold"),
+		{Key: "$opt_bot_filtering", EntityID: "$opt_bot_filtering", AttributeType: "custom", Value: true},
+	}, processed.Impression.Attributes)
+}
+
+func TestEventPipelineRedact(t *testing.T) {
+	pipelines := newTestEventPipelines(t, config.EventPipelineStep{Type: "redact", Pattern: `[\w.]+@[\w.]+`, Replacement: "[email]"})
+	processed := processUserEvent(pipelines.steps("sdkKey"), pipelineImpression(
+		pipelineAttribute("contact", "mail user@example.com"),
+		pipelineAttribute("age", 42),
+	))
+
+	assert.Equal(t, "mail [email]", processed.Impression.Attributes[0].Value)
+	assert.Equal(t, 42, processed.Impression.Attributes[1].Value)
+}
+
+func TestEventPipelineHash(t *testing.T) {
+	pipelines := newTestEventPipelines(t, config.EventPipelineStep{Type: "hash", Keys: []string{"userName"}, HashKey: "secret"})
+	processed := processUserEvent(pipelines.steps("sdkKey"), pipelineImpression(
+		pipelineAttribute("userName", "jane"),
+		pipelineAttribute("plan", "gold"),
+	))
+
+	assert.Equal(t, hashValue([]byte("secret"), "jane"), processed.Impression.Attributes[0].Value)
+	assert.Equal(t, "gold", processed.Impression.Attributes[1].Value)
+	assert.Equal(t, true, processed.Impression.Attributes[2].Value)
+}
+
+func TestEventPipelineInject(t *testing.T) {
+	t.Setenv("POD_NAME", "agent-0")
+	pipelines := newTestEventPipelines(t, config.EventPipelineStep{Type: "inject", Target: "attributes", Values: []config.EventPipelineValue{
+		{Key: "region", Value: "us-east-1"},
+		{Key: "pod", Value: "${POD_NAME}"},
+	}})
+	processed := processUserEvent(pipelines.steps("sdkKey"), pipelineImpression(pipelineAttribute("region", "unknown")))
+
+	assert.Equal(t, []event.VisitorAttribute{
+		pipelineAttribute("region", "us-east-1"),
+		{Key: "$opt_bot_filtering", EntityID: "$opt_bot_filtering", AttributeType: "custom", Value: true},
+		{Key: "pod", EntityID: "pod", AttributeType: "custom", Value: "agent-0"},
+	}, processed.Impression.Attributes)
+}
+
+func TestEventPipelineConversionTags(t *testing.T) {
+	pipelines := newTestEventPipelines(t,
+		config.EventPipelineStep{Type: "deny", Target: "tags", Keys: []string{"card"}},
+		config.EventPipelineStep{Type: "deny", Target: "attributes", Keys: []string{"plan"}},
+		config.EventPipelineStep{Type: "inject", Target: "tags", Values: []config.EventPipelineValue{{Key: "source", Value: "agent"}}},
+	)
+	conversion := &event.ConversionEvent{
+		Key:        "purchase",
+		Attributes: []event.VisitorAttribute{pipelineAttribute("plan", "gold"), pipelineAttribute("card", "4111")},
+		Tags:       map[string]interface{}{"revenue": 100, "card": "4111", "plan": "gold"},
+	}
+	userEvent := event.UserEvent{VisitorID: "user", Conversion: conversion}
+
+	processed := processUserEvent(pipelines.steps("sdkKey"), userEvent)
+	assert.Equal(t, []event.VisitorAttribute{pipelineAttribute("card", "4111")}, processed.Conversion.Attributes)
+	assert.Equal(t, map[string]interface{}{"revenue": 100, "plan": "gold", "source": "agent"}, processed.Conversion.Tags)
+
+	// The original event is left as is
+	assert.Len(t, conversion.Attributes, 2)
+	assert.Equal(t, "4111", conversion.Tags["card"])
+
+	// Tags are added to conversions without tags
+	processed = processUserEvent(pipelines.steps("sdkKey"), event.UserEvent{Conversion: &event.ConversionEvent{Key: "signup"}})
+	assert.Equal(t, map[string]interface{}{"source": "agent"}, processed.Conversion.Tags)
+}
+
+func TestEventPipelinesWrap(t *testing.T) {
+	pipelines, err := NewEventPipelines([]config.EventPipelineConfig{{
+		SDKKeys: []string{"sdkKey"},
+		Steps:   []config.EventPipelineStep{{Type: "deny", Keys: []string{"email"}}},
+	}})
+	require.NoError(t, err)
+
+	q := event.NewInMemoryQueue(10)
+	assert.Equal(t, q, pipelines.wrap("other", q))
+
+	wrapped := pipelines.wrap("sdkKey", q)
+	wrapped.Add(pipelineImpression(pipelineAttribute("email", "user@example.com")))
+	wrapped.Add("not an event")
+	require.Equal(t, 2, wrapped.Size())
+
+	items := q.Get(2)
+	userEvent, ok := items[0].(event.UserEvent)
+	require.True(t, ok)
+	assert.Len(t, userEvent.Impression.Attributes, 1)
+	assert.Equal(t, "not an event", items[1])
+}
@@ -0,0 +1,102 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package optimizely //
+package optimizely
+
+import (
+	"sync"
+
+	go_sdk_metrics "github.com/optimizely/go-sdk/v2/pkg/metrics"
+
+	"github.com/optimizely/agent/plugins/eventqueue"
+)
+
+// eventQueueMetrics reports the total depth and disk usage of the persistent event queues of the cached clients
+type eventQueueMetrics struct {
+	lock           sync.Mutex
+	depth          int64
+	diskUsage      int64
+	depthGauge     go_sdk_metrics.Gauge
+	diskUsageGauge go_sdk_metrics.Gauge
+}
+
+func newEventQueueMetrics(metricsRegistry *MetricsRegistry) *eventQueueMetrics {
+	return &eventQueueMetrics{
+		depthGauge:     metricsRegistry.GetGauge("event-queue.depth"),
+		diskUsageGauge: metricsRegistry.GetGauge("event-queue.disk-usage"),
+	}
+}
+
+func (m *eventQueueMetrics) add(depth, diskUsage int64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.depth += depth
+	m.diskUsage += diskUsage
+	m.depthGauge.Set(float64(m.depth))
+	m.diskUsageGauge.Set(float64(m.diskUsage))
+}
+
+// meteredQueue reports the changes of the depth and disk usage of an event queue to eventQueueMetrics
+type meteredQueue struct {
+	eventqueue.Queue
+	metrics *eventQueueMetrics
+
+	lock      sync.Mutex
+	depth     int64
+	diskUsage int64
+}
+
+func (m *eventQueueMetrics) wrap(q eventqueue.Queue) *meteredQueue {
+	mq := &meteredQueue{Queue: q, metrics: m}
+	mq.measure()
+	return mq
+}
+
+// Add adds the event to the queue
+func (q *meteredQueue) Add(item interface{}) {
+	q.Queue.Add(item)
+	q.measure()
+}
+
+// Remove removes the first count events of the queue and returns them
+func (q *meteredQueue) Remove(count int) []interface{} {
+	items := q.Queue.Remove(count)
+	q.measure()
+	return items
+}
+
+// Close closes the queue, its events are no longer reported
+func (q *meteredQueue) Close() error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.metrics.add(-q.depth, -q.diskUsage)
+	q.depth, q.diskUsage = 0, 0
+	return q.Queue.Close()
+}
+
+func (q *meteredQueue) measure() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	depth := int64(q.Queue.Size())
+	diskUsage := q.diskUsage
+	if du, ok := q.Queue.(eventqueue.DiskUsage); ok {
+		diskUsage = du.DiskUsage()
+	}
+	q.metrics.add(depth-q.depth, diskUsage-q.diskUsage)
+	q.depth, q.diskUsage = depth, diskUsage
+}
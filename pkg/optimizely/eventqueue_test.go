@@ -0,0 +1,62 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package optimizely //
+package optimizely
+
+import (
+	"testing"
+
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/stretchr/testify/assert"
+)
+
+// diskEventQueue uses 10 bytes of disk per event
+type diskEventQueue struct {
+	MockEventQueue
+}
+
+func (d *diskEventQueue) DiskUsage() int64 {
+	return int64(d.Size() * 10)
+}
+
+func TestMeteredQueue(t *testing.T) {
+	queueMetrics := &eventQueueMetrics{depthGauge: &testGauge{}, diskUsageGauge: &testGauge{}}
+	depth := func() float64 { return queueMetrics.depthGauge.(*testGauge).value }
+	diskUsage := func() float64 { return queueMetrics.diskUsageGauge.(*testGauge).value }
+
+	queue1 := &diskEventQueue{MockEventQueue{Queue: event.NewInMemoryQueue(10)}}
+	queue1.Add(event.UserEvent{})
+	q1 := queueMetrics.wrap(queue1)
+	assert.Equal(t, 1.0, depth())
+	assert.Equal(t, 10.0, diskUsage())
+
+	// Queues without disk usage only report their depth
+	q2 := queueMetrics.wrap(&MockEventQueue{Queue: event.NewInMemoryQueue(10)})
+	q2.Add(event.UserEvent{})
+	q2.Add(event.UserEvent{})
+	q1.Add(event.UserEvent{})
+	assert.Equal(t, 4.0, depth())
+	assert.Equal(t, 20.0, diskUsage())
+
+	assert.Len(t, q1.Remove(5), 2)
+	assert.Equal(t, 2.0, depth())
+	assert.Equal(t, 0.0, diskUsage())
+
+	assert.NoError(t, q2.Close())
+	assert.True(t, q2.Queue.(*MockEventQueue).closed)
+	assert.Equal(t, 0.0, depth())
+}
@@ -0,0 +1,127 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package optimizely //
+package optimizely
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// clientUsage tracks when each cached client was last used, ordered from most to least recently used
+type clientUsage struct {
+	lock  sync.Mutex
+	order *list.List
+	items map[string]*list.Element
+}
+
+type usageEntry struct {
+	key      string
+	lastUsed time.Time
+	// evicted is closed once the client is removed from the cache
+	evicted chan struct{}
+}
+
+func newClientUsage() *clientUsage {
+	return &clientUsage{
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// add calls set, which caches the client of the given key, and starts tracking the key when it returns true.
+// It returns a channel that is closed when the key is removed. Caching and tracking the key under the lock keeps
+// them consistent with remove. A key that is still tracked is moved to the front, and the channel of its previous
+// client is closed.
+func (u *clientUsage) add(key string, set func() bool) (<-chan struct{}, bool) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if !set() {
+		return nil, false
+	}
+
+	if elem, ok := u.items[key]; ok {
+		entry := elem.Value.(*usageEntry)
+		close(entry.evicted)
+		entry.lastUsed = time.Now()
+		entry.evicted = make(chan struct{})
+		u.order.MoveToFront(elem)
+		return entry.evicted, true
+	}
+	entry := &usageEntry{key: key, lastUsed: time.Now(), evicted: make(chan struct{})}
+	u.items[key] = u.order.PushFront(entry)
+	return entry.evicted, true
+}
+
+// touch marks the given key as the most recently used
+func (u *clientUsage) touch(key string) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if elem, ok := u.items[key]; ok {
+		elem.Value.(*usageEntry).lastUsed = time.Now()
+		u.order.MoveToFront(elem)
+	}
+}
+
+// remove calls drop, which removes the client of the given key from the cache, then stops tracking the key and
+// signals its eviction. It returns false if the key was not tracked.
+func (u *clientUsage) remove(key string, drop func()) bool {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	drop()
+	elem, ok := u.items[key]
+	if !ok {
+		return false
+	}
+	u.order.Remove(elem)
+	delete(u.items, key)
+	close(elem.Value.(*usageEntry).evicted)
+	return true
+}
+
+// len returns the number of tracked keys
+func (u *clientUsage) len() int {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	return u.order.Len()
+}
+
+// leastRecentlyUsed returns the key that has not been used for the longest time
+func (u *clientUsage) leastRecentlyUsed() (string, bool) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if elem := u.order.Back(); elem != nil {
+		return elem.Value.(*usageEntry).key, true
+	}
+	return "", false
+}
+
+// idleSince returns the keys that have not been used since the given time
+func (u *clientUsage) idleSince(t time.Time) []string {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	var keys []string
+	for elem := u.order.Back(); elem != nil; elem = elem.Prev() {
+		entry := elem.Value.(*usageEntry)
+		if entry.lastUsed.After(t) {
+			break
+		}
+		keys = append(keys, entry.key)
+	}
+	return keys
+}
@@ -0,0 +1,232 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package optimizely //
+package optimizely
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+
+	"github.com/optimizely/go-sdk/v2/pkg/config"
+	"github.com/optimizely/go-sdk/v2/pkg/config/datafileprojectconfig"
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+	"github.com/optimizely/go-sdk/v2/pkg/notification"
+	"github.com/optimizely/go-sdk/v2/pkg/registry"
+)
+
+// FileConfigManager implements the SyncedConfigManager interface by reading the datafile of an SDK key
+// from <dir>/<sdkKey>.json, with the SDK key path escaped so that it can not name a file outside of the directory.
+// The directory is watched so that changes are picked up without polling.
+type FileConfigManager struct {
+	sdkKey             string
+	path               string
+	notificationCenter notification.Center
+	cancel             context.CancelFunc
+
+	configLock       sync.RWMutex
+	projectConfig    config.ProjectConfig
+	optimizelyConfig *config.OptimizelyConfig
+	err              error
+}
+
+// NewFileConfigManager loads the datafile of the given sdkKey from dir and watches it for changes
+// until ctx is done or the manager is closed
+func NewFileConfigManager(ctx context.Context, sdkKey, dir string) *FileConfigManager {
+	ctx, cancel := context.WithCancel(ctx)
+	cm := &FileConfigManager{
+		sdkKey:             sdkKey,
+		path:               filepath.Join(dir, url.PathEscape(sdkKey)+".json"),
+		notificationCenter: registry.GetNotificationCenter(sdkKey),
+		cancel:             cancel,
+	}
+	cm.SyncConfig()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to watch datafile directory")
+		return cm
+	}
+	// Watch the directory rather than the file so that datafiles replaced by a rename are still picked up
+	if err := watcher.Add(dir); err != nil {
+		log.Error().Err(err).Msg("Unable to watch datafile directory")
+		_ = watcher.Close()
+		return cm
+	}
+
+	go cm.watch(ctx, watcher)
+	return cm
+}
+
+func (cm *FileConfigManager) watch(ctx context.Context, watcher *fsnotify.Watcher) {
+	defer watcher.Close()
+	for {
+		select {
+		case e, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(e.Name) == cm.path && e.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				cm.SyncConfig()
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Error().Err(err).Msg("Error watching datafile directory")
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Close stops watching the datafile
+func (cm *FileConfigManager) Close() {
+	cm.cancel()
+}
+
+// SyncConfig reloads the datafile from disk. Subscribers are notified when the revision changes.
+func (cm *FileConfigManager) SyncConfig() {
+	datafile, err := os.ReadFile(cm.path)
+	if err != nil {
+		cm.setErr(fmt.Errorf("unable to read datafile: %w", err))
+		return
+	}
+
+	projectConfig, err := datafileprojectconfig.NewDatafileProjectConfig(datafile, logging.GetLogger(cm.sdkKey, "NewDatafileProjectConfig"))
+	if err != nil {
+		cm.setErr(fmt.Errorf("unable to parse datafile: %w", err))
+		return
+	}
+
+	cm.configLock.Lock()
+	var previousRevision string
+	if cm.projectConfig != nil {
+		previousRevision = cm.projectConfig.GetRevision()
+	}
+	cm.err = nil
+	if projectConfig.GetRevision() == previousRevision {
+		cm.configLock.Unlock()
+		return
+	}
+	cm.projectConfig = projectConfig
+	cm.optimizelyConfig = nil
+	cm.configLock.Unlock()
+
+	if err := cm.notificationCenter.Send(notification.ProjectConfigUpdate, notification.ProjectConfigUpdateNotification{
+		Type:     notification.ProjectConfigUpdate,
+		Revision: projectConfig.GetRevision(),
+	}); err != nil {
+		log.Warn().Err(err).Msg("Problem with sending notification")
+	}
+}
+
+// setErr records a failed reload, the last valid config keeps being served
+func (cm *FileConfigManager) setErr(err error) {
+	log.Error().Err(err).Str("path", cm.path).Msg("Failed to load datafile")
+	cm.configLock.Lock()
+	defer cm.configLock.Unlock()
+	cm.err = err
+}
+
+// GetConfig returns the project config
+func (cm *FileConfigManager) GetConfig() (config.ProjectConfig, error) {
+	cm.configLock.RLock()
+	defer cm.configLock.RUnlock()
+	if cm.projectConfig == nil {
+		return nil, cm.err
+	}
+	return cm.projectConfig, nil
+}
+
+// GetOptimizelyConfig returns the optimizely project config
+func (cm *FileConfigManager) GetOptimizelyConfig() *config.OptimizelyConfig {
+	cm.configLock.Lock()
+	defer cm.configLock.Unlock()
+	if cm.optimizelyConfig == nil && cm.projectConfig != nil {
+		cm.optimizelyConfig = config.NewOptimizelyConfig(cm.projectConfig)
+	}
+	return cm.optimizelyConfig
+}
+
+// OnProjectConfigUpdate registers a handler for ProjectConfigUpdate notifications
+func (cm *FileConfigManager) OnProjectConfigUpdate(callback func(notification.ProjectConfigUpdateNotification)) (int, error) {
+	handler := func(payload interface{}) {
+		if projectConfigUpdateNotification, ok := payload.(notification.ProjectConfigUpdateNotification); ok {
+			callback(projectConfigUpdateNotification)
+		}
+	}
+	return cm.notificationCenter.AddHandler(notification.ProjectConfigUpdate, handler)
+}
+
+// RemoveOnProjectConfigUpdate removes handler for ProjectConfigUpdate notification with given id
+func (cm *FileConfigManager) RemoveOnProjectConfigUpdate(id int) error {
+	return cm.notificationCenter.RemoveHandler(id, notification.ProjectConfigUpdate)
+}
+
+// FileEventDispatcher implements the event.Dispatcher interface by appending every event batch
+// as a line of newline delimited JSON to a file instead of sending it to the event endpoint.
+type FileEventDispatcher struct {
+	lock    sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// NewFileEventDispatcher opens, or creates, the file that events are appended to
+func NewFileEventDispatcher(path string) (*FileEventDispatcher, error) {
+	if path == "" {
+		return nil, errors.New("events file is not configured")
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileEventDispatcher{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// DispatchEvent appends the event batch to the file
+func (d *FileEventDispatcher) DispatchEvent(logEvent event.LogEvent) (bool, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if err := d.encoder.Encode(logEvent.Event); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Close closes the underlying file
+func (d *FileEventDispatcher) Close() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.file.Close()
+}
+
+// discardEventDispatcher drops every event, it is used in offline mode when no events file is configured
+type discardEventDispatcher struct{}
+
+func (discardEventDispatcher) DispatchEvent(event.LogEvent) (bool, error) {
+	return true, nil
+}
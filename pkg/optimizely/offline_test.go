@@ -0,0 +1,163 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package optimizely //
+package optimizely
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/optimizely/go-sdk/v2/pkg/notification"
+)
+
+type FileConfigManagerTestSuite struct {
+	suite.Suite
+	dir    string
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (s *FileConfigManagerTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+}
+
+func (s *FileConfigManagerTestSuite) TearDownTest() {
+	s.cancel()
+}
+
+func (s *FileConfigManagerTestSuite) writeDatafile(sdkKey, revision string) {
+	datafile := []byte(`{"version":"4","revision":"` + revision + `","projectId":"1","accountId":"1"}`)
+	// Replace the file atomically, as a deployment tool would
+	tmp := filepath.Join(s.dir, ".tmp-"+sdkKey)
+	s.NoError(os.WriteFile(tmp, datafile, 0o600))
+	s.NoError(os.Rename(tmp, filepath.Join(s.dir, sdkKey+".json")))
+}
+
+func (s *FileConfigManagerTestSuite) TestGetConfig() {
+	s.writeDatafile("sdkKey", "1")
+
+	cm := NewFileConfigManager(s.ctx, "sdkKey", s.dir)
+	projectConfig, err := cm.GetConfig()
+	s.NoError(err)
+	s.Equal("1", projectConfig.GetRevision())
+	s.Equal("1", cm.GetOptimizelyConfig().Revision)
+}
+
+func (s *FileConfigManagerTestSuite) TestGetConfigMissingDatafile() {
+	cm := NewFileConfigManager(s.ctx, "missing", s.dir)
+	_, err := cm.GetConfig()
+	s.ErrorIs(err, os.ErrNotExist)
+	s.Nil(cm.GetOptimizelyConfig())
+}
+
+func (s *FileConfigManagerTestSuite) TestInvalidDatafileKeepsLastConfig() {
+	s.writeDatafile("sdkKey", "1")
+	cm := NewFileConfigManager(s.ctx, "sdkKey", s.dir)
+
+	s.NoError(os.WriteFile(filepath.Join(s.dir, "sdkKey.json"), []byte("{invalid"), 0o600))
+	cm.SyncConfig()
+
+	projectConfig, err := cm.GetConfig()
+	s.NoError(err)
+	s.Equal("1", projectConfig.GetRevision())
+}
+
+func (s *FileConfigManagerTestSuite) TestSDKKeyCanNotEscapeDir() {
+	parent := filepath.Dir(s.dir)
+	s.NoError(os.WriteFile(filepath.Join(parent, "outside.json"), []byte(`{"version":"4","revision":"1"}`), 0o600))
+	defer os.Remove(filepath.Join(parent, "outside.json"))
+
+	cm := NewFileConfigManager(s.ctx, "../outside", s.dir)
+	s.Equal(filepath.Join(s.dir, "..%2Foutside.json"), cm.path)
+	_, err := cm.GetConfig()
+	s.ErrorIs(err, os.ErrNotExist)
+}
+
+func (s *FileConfigManagerTestSuite) TestWatchNotifiesOnNewRevision() {
+	s.writeDatafile("watched", "1")
+	cm := NewFileConfigManager(s.ctx, "watched", s.dir)
+
+	revisions := make(chan string, 10)
+	id, err := cm.OnProjectConfigUpdate(func(n notification.ProjectConfigUpdateNotification) {
+		revisions <- n.Revision
+	})
+	s.NoError(err)
+	defer func() {
+		s.NoError(cm.RemoveOnProjectConfigUpdate(id))
+	}()
+
+	// Other files in the directory are ignored
+	s.writeDatafile("other", "3")
+	s.writeDatafile("watched", "2")
+
+	select {
+	case revision := <-revisions:
+		s.Equal("2", revision)
+	case <-time.After(5 * time.Second):
+		s.Fail("datafile change was not detected")
+	}
+
+	projectConfig, err := cm.GetConfig()
+	s.NoError(err)
+	s.Equal("2", projectConfig.GetRevision())
+	s.Equal("2", cm.GetOptimizelyConfig().Revision)
+}
+
+func TestFileConfigManagerTestSuite(t *testing.T) {
+	suite.Run(t, new(FileConfigManagerTestSuite))
+}
+
+func TestFileEventDispatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	dispatcher, err := NewFileEventDispatcher(path)
+	assert.NoError(t, err)
+
+	for _, revision := range []string{"1", "2"} {
+		success, err := dispatcher.DispatchEvent(event.LogEvent{Event: event.Batch{Revision: revision}})
+		assert.NoError(t, err)
+		assert.True(t, success)
+	}
+	assert.NoError(t, dispatcher.Close())
+
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	var revisions []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var batch event.Batch
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &batch))
+		revisions = append(revisions, batch.Revision)
+	}
+	assert.Equal(t, []string{"1", "2"}, revisions)
+}
+
+func TestFileEventDispatcherRequiresPath(t *testing.T) {
+	_, err := NewFileEventDispatcher("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,188 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package optimizely //
+package optimizely
+
+import (
+	"sync"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+	go_sdk_metrics "github.com/optimizely/go-sdk/v2/pkg/metrics"
+
+	"github.com/optimizely/agent/plugins/userprofileservice"
+)
+
+// User profile service metrics
+const (
+	UserProfileLookupsMetric   = "user-profile.lookups"
+	UserProfileHitsMetric      = "user-profile.hits"
+	UserProfileSavesMetric     = "user-profile.saves"
+	UserProfileEvictionsMetric = "user-profile.evictions"
+	UserProfileRetriesMetric   = "user-profile.retries"
+	UserProfileFailuresMetric  = "user-profile.failures"
+	UserProfileRejectedMetric  = "user-profile.rejected"
+	// UserProfileCircuitMetric is suffixed with the circuit state, its gauges count the services in each state
+	UserProfileCircuitMetric = "user-profile.circuit."
+)
+
+// userProfileCircuitMetrics reports the retried, failed and rejected requests of the user profile services of the
+// cached clients and the number of those services in each circuit state
+type userProfileCircuitMetrics struct {
+	retries  go_sdk_metrics.Counter
+	failures go_sdk_metrics.Counter
+	rejected go_sdk_metrics.Counter
+	gauges   map[userprofileservice.CircuitState]go_sdk_metrics.Gauge
+
+	lock   sync.Mutex
+	counts map[userprofileservice.CircuitState]int64
+}
+
+func newUserProfileCircuitMetrics(metricsRegistry *MetricsRegistry) *userProfileCircuitMetrics {
+	m := &userProfileCircuitMetrics{
+		retries:  metricsRegistry.GetCounter(UserProfileRetriesMetric),
+		failures: metricsRegistry.GetCounter(UserProfileFailuresMetric),
+		rejected: metricsRegistry.GetCounter(UserProfileRejectedMetric),
+		gauges:   map[userprofileservice.CircuitState]go_sdk_metrics.Gauge{},
+		counts:   map[userprofileservice.CircuitState]int64{},
+	}
+	for _, state := range []userprofileservice.CircuitState{
+		userprofileservice.CircuitClosed, userprofileservice.CircuitOpen, userprofileservice.CircuitHalfOpen,
+	} {
+		m.gauges[state] = metricsRegistry.GetGauge(UserProfileCircuitMetric + string(state))
+	}
+	return m
+}
+
+// move moves a service from a circuit state to another, the empty state is not reported
+func (m *userProfileCircuitMetrics) move(from, to userprofileservice.CircuitState) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for state, delta := range map[userprofileservice.CircuitState]int64{from: -1, to: 1} {
+		if gauge, ok := m.gauges[state]; ok {
+			m.counts[state] += delta
+			gauge.Set(float64(m.counts[state]))
+		}
+	}
+}
+
+// meteredUserProfileService counts the lookups, hits, saves and evictions of the user profile service of a client,
+// along with the retried, failed and rejected requests and the circuit state of the services with a circuit breaker
+type meteredUserProfileService struct {
+	decision.UserProfileService
+
+	lookups   go_sdk_metrics.Counter
+	hits      go_sdk_metrics.Counter
+	saves     go_sdk_metrics.Counter
+	evictions go_sdk_metrics.Counter
+
+	circuits *userProfileCircuitMetrics
+
+	lock    sync.Mutex
+	evicted int64
+	circuit userprofileservice.CircuitStats
+}
+
+// newMeteredUserProfileService meters ups, the circuit metrics are only requested when ups has a circuit breaker
+func newMeteredUserProfileService(ups decision.UserProfileService, metricsRegistry *MetricsRegistry,
+	circuits func() *userProfileCircuitMetrics) *meteredUserProfileService {
+	m := &meteredUserProfileService{
+		UserProfileService: ups,
+		lookups:            metricsRegistry.GetCounter(UserProfileLookupsMetric),
+		hits:               metricsRegistry.GetCounter(UserProfileHitsMetric),
+		saves:              metricsRegistry.GetCounter(UserProfileSavesMetric),
+		evictions:          metricsRegistry.GetCounter(UserProfileEvictionsMetric),
+	}
+	// evictions and requests that happened before the service was metered are not reported
+	if e, ok := ups.(userprofileservice.Evictions); ok {
+		m.evicted = e.Evictions()
+	}
+	m.meterCircuit(circuits)
+	return m
+}
+
+func (m *meteredUserProfileService) meterCircuit(circuits func() *userProfileCircuitMetrics) {
+	cb, ok := m.UserProfileService.(userprofileservice.CircuitBreaker)
+	if !ok {
+		return
+	}
+	if stats := cb.CircuitStats(); stats.State != "" {
+		m.circuits = circuits()
+		m.circuit = stats
+		m.circuit.State = ""
+		m.measureCircuit()
+	}
+}
+
+// Lookup returns the profile of the user, a profile with an ID counts as a hit
+func (m *meteredUserProfileService) Lookup(userID string) decision.UserProfile {
+	profile := m.UserProfileService.Lookup(userID)
+	m.lookups.Add(1)
+	if profile.ID != "" {
+		m.hits.Add(1)
+	}
+	m.measureCircuit()
+	return profile
+}
+
+// Save saves the profile of the user
+func (m *meteredUserProfileService) Save(profile decision.UserProfile) {
+	m.UserProfileService.Save(profile)
+	m.saves.Add(1)
+	m.measureEvictions()
+	m.measureCircuit()
+}
+
+// release removes the service from the circuit state gauges once its client is closed
+func (m *meteredUserProfileService) release() {
+	if m.circuits == nil {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.circuits.move(m.circuit.State, "")
+	m.circuit.State = ""
+}
+
+func (m *meteredUserProfileService) measureEvictions() {
+	e, ok := m.UserProfileService.(userprofileservice.Evictions)
+	if !ok {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	evicted := e.Evictions()
+	if evicted > m.evicted {
+		m.evictions.Add(float64(evicted - m.evicted))
+		m.evicted = evicted
+	}
+}
+
+func (m *meteredUserProfileService) measureCircuit() {
+	if m.circuits == nil {
+		return
+	}
+	stats := m.UserProfileService.(userprofileservice.CircuitBreaker).CircuitStats()
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.circuits.retries.Add(float64(stats.Retries - m.circuit.Retries))
+	m.circuits.failures.Add(float64(stats.Failures - m.circuit.Failures))
+	m.circuits.rejected.Add(float64(stats.Rejected - m.circuit.Rejected))
+	if stats.State != m.circuit.State {
+		m.circuits.move(m.circuit.State, stats.State)
+	}
+	m.circuit = stats
+}
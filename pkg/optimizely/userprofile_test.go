@@ -0,0 +1,133 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package optimizely //
+package optimizely
+
+import (
+	"testing"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+	go_sdk_metrics "github.com/optimizely/go-sdk/v2/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/optimizely/agent/plugins/userprofileservice"
+	"github.com/optimizely/agent/plugins/userprofileservice/services"
+)
+
+func newTestMeteredUserProfileService(ups decision.UserProfileService) *meteredUserProfileService {
+	return &meteredUserProfileService{
+		UserProfileService: ups,
+		lookups:            &testCounter{},
+		hits:               &testCounter{},
+		saves:              &testCounter{},
+		evictions:          &testCounter{},
+	}
+}
+
+func TestMeteredUserProfileService(t *testing.T) {
+	ups := newTestMeteredUserProfileService(&services.InMemoryUserProfileService{Capacity: 1})
+
+	profile := decision.UserProfile{
+		ID: "one",
+		ExperimentBucketMap: map[decision.UserDecisionKey]string{
+			decision.NewUserDecisionKey("exp"): "var",
+		},
+	}
+	assert.Equal(t, decision.UserProfile{}, ups.Lookup("one"))
+	ups.Save(profile)
+	assert.Equal(t, profile, ups.Lookup("one"))
+
+	// The capacity of the service is 1, saving another profile evicts the first one
+	profile.ID = "two"
+	ups.Save(profile)
+
+	assert.Equal(t, 2.0, ups.lookups.(*testCounter).value)
+	assert.Equal(t, 1.0, ups.hits.(*testCounter).value)
+	assert.Equal(t, 2.0, ups.saves.(*testCounter).value)
+	assert.Equal(t, 1.0, ups.evictions.(*testCounter).value)
+}
+
+func TestMeteredUserProfileServiceWithoutEvictions(t *testing.T) {
+	ups := newTestMeteredUserProfileService(&MockUserProfileService{})
+	ups.Save(decision.UserProfile{ID: "one"})
+
+	assert.Equal(t, 1.0, ups.saves.(*testCounter).value)
+	assert.Equal(t, 0.0, ups.evictions.(*testCounter).value)
+}
+
+type circuitBreakerUPS struct {
+	MockUserProfileService
+	stats userprofileservice.CircuitStats
+}
+
+func (c *circuitBreakerUPS) CircuitStats() userprofileservice.CircuitStats {
+	return c.stats
+}
+
+func TestMeteredUserProfileServiceCircuit(t *testing.T) {
+	circuits := &userProfileCircuitMetrics{
+		retries:  &testCounter{},
+		failures: &testCounter{},
+		rejected: &testCounter{},
+		gauges: map[userprofileservice.CircuitState]go_sdk_metrics.Gauge{
+			userprofileservice.CircuitClosed:   &testGauge{},
+			userprofileservice.CircuitOpen:     &testGauge{},
+			userprofileservice.CircuitHalfOpen: &testGauge{},
+		},
+		counts: map[userprofileservice.CircuitState]int64{},
+	}
+	gauge := func(state userprofileservice.CircuitState) float64 {
+		return circuits.gauges[state].(*testGauge).value
+	}
+
+	// The requests made before the services were metered are not reported
+	first := &circuitBreakerUPS{stats: userprofileservice.CircuitStats{State: userprofileservice.CircuitClosed, Failures: 3}}
+	second := &circuitBreakerUPS{stats: userprofileservice.CircuitStats{State: userprofileservice.CircuitClosed}}
+	getCircuits := func() *userProfileCircuitMetrics { return circuits }
+	metered := newTestMeteredUserProfileService(first)
+	metered.meterCircuit(getCircuits)
+	newTestMeteredUserProfileService(second).meterCircuit(getCircuits)
+	assert.Equal(t, 2.0, gauge(userprofileservice.CircuitClosed))
+
+	first.stats = userprofileservice.CircuitStats{State: userprofileservice.CircuitOpen, Retries: 1, Failures: 5, Rejected: 2}
+	metered.Lookup("one")
+	assert.Equal(t, 1.0, circuits.retries.(*testCounter).value)
+	assert.Equal(t, 2.0, circuits.failures.(*testCounter).value)
+	assert.Equal(t, 2.0, circuits.rejected.(*testCounter).value)
+	assert.Equal(t, 1.0, gauge(userprofileservice.CircuitClosed))
+	assert.Equal(t, 1.0, gauge(userprofileservice.CircuitOpen))
+
+	first.stats.State = userprofileservice.CircuitHalfOpen
+	metered.Save(decision.UserProfile{ID: "one"})
+	assert.Equal(t, 0.0, gauge(userprofileservice.CircuitOpen))
+	assert.Equal(t, 1.0, gauge(userprofileservice.CircuitHalfOpen))
+
+	metered.release()
+	assert.Equal(t, 0.0, gauge(userprofileservice.CircuitHalfOpen))
+	assert.Equal(t, 1.0, gauge(userprofileservice.CircuitClosed))
+}
+
+func TestMeteredUserProfileServiceWithoutCircuitBreaker(t *testing.T) {
+	ups := newTestMeteredUserProfileService(&MockUserProfileService{})
+	ups.meterCircuit(func() *userProfileCircuitMetrics {
+		t.Fatal("circuit metrics requested without a circuit breaker")
+		return nil
+	})
+	ups.Lookup("one")
+	ups.release()
+	assert.Nil(t, ups.circuits)
+}
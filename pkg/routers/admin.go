@@ -24,14 +24,20 @@ import (
 	"github.com/optimizely/agent/config"
 	"github.com/optimizely/agent/pkg/handlers"
 	"github.com/optimizely/agent/pkg/middleware"
+	"github.com/optimizely/agent/pkg/optimizely"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 	"github.com/rs/zerolog/log"
 )
 
+// deadLetterCache is implemented by the caches that dead-letter the event batches they fail to dispatch
+type deadLetterCache interface {
+	DeadLetters() *optimizely.DeadLetters
+}
+
 // NewAdminRouter returns HTTP admin router
-func NewAdminRouter(conf config.AgentConfig) http.Handler {
+func NewAdminRouter(conf config.AgentConfig, optlyCache optimizely.Cache) http.Handler {
 	r := chi.NewRouter()
 
 	authProvider := middleware.NewAuth(&conf.Admin.Auth)
@@ -48,6 +54,14 @@ func NewAdminRouter(conf config.AgentConfig) http.Handler {
 	}
 
 	optlyAdmin := handlers.NewAdmin(conf)
+	if datafiles, ok := optlyCache.(handlers.DatafileInfoProvider); ok {
+		optlyAdmin.Datafiles = datafiles
+	}
+	if cache, ok := optlyCache.(deadLetterCache); ok {
+		if deadLetters := cache.DeadLetters(); deadLetters != nil {
+			optlyAdmin.DeadLetters = deadLetters
+		}
+	}
 	r.Use(optlyAdmin.AppInfoHeader)
 	r.Use(render.SetContentType(render.ContentTypeJSON))
 
@@ -55,6 +69,16 @@ func NewAdminRouter(conf config.AgentConfig) http.Handler {
 	r.With(authProvider.AuthorizeAdmin).Get("/info", optlyAdmin.AppInfo)
 	r.With(authProvider.AuthorizeAdmin).Get("/metrics", optlyAdmin.Metrics)
 
+	r.Route("/dead-letters/{sdkKey}", func(r chi.Router) {
+		r.Use(authProvider.AuthorizeAdmin)
+		r.Get("/", optlyAdmin.ListDeadLetters)
+		r.Delete("/", optlyAdmin.PurgeDeadLetters)
+		r.Post("/replay", optlyAdmin.ReplayDeadLetters)
+		r.Get("/{batchID}", optlyAdmin.GetDeadLetter)
+		r.Delete("/{batchID}", optlyAdmin.RemoveDeadLetter)
+		r.Post("/{batchID}/replay", optlyAdmin.ReplayDeadLetter)
+	})
+
 	r.With(authProvider.AuthorizeAdmin).Get("/debug/pprof/*", pprof.Index)
 	r.With(authProvider.AuthorizeAdmin).Get("/debug/pprof/cmdline", pprof.Cmdline)
 	r.With(authProvider.AuthorizeAdmin).Get("/debug/pprof/profile", pprof.Profile)
@@ -19,18 +19,29 @@ package routers
 
 import (
 	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/optimizely/agent/config"
+	"github.com/optimizely/agent/pkg/handlers"
+	"github.com/optimizely/agent/pkg/optimizely"
 	"github.com/stretchr/testify/assert"
 )
 
+type datafileInfoCache struct {
+	MockCache
+}
+
+func (d datafileInfoCache) DatafileInfo() map[string]optimizely.DatafileInfo {
+	return map[string]optimizely.DatafileInfo{"sdkKey": {Source: optimizely.DatafileSourceCDN, Revision: "1"}}
+}
+
 func TestAdminAllowedContentTypeMiddleware(t *testing.T) {
 
 	conf := config.NewDefaultConfig()
-	router := NewAdminRouter(*conf)
+	router := NewAdminRouter(*conf, MockCache{})
 
 	// Testing unsupported content type
 	body := "<request> <parameters> <email>test@123.com</email> </parameters> </request>"
@@ -48,3 +59,40 @@ func TestAdminAllowedContentTypeMiddleware(t *testing.T) {
 	router.ServeHTTP(rec, req)
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 }
+
+func TestAdminInfoDatafiles(t *testing.T) {
+	conf := config.NewDefaultConfig()
+	router := NewAdminRouter(*conf, datafileInfoCache{})
+
+	req := httptest.NewRequest("GET", "/info", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var actual handlers.Info
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &actual))
+	assert.Equal(t, datafileInfoCache{}.DatafileInfo(), actual.Datafiles)
+}
+
+func TestAdminDeadLettersWithoutStore(t *testing.T) {
+	conf := config.NewDefaultConfig()
+	router := NewAdminRouter(*conf, MockCache{})
+
+	for _, route := range []struct{ method, path string }{
+		{"GET", "/dead-letters/sdkKey"},
+		{"DELETE", "/dead-letters/sdkKey"},
+		{"POST", "/dead-letters/sdkKey/replay"},
+		{"GET", "/dead-letters/sdkKey/1"},
+		{"DELETE", "/dead-letters/sdkKey/1"},
+		{"POST", "/dead-letters/sdkKey/1/replay"},
+	} {
+		req := httptest.NewRequest(route.method, route.path, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code, route.path)
+
+		var actual handlers.ErrorResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &actual))
+		assert.Equal(t, handlers.ErrDeadLettersDisabled.Error(), actual.Error)
+	}
+}
@@ -38,23 +38,31 @@ import (
 
 // APIOptions defines the configuration parameters for Router.
 type APIOptions struct {
-	maxConns            int
-	sdkMiddleware       func(next http.Handler) http.Handler
-	metricsRegistry     *metrics.Registry
-	configHandler       http.HandlerFunc
-	datafileHandler     http.HandlerFunc
-	activateHandler     http.HandlerFunc
-	decideHandler       http.HandlerFunc
-	trackHandler        http.HandlerFunc
-	overrideHandler     http.HandlerFunc
-	lookupHandler       http.HandlerFunc
-	saveHandler         http.HandlerFunc
-	resetHandler        http.HandlerFunc
-	sendOdpEventHandler http.HandlerFunc
-	nStreamHandler      http.HandlerFunc
-	oAuthHandler        http.HandlerFunc
-	oAuthMiddleware     func(next http.Handler) http.Handler
-	corsHandler         func(next http.Handler) http.Handler
+	maxConns                       int
+	sdkMiddleware                  func(next http.Handler) http.Handler
+	metricsRegistry                *metrics.Registry
+	configHandler                  http.HandlerFunc
+	datafileHandler                http.HandlerFunc
+	activateHandler                http.HandlerFunc
+	decideHandler                  http.HandlerFunc
+	decideGetHandler               http.HandlerFunc
+	decideBulkHandler              http.HandlerFunc
+	trackHandler                   http.HandlerFunc
+	trackBulkHandler               http.HandlerFunc
+	overrideHandler                http.HandlerFunc
+	lookupHandler                  http.HandlerFunc
+	saveHandler                    http.HandlerFunc
+	deleteProfileHandler           http.HandlerFunc
+	deleteProfileExperimentHandler http.HandlerFunc
+	exportProfilesHandler          http.HandlerFunc
+	importProfilesHandler          http.HandlerFunc
+	resetHandler                   http.HandlerFunc
+	sendOdpEventHandler            http.HandlerFunc
+	nStreamHandler                 http.HandlerFunc
+	oAuthHandler                   http.HandlerFunc
+	oAuthMiddleware                func(next http.Handler) http.Handler
+	rateLimitMiddleware            func(next http.Handler) http.Handler
+	corsHandler                    func(next http.Handler) http.Handler
 }
 
 func forbiddenHandler(message string) http.HandlerFunc {
@@ -63,8 +71,10 @@ func forbiddenHandler(message string) http.HandlerFunc {
 	}
 }
 
-// NewDefaultAPIRouter creates a new router with the default backing optimizely.Cache
-func NewDefaultAPIRouter(optlyCache optimizely.Cache, conf config.AgentConfig, metricsRegistry *metrics.Registry) http.Handler {
+// NewDefaultAPIRouter creates a new router with the default backing optimizely.Cache. The SDK key filter
+// and the rate limiter are shared with the gRPC API, a nil filter accepts every SDK key and a nil rate limiter
+// does not limit requests.
+func NewDefaultAPIRouter(optlyCache optimizely.Cache, conf config.AgentConfig, metricsRegistry *metrics.Registry, sdkKeys *middleware.SDKKeyFilter, rateLimiter *middleware.RateLimiter) http.Handler {
 	authProvider := middleware.NewAuth(&conf.API.Auth)
 	if authProvider == nil {
 		log.Error().Msg("unable to initialize api auth middleware.")
@@ -83,35 +93,55 @@ func NewDefaultAPIRouter(optlyCache optimizely.Cache, conf config.AgentConfig, m
 	}
 	resetHandler := handlers.ResetClient
 
+	// exporting, importing and deleting profiles reads or erases the data of every user
+	deleteProfileHandler := forbiddenHandler("Profile management not enabled")
+	deleteProfileExperimentHandler := deleteProfileHandler
+	exportProfilesHandler := deleteProfileHandler
+	importProfilesHandler := deleteProfileHandler
+	if conf.API.EnableProfileManagement {
+		deleteProfileHandler = handlers.DeleteProfile
+		deleteProfileExperimentHandler = handlers.DeleteProfileExperiment
+		exportProfilesHandler = handlers.ExportProfiles
+		importProfilesHandler = handlers.ImportProfiles
+	}
+
 	nStreamHandler := forbiddenHandler("Notification stream not enabled")
 	if conf.API.EnableNotifications {
-		nStreamHandler = handlers.NotificationEventStreamHandler(handlers.DefaultNotificationReceiver)
-		if conf.Synchronization.Notification.Enable {
-			nStreamHandler = handlers.NotificationEventStreamHandler(handlers.SyncedNotificationReceiver(conf.Synchronization))
-		}
+		nStreamHandler = handlers.NotificationEventStreamHandler(handlers.NotificationReceiver(conf.Synchronization))
 	}
 
-	mw := middleware.CachedOptlyMiddleware{Cache: optlyCache}
+	mw := middleware.CachedOptlyMiddleware{
+		Cache:   optlyCache,
+		SDKKeys: sdkKeys,
+	}
 	corsHandler := createCorsHandler(conf.API.CORS)
 
 	spec := &APIOptions{
-		maxConns:            conf.API.MaxConns,
-		metricsRegistry:     metricsRegistry,
-		configHandler:       handlers.OptimizelyConfig,
-		datafileHandler:     handlers.GetDatafile,
-		activateHandler:     handlers.Activate,
-		decideHandler:       handlers.Decide,
-		overrideHandler:     overrideHandler,
-		lookupHandler:       handlers.Lookup,
-		saveHandler:         handlers.Save,
-		resetHandler:        resetHandler,
-		trackHandler:        handlers.TrackEvent,
-		sendOdpEventHandler: handlers.SendOdpEvent,
-		sdkMiddleware:       mw.ClientCtx,
-		nStreamHandler:      nStreamHandler,
-		oAuthHandler:        authHandler.CreateAPIAccessToken,
-		oAuthMiddleware:     authProvider.AuthorizeAPI,
-		corsHandler:         corsHandler,
+		maxConns:                       conf.API.MaxConns,
+		metricsRegistry:                metricsRegistry,
+		configHandler:                  handlers.OptimizelyConfig,
+		datafileHandler:                handlers.GetDatafile,
+		activateHandler:                handlers.Activate,
+		decideHandler:                  handlers.Decide,
+		decideGetHandler:               handlers.DecideGet,
+		decideBulkHandler:              handlers.DecideBulk(conf.API.DecideBulk.MaxConcurrency),
+		overrideHandler:                overrideHandler,
+		lookupHandler:                  handlers.Lookup,
+		saveHandler:                    handlers.Save,
+		deleteProfileHandler:           deleteProfileHandler,
+		deleteProfileExperimentHandler: deleteProfileExperimentHandler,
+		exportProfilesHandler:          exportProfilesHandler,
+		importProfilesHandler:          importProfilesHandler,
+		resetHandler:                   resetHandler,
+		trackHandler:                   handlers.TrackEvent,
+		trackBulkHandler:               handlers.TrackBulk,
+		sendOdpEventHandler:            handlers.SendOdpEvent,
+		sdkMiddleware:                  mw.ClientCtx,
+		nStreamHandler:                 nStreamHandler,
+		oAuthHandler:                   authHandler.CreateAPIAccessToken,
+		oAuthMiddleware:                authProvider.AuthorizeAPI,
+		rateLimitMiddleware:            rateLimiter.Limit,
+		corsHandler:                    corsHandler,
 	}
 
 	return NewAPIRouter(spec)
@@ -131,28 +161,49 @@ func WithAPIRouter(opt *APIOptions, r chi.Router) {
 	getDatafileTimer := middleware.Metricize("get-datafile", opt.metricsRegistry)
 	activateTimer := middleware.Metricize("activate", opt.metricsRegistry)
 	decideTimer := middleware.Metricize("decide", opt.metricsRegistry)
+	decideGetTimer := middleware.Metricize("decide-get", opt.metricsRegistry)
+	decideBulkTimer := middleware.Metricize("decide-bulk", opt.metricsRegistry)
 	overrideTimer := middleware.Metricize("override", opt.metricsRegistry)
 	lookupTimer := middleware.Metricize("lookup", opt.metricsRegistry)
 	saveTimer := middleware.Metricize("save", opt.metricsRegistry)
+	deleteProfileTimer := middleware.Metricize("delete-profile", opt.metricsRegistry)
+	deleteProfileExperimentTimer := middleware.Metricize("delete-profile-experiment", opt.metricsRegistry)
+	exportProfilesTimer := middleware.Metricize("export-profiles", opt.metricsRegistry)
+	importProfilesTimer := middleware.Metricize("import-profiles", opt.metricsRegistry)
 	resetTimer := middleware.Metricize("reset", opt.metricsRegistry)
 	trackTimer := middleware.Metricize("track-event", opt.metricsRegistry)
+	trackBulkTimer := middleware.Metricize("track-bulk", opt.metricsRegistry)
 	sendOdpEventTimer := middleware.Metricize("send-odp-event", opt.metricsRegistry)
 	createAccesstokenTimer := middleware.Metricize("create-api-access-token", opt.metricsRegistry)
 	contentTypeMiddleware := chimw.AllowContentType("application/json")
+	bulkContentTypeMiddleware := chimw.AllowContentType("application/json", "application/x-ndjson")
 
 	configTracer := middleware.AddTracing("configHandler", "OptimizelyConfig")
 	datafileTracer := middleware.AddTracing("datafileHandler", "OptimizelyDatafile")
 	activateTracer := middleware.AddTracing("activateHandler", "Activate")
 	decideTracer := middleware.AddTracing("decideHandler", "Decide")
+	decideGetTracer := middleware.AddTracing("decideGetHandler", "DecideGet")
+	decideBulkTracer := middleware.AddTracing("decideBulkHandler", "DecideBulk")
 	trackTracer := middleware.AddTracing("trackHandler", "Track")
+	trackBulkTracer := middleware.AddTracing("trackBulkHandler", "TrackBulk")
 	overrideTracer := middleware.AddTracing("overrideHandler", "Override")
 	lookupTracer := middleware.AddTracing("lookupHandler", "Lookup")
 	saveTracer := middleware.AddTracing("saveHandler", "Save")
+	deleteProfileTracer := middleware.AddTracing("deleteProfileHandler", "DeleteProfile")
+	deleteProfileExperimentTracer := middleware.AddTracing("deleteProfileExperimentHandler", "DeleteProfileExperiment")
+	exportProfilesTracer := middleware.AddTracing("exportProfilesHandler", "ExportProfiles")
+	importProfilesTracer := middleware.AddTracing("importProfilesHandler", "ImportProfiles")
 	resetTracer := middleware.AddTracing("resetHandler", "Reset")
 	sendOdpEventTracer := middleware.AddTracing("sendOdpEventHandler", "SendOdpEvent")
 	nStreamTracer := middleware.AddTracing("notificationHandler", "SendNotificationEvent")
 	authTracer := middleware.AddTracing("authHandler", "AuthToken")
 
+	// Rate limits are applied after authorization so that requests can be limited by OAuth client ID
+	rateLimit := opt.rateLimitMiddleware
+	if rateLimit == nil {
+		rateLimit = func(next http.Handler) http.Handler { return next }
+	}
+
 	if opt.maxConns > 0 {
 		// Note this is NOT a rate limiter, but a concurrency threshold
 		r.Use(chimw.Throttle(opt.maxConns))
@@ -163,17 +214,24 @@ func WithAPIRouter(opt *APIOptions, r chi.Router) {
 
 	r.Route("/v1", func(r chi.Router) {
 		r.Use(opt.corsHandler, opt.sdkMiddleware)
-		r.With(getConfigTimer, opt.oAuthMiddleware, configTracer).Get("/config", opt.configHandler)
-		r.With(getDatafileTimer, opt.oAuthMiddleware, datafileTracer).Get("/datafile", opt.datafileHandler)
-		r.With(activateTimer, opt.oAuthMiddleware, contentTypeMiddleware, activateTracer).Post("/activate", opt.activateHandler)
-		r.With(decideTimer, opt.oAuthMiddleware, contentTypeMiddleware, decideTracer).Post("/decide", opt.decideHandler)
-		r.With(trackTimer, opt.oAuthMiddleware, contentTypeMiddleware, trackTracer).Post("/track", opt.trackHandler)
-		r.With(overrideTimer, opt.oAuthMiddleware, contentTypeMiddleware, overrideTracer).Post("/override", opt.overrideHandler)
-		r.With(resetTimer, opt.oAuthMiddleware, contentTypeMiddleware, resetTracer).Post("/reset", opt.resetHandler)
-		r.With(lookupTimer, opt.oAuthMiddleware, contentTypeMiddleware, lookupTracer).Post("/lookup", opt.lookupHandler)
-		r.With(saveTimer, opt.oAuthMiddleware, contentTypeMiddleware, saveTracer).Post("/save", opt.saveHandler)
-		r.With(sendOdpEventTimer, opt.oAuthMiddleware, contentTypeMiddleware, sendOdpEventTracer).Post("/send-odp-event", opt.sendOdpEventHandler)
-		r.With(opt.oAuthMiddleware, nStreamTracer).Get("/notifications/event-stream", opt.nStreamHandler)
+		r.With(getConfigTimer, opt.oAuthMiddleware, rateLimit, configTracer).Get("/config", opt.configHandler)
+		r.With(getDatafileTimer, opt.oAuthMiddleware, rateLimit, datafileTracer).Get("/datafile", opt.datafileHandler)
+		r.With(activateTimer, opt.oAuthMiddleware, rateLimit, contentTypeMiddleware, activateTracer).Post("/activate", opt.activateHandler)
+		r.With(decideTimer, opt.oAuthMiddleware, rateLimit, contentTypeMiddleware, decideTracer).Post("/decide", opt.decideHandler)
+		r.With(decideGetTimer, opt.oAuthMiddleware, rateLimit, decideGetTracer).Get("/decide", opt.decideGetHandler)
+		r.With(decideBulkTimer, opt.oAuthMiddleware, rateLimit, bulkContentTypeMiddleware, decideBulkTracer).Post("/decide/bulk", opt.decideBulkHandler)
+		r.With(trackTimer, opt.oAuthMiddleware, rateLimit, contentTypeMiddleware, trackTracer).Post("/track", opt.trackHandler)
+		r.With(trackBulkTimer, opt.oAuthMiddleware, rateLimit, bulkContentTypeMiddleware, trackBulkTracer).Post("/track/bulk", opt.trackBulkHandler)
+		r.With(overrideTimer, opt.oAuthMiddleware, rateLimit, contentTypeMiddleware, overrideTracer).Post("/override", opt.overrideHandler)
+		r.With(resetTimer, opt.oAuthMiddleware, rateLimit, contentTypeMiddleware, resetTracer).Post("/reset", opt.resetHandler)
+		r.With(lookupTimer, opt.oAuthMiddleware, rateLimit, contentTypeMiddleware, lookupTracer).Post("/lookup", opt.lookupHandler)
+		r.With(saveTimer, opt.oAuthMiddleware, rateLimit, contentTypeMiddleware, saveTracer).Post("/save", opt.saveHandler)
+		r.With(exportProfilesTimer, opt.oAuthMiddleware, rateLimit, exportProfilesTracer).Get("/profiles", opt.exportProfilesHandler)
+		r.With(importProfilesTimer, opt.oAuthMiddleware, rateLimit, bulkContentTypeMiddleware, importProfilesTracer).Post("/profiles/import", opt.importProfilesHandler)
+		r.With(deleteProfileTimer, opt.oAuthMiddleware, rateLimit, deleteProfileTracer).Delete("/profiles/{userId}", opt.deleteProfileHandler)
+		r.With(deleteProfileExperimentTimer, opt.oAuthMiddleware, rateLimit, deleteProfileExperimentTracer).Delete("/profiles/{userId}/experiments/{experimentId}", opt.deleteProfileExperimentHandler)
+		r.With(sendOdpEventTimer, opt.oAuthMiddleware, rateLimit, contentTypeMiddleware, sendOdpEventTracer).Post("/send-odp-event", opt.sendOdpEventHandler)
+		r.With(opt.oAuthMiddleware, rateLimit, nStreamTracer).Get("/notifications/event-stream", opt.nStreamHandler)
 	})
 
 	r.With(createAccesstokenTimer, authTracer).Post("/oauth/token", opt.oAuthHandler)
@@ -112,21 +112,29 @@ func (suite *APIV1TestSuite) SetupTest() {
 	suite.tc = testClient
 
 	opts = &APIOptions{
-		maxConns:            1,
-		sdkMiddleware:       testOptlyMiddleware,
-		configHandler:       testHandler("config"),
-		datafileHandler:     testHandler("datafile"),
-		activateHandler:     testHandler("activate"),
-		overrideHandler:     testHandler("override"),
-		lookupHandler:       testHandler("lookup"),
-		saveHandler:         testHandler("save"),
-		trackHandler:        testHandler("track"),
-		sendOdpEventHandler: testHandler("send-odp-event"),
-		nStreamHandler:      testHandler("notifications/event-stream"),
-		oAuthHandler:        testHandler("oauth/token"),
-		oAuthMiddleware:     testAuthMiddleware,
-		metricsRegistry:     metricsRegistry,
-		corsHandler:         testCorsHandler,
+		maxConns:                       1,
+		sdkMiddleware:                  testOptlyMiddleware,
+		configHandler:                  testHandler("config"),
+		datafileHandler:                testHandler("datafile"),
+		activateHandler:                testHandler("activate"),
+		decideHandler:                  testHandler("decide"),
+		decideGetHandler:               testHandler("decide"),
+		decideBulkHandler:              testHandler("decide/bulk"),
+		overrideHandler:                testHandler("override"),
+		lookupHandler:                  testHandler("lookup"),
+		saveHandler:                    testHandler("save"),
+		deleteProfileHandler:           testHandler("profiles/user"),
+		deleteProfileExperimentHandler: testHandler("profiles/user/experiments/exp"),
+		exportProfilesHandler:          testHandler("profiles"),
+		importProfilesHandler:          testHandler("profiles/import"),
+		trackHandler:                   testHandler("track"),
+		trackBulkHandler:               testHandler("track/bulk"),
+		sendOdpEventHandler:            testHandler("send-odp-event"),
+		nStreamHandler:                 testHandler("notifications/event-stream"),
+		oAuthHandler:                   testHandler("oauth/token"),
+		oAuthMiddleware:                testAuthMiddleware,
+		metricsRegistry:                metricsRegistry,
+		corsHandler:                    testCorsHandler,
 	}
 
 	suite.mux = NewAPIRouter(opts)
@@ -150,10 +158,18 @@ func (suite *APIV1TestSuite) TestValidRoutes() {
 		{"GET", "config"},
 		{"GET", "datafile"},
 		{"POST", "activate"},
+		{"POST", "decide"},
+		{"GET", "decide"},
+		{"POST", "decide/bulk"},
 		{"POST", "track"},
+		{"POST", "track/bulk"},
 		{"POST", "override"},
 		{"POST", "lookup"},
 		{"POST", "save"},
+		{"GET", "profiles"},
+		{"POST", "profiles/import"},
+		{"DELETE", "profiles/user"},
+		{"DELETE", "profiles/user/experiments/exp"},
 		{"POST", "send-odp-event"},
 		{"GET", "notifications/event-stream"},
 	}
@@ -331,7 +347,7 @@ func TestAPIV1TestSuite(t *testing.T) {
 }
 
 func TestNewDefaultAPIV1Router(t *testing.T) {
-	client := NewDefaultAPIRouter(MockCache{}, config.AgentConfig{}, metricsRegistry)
+	client := NewDefaultAPIRouter(MockCache{}, config.AgentConfig{}, metricsRegistry, nil, nil)
 	assert.NotNil(t, client)
 }
 
@@ -356,7 +372,7 @@ func TestNewDefaultAPIV1RouterInvalidHandlerConfig(t *testing.T) {
 		EnableNotifications: false,
 		EnableOverrides:     false,
 	}
-	client := NewDefaultAPIRouter(MockCache{}, config.AgentConfig{API: invalidAPIConfig}, metricsRegistry)
+	client := NewDefaultAPIRouter(MockCache{}, config.AgentConfig{API: invalidAPIConfig}, metricsRegistry, nil, nil)
 	assert.Nil(t, client)
 }
 
@@ -371,12 +387,12 @@ func TestNewDefaultClientRouterInvalidMiddlewareConfig(t *testing.T) {
 		EnableNotifications: false,
 		EnableOverrides:     false,
 	}
-	client := NewDefaultAPIRouter(MockCache{}, config.AgentConfig{API: invalidAPIConfig}, metricsRegistry)
+	client := NewDefaultAPIRouter(MockCache{}, config.AgentConfig{API: invalidAPIConfig}, metricsRegistry, nil, nil)
 	assert.Nil(t, client)
 }
 
 func TestForbiddenRoutes(t *testing.T) {
-	mux := NewDefaultAPIRouter(MockCache{}, config.AgentConfig{}, metricsRegistry)
+	mux := NewDefaultAPIRouter(MockCache{}, config.AgentConfig{}, metricsRegistry, nil, nil)
 
 	routes := []struct {
 		method string
@@ -385,6 +401,10 @@ func TestForbiddenRoutes(t *testing.T) {
 	}{
 		{"POST", "override", "Overrides not enabled\n"},
 		{"GET", "notifications/event-stream", "Notification stream not enabled\n"},
+		{"GET", "profiles", "Profile management not enabled\n"},
+		{"POST", "profiles/import", "Profile management not enabled\n"},
+		{"DELETE", "profiles/user", "Profile management not enabled\n"},
+		{"DELETE", "profiles/user/experiments/exp", "Profile management not enabled\n"},
 	}
 
 	for _, route := range routes {
@@ -429,3 +449,21 @@ func (suite *APIV1TestSuite) TestAllowedContentTypeMiddleware() {
 		suite.Equal(http.StatusOK, rec.Code)
 	}
 }
+
+func (suite *APIV1TestSuite) TestBulkContentTypeMiddleware() {
+	for _, path := range []string{"decide/bulk", "track/bulk"} {
+		for _, contentType := range []string{"application/json", "application/x-ndjson"} {
+			req := httptest.NewRequest("POST", "/v1/"+path, bytes.NewBufferString(`{"userId":"user"}`))
+			req.Header.Add(contentTypeHeaderKey, contentType)
+			rec := httptest.NewRecorder()
+			suite.mux.ServeHTTP(rec, req)
+			suite.Equal(http.StatusOK, rec.Code)
+		}
+
+		req := httptest.NewRequest("POST", "/v1/"+path, bytes.NewBufferString(`<request/>`))
+		req.Header.Add(contentTypeHeaderKey, "application/xml")
+		rec := httptest.NewRecorder()
+		suite.mux.ServeHTTP(rec, req)
+		suite.Equal(http.StatusUnsupportedMediaType, rec.Code)
+	}
+}
@@ -19,7 +19,9 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
 	"sync"
 
@@ -89,6 +91,68 @@ func (g *Group) GoListenAndServe(name, port string, handler http.Handler) {
 	wg.Wait()
 }
 
+// Listener is a server that accepts connections on a net.Listener, such as a grpc.Server
+type Listener interface {
+	Serve(net.Listener) error
+	GracefulStop()
+}
+
+// GoServe adds a non-HTTP Listener to the Group on the configured host and port.
+// TLS is enabled with the same certificates as the HTTP listeners. Like GoListenAndServe,
+// one goroutine serves connections and another initiates a graceful stop.
+func (g *Group) GoServe(name, port string, srv Listener) {
+
+	if port == "0" {
+		log.Info().Msg(fmt.Sprintf(`%q not enabled`, name))
+		return
+	}
+
+	logger := log.With().Str("port", port).Str("name", name).Logger()
+	lis, err := net.Listen("tcp", net.JoinHostPort(g.conf.Host, port))
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed starting server")
+		g.stop()
+		return
+	}
+
+	if g.conf.KeyFile != "" && g.conf.CertFile != "" {
+		tlsConfig, err := makeTLSConfig(g.conf)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed starting server")
+			_ = lis.Close()
+			g.stop()
+			return
+		}
+		tlsConfig.NextProtos = []string{"h2"}
+		lis = tls.NewListener(lis, tlsConfig)
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	g.eg.Go(func() error {
+		wg.Done()
+		defer g.stop()
+		logger.Info().Msg("Starting server.")
+		if err := srv.Serve(lis); err != nil {
+			logger.Error().Err(err).Msg("Server failed.")
+			return err
+		}
+		return nil
+	})
+
+	// Shutdown on signal
+	wg.Add(1)
+	g.eg.Go(func() error {
+		wg.Done()
+		<-g.ctx.Done()
+		logger.Info().Msg("Shutting down server.")
+		srv.GracefulStop()
+		return g.ctx.Err()
+	})
+
+	wg.Wait()
+}
+
 // Wait waits for all servers to complete before returning
 func (g *Group) Wait() error {
 	return g.eg.Wait()
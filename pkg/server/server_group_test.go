@@ -18,7 +18,10 @@ package server
 
 import (
 	"context"
+	"net"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestServeAndShutdown(t *testing.T) {
@@ -44,3 +47,43 @@ func TestInvalidServer(t *testing.T) {
 	sg.GoListenAndServe("invalid", "-1", handler)
 	sg.Wait() // Don't need to shutdown since server never started
 }
+
+type testListener struct {
+	stopped chan struct{}
+}
+
+func (l *testListener) Serve(lis net.Listener) error {
+	<-l.stopped
+	return lis.Close()
+}
+
+func (l *testListener) GracefulStop() {
+	close(l.stopped)
+}
+
+func TestGoServeAndShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sg := NewGroup(ctx, conf)
+
+	srv := &testListener{stopped: make(chan struct{})}
+	sg.GoServe("listener", "1002", srv)
+
+	cancel()
+	assert.Equal(t, context.Canceled, sg.Wait())
+
+	_, ok := <-srv.stopped
+	assert.False(t, ok)
+}
+
+func TestNotEnabledGoServe(t *testing.T) {
+	sg := NewGroup(context.Background(), conf)
+	sg.GoServe("disabled", "0", &testListener{stopped: make(chan struct{})})
+
+	assert.NoError(t, sg.Wait())
+}
+
+func TestInvalidGoServe(t *testing.T) {
+	sg := NewGroup(context.Background(), conf)
+	sg.GoServe("invalid", "-1", &testListener{stopped: make(chan struct{})})
+	sg.Wait() // Don't need to shutdown since listener never started
+}
@@ -20,12 +20,14 @@ package syncer
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/optimizely/agent/config"
 	"github.com/optimizely/agent/pkg/syncer/pubsub"
 	"github.com/optimizely/agent/pkg/utils/redisauth"
+	"github.com/optimizely/agent/pkg/utils/redisclient"
 	"github.com/rs/zerolog/log"
 )
 
@@ -36,6 +38,12 @@ const (
 	PubSubRedis = "redis"
 	// PubSubRedisStreams is the name of pubsub type of Redis Streams (persistent)
 	PubSubRedisStreams = "redis-streams"
+	// PubSubNATS is the name of pubsub type of NATS (fire-and-forget, or persistent with JetStream)
+	PubSubNATS = "nats"
+	// PubSubKafka is the name of pubsub type of Kafka (persistent)
+	PubSubKafka = "kafka"
+	// PubSubMemory is the name of pubsub type within the Agent process (single node)
+	PubSubMemory = "memory"
 )
 
 type SyncFeatureFlag string
@@ -61,15 +69,83 @@ func newPubSub(conf config.SyncConfig, featureFlag SyncFeatureFlag) (PubSub, err
 		return nil, errors.New("provided feature flag not supported")
 	}
 
-	// Only support auto-detection
-	if defaultPubSub == PubSubRedis {
+	switch defaultPubSub {
+	case PubSubRedis:
 		// Use auto-detection (with fallback to Pub/Sub if detection fails)
 		return getPubSubWithAutoDetect(conf)
+	case PubSubNATS:
+		return getPubSubNATS(conf)
+	case PubSubKafka:
+		return getPubSubKafka(conf)
+	case PubSubMemory:
+		return getPubSubMemory(conf), nil
 	}
 
 	return nil, errors.New("pubsub type not supported")
 }
 
+// getPubSubMemory returns the in-process pubsub, its configuration is optional
+func getPubSubMemory(conf config.SyncConfig) PubSub {
+	memoryConf, _ := conf.Pubsub[PubSubMemory].(map[string]interface{})
+	return &pubsub.Memory{
+		BufferSize: getIntFromConfig(memoryConf, "buffer_size", 100),
+	}
+}
+
+func getPubSubNATS(conf config.SyncConfig) (PubSub, error) {
+	pubsubConf, found := conf.Pubsub[PubSubNATS]
+	if !found {
+		return nil, errors.New("pubsub nats config not found")
+	}
+
+	natsConf, ok := pubsubConf.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("pubsub nats config not valid")
+	}
+
+	url, err := getStringFromConfig(natsConf, "url")
+	if err != nil {
+		return nil, fmt.Errorf("pubsub nats %w", err)
+	}
+
+	return &pubsub.NATS{
+		URL:           url,
+		User:          getOptionalStringFromConfig(natsConf, "user"),
+		Password:      redisauth.GetPassword(natsConf, "NATS_PASSWORD"),
+		SubjectPrefix: getOptionalStringFromConfig(natsConf, "subject_prefix"),
+		ConnTimeout:   getDurationFromConfig(natsConf, "connection_timeout", 10*time.Second),
+		JetStream:     getBoolFromConfig(natsConf, "jetstream"),
+		Stream:        getOptionalStringFromConfig(natsConf, "stream"),
+		MaxAge:        getDurationFromConfig(natsConf, "max_age", time.Hour),
+	}, nil
+}
+
+func getPubSubKafka(conf config.SyncConfig) (PubSub, error) {
+	pubsubConf, found := conf.Pubsub[PubSubKafka]
+	if !found {
+		return nil, errors.New("pubsub kafka config not found")
+	}
+
+	kafkaConf, ok := pubsubConf.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("pubsub kafka config not valid")
+	}
+
+	brokers := getStringsFromConfig(kafkaConf, "brokers")
+	if len(brokers) == 0 {
+		return nil, errors.New("pubsub kafka brokers not found")
+	}
+
+	return &pubsub.Kafka{
+		Brokers:     brokers,
+		Topic:       getOptionalStringFromConfig(kafkaConf, "topic"),
+		User:        getOptionalStringFromConfig(kafkaConf, "user"),
+		Password:    redisauth.GetPassword(kafkaConf, "KAFKA_PASSWORD"),
+		TLS:         getBoolFromConfig(kafkaConf, "tls"),
+		ConnTimeout: getDurationFromConfig(kafkaConf, "connection_timeout", 10*time.Second),
+	}, nil
+}
+
 func getPubSubRedis(conf config.SyncConfig) (PubSub, error) {
 	pubsubConf, found := conf.Pubsub[PubSubRedis]
 	if !found {
@@ -108,11 +184,17 @@ func getPubSubRedis(conf config.SyncConfig) (PubSub, error) {
 		return nil, errors.New("pubsub redis database not valid, database must be numeric")
 	}
 
+	redisClientConf, err := redisclient.ParseConfig(redisConf)
+	if err != nil {
+		return nil, fmt.Errorf("pubsub redis config not valid: %w", err)
+	}
+
 	// Return original Redis pub/sub implementation (fire-and-forget)
 	return &pubsub.Redis{
 		Host:     host,
 		Password: password,
 		Database: database,
+		Config:   redisClientConf,
 	}, nil
 }
 
@@ -154,6 +236,11 @@ func getPubSubRedisStreams(conf config.SyncConfig) (PubSub, error) {
 		return nil, errors.New("pubsub redis database not valid, database must be numeric")
 	}
 
+	redisClientConf, err := redisclient.ParseConfig(redisConf)
+	if err != nil {
+		return nil, fmt.Errorf("pubsub redis config not valid: %w", err)
+	}
+
 	// Parse optional Redis Streams configuration parameters
 	batchSize := getIntFromConfig(redisConf, "batch_size", 10)
 	flushInterval := getDurationFromConfig(redisConf, "flush_interval", 5*time.Second)
@@ -167,6 +254,7 @@ func getPubSubRedisStreams(conf config.SyncConfig) (PubSub, error) {
 		Host:          host,
 		Password:      password,
 		Database:      database,
+		Config:        redisClientConf,
 		BatchSize:     batchSize,
 		FlushInterval: flushInterval,
 		MaxRetries:    maxRetries,
@@ -186,6 +274,64 @@ func getIntFromConfig(config map[string]interface{}, key string, defaultValue in
 	return defaultValue
 }
 
+// getStringFromConfig extracts a required string value from config map
+func getStringFromConfig(config map[string]interface{}, key string) (string, error) {
+	val, found := config[key]
+	if !found {
+		return "", fmt.Errorf("%s not found", key)
+	}
+	strVal, ok := val.(string)
+	if !ok {
+		return "", fmt.Errorf("%s not valid, %s must be string", key, key)
+	}
+	return strVal, nil
+}
+
+// getOptionalStringFromConfig safely extracts a string value from config map, empty when not found
+func getOptionalStringFromConfig(config map[string]interface{}, key string) string {
+	if val, found := config[key]; found {
+		if strVal, ok := val.(string); ok {
+			return strVal
+		}
+	}
+	return ""
+}
+
+// getBoolFromConfig safely extracts a boolean value from config map, false when not found
+func getBoolFromConfig(config map[string]interface{}, key string) bool {
+	if val, found := config[key]; found {
+		if boolVal, ok := val.(bool); ok {
+			return boolVal
+		}
+	}
+	return false
+}
+
+// getStringsFromConfig extracts a list of strings from config map, given as a list or a comma separated string
+func getStringsFromConfig(config map[string]interface{}, key string) []string {
+	var values []string
+	switch v := config[key].(type) {
+	case string:
+		values = strings.Split(v, ",")
+	case []string:
+		values = v
+	case []interface{}:
+		for _, item := range v {
+			if strVal, ok := item.(string); ok {
+				values = append(values, strVal)
+			}
+		}
+	}
+
+	result := []string{}
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
 // getDurationFromConfig safely extracts a duration value from config map with default fallback
 func getDurationFromConfig(config map[string]interface{}, key string, defaultValue time.Duration) time.Duration {
 	if val, found := config[key]; found {
@@ -237,12 +383,13 @@ func getPubSubWithAutoDetect(conf config.SyncConfig) (PubSub, error) {
 		return nil, errors.New("pubsub redis database not valid, database must be numeric")
 	}
 
+	redisClientConf, err := redisclient.ParseConfig(redisConf)
+	if err != nil {
+		return nil, fmt.Errorf("pubsub redis config not valid: %w", err)
+	}
+
 	// Create temporary Redis client for version detection
-	client := redis.NewClient(&redis.Options{
-		Addr:     host,
-		Password: password,
-		DB:       database,
-	})
+	client := redisclient.NewClient(host, password, database, redisClientConf)
 	defer client.Close()
 
 	// Attempt version detection
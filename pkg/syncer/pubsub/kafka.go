@@ -0,0 +1,273 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package pubsub provides pubsub functionality for the agent syncer
+package pubsub
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+)
+
+// Kafka implements persistent message delivery over the Kafka protocol. Every channel shares one topic,
+// with the channel name as the record key. The subscribers of a process share one consumer of all partitions of
+// the topic, without a consumer group so that every Agent receives every message, which hands every record to the
+// subscribers of its channel. Messages are received from the time the consumer was started by the first
+// subscription.
+type Kafka struct {
+	Brokers  []string
+	Topic    string
+	User     string
+	Password string
+	TLS      bool
+	// Connection timeout
+	ConnTimeout time.Duration
+
+	lock     sync.Mutex
+	producer *kgo.Client
+}
+
+func (k *Kafka) Publish(ctx context.Context, channel string, message interface{}) error {
+	data, err := encodeMessage(message)
+	if err != nil {
+		return err
+	}
+
+	producer, err := k.getProducer()
+	if err != nil {
+		return err
+	}
+
+	record := &kgo.Record{Topic: k.getTopic(), Key: []byte(channel), Value: data}
+	return producer.ProduceSync(ctx, record).FirstErr()
+}
+
+// kafkaSubscriberBufferSize is the number of messages held for each subscriber, so that a slow subscriber does not
+// hold up the others. Messages are dropped when it is full.
+const kafkaSubscriberBufferSize = 100
+
+// Subscribe returns the messages of the channel until ctx is done
+func (k *Kafka) Subscribe(ctx context.Context, channel string) (chan string, error) {
+	sub := &kafkaSubscriber{ch: make(chan string, kafkaSubscriberBufferSize)}
+	consumer, err := subscribeKafka(k, channel, sub)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		// the consumer no longer sends to the subscriber once it is removed
+		consumer.remove(channel, sub)
+		close(sub.ch)
+	}()
+	return sub.ch, nil
+}
+
+// kafkaConsumers holds the consumer of every topic consumed by the process
+var kafkaConsumers = struct {
+	lock      sync.Mutex
+	consumers map[kafkaConsumerKey]*kafkaConsumer
+}{consumers: make(map[kafkaConsumerKey]*kafkaConsumer)}
+
+// kafkaConsumerKey identifies the cluster, credentials and topic of a consumer
+type kafkaConsumerKey struct {
+	brokers  string
+	topic    string
+	user     string
+	password string
+	tls      bool
+}
+
+type kafkaSubscriber struct {
+	ch chan string
+}
+
+// kafkaConsumer hands the records of a topic to the subscribers of their channel, it is closed once its last
+// subscriber is removed
+type kafkaConsumer struct {
+	key         kafkaConsumerKey
+	client      *kgo.Client
+	cancel      context.CancelFunc
+	lock        sync.RWMutex
+	subscribers map[string]map[*kafkaSubscriber]struct{}
+}
+
+// subscribeKafka adds the subscriber to the consumer of the topic of k, which is started by the first subscriber
+func subscribeKafka(k *Kafka, channel string, sub *kafkaSubscriber) (*kafkaConsumer, error) {
+	key := kafkaConsumerKey{
+		brokers:  strings.Join(k.Brokers, ","),
+		topic:    k.getTopic(),
+		user:     k.User,
+		password: k.Password,
+		tls:      k.TLS,
+	}
+
+	kafkaConsumers.lock.Lock()
+	defer kafkaConsumers.lock.Unlock()
+
+	consumer, ok := kafkaConsumers.consumers[key]
+	if !ok {
+		opts := append(k.clientOpts(),
+			kgo.ConsumeTopics(key.topic),
+			kgo.ConsumeResetOffset(kgo.NewOffset().AfterMilli(time.Now().UnixMilli())),
+		)
+		client, err := kgo.NewClient(opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		consumer = &kafkaConsumer{
+			key:         key,
+			client:      client,
+			cancel:      cancel,
+			subscribers: make(map[string]map[*kafkaSubscriber]struct{}),
+		}
+		kafkaConsumers.consumers[key] = consumer
+		go consumer.run(ctx)
+	}
+
+	consumer.lock.Lock()
+	defer consumer.lock.Unlock()
+	if consumer.subscribers[channel] == nil {
+		consumer.subscribers[channel] = make(map[*kafkaSubscriber]struct{})
+	}
+	consumer.subscribers[channel][sub] = struct{}{}
+	return consumer, nil
+}
+
+// remove removes the subscriber and closes the consumer once it has no subscribers left
+func (c *kafkaConsumer) remove(channel string, sub *kafkaSubscriber) {
+	kafkaConsumers.lock.Lock()
+	defer kafkaConsumers.lock.Unlock()
+
+	c.lock.Lock()
+	delete(c.subscribers[channel], sub)
+	if len(c.subscribers[channel]) == 0 {
+		delete(c.subscribers, channel)
+	}
+	empty := len(c.subscribers) == 0
+	c.lock.Unlock()
+
+	if empty && kafkaConsumers.consumers[c.key] == c {
+		delete(kafkaConsumers.consumers, c.key)
+		c.cancel()
+	}
+}
+
+// run polls the topic until ctx is done, then closes the client
+func (c *kafkaConsumer) run(ctx context.Context) {
+	defer c.client.Close()
+
+	for {
+		fetches := c.client.PollFetches(ctx)
+		if fetches.IsClientClosed() || ctx.Err() != nil {
+			return
+		}
+
+		fetches.EachError(func(topic string, partition int32, err error) {
+			if !errors.Is(err, context.Canceled) {
+				log.Error().Err(err).Str("topic", topic).Int32("partition", partition).Msg("Failed to consume from Kafka")
+			}
+		})
+
+		iter := fetches.RecordIter()
+		for !iter.Done() {
+			c.deliver(iter.Next())
+		}
+	}
+}
+
+// deliver sends the record to the subscribers of its channel
+func (c *kafkaConsumer) deliver(record *kgo.Record) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	channel := string(record.Key)
+	for sub := range c.subscribers[channel] {
+		select {
+		case sub.ch <- string(record.Value):
+		default:
+			log.Warn().Str("channel", channel).Msg("Kafka pubsub subscriber is full, dropping message")
+		}
+	}
+}
+
+// Close closes the Kafka producer
+func (k *Kafka) Close() {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if k.producer != nil {
+		k.producer.Close()
+		k.producer = nil
+	}
+}
+
+// getProducer lazily creates the client shared by publishers
+func (k *Kafka) getProducer() (*kgo.Client, error) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if k.producer != nil {
+		return k.producer, nil
+	}
+
+	producer, err := kgo.NewClient(k.clientOpts()...)
+	if err != nil {
+		return nil, err
+	}
+	k.producer = producer
+	return producer, nil
+}
+
+func (k *Kafka) clientOpts() []kgo.Opt {
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(k.Brokers...),
+		kgo.ClientID("optimizely-agent"),
+		kgo.DialTimeout(k.getConnTimeout()),
+		kgo.AllowAutoTopicCreation(),
+	}
+	if k.TLS {
+		opts = append(opts, kgo.DialTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}))
+	}
+	if k.User != "" {
+		opts = append(opts, kgo.SASL(plain.Auth{User: k.User, Pass: k.Password}.AsMechanism()))
+	}
+	return opts
+}
+
+func (k *Kafka) getTopic() string {
+	if k.Topic == "" {
+		return "optimizely-sync"
+	}
+	return k.Topic
+}
+
+func (k *Kafka) getConnTimeout() time.Duration {
+	if k.ConnTimeout <= 0 {
+		return 10 * time.Second
+	}
+	return k.ConnTimeout
+}
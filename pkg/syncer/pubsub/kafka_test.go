@@ -0,0 +1,125 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kfake"
+)
+
+func runKafkaCluster(t *testing.T, topic string) []string {
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(2, topic))
+	require.NoError(t, err)
+	t.Cleanup(cluster.Close)
+	return cluster.ListenAddrs()
+}
+
+func TestKafka_PublishSubscribe(t *testing.T) {
+	k := &Kafka{Brokers: runKafkaCluster(t, "optimizely-sync")}
+	defer k.Close()
+
+	// Messages published before the subscription are not delivered
+	require.NoError(t, k.Publish(context.Background(), "channel", "old message"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := k.Subscribe(ctx, "channel")
+	require.NoError(t, err)
+	other, err := k.Subscribe(ctx, "other-channel")
+	require.NoError(t, err)
+
+	require.NoError(t, k.Publish(ctx, "channel", "string message"))
+	require.NoError(t, k.Publish(ctx, "other-channel", "other message"))
+	require.NoError(t, k.Publish(ctx, "channel", []byte("byte message")))
+	require.NoError(t, k.Publish(ctx, "channel", map[string]string{"key": "value"}))
+
+	// Records with the same key are kept in order
+	assert.Equal(t, "string message", receive(t, ch))
+	assert.Equal(t, "byte message", receive(t, ch))
+	assert.JSONEq(t, `{"key":"value"}`, receive(t, ch))
+	assert.Equal(t, "other message", receive(t, other))
+
+	cancel()
+	assertClosed(t, ch)
+	assertClosed(t, other)
+}
+
+func TestKafka_Topic(t *testing.T) {
+	k := &Kafka{Brokers: runKafkaCluster(t, "agent"), Topic: "agent"}
+	defer k.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := k.Subscribe(ctx, "channel")
+	require.NoError(t, err)
+
+	require.NoError(t, k.Publish(ctx, "channel", "message"))
+	assert.Equal(t, "message", receive(t, ch))
+}
+
+func TestKafka_SharedConsumer(t *testing.T) {
+	brokers := runKafkaCluster(t, "optimizely-sync")
+	k1 := &Kafka{Brokers: brokers}
+	defer k1.Close()
+	k2 := &Kafka{Brokers: brokers}
+	defer k2.Close()
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ch1, err := k1.Subscribe(ctx1, "channel")
+	require.NoError(t, err)
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	ch2, err := k2.Subscribe(ctx2, "channel")
+	require.NoError(t, err)
+
+	consumers := func() int {
+		kafkaConsumers.lock.Lock()
+		defer kafkaConsumers.lock.Unlock()
+		return len(kafkaConsumers.consumers)
+	}
+	assert.Equal(t, 1, consumers())
+
+	require.NoError(t, k1.Publish(ctx1, "channel", "message"))
+	assert.Equal(t, "message", receive(t, ch1))
+	assert.Equal(t, "message", receive(t, ch2))
+
+	// The consumer is closed with its last subscriber
+	cancel1()
+	assertClosed(t, ch1)
+	assert.Equal(t, 1, consumers())
+	cancel2()
+	assertClosed(t, ch2)
+	assert.Equal(t, 0, consumers())
+}
+
+func TestKafka_PublishError(t *testing.T) {
+	k := &Kafka{Brokers: []string{"127.0.0.1:1"}, ConnTimeout: 100 * time.Millisecond}
+	defer k.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	assert.Error(t, k.Publish(ctx, "channel", "message"))
+}
+
+func TestKafka_Defaults(t *testing.T) {
+	k := &Kafka{}
+	assert.Equal(t, "optimizely-sync", k.getTopic())
+	assert.Equal(t, 10*time.Second, k.getConnTimeout())
+}
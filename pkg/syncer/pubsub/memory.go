@@ -0,0 +1,93 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package pubsub provides pubsub functionality for the agent syncer
+package pubsub
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// memoryBroker is shared by every Memory pubsub of the process
+var memoryBroker = &broker{
+	subscribers: make(map[string]map[chan string]struct{}),
+}
+
+type broker struct {
+	lock        sync.RWMutex
+	subscribers map[string]map[chan string]struct{}
+}
+
+// Memory implements message delivery between the subscribers of this Agent process, without an external
+// service. It synchronizes a single Agent instance only, for single-node deployments and tests.
+type Memory struct {
+	// BufferSize is the number of messages held for each subscriber, messages are dropped when it is full
+	BufferSize int
+}
+
+func (m *Memory) Publish(_ context.Context, channel string, message interface{}) error {
+	data, err := encodeMessage(message)
+	if err != nil {
+		return err
+	}
+
+	memoryBroker.lock.RLock()
+	defer memoryBroker.lock.RUnlock()
+
+	for ch := range memoryBroker.subscribers[channel] {
+		select {
+		case ch <- string(data):
+		default:
+			log.Warn().Str("channel", channel).Msg("Memory pubsub subscriber is full, dropping message")
+		}
+	}
+	return nil
+}
+
+func (m *Memory) Subscribe(ctx context.Context, channel string) (chan string, error) {
+	ch := make(chan string, m.getBufferSize())
+
+	memoryBroker.lock.Lock()
+	if memoryBroker.subscribers[channel] == nil {
+		memoryBroker.subscribers[channel] = make(map[chan string]struct{})
+	}
+	memoryBroker.subscribers[channel][ch] = struct{}{}
+	memoryBroker.lock.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		memoryBroker.lock.Lock()
+		defer memoryBroker.lock.Unlock()
+		delete(memoryBroker.subscribers[channel], ch)
+		if len(memoryBroker.subscribers[channel]) == 0 {
+			delete(memoryBroker.subscribers, channel)
+		}
+		close(ch)
+	}()
+
+	return ch, nil
+}
+
+func (m *Memory) getBufferSize() int {
+	if m.BufferSize <= 0 {
+		return 100
+	}
+	return m.BufferSize
+}
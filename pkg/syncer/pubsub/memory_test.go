@@ -0,0 +1,82 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_PublishSubscribe(t *testing.T) {
+	publisher := &Memory{}
+	subscriber := &Memory{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := subscriber.Subscribe(ctx, "memory-channel")
+	require.NoError(t, err)
+	other, err := subscriber.Subscribe(ctx, "memory-channel")
+	require.NoError(t, err)
+
+	require.NoError(t, publisher.Publish(ctx, "memory-channel", "string message"))
+	require.NoError(t, publisher.Publish(ctx, "memory-channel", []byte("byte message")))
+	require.NoError(t, publisher.Publish(ctx, "memory-channel", map[string]string{"key": "value"}))
+	require.NoError(t, publisher.Publish(ctx, "memory-other-channel", "ignored"))
+
+	for _, c := range []chan string{ch, other} {
+		assert.Equal(t, "string message", receive(t, c))
+		assert.Equal(t, "byte message", receive(t, c))
+		assert.JSONEq(t, `{"key":"value"}`, receive(t, c))
+	}
+
+	cancel()
+	assertClosed(t, ch)
+	assertClosed(t, other)
+	assert.Eventually(t, func() bool {
+		memoryBroker.lock.RLock()
+		defer memoryBroker.lock.RUnlock()
+		_, ok := memoryBroker.subscribers["memory-channel"]
+		return !ok
+	}, time.Second, time.Millisecond)
+
+	// Publishing without subscribers is not an error
+	assert.NoError(t, publisher.Publish(context.Background(), "memory-channel", "message"))
+}
+
+func TestMemory_DropsWhenBufferIsFull(t *testing.T) {
+	m := &Memory{BufferSize: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := m.Subscribe(ctx, "memory-full-channel")
+	require.NoError(t, err)
+
+	require.NoError(t, m.Publish(ctx, "memory-full-channel", "first"))
+	require.NoError(t, m.Publish(ctx, "memory-full-channel", "dropped"))
+	require.NoError(t, m.Publish(ctx, "memory-full-channel", "dropped"))
+
+	assert.Equal(t, "first", receive(t, ch))
+	assert.Empty(t, ch)
+}
+
+func TestMemory_Defaults(t *testing.T) {
+	assert.Equal(t, 100, (&Memory{}).getBufferSize())
+	assert.Equal(t, 5, (&Memory{BufferSize: 5}).getBufferSize())
+}
@@ -0,0 +1,252 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package pubsub provides pubsub functionality for the agent syncer
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/rs/zerolog/log"
+)
+
+// NATS implements message delivery using NATS. Messages are published to core NATS subjects (fire-and-forget),
+// or to a JetStream stream when JetStream is enabled so that publishes are acknowledged and subscribers
+// resume from their last delivered message after a reconnect.
+type NATS struct {
+	URL      string
+	User     string
+	Password string
+	// SubjectPrefix is prepended to the channel name to build the subject
+	SubjectPrefix string
+	ConnTimeout   time.Duration
+	// JetStream configuration
+	JetStream bool
+	Stream    string
+	MaxAge    time.Duration
+
+	lock sync.Mutex
+	conn *nats.Conn
+	js   jetstream.JetStream
+}
+
+func (n *NATS) Publish(ctx context.Context, channel string, message interface{}) error {
+	data, err := encodeMessage(message)
+	if err != nil {
+		return err
+	}
+
+	conn, js, err := n.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	subject := n.getSubject(channel)
+	if js != nil {
+		_, err = js.Publish(ctx, subject, data)
+		return err
+	}
+	return conn.Publish(subject, data)
+}
+
+func (n *NATS) Subscribe(ctx context.Context, channel string) (chan string, error) {
+	conn, js, err := n.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := n.getSubject(channel)
+	if js != nil {
+		return n.subscribeJetStream(ctx, js, subject)
+	}
+
+	msgs := make(chan *nats.Msg, 64)
+	sub, err := conn.ChanSubscribe(subject, msgs)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		defer func() {
+			if err := sub.Unsubscribe(); err != nil && !errors.Is(err, nats.ErrConnectionClosed) {
+				log.Warn().Err(err).Str("subject", subject).Msg("Failed to unsubscribe from NATS subject")
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-msgs:
+				select {
+				case ch <- string(msg.Data):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+func (n *NATS) subscribeJetStream(ctx context.Context, js jetstream.JetStream, subject string) (chan string, error) {
+	// An ordered consumer delivers every new message to each subscriber and recreates itself from the
+	// last delivered sequence when the connection is lost, so no message is skipped
+	consumer, err := js.OrderedConsumer(ctx, n.getStream(), jetstream.OrderedConsumerConfig{
+		FilterSubjects: []string{subject},
+		DeliverPolicy:  jetstream.DeliverNewPolicy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := consumer.Messages()
+	if err != nil {
+		return nil, err
+	}
+
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-stop:
+		}
+		iter.Stop()
+	}()
+
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		defer close(stop)
+
+		for {
+			msg, err := iter.Next()
+			if err != nil {
+				if !errors.Is(err, jetstream.ErrMsgIteratorClosed) {
+					log.Error().Err(err).Str("subject", subject).Msg("Failed to consume from NATS JetStream")
+				}
+				return
+			}
+
+			select {
+			case ch <- string(msg.Data()):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// connect lazily opens the connection shared by publishers and subscribers, creating the stream if needed
+func (n *NATS) connect(ctx context.Context) (*nats.Conn, jetstream.JetStream, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.conn != nil {
+		return n.conn, n.js, nil
+	}
+
+	opts := []nats.Option{
+		nats.Name("optimizely-agent"),
+		nats.Timeout(n.getConnTimeout()),
+		nats.MaxReconnects(-1),
+	}
+	if n.User != "" {
+		opts = append(opts, nats.UserInfo(n.User, n.Password))
+	} else if n.Password != "" {
+		opts = append(opts, nats.Token(n.Password))
+	}
+
+	conn, err := nats.Connect(n.getURL(), opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var js jetstream.JetStream
+	if n.JetStream {
+		if js, err = jetstream.New(conn); err == nil {
+			_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+				Name:     n.getStream(),
+				Subjects: []string{n.getSubject(">")},
+				MaxAge:   n.getMaxAge(),
+				Storage:  jetstream.FileStorage,
+			})
+		}
+		if err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+	}
+
+	n.conn, n.js = conn, js
+	return conn, js, nil
+}
+
+// Close closes the NATS connection
+func (n *NATS) Close() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.conn != nil {
+		n.conn.Close()
+		n.conn, n.js = nil, nil
+	}
+}
+
+func (n *NATS) getSubject(channel string) string {
+	if n.SubjectPrefix == "" {
+		return "optimizely." + channel
+	}
+	return n.SubjectPrefix + "." + channel
+}
+
+func (n *NATS) getURL() string {
+	if n.URL == "" {
+		return nats.DefaultURL
+	}
+	return n.URL
+}
+
+func (n *NATS) getStream() string {
+	if n.Stream == "" {
+		return "OPTIMIZELY_SYNC"
+	}
+	return n.Stream
+}
+
+func (n *NATS) getMaxAge() time.Duration {
+	if n.MaxAge <= 0 {
+		return time.Hour
+	}
+	return n.MaxAge
+}
+
+func (n *NATS) getConnTimeout() time.Duration {
+	if n.ConnTimeout <= 0 {
+		return 10 * time.Second
+	}
+	return n.ConnTimeout
+}
@@ -0,0 +1,182 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runNATSServer(t *testing.T, opts *server.Options) *server.Server {
+	opts.Host = "127.0.0.1"
+	opts.Port = server.RANDOM_PORT
+	opts.NoLog = true
+	opts.NoSigs = true
+	if opts.JetStream {
+		opts.StoreDir = t.TempDir()
+	}
+
+	s, err := server.NewServer(opts)
+	require.NoError(t, err)
+	go s.Start()
+	require.True(t, s.ReadyForConnections(5*time.Second))
+	t.Cleanup(s.Shutdown)
+	return s
+}
+
+func receive(t *testing.T, ch chan string) string {
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for message")
+		return ""
+	}
+}
+
+func assertClosed(t *testing.T, ch chan string) {
+	select {
+	case _, ok := <-ch:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for channel to close")
+	}
+}
+
+func TestNATS_PublishSubscribe(t *testing.T) {
+	s := runNATSServer(t, &server.Options{})
+	n := &NATS{URL: s.ClientURL()}
+	defer n.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := n.Subscribe(ctx, "channel")
+	require.NoError(t, err)
+	other, err := n.Subscribe(ctx, "other-channel")
+	require.NoError(t, err)
+
+	require.NoError(t, n.Publish(ctx, "channel", "string message"))
+	require.NoError(t, n.Publish(ctx, "channel", []byte("byte message")))
+	require.NoError(t, n.Publish(ctx, "channel", map[string]string{"key": "value"}))
+	require.NoError(t, n.Publish(ctx, "other-channel", "other message"))
+
+	assert.Equal(t, "string message", receive(t, ch))
+	assert.Equal(t, "byte message", receive(t, ch))
+	assert.JSONEq(t, `{"key":"value"}`, receive(t, ch))
+	assert.Equal(t, "other message", receive(t, other))
+
+	cancel()
+	assertClosed(t, ch)
+	assertClosed(t, other)
+}
+
+func TestNATS_SubjectPrefix(t *testing.T) {
+	s := runNATSServer(t, &server.Options{})
+	prefixed := &NATS{URL: s.ClientURL(), SubjectPrefix: "agent"}
+	defer prefixed.Close()
+	unprefixed := &NATS{URL: s.ClientURL()}
+	defer unprefixed.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := prefixed.Subscribe(ctx, "channel")
+	require.NoError(t, err)
+
+	require.NoError(t, unprefixed.Publish(ctx, "channel", "ignored"))
+	require.NoError(t, prefixed.Publish(ctx, "channel", "message"))
+	assert.Equal(t, "message", receive(t, ch))
+}
+
+func TestNATS_TokenAuthentication(t *testing.T) {
+	s := runNATSServer(t, &server.Options{Authorization: "secret"})
+
+	n := &NATS{URL: s.ClientURL(), Password: "wrong"}
+	assert.Error(t, n.Publish(context.Background(), "channel", "message"))
+
+	n = &NATS{URL: s.ClientURL(), Password: "secret"}
+	defer n.Close()
+	assert.NoError(t, n.Publish(context.Background(), "channel", "message"))
+}
+
+func TestNATS_UserAuthentication(t *testing.T) {
+	s := runNATSServer(t, &server.Options{Username: "agent", Password: "secret"})
+
+	n := &NATS{URL: s.ClientURL(), User: "agent", Password: "secret"}
+	defer n.Close()
+	assert.NoError(t, n.Publish(context.Background(), "channel", "message"))
+}
+
+func TestNATS_ConnectionError(t *testing.T) {
+	n := &NATS{URL: "nats://127.0.0.1:1", ConnTimeout: 100 * time.Millisecond}
+
+	assert.Error(t, n.Publish(context.Background(), "channel", "message"))
+	_, err := n.Subscribe(context.Background(), "channel")
+	assert.Error(t, err)
+}
+
+func TestNATS_JetStream(t *testing.T) {
+	s := runNATSServer(t, &server.Options{JetStream: true})
+	n := &NATS{URL: s.ClientURL(), JetStream: true, Stream: "TEST", MaxAge: time.Minute}
+	defer n.Close()
+
+	// Messages published before the subscription are not delivered
+	require.NoError(t, n.Publish(context.Background(), "channel", "old message"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := n.Subscribe(ctx, "channel")
+	require.NoError(t, err)
+	other, err := n.Subscribe(ctx, "other-channel")
+	require.NoError(t, err)
+
+	require.NoError(t, n.Publish(ctx, "channel", "first"))
+	require.NoError(t, n.Publish(ctx, "other-channel", "other"))
+	require.NoError(t, n.Publish(ctx, "channel", []byte("second")))
+
+	assert.Equal(t, "first", receive(t, ch))
+	assert.Equal(t, "second", receive(t, ch))
+	assert.Equal(t, "other", receive(t, other))
+
+	stream, err := n.js.Stream(ctx, "TEST")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"optimizely.>"}, stream.CachedInfo().Config.Subjects)
+	assert.Equal(t, time.Minute, stream.CachedInfo().Config.MaxAge)
+
+	cancel()
+	assertClosed(t, ch)
+	assertClosed(t, other)
+}
+
+func TestNATS_JetStreamNotEnabled(t *testing.T) {
+	s := runNATSServer(t, &server.Options{})
+	n := &NATS{URL: s.ClientURL(), JetStream: true}
+
+	assert.Error(t, n.Publish(context.Background(), "channel", "message"))
+	assert.Nil(t, n.conn)
+}
+
+func TestNATS_Defaults(t *testing.T) {
+	n := &NATS{}
+	assert.Equal(t, "nats://127.0.0.1:4222", n.getURL())
+	assert.Equal(t, "optimizely.channel", n.getSubject("channel"))
+	assert.Equal(t, "OPTIMIZELY_SYNC", n.getStream())
+	assert.Equal(t, time.Hour, n.getMaxAge())
+	assert.Equal(t, 10*time.Second, n.getConnTimeout())
+}
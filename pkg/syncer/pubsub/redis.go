@@ -20,32 +20,26 @@ package pubsub
 import (
 	"context"
 
-	"github.com/go-redis/redis/v8"
+	"github.com/optimizely/agent/pkg/utils/redisclient"
 )
 
 type Redis struct {
 	Host     string
 	Password string
 	Database int
+	// Config holds the mode, TLS, pool and timeout settings of the connection
+	Config redisclient.Config
 }
 
 func (r *Redis) Publish(ctx context.Context, channel string, message interface{}) error {
-	client := redis.NewClient(&redis.Options{
-		Addr:     r.Host,
-		Password: r.Password,
-		DB:       r.Database,
-	})
+	client := redisclient.NewClient(r.Host, r.Password, r.Database, r.Config)
 	defer client.Close()
 
 	return client.Publish(ctx, channel, message).Err()
 }
 
 func (r *Redis) Subscribe(ctx context.Context, channel string) (chan string, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:     r.Host,
-		Password: r.Password,
-		DB:       r.Database,
-	})
+	client := redisclient.NewClient(r.Host, r.Password, r.Database, r.Config)
 
 	// Subscribe to a Redis channel
 	pubsub := client.Subscribe(ctx, channel)
@@ -30,6 +30,7 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/optimizely/agent/pkg/metrics"
+	"github.com/optimizely/agent/pkg/utils/redisclient"
 )
 
 // RedisStreams implements persistent message delivery using Redis Streams
@@ -37,6 +38,8 @@ type RedisStreams struct {
 	Host     string
 	Password string
 	Database int
+	// Config holds the mode, TLS and pool settings of the connection
+	Config redisclient.Config
 	// Stream configuration
 	MaxLen        int64
 	ConsumerGroup string
@@ -88,7 +91,7 @@ func (r *RedisStreams) Publish(ctx context.Context, channel string, message inte
 		args.Approx = true // Use approximate trimming for better performance
 	}
 
-	return r.executeWithRetry(ctx, func(client *redis.Client) error {
+	return r.executeWithRetry(ctx, func(client redis.UniversalClient) error {
 		return client.XAdd(ctx, args).Err()
 	})
 }
@@ -111,7 +114,7 @@ func (r *RedisStreams) Subscribe(ctx context.Context, channel string) (chan stri
 		defer flushTicker.Stop()
 
 		var batch []string
-		var client *redis.Client
+		var client redis.UniversalClient
 		var lastReconnect time.Time
 		reconnectDelay := 1 * time.Second
 		maxReconnectDelay := 30 * time.Second
@@ -330,21 +333,21 @@ func (r *RedisStreams) getConnTimeout() time.Duration {
 	return r.ConnTimeout
 }
 
-// createClient creates a new Redis client with configured timeouts
-func (r *RedisStreams) createClient() *redis.Client {
-	return redis.NewClient(&redis.Options{
-		Addr:         r.Host,
-		Password:     r.Password,
-		DB:           r.Database,
-		DialTimeout:  r.getConnTimeout(),
-		ReadTimeout:  r.getConnTimeout(),
-		WriteTimeout: r.getConnTimeout(),
-		PoolTimeout:  r.getConnTimeout(),
-	})
+// createClient creates a new Redis client with configured timeouts, ConnTimeout applies to the timeouts not set by Config
+func (r *RedisStreams) createClient() redis.UniversalClient {
+	conf := r.Config
+	for _, timeout := range []*time.Duration{
+		&conf.DialTimeout.Duration, &conf.ReadTimeout.Duration, &conf.WriteTimeout.Duration, &conf.PoolTimeout.Duration,
+	} {
+		if *timeout == 0 {
+			*timeout = r.getConnTimeout()
+		}
+	}
+	return redisclient.NewClient(r.Host, r.Password, r.Database, conf)
 }
 
 // executeWithRetry executes a Redis operation with retry logic
-func (r *RedisStreams) executeWithRetry(ctx context.Context, operation func(client *redis.Client) error) error {
+func (r *RedisStreams) executeWithRetry(ctx context.Context, operation func(client redis.UniversalClient) error) error {
 	start := time.Now()
 	maxRetries := r.getMaxRetries()
 	retryDelay := r.getRetryDelay()
@@ -399,7 +402,7 @@ func (r *RedisStreams) executeWithRetry(ctx context.Context, operation func(clie
 }
 
 // createConsumerGroupWithRetry creates a consumer group with retry logic
-func (r *RedisStreams) createConsumerGroupWithRetry(ctx context.Context, client *redis.Client, streamName, consumerGroup string) error {
+func (r *RedisStreams) createConsumerGroupWithRetry(ctx context.Context, client redis.UniversalClient, streamName, consumerGroup string) error {
 	maxRetries := r.getMaxRetries()
 	retryDelay := r.getRetryDelay()
 	maxRetryDelay := r.getMaxRetryDelay()
@@ -436,7 +439,7 @@ func (r *RedisStreams) createConsumerGroupWithRetry(ctx context.Context, client
 }
 
 // acknowledgeMessage acknowledges a message with retry logic
-func (r *RedisStreams) acknowledgeMessage(ctx context.Context, client *redis.Client, streamName, consumerGroup, messageID string) error {
+func (r *RedisStreams) acknowledgeMessage(ctx context.Context, client redis.UniversalClient, streamName, consumerGroup, messageID string) error {
 	maxRetries := 2 // Fewer retries for ACK operations
 	retryDelay := 50 * time.Millisecond
 
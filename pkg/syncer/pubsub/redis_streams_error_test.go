@@ -341,7 +341,7 @@ func TestRedisStreams_ExecuteWithRetry_NonRetryableError(t *testing.T) {
 	ctx := context.Background()
 
 	// Simulate a non-retryable error
-	operation := func(client *redis.Client) error {
+	operation := func(client redis.UniversalClient) error {
 		return errors.New("ERR syntax error") // Non-retryable
 	}
 
@@ -360,7 +360,7 @@ func TestRedisStreams_ExecuteWithRetry_SuccessAfterRetries(t *testing.T) {
 	ctx := context.Background()
 
 	attemptCount := 0
-	operation := func(client *redis.Client) error {
+	operation := func(client redis.UniversalClient) error {
 		attemptCount++
 		if attemptCount < 3 {
 			return errors.New("connection refused") // Retryable
@@ -383,7 +383,7 @@ func TestRedisStreams_ExecuteWithRetry_ExhaustRetries(t *testing.T) {
 	ctx := context.Background()
 
 	attemptCount := 0
-	operation := func(client *redis.Client) error {
+	operation := func(client redis.UniversalClient) error {
 		attemptCount++
 		return errors.New("connection refused") // Always retryable error
 	}
@@ -438,7 +438,7 @@ func TestRedisStreams_ErrorHandling_ContextCancellation(t *testing.T) {
 		cancel()
 	}()
 
-	operation := func(client *redis.Client) error {
+	operation := func(client redis.UniversalClient) error {
 		return errors.New("connection refused") // Retryable error
 	}
 
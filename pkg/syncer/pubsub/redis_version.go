@@ -37,7 +37,7 @@ const (
 
 // detectRedisMajorVersion attempts to detect the major version of Redis
 // Returns the major version number (e.g., 6 for Redis 6.2.5) or 0 on error
-func detectRedisMajorVersion(client *redis.Client) (int, error) {
+func detectRedisMajorVersion(client redis.UniversalClient) (int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), RedisVersionCheckTimeout)
 	defer cancel()
 
@@ -81,7 +81,7 @@ func detectRedisMajorVersion(client *redis.Client) (int, error) {
 // SupportsRedisStreams checks if the connected Redis instance supports Streams
 // Returns true if Redis >= 5.0, false otherwise
 // Falls back to false on any error (safe default)
-func SupportsRedisStreams(client *redis.Client) bool {
+func SupportsRedisStreams(client redis.UniversalClient) bool {
 	majorVersion, err := detectRedisMajorVersion(client)
 	if err != nil {
 		// Detection failed - log warning and return false (safe fallback)
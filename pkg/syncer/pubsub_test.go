@@ -999,3 +999,295 @@ func TestGetPubSubRedisStreams_ErrorPaths(t *testing.T) {
 		})
 	}
 }
+
+func TestNewPubSub_NATSAndKafka(t *testing.T) {
+	conf := config.SyncConfig{
+		Pubsub: map[string]interface{}{
+			"nats": map[string]interface{}{
+				"url": "nats://localhost:4222",
+			},
+			"kafka": map[string]interface{}{
+				"brokers": []interface{}{"localhost:9092"},
+			},
+		},
+		Notification: config.FeatureSyncConfig{
+			Default: "nats",
+			Enable:  true,
+		},
+		Datafile: config.FeatureSyncConfig{
+			Default: "kafka",
+			Enable:  true,
+		},
+	}
+
+	got, err := newPubSub(conf, SyncFeatureFlagNotification)
+	if err != nil {
+		t.Fatalf("newPubSub() error = %v", err)
+	}
+	if _, ok := got.(*pubsub.NATS); !ok {
+		t.Errorf("newPubSub() = %T, want *pubsub.NATS", got)
+	}
+
+	got, err = newPubSub(conf, SyncFeatureFlagDatafile)
+	if err != nil {
+		t.Fatalf("newPubSub() error = %v", err)
+	}
+	if _, ok := got.(*pubsub.Kafka); !ok {
+		t.Errorf("newPubSub() = %T, want *pubsub.Kafka", got)
+	}
+}
+
+func TestGetPubSubNATS_DirectCall(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    config.SyncConfig
+		want    PubSub
+		wantErr bool
+	}{
+		{
+			name: "Valid NATS config with defaults",
+			conf: config.SyncConfig{
+				Pubsub: map[string]interface{}{
+					"nats": map[string]interface{}{
+						"url": "nats://localhost:4222",
+					},
+				},
+			},
+			want: &pubsub.NATS{
+				URL:         "nats://localhost:4222",
+				ConnTimeout: 10 * time.Second,
+				MaxAge:      time.Hour,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Valid JetStream config",
+			conf: config.SyncConfig{
+				Pubsub: map[string]interface{}{
+					"nats": map[string]interface{}{
+						"url":                "nats://localhost:4222",
+						"user":               "agent",
+						"auth_token":         "secret",
+						"subject_prefix":     "agent",
+						"connection_timeout": "5s",
+						"jetstream":          true,
+						"stream":             "AGENT",
+						"max_age":            "30m",
+					},
+				},
+			},
+			want: &pubsub.NATS{
+				URL:           "nats://localhost:4222",
+				User:          "agent",
+				Password:      "secret",
+				SubjectPrefix: "agent",
+				ConnTimeout:   5 * time.Second,
+				JetStream:     true,
+				Stream:        "AGENT",
+				MaxAge:        30 * time.Minute,
+			},
+			wantErr: false,
+		},
+		{
+			name: "NATS config not found",
+			conf: config.SyncConfig{
+				Pubsub: map[string]interface{}{},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "NATS config not valid",
+			conf: config.SyncConfig{
+				Pubsub: map[string]interface{}{
+					"nats": "invalid",
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "NATS url not found",
+			conf: config.SyncConfig{
+				Pubsub: map[string]interface{}{
+					"nats": map[string]interface{}{},
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "NATS url invalid type",
+			conf: config.SyncConfig{
+				Pubsub: map[string]interface{}{
+					"nats": map[string]interface{}{
+						"url": 4222,
+					},
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getPubSubNATS(tt.conf)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getPubSubNATS() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getPubSubNATS() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPubSubKafka_DirectCall(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    config.SyncConfig
+		want    PubSub
+		wantErr bool
+	}{
+		{
+			name: "Valid Kafka config with defaults",
+			conf: config.SyncConfig{
+				Pubsub: map[string]interface{}{
+					"kafka": map[string]interface{}{
+						"brokers": []interface{}{"broker1:9092", "broker2:9092"},
+					},
+				},
+			},
+			want: &pubsub.Kafka{
+				Brokers:     []string{"broker1:9092", "broker2:9092"},
+				ConnTimeout: 10 * time.Second,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Valid Kafka config with brokers as string",
+			conf: config.SyncConfig{
+				Pubsub: map[string]interface{}{
+					"kafka": map[string]interface{}{
+						"brokers":            "broker1:9092, broker2:9092",
+						"topic":              "agent",
+						"user":               "agent",
+						"password":           "secret",
+						"tls":                true,
+						"connection_timeout": "5s",
+					},
+				},
+			},
+			want: &pubsub.Kafka{
+				Brokers:     []string{"broker1:9092", "broker2:9092"},
+				Topic:       "agent",
+				User:        "agent",
+				Password:    "secret",
+				TLS:         true,
+				ConnTimeout: 5 * time.Second,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Kafka config not found",
+			conf: config.SyncConfig{
+				Pubsub: map[string]interface{}{},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Kafka config not valid",
+			conf: config.SyncConfig{
+				Pubsub: map[string]interface{}{
+					"kafka": "invalid",
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Kafka brokers not found",
+			conf: config.SyncConfig{
+				Pubsub: map[string]interface{}{
+					"kafka": map[string]interface{}{
+						"brokers": "",
+					},
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getPubSubKafka(tt.conf)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getPubSubKafka() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getPubSubKafka() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetStringsFromConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		want   []string
+	}{
+		{
+			name:   "List of strings",
+			config: map[string]interface{}{"test_key": []string{"a", "b"}},
+			want:   []string{"a", "b"},
+		},
+		{
+			name:   "List of interfaces",
+			config: map[string]interface{}{"test_key": []interface{}{"a", 1, "b"}},
+			want:   []string{"a", "b"},
+		},
+		{
+			name:   "Comma separated string",
+			config: map[string]interface{}{"test_key": "a, b,,"},
+			want:   []string{"a", "b"},
+		},
+		{
+			name:   "Missing key returns empty list",
+			config: map[string]interface{}{},
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getStringsFromConfig(tt.config, "test_key")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getStringsFromConfig() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPubSubMemory(t *testing.T) {
+	got := getPubSubMemory(config.SyncConfig{})
+	if !reflect.DeepEqual(got, &pubsub.Memory{BufferSize: 100}) {
+		t.Errorf("getPubSubMemory() = %v, want default buffer size", got)
+	}
+
+	got = getPubSubMemory(config.SyncConfig{
+		Pubsub: map[string]interface{}{
+			"memory": map[string]interface{}{
+				"buffer_size": 10,
+			},
+		},
+	})
+	if !reflect.DeepEqual(got, &pubsub.Memory{BufferSize: 10}) {
+		t.Errorf("getPubSubMemory() = %v, want configured buffer size", got)
+	}
+}
@@ -483,3 +483,41 @@ func TestGetChannelForSDKKey(t *testing.T) {
 	expected := "test-channel-sdk-123"
 	assert.Equal(t, expected, result)
 }
+
+func TestSyncedNotificationCenterWithMemoryPubSub(t *testing.T) {
+	conf := config.SyncConfig{
+		Notification: config.FeatureSyncConfig{
+			Default: "memory",
+			Enable:  true,
+		},
+	}
+	nc, err := NewSyncedNotificationCenter(context.Background(), "memory-sdk-key", conf)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := nc.Subscribe(ctx, GetChannelForSDKKey(PubSubDefaultChan, "memory-sdk-key"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, nc.Send(notification.Track, map[string]string{"eventKey": "event"}))
+	assert.JSONEq(t, `{"type":"track","message":{"eventKey":"event"}}`, <-ch)
+}
+
+func TestDatafileSyncerWithMemoryPubSub(t *testing.T) {
+	conf := config.SyncConfig{
+		Datafile: config.FeatureSyncConfig{
+			Default: "memory",
+			Enable:  true,
+		},
+	}
+	syncer, err := NewDatafileSyncer(conf)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := syncer.Subscribe(ctx, GetDatafileSyncChannel())
+	assert.NoError(t, err)
+
+	assert.NoError(t, syncer.Sync(ctx, GetDatafileSyncChannel(), "memory-sdk-key"))
+	assert.Equal(t, "memory-sdk-key", <-ch)
+}
@@ -0,0 +1,267 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package redisclient creates the redis clients of the redis integrations from a connection configuration shared by
+// all of them
+package redisclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/rs/zerolog/log"
+
+	"github.com/optimizely/agent/pkg/utils/redisauth"
+	"github.com/optimizely/agent/plugins/utils"
+)
+
+// Connection modes
+const (
+	ModeSingle   = "single"
+	ModeSentinel = "sentinel"
+	ModeCluster  = "cluster"
+)
+
+// Config holds the connection settings of a redis integration besides its host, password and database. The settings
+// that are not set fall back to the shared configuration of SetDefaults.
+type Config struct {
+	// Mode is "single", the default, "sentinel" or "cluster"
+	Mode string `json:"mode"`
+	// Addresses are the cluster nodes or the sentinels, the host of the integration is used when they are not set
+	Addresses []string `json:"addresses"`
+	// MasterName is the name of the master monitored by the sentinels
+	MasterName string `json:"masterName"`
+	// Username is the ACL username, the password of the integration is the password of that user
+	Username         string `json:"username"`
+	SentinelUsername string `json:"sentinelUsername"`
+	// SentinelPassword falls back to the REDIS_SENTINEL_PASSWORD environment variable
+	SentinelPassword string     `json:"sentinelPassword"`
+	TLS              *TLSConfig `json:"tls"`
+
+	PoolSize     int            `json:"poolSize"`
+	MinIdleConns int            `json:"minIdleConns"`
+	DialTimeout  utils.Duration `json:"dialTimeout"`
+	ReadTimeout  utils.Duration `json:"readTimeout"`
+	WriteTimeout utils.Duration `json:"writeTimeout"`
+	PoolTimeout  utils.Duration `json:"poolTimeout"`
+}
+
+// TLSConfig enables TLS, the certificate of the server is verified against the system roots unless CAFile is set
+type TLSConfig struct {
+	Enable bool `json:"enable"`
+	// CertFile and KeyFile hold the client certificate, when the server requires one
+	CertFile   string `json:"certFile"`
+	KeyFile    string `json:"keyFile"`
+	CAFile     string `json:"caFile"`
+	ServerName string `json:"serverName"`
+	// InsecureSkipVerify disables the verification of the certificate of the server, for testing only
+	InsecureSkipVerify bool `json:"insecureSkipVerify"`
+}
+
+var defaults = struct {
+	sync.RWMutex
+	conf     Config
+	password string
+}{}
+
+// ParseConfig parses the connection settings of a configuration map
+func ParseConfig(conf map[string]interface{}) (Config, error) {
+	var c Config
+	data, err := json.Marshal(conf)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
+
+// SetDefaults sets the shared connection settings, along with the password used by the integrations that have none,
+// which falls back to the REDIS_PASSWORD environment variable
+func SetDefaults(conf map[string]interface{}) error {
+	c, err := ParseConfig(conf)
+	if err != nil {
+		return err
+	}
+	defaults.Lock()
+	defer defaults.Unlock()
+	defaults.conf = c
+	defaults.password = redisauth.GetPassword(conf, "REDIS_PASSWORD")
+	return nil
+}
+
+// NewClient returns the client of a redis integration. The addresses of conf take precedence over host, which takes
+// precedence over the shared addresses. A client that can not be configured, for instance because its certificates
+// can not be loaded, is returned anyway and fails every command with the configuration error.
+func NewClient(host, password string, database int, conf Config) redis.UniversalClient {
+	addrs := conf.Addresses
+	if len(addrs) == 0 && host != "" {
+		addrs = []string{host}
+	}
+
+	defaults.RLock()
+	conf = conf.withDefaults(defaults.conf)
+	if password == "" {
+		password = defaults.password
+	}
+	defaults.RUnlock()
+
+	if len(addrs) == 0 {
+		addrs = conf.Addresses
+	}
+	if conf.SentinelPassword == "" {
+		conf.SentinelPassword = os.Getenv("REDIS_SENTINEL_PASSWORD")
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:            addrs,
+		DB:               database,
+		Username:         conf.Username,
+		Password:         password,
+		SentinelUsername: conf.SentinelUsername,
+		SentinelPassword: conf.SentinelPassword,
+		MasterName:       conf.MasterName,
+		PoolSize:         conf.PoolSize,
+		MinIdleConns:     conf.MinIdleConns,
+		DialTimeout:      conf.DialTimeout.Duration,
+		ReadTimeout:      conf.ReadTimeout.Duration,
+		WriteTimeout:     conf.WriteTimeout.Duration,
+		PoolTimeout:      conf.PoolTimeout.Duration,
+	}
+
+	var err error
+	if opts.TLSConfig, err = conf.TLS.config(); err == nil {
+		switch conf.Mode {
+		case "", ModeSingle:
+		case ModeSentinel:
+			if conf.MasterName == "" {
+				err = fmt.Errorf("redis sentinel mode requires a masterName")
+			}
+		case ModeCluster:
+			if database != 0 {
+				log.Warn().Int("database", database).Msg("Redis cluster only supports database 0, ignoring the database")
+			}
+		default:
+			err = fmt.Errorf("unknown redis mode %q", conf.Mode)
+		}
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to configure the redis client")
+		opts.Dialer = func(context.Context, string, string) (net.Conn, error) {
+			return nil, err
+		}
+		return redis.NewClient(opts.Simple())
+	}
+
+	switch conf.Mode {
+	case ModeSentinel:
+		return redis.NewFailoverClient(opts.Failover())
+	case ModeCluster:
+		return redis.NewClusterClient(opts.Cluster())
+	default:
+		return redis.NewClient(opts.Simple())
+	}
+}
+
+// ForEachShard calls fn with the client of every master of a cluster, or with client itself otherwise, for commands
+// such as SCAN and FLUSHDB which only apply to the node they are sent to
+func ForEachShard(ctx context.Context, client redis.UniversalClient, fn func(ctx context.Context, client redis.UniversalClient) error) error {
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+			return fn(ctx, master)
+		})
+	}
+	return fn(ctx, client)
+}
+
+func (c Config) withDefaults(d Config) Config {
+	if c.Mode == "" {
+		c.Mode = d.Mode
+	}
+	if len(c.Addresses) == 0 {
+		c.Addresses = d.Addresses
+	}
+	if c.MasterName == "" {
+		c.MasterName = d.MasterName
+	}
+	if c.Username == "" {
+		c.Username = d.Username
+	}
+	if c.SentinelUsername == "" {
+		c.SentinelUsername = d.SentinelUsername
+	}
+	if c.SentinelPassword == "" {
+		c.SentinelPassword = d.SentinelPassword
+	}
+	if c.TLS == nil {
+		c.TLS = d.TLS
+	}
+	if c.PoolSize == 0 {
+		c.PoolSize = d.PoolSize
+	}
+	if c.MinIdleConns == 0 {
+		c.MinIdleConns = d.MinIdleConns
+	}
+	if c.DialTimeout.Duration == 0 {
+		c.DialTimeout = d.DialTimeout
+	}
+	if c.ReadTimeout.Duration == 0 {
+		c.ReadTimeout = d.ReadTimeout
+	}
+	if c.WriteTimeout.Duration == 0 {
+		c.WriteTimeout = d.WriteTimeout
+	}
+	if c.PoolTimeout.Duration == 0 {
+		c.PoolTimeout = d.PoolTimeout
+	}
+	return c
+}
+
+func (t *TLSConfig) config() (*tls.Config, error) {
+	if t == nil || !t.Enable {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", t.CAFile)
+		}
+	}
+	return tlsConfig, nil
+}
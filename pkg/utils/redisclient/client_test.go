@@ -0,0 +1,203 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package redisclient
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optimizely/agent/plugins/utils"
+)
+
+func resetDefaults(t *testing.T) {
+	t.Cleanup(func() {
+		defaults.Lock()
+		defaults.conf = Config{}
+		defaults.password = ""
+		defaults.Unlock()
+	})
+}
+
+func TestParseConfig(t *testing.T) {
+	conf, err := ParseConfig(map[string]interface{}{
+		"mode":         "sentinel",
+		"addresses":    []string{"sentinel-0:26379", "sentinel-1:26379"},
+		"masterName":   "mymaster",
+		"username":     "agent",
+		"tls":          map[string]interface{}{"enable": true, "serverName": "redis.local"},
+		"poolSize":     20,
+		"minIdleConns": 2,
+		"dialTimeout":  "2s",
+		"readTimeout":  "500ms",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, ModeSentinel, conf.Mode)
+	assert.Equal(t, []string{"sentinel-0:26379", "sentinel-1:26379"}, conf.Addresses)
+	assert.Equal(t, "mymaster", conf.MasterName)
+	assert.Equal(t, "agent", conf.Username)
+	assert.Equal(t, &TLSConfig{Enable: true, ServerName: "redis.local"}, conf.TLS)
+	assert.Equal(t, 20, conf.PoolSize)
+	assert.Equal(t, 2, conf.MinIdleConns)
+	assert.Equal(t, 2*time.Second, conf.DialTimeout.Duration)
+	assert.Equal(t, 500*time.Millisecond, conf.ReadTimeout.Duration)
+
+	_, err = ParseConfig(map[string]interface{}{"dialTimeout": "invalid"})
+	assert.Error(t, err)
+}
+
+func TestNewClientSingle(t *testing.T) {
+	client := NewClient("localhost:6379", "pass", 2, Config{PoolSize: 5})
+	defer client.Close()
+
+	c, ok := client.(*redis.Client)
+	require.True(t, ok)
+	assert.Equal(t, "localhost:6379", c.Options().Addr)
+	assert.Equal(t, "pass", c.Options().Password)
+	assert.Equal(t, 2, c.Options().DB)
+	assert.Equal(t, 5, c.Options().PoolSize)
+	assert.Nil(t, c.Options().TLSConfig)
+}
+
+func TestNewClientDefaults(t *testing.T) {
+	resetDefaults(t)
+	require.NoError(t, SetDefaults(map[string]interface{}{
+		"addresses":   []string{"shared:6379"},
+		"username":    "agent",
+		"auth_token":  "shared-pass",
+		"poolSize":    10,
+		"readTimeout": "1s",
+		"tls":         map[string]interface{}{"enable": true},
+	}))
+
+	client := NewClient("", "", 0, Config{PoolSize: 3})
+	defer client.Close()
+	c := client.(*redis.Client)
+	assert.Equal(t, "shared:6379", c.Options().Addr)
+	assert.Equal(t, "agent", c.Options().Username)
+	assert.Equal(t, "shared-pass", c.Options().Password)
+	assert.Equal(t, 3, c.Options().PoolSize)
+	assert.Equal(t, time.Second, c.Options().ReadTimeout)
+	assert.NotNil(t, c.Options().TLSConfig)
+
+	// the host and password of the integration take precedence over the shared settings
+	client = NewClient("own:6379", "own-pass", 0, Config{TLS: &TLSConfig{}})
+	defer client.Close()
+	c = client.(*redis.Client)
+	assert.Equal(t, "own:6379", c.Options().Addr)
+	assert.Equal(t, "own-pass", c.Options().Password)
+	assert.Nil(t, c.Options().TLSConfig)
+}
+
+func TestNewClientCluster(t *testing.T) {
+	client := NewClient("localhost:6379", "", 0, Config{
+		Mode:      ModeCluster,
+		Addresses: []string{"node-0:6379", "node-1:6379"},
+	})
+	defer client.Close()
+
+	c, ok := client.(*redis.ClusterClient)
+	require.True(t, ok)
+	assert.Equal(t, []string{"node-0:6379", "node-1:6379"}, c.Options().Addrs)
+}
+
+func TestNewClientSentinel(t *testing.T) {
+	os.Setenv("REDIS_SENTINEL_PASSWORD", "sentinel-pass")
+	defer os.Unsetenv("REDIS_SENTINEL_PASSWORD")
+
+	client := NewClient("", "", 1, Config{
+		Mode:       ModeSentinel,
+		Addresses:  []string{"sentinel-0:26379"},
+		MasterName: "mymaster",
+	})
+	defer client.Close()
+
+	c, ok := client.(*redis.Client)
+	require.True(t, ok)
+	assert.Equal(t, "FailoverClient", c.Options().Addr)
+	assert.Equal(t, 1, c.Options().DB)
+}
+
+func TestNewClientInvalid(t *testing.T) {
+	invalid := map[string]struct {
+		conf Config
+		err  string
+	}{
+		"missing master name": {Config{Mode: ModeSentinel}, "redis sentinel mode requires a masterName"},
+		"unknown mode":        {Config{Mode: "invalid"}, `unknown redis mode "invalid"`},
+		"missing certificate": {Config{TLS: &TLSConfig{Enable: true, CertFile: "missing.crt", KeyFile: "missing.key"}}, "missing.crt"},
+		"missing ca":          {Config{TLS: &TLSConfig{Enable: true, CAFile: "missing.pem"}}, "missing.pem"},
+	}
+	for name, tc := range invalid {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient("localhost:6379", "", 0, tc.conf)
+			defer client.Close()
+			err := client.Ping(context.Background()).Err()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	conf, err := (&TLSConfig{Enable: true, ServerName: "redis.local"}).config()
+	require.NoError(t, err)
+	assert.Equal(t, "redis.local", conf.ServerName)
+	assert.Nil(t, conf.RootCAs)
+
+	conf, err = (&TLSConfig{}).config()
+	assert.NoError(t, err)
+	assert.Nil(t, conf)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0600))
+	_, err = (&TLSConfig{Enable: true, CAFile: caFile}).config()
+	assert.Error(t, err)
+}
+
+func TestForEachShard(t *testing.T) {
+	client := NewClient("localhost:6379", "", 0, Config{})
+	defer client.Close()
+
+	var shards []redis.UniversalClient
+	err := ForEachShard(context.Background(), client, func(ctx context.Context, shard redis.UniversalClient) error {
+		shards = append(shards, shard)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []redis.UniversalClient{client}, shards)
+}
+
+func TestWithDefaults(t *testing.T) {
+	conf := Config{Mode: ModeCluster, PoolSize: 4}.withDefaults(Config{
+		Mode:        ModeSentinel,
+		MasterName:  "mymaster",
+		PoolSize:    10,
+		DialTimeout: utils.Duration{Duration: time.Second},
+	})
+	assert.Equal(t, ModeCluster, conf.Mode)
+	assert.Equal(t, "mymaster", conf.MasterName)
+	assert.Equal(t, 4, conf.PoolSize)
+	assert.Equal(t, time.Second, conf.DialTimeout.Duration)
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/go-redis/redis/v8"
 	"github.com/optimizely/agent/pkg/utils/redisauth"
+	"github.com/optimizely/agent/pkg/utils/redisclient"
 	"github.com/optimizely/agent/plugins/cmabcache"
 	"github.com/optimizely/agent/plugins/utils"
 	"github.com/optimizely/go-sdk/v2/pkg/cache"
@@ -33,11 +34,13 @@ var ctx = context.Background()
 
 // RedisCache represents the redis implementation of Cache interface for CMAB
 type RedisCache struct {
-	Client   *redis.Client
+	Client   redis.UniversalClient
 	Address  string         `json:"host"`
 	Password string         `json:"password"`
 	Database int            `json:"database"`
 	Timeout  utils.Duration `json:"timeout"`
+	// Config holds the mode, TLS, pool and timeout settings of the connection
+	redisclient.Config
 }
 
 // UnmarshalJSON implements custom JSON unmarshaling with flexible password field names
@@ -137,18 +140,17 @@ func (r *RedisCache) Reset() {
 	}
 
 	if r.Client != nil {
-		if flushError := r.Client.FlushDB(ctx).Err(); flushError != nil {
+		flushError := redisclient.ForEachShard(ctx, r.Client, func(ctx context.Context, client redis.UniversalClient) error {
+			return client.FlushDB(ctx).Err()
+		})
+		if flushError != nil {
 			log.Error().Err(flushError).Msg("Failed to flush CMAB cache in Redis")
 		}
 	}
 }
 
 func (r *RedisCache) initClient() {
-	r.Client = redis.NewClient(&redis.Options{
-		Addr:     r.Address,
-		Password: r.Password,
-		DB:       r.Database,
-	})
+	r.Client = redisclient.NewClient(r.Address, r.Password, r.Database, r.Config)
 }
 
 func init() {
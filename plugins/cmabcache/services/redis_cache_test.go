@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/optimizely/agent/plugins/utils"
 	"github.com/stretchr/testify/suite"
 )
@@ -93,9 +94,9 @@ func (r *RedisCacheTestSuite) TestClientConfiguration() {
 	r.cache.initClient()
 
 	r.NotNil(r.cache.Client)
-	r.Equal("invalid-redis-host:6379", r.cache.Client.Options().Addr)
-	r.Equal("test-password", r.cache.Client.Options().Password)
-	r.Equal(1, r.cache.Client.Options().DB)
+	r.Equal("invalid-redis-host:6379", r.cache.Client.(*redis.Client).Options().Addr)
+	r.Equal("test-password", r.cache.Client.(*redis.Client).Options().Password)
+	r.Equal(1, r.cache.Client.(*redis.Client).Options().DB)
 }
 
 func TestRedisCache_UnmarshalJSON(t *testing.T) {
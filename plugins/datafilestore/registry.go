@@ -0,0 +1,44 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package datafilestore //
+package datafilestore
+
+import (
+	"fmt"
+)
+
+// Store persists the last known good datafile of each SDK key
+type Store interface {
+	// Save stores the datafile for the given sdkKey, replacing any previous snapshot
+	Save(sdkKey string, datafile []byte) error
+	// Load returns the stored datafile for the given sdkKey, or nil if no snapshot exists
+	Load(sdkKey string) ([]byte, error)
+}
+
+// Creator type defines a function for creating an instance of a Store
+type Creator func() Store
+
+// Creators stores the mapping of Creator against datafileStoreName
+var Creators = map[string]Creator{}
+
+// Add registers a creator against datafileStoreName
+func Add(datafileStoreName string, creator Creator) {
+	if _, ok := Creators[datafileStoreName]; ok {
+		panic(fmt.Sprintf("Datafile Store with name %q already exists", datafileStoreName))
+	}
+	Creators[datafileStoreName] = creator
+}
@@ -0,0 +1,70 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package datafilestore //
+package datafilestore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockStore struct {
+}
+
+// Save is used to save a datafile
+func (m *MockStore) Save(sdkKey string, datafile []byte) error {
+	return nil
+}
+
+// Load is used to load a datafile
+func (m *MockStore) Load(sdkKey string) ([]byte, error) {
+	return nil, nil
+}
+
+func TestAdd(t *testing.T) {
+	mockStoreCreator := func() Store {
+		return &MockStore{}
+	}
+
+	Add("mock", mockStoreCreator)
+	creator := Creators["mock"]()
+	if _, ok := creator.(*MockStore); !ok {
+		assert.Fail(t, "Cannot convert to type MockStore")
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			assert.Fail(t, "Should have recovered")
+		}
+	}()
+
+	mockStoreCreator := func() Store {
+		return &MockStore{}
+	}
+
+	Add("mock", mockStoreCreator)
+	Add("mock", mockStoreCreator)
+	assert.Fail(t, "Should have panicked")
+}
+
+func TestDoesNotExist(t *testing.T) {
+	dne := Creators["DNE"]
+	assert.Nil(t, dne)
+}
@@ -0,0 +1,75 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/optimizely/agent/plugins/datafilestore"
+)
+
+// FileStore keeps one datafile snapshot per SDK key in a local directory
+type FileStore struct {
+	Dir string `json:"dir"`
+}
+
+// Save atomically writes the datafile to <dir>/<sdkKey>.json
+func (f *FileStore) Save(sdkKey string, datafile []byte) error {
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that readers never observe a partial datafile
+	tmp, err := os.CreateTemp(f.Dir, ".datafile-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(datafile); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path(sdkKey))
+}
+
+// Load reads the datafile snapshot of the given sdkKey
+func (f *FileStore) Load(sdkKey string) ([]byte, error) {
+	datafile, err := os.ReadFile(f.path(sdkKey))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return datafile, err
+}
+
+func (f *FileStore) path(sdkKey string) string {
+	return filepath.Join(f.Dir, url.PathEscape(sdkKey)+".json")
+}
+
+func init() {
+	fileStoreCreator := func() datafilestore.Store {
+		return &FileStore{}
+	}
+	datafilestore.Add("file", fileStoreCreator)
+}
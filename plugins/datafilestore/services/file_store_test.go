@@ -0,0 +1,74 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FileStoreTestSuite struct {
+	suite.Suite
+	store FileStore
+}
+
+func (f *FileStoreTestSuite) SetupTest() {
+	f.store = FileStore{Dir: filepath.Join(f.T().TempDir(), "datafiles")}
+}
+
+func (f *FileStoreTestSuite) TestSaveAndLoad() {
+	f.NoError(f.store.Save("sdkKey", []byte(`{"revision":"1"}`)))
+	f.NoError(f.store.Save("sdkKey", []byte(`{"revision":"2"}`)))
+
+	datafile, err := f.store.Load("sdkKey")
+	f.NoError(err)
+	f.Equal(`{"revision":"2"}`, string(datafile))
+
+	entries, err := os.ReadDir(f.store.Dir)
+	f.NoError(err)
+	f.Len(entries, 1)
+	f.Equal("sdkKey.json", entries[0].Name())
+}
+
+func (f *FileStoreTestSuite) TestLoadMissingSnapshot() {
+	datafile, err := f.store.Load("missing")
+	f.NoError(err)
+	f.Nil(datafile)
+}
+
+func (f *FileStoreTestSuite) TestSDKKeyIsEscaped() {
+	f.NoError(f.store.Save("../sdk/Key", []byte(`{}`)))
+
+	_, err := os.Stat(filepath.Join(f.store.Dir, "..%2Fsdk%2FKey.json"))
+	f.NoError(err)
+}
+
+func (f *FileStoreTestSuite) TestSaveInvalidDir() {
+	file := filepath.Join(f.T().TempDir(), "file")
+	f.NoError(os.WriteFile(file, nil, 0o600))
+
+	f.store.Dir = file
+	f.Error(f.store.Save("sdkKey", []byte(`{}`)))
+}
+
+func TestFileStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(FileStoreTestSuite))
+}
@@ -0,0 +1,102 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/optimizely/agent/pkg/utils/redisauth"
+	"github.com/optimizely/agent/pkg/utils/redisclient"
+	"github.com/optimizely/agent/plugins/datafilestore"
+)
+
+var ctx = context.Background()
+
+// defaultRedisPrefix is prepended to the SDK key when no prefix is configured
+const defaultRedisPrefix = "optimizely-datafile-"
+
+// RedisStore keeps one datafile snapshot per SDK key in redis
+type RedisStore struct {
+	Client   redis.UniversalClient
+	Address  string `json:"host"`
+	Password string `json:"password"`
+	Database int    `json:"database"`
+	Prefix   string `json:"prefix"`
+	// Config holds the mode, TLS, pool and timeout settings of the connection
+	redisclient.Config
+	once sync.Once
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling with flexible password field names
+// Supports: auth_token, redis_secret, password (in order of preference)
+// Fallback: REDIS_DATAFILE_PASSWORD environment variable
+func (r *RedisStore) UnmarshalJSON(data []byte) error {
+	// Use an alias type to avoid infinite recursion
+	type Alias RedisStore
+	alias := (*Alias)(r)
+
+	// Use shared unmarshal logic with password extraction
+	password, err := redisauth.UnmarshalWithPasswordExtraction(data, alias, "REDIS_DATAFILE_PASSWORD")
+	if err != nil {
+		return err
+	}
+
+	r.Password = password
+	return nil
+}
+
+// Save stores the datafile under the prefixed sdkKey
+func (r *RedisStore) Save(sdkKey string, datafile []byte) error {
+	r.once.Do(r.initClient)
+	return r.Client.Set(ctx, r.key(sdkKey), datafile, 0).Err()
+}
+
+// Load retrieves the datafile stored under the prefixed sdkKey
+func (r *RedisStore) Load(sdkKey string) ([]byte, error) {
+	r.once.Do(r.initClient)
+
+	datafile, err := r.Client.Get(ctx, r.key(sdkKey)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	return datafile, err
+}
+
+func (r *RedisStore) key(sdkKey string) string {
+	if r.Prefix == "" {
+		return defaultRedisPrefix + sdkKey
+	}
+	return r.Prefix + sdkKey
+}
+
+func (r *RedisStore) initClient() {
+	if r.Client != nil {
+		return
+	}
+	r.Client = redisclient.NewClient(r.Address, r.Password, r.Database, r.Config)
+}
+
+func init() {
+	redisStoreCreator := func() datafilestore.Store {
+		return &RedisStore{}
+	}
+	datafilestore.Add("redis", redisStoreCreator)
+}
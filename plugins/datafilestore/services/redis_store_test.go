@@ -0,0 +1,138 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-redis/redismock/v8"
+	"github.com/stretchr/testify/suite"
+)
+
+type RedisStoreTestSuite struct {
+	suite.Suite
+	store RedisStore
+	mock  redismock.ClientMock
+}
+
+func (r *RedisStoreTestSuite) SetupTest() {
+	var client *redis.Client
+	client, r.mock = redismock.NewClientMock()
+	r.store = RedisStore{Client: client}
+}
+
+func (r *RedisStoreTestSuite) TearDownTest() {
+	r.NoError(r.mock.ExpectationsWereMet())
+}
+
+func (r *RedisStoreTestSuite) TestFirstSaveOrLoadConfiguresClient() {
+	store := RedisStore{Address: "100", Password: "10", Database: 1}
+	_ = store.Save("sdkKey", []byte(`{}`))
+	r.NotNil(store.Client)
+	r.Equal("100", store.Client.(*redis.Client).Options().Addr)
+	r.Equal("10", store.Client.(*redis.Client).Options().Password)
+	r.Equal(1, store.Client.(*redis.Client).Options().DB)
+
+	store = RedisStore{Address: "100", Password: "10", Database: 1}
+	_, _ = store.Load("sdkKey")
+	r.NotNil(store.Client)
+}
+
+func (r *RedisStoreTestSuite) TestSave() {
+	r.mock.ExpectSet("optimizely-datafile-sdkKey", []byte(`{}`), 0).SetVal("OK")
+	r.NoError(r.store.Save("sdkKey", []byte(`{}`)))
+}
+
+func (r *RedisStoreTestSuite) TestSaveWithPrefix() {
+	r.store.Prefix = "prefix-"
+	r.mock.ExpectSet("prefix-sdkKey", []byte(`{}`), 0).SetErr(errors.New("failed"))
+	r.EqualError(r.store.Save("sdkKey", []byte(`{}`)), "failed")
+}
+
+func (r *RedisStoreTestSuite) TestLoad() {
+	r.mock.ExpectGet("optimizely-datafile-sdkKey").SetVal(`{"revision":"1"}`)
+	datafile, err := r.store.Load("sdkKey")
+	r.NoError(err)
+	r.Equal(`{"revision":"1"}`, string(datafile))
+}
+
+func (r *RedisStoreTestSuite) TestLoadMissingSnapshot() {
+	r.mock.ExpectGet("optimizely-datafile-sdkKey").RedisNil()
+	datafile, err := r.store.Load("sdkKey")
+	r.NoError(err)
+	r.Nil(datafile)
+}
+
+func (r *RedisStoreTestSuite) TestLoadError() {
+	r.mock.ExpectGet("optimizely-datafile-sdkKey").SetErr(errors.New("failed"))
+	_, err := r.store.Load("sdkKey")
+	r.EqualError(err, "failed")
+}
+
+func TestRedisStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(RedisStoreTestSuite))
+}
+
+func TestRedisStore_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		json         string
+		wantPassword string
+		wantPrefix   string
+		wantErr      bool
+	}{
+		{
+			name:         "auth_token has priority",
+			json:         `{"host":"localhost:6379","auth_token":"token123","password":"pass456","database":0}`,
+			wantPassword: "token123",
+			wantErr:      false,
+		},
+		{
+			name:         "password and prefix",
+			json:         `{"host":"localhost:6379","password":"pass456","prefix":"df-"}`,
+			wantPassword: "pass456",
+			wantPrefix:   "df-",
+			wantErr:      false,
+		},
+		{
+			name:         "invalid json",
+			json:         `{invalid}`,
+			wantPassword: "",
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var store RedisStore
+			err := store.UnmarshalJSON([]byte(tt.json))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && store.Password != tt.wantPassword {
+				t.Errorf("UnmarshalJSON() Password = %v, want %v", store.Password, tt.wantPassword)
+			}
+			if !tt.wantErr && store.Prefix != tt.wantPrefix {
+				t.Errorf("UnmarshalJSON() Prefix = %v, want %v", store.Prefix, tt.wantPrefix)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package deadletter //
+package deadletter
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+)
+
+// ErrNotFound is returned when no dead-lettered batch has the requested ID
+var ErrNotFound = errors.New("dead-lettered batch not found")
+
+// Batch is an event batch that could not be dispatched to the event endpoint
+type Batch struct {
+	ID       string      `json:"id"`
+	EndPoint string      `json:"endPoint"`
+	Event    event.Batch `json:"event"`
+	// Error is the last dispatch error
+	Error     string    `json:"error"`
+	Attempts  int       `json:"attempts"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Store keeps the dead-lettered batches of each SDK key
+type Store interface {
+	// Add stores a batch of the given sdkKey
+	Add(sdkKey string, batch Batch) error
+	// List returns the batches of the given sdkKey, oldest first
+	List(sdkKey string) ([]Batch, error)
+	// Get returns the batch of the given sdkKey with the given ID, or ErrNotFound
+	Get(sdkKey, id string) (Batch, error)
+	// Remove deletes the batch of the given sdkKey with the given ID, or returns ErrNotFound
+	Remove(sdkKey, id string) error
+	// Purge deletes every batch of the given sdkKey and returns how many were deleted
+	Purge(sdkKey string) (int, error)
+}
+
+// Creator type defines a function for creating an instance of a Store
+type Creator func() Store
+
+// Creators stores the mapping of Creator against deadLetterStoreName
+var Creators = map[string]Creator{}
+
+// Add registers a creator against deadLetterStoreName
+func Add(deadLetterStoreName string, creator Creator) {
+	if _, ok := Creators[deadLetterStoreName]; ok {
+		panic(fmt.Sprintf("Dead Letter Store with name %q already exists", deadLetterStoreName))
+	}
+	Creators[deadLetterStoreName] = creator
+}
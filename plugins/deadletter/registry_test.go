@@ -0,0 +1,85 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package deadletter //
+package deadletter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockStore struct {
+}
+
+// Add is used to store a batch
+func (m *MockStore) Add(sdkKey string, batch Batch) error {
+	return nil
+}
+
+// List is used to list the batches of an SDK key
+func (m *MockStore) List(sdkKey string) ([]Batch, error) {
+	return nil, nil
+}
+
+// Get is used to get a batch
+func (m *MockStore) Get(sdkKey, id string) (Batch, error) {
+	return Batch{}, ErrNotFound
+}
+
+// Remove is used to delete a batch
+func (m *MockStore) Remove(sdkKey, id string) error {
+	return nil
+}
+
+// Purge is used to delete the batches of an SDK key
+func (m *MockStore) Purge(sdkKey string) (int, error) {
+	return 0, nil
+}
+
+func TestAdd(t *testing.T) {
+	mockStoreCreator := func() Store {
+		return &MockStore{}
+	}
+
+	Add("mock", mockStoreCreator)
+	creator := Creators["mock"]()
+	if _, ok := creator.(*MockStore); !ok {
+		assert.Fail(t, "Cannot convert to type MockStore")
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			assert.Fail(t, "Should have recovered")
+		}
+	}()
+
+	mockStoreCreator := func() Store {
+		return &MockStore{}
+	}
+
+	Add("mock", mockStoreCreator)
+	Add("mock", mockStoreCreator)
+	assert.Fail(t, "Should have panicked")
+}
+
+func TestDoesNotExist(t *testing.T) {
+	dne := Creators["DNE"]
+	assert.Nil(t, dne)
+}
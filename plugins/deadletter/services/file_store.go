@@ -0,0 +1,174 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/optimizely/agent/plugins/deadletter"
+)
+
+const batchExtension = ".json"
+
+// FileStore keeps one JSON file per dead-lettered batch in a directory per SDK key
+type FileStore struct {
+	Dir string `json:"dir"`
+}
+
+// Add atomically writes the batch to <dir>/<sdkKey>/<id>.json
+func (f *FileStore) Add(sdkKey string, batch deadletter.Batch) error {
+	if f.Dir == "" {
+		return errors.New("file dead letter store requires a dir")
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	dir := f.dir(sdkKey)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that readers never observe a partial batch
+	tmp, err := os.CreateTemp(dir, ".batch-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path(sdkKey, batch.ID))
+}
+
+// List reads every batch of sdkKey, oldest first
+func (f *FileStore) List(sdkKey string) ([]deadletter.Batch, error) {
+	names, err := f.files(sdkKey)
+	if err != nil {
+		return nil, err
+	}
+
+	batches := make([]deadletter.Batch, 0, len(names))
+	for _, name := range names {
+		batch, err := f.read(filepath.Join(f.dir(sdkKey), name))
+		if errors.Is(err, os.ErrNotExist) {
+			// removed while listing
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+	}
+
+	sort.SliceStable(batches, func(i, j int) bool {
+		return batches[i].Timestamp.Before(batches[j].Timestamp)
+	})
+	return batches, nil
+}
+
+// Get reads the batch of sdkKey with the given ID
+func (f *FileStore) Get(sdkKey, id string) (deadletter.Batch, error) {
+	batch, err := f.read(f.path(sdkKey, id))
+	if errors.Is(err, os.ErrNotExist) {
+		return deadletter.Batch{}, deadletter.ErrNotFound
+	}
+	return batch, err
+}
+
+// Remove deletes the batch of sdkKey with the given ID
+func (f *FileStore) Remove(sdkKey, id string) error {
+	err := os.Remove(f.path(sdkKey, id))
+	if errors.Is(err, os.ErrNotExist) {
+		return deadletter.ErrNotFound
+	}
+	return err
+}
+
+// Purge deletes every batch of sdkKey
+func (f *FileStore) Purge(sdkKey string) (int, error) {
+	names, err := f.files(sdkKey)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, name := range names {
+		err := os.Remove(filepath.Join(f.dir(sdkKey), name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (f *FileStore) files(sdkKey string) ([]string, error) {
+	entries, err := os.ReadDir(f.dir(sdkKey))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), batchExtension) {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+func (f *FileStore) read(path string) (deadletter.Batch, error) {
+	var batch deadletter.Batch
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return batch, err
+	}
+	err = json.Unmarshal(data, &batch)
+	return batch, err
+}
+
+func (f *FileStore) dir(sdkKey string) string {
+	return filepath.Join(f.Dir, url.PathEscape(sdkKey))
+}
+
+func (f *FileStore) path(sdkKey, id string) string {
+	return filepath.Join(f.dir(sdkKey), url.PathEscape(id)+batchExtension)
+}
+
+func init() {
+	fileStoreCreator := func() deadletter.Store {
+		return &FileStore{}
+	}
+	deadletter.Add("file", fileStoreCreator)
+}
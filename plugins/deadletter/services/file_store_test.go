@@ -0,0 +1,116 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/agent/plugins/deadletter"
+)
+
+type FileStoreTestSuite struct {
+	suite.Suite
+	store FileStore
+}
+
+func (f *FileStoreTestSuite) SetupTest() {
+	f.store = FileStore{Dir: filepath.Join(f.T().TempDir(), "dead-letters")}
+}
+
+func testBatch(id string, timestamp int64) deadletter.Batch {
+	return deadletter.Batch{
+		ID:        id,
+		EndPoint:  "https://logx.optimizely.com/v1/events",
+		Event:     event.Batch{AccountID: "1", Revision: "2", Visitors: []event.Visitor{{VisitorID: "user"}}},
+		Error:     "unavailable",
+		Attempts:  3,
+		Timestamp: time.Unix(timestamp, 0).UTC(),
+	}
+}
+
+func (f *FileStoreTestSuite) TestAddListGet() {
+	f.NoError(f.store.Add("sdkKey", testBatch("2", 2)))
+	f.NoError(f.store.Add("sdkKey", testBatch("1", 1)))
+	f.NoError(f.store.Add("other", testBatch("3", 3)))
+
+	batches, err := f.store.List("sdkKey")
+	f.NoError(err)
+	f.Equal([]deadletter.Batch{testBatch("1", 1), testBatch("2", 2)}, batches)
+
+	batch, err := f.store.Get("sdkKey", "2")
+	f.NoError(err)
+	f.Equal(testBatch("2", 2), batch)
+
+	_, err = f.store.Get("sdkKey", "3")
+	f.Equal(deadletter.ErrNotFound, err)
+}
+
+func (f *FileStoreTestSuite) TestListWithoutBatches() {
+	batches, err := f.store.List("sdkKey")
+	f.NoError(err)
+	f.Empty(batches)
+}
+
+func (f *FileStoreTestSuite) TestRemove() {
+	f.NoError(f.store.Add("sdkKey", testBatch("1", 1)))
+	f.NoError(f.store.Remove("sdkKey", "1"))
+	f.Equal(deadletter.ErrNotFound, f.store.Remove("sdkKey", "1"))
+
+	batches, err := f.store.List("sdkKey")
+	f.NoError(err)
+	f.Empty(batches)
+}
+
+func (f *FileStoreTestSuite) TestPurge() {
+	f.NoError(f.store.Add("sdkKey", testBatch("1", 1)))
+	f.NoError(f.store.Add("sdkKey", testBatch("2", 2)))
+	f.NoError(f.store.Add("other", testBatch("3", 3)))
+
+	purged, err := f.store.Purge("sdkKey")
+	f.NoError(err)
+	f.Equal(2, purged)
+
+	batches, err := f.store.List("other")
+	f.NoError(err)
+	f.Len(batches, 1)
+}
+
+func (f *FileStoreTestSuite) TestPathsAreEscaped() {
+	f.NoError(f.store.Add("../sdkKey", testBatch("../1", 1)))
+	_, err := os.Stat(filepath.Join(f.store.Dir, "..%2FsdkKey", "..%2F1.json"))
+	f.NoError(err)
+
+	batch, err := f.store.Get("../sdkKey", "../1")
+	f.NoError(err)
+	f.Equal("../1", batch.ID)
+}
+
+func (f *FileStoreTestSuite) TestAddWithoutDir() {
+	store := FileStore{}
+	f.EqualError(store.Add("sdkKey", testBatch("1", 1)), "file dead letter store requires a dir")
+}
+
+func TestFileStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(FileStoreTestSuite))
+}
@@ -0,0 +1,167 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/optimizely/agent/pkg/utils/redisauth"
+	"github.com/optimizely/agent/pkg/utils/redisclient"
+	"github.com/optimizely/agent/plugins/deadletter"
+)
+
+var ctx = context.Background()
+
+// defaultRedisPrefix is prepended to the SDK key when no prefix is configured
+const defaultRedisPrefix = "optimizely-dead-letters-"
+
+// RedisStore keeps the dead-lettered batches of each SDK key in a redis hash keyed by batch ID
+type RedisStore struct {
+	Client   redis.UniversalClient
+	Address  string `json:"host"`
+	Password string `json:"password"`
+	Database int    `json:"database"`
+	Prefix   string `json:"prefix"`
+	// Config holds the mode, TLS, pool and timeout settings of the connection
+	redisclient.Config
+	once sync.Once
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling with flexible password field names
+// Supports: auth_token, redis_secret, password (in order of preference)
+// Fallback: REDIS_DEADLETTER_PASSWORD environment variable
+func (r *RedisStore) UnmarshalJSON(data []byte) error {
+	// Use an alias type to avoid infinite recursion
+	type Alias RedisStore
+	alias := (*Alias)(r)
+
+	// Use shared unmarshal logic with password extraction
+	password, err := redisauth.UnmarshalWithPasswordExtraction(data, alias, "REDIS_DEADLETTER_PASSWORD")
+	if err != nil {
+		return err
+	}
+
+	r.Password = password
+	return nil
+}
+
+// Add stores the batch in the hash of sdkKey
+func (r *RedisStore) Add(sdkKey string, batch deadletter.Batch) error {
+	r.once.Do(r.initClient)
+
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	return r.Client.HSet(ctx, r.key(sdkKey), batch.ID, data).Err()
+}
+
+// List reads every batch of sdkKey, oldest first
+func (r *RedisStore) List(sdkKey string) ([]deadletter.Batch, error) {
+	r.once.Do(r.initClient)
+
+	values, err := r.Client.HGetAll(ctx, r.key(sdkKey)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	batches := make([]deadletter.Batch, 0, len(values))
+	for _, value := range values {
+		var batch deadletter.Batch
+		if err := json.Unmarshal([]byte(value), &batch); err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+	}
+
+	sort.Slice(batches, func(i, j int) bool {
+		if batches[i].Timestamp.Equal(batches[j].Timestamp) {
+			return batches[i].ID < batches[j].ID
+		}
+		return batches[i].Timestamp.Before(batches[j].Timestamp)
+	})
+	return batches, nil
+}
+
+// Get reads the batch of sdkKey with the given ID
+func (r *RedisStore) Get(sdkKey, id string) (deadletter.Batch, error) {
+	r.once.Do(r.initClient)
+
+	var batch deadletter.Batch
+	data, err := r.Client.HGet(ctx, r.key(sdkKey), id).Bytes()
+	if err == redis.Nil {
+		return batch, deadletter.ErrNotFound
+	} else if err != nil {
+		return batch, err
+	}
+	err = json.Unmarshal(data, &batch)
+	return batch, err
+}
+
+// Remove deletes the batch of sdkKey with the given ID
+func (r *RedisStore) Remove(sdkKey, id string) error {
+	r.once.Do(r.initClient)
+
+	removed, err := r.Client.HDel(ctx, r.key(sdkKey), id).Result()
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return deadletter.ErrNotFound
+	}
+	return nil
+}
+
+// Purge deletes the hash of sdkKey
+func (r *RedisStore) Purge(sdkKey string) (int, error) {
+	r.once.Do(r.initClient)
+
+	pipe := r.Client.TxPipeline()
+	count := pipe.HLen(ctx, r.key(sdkKey))
+	pipe.Del(ctx, r.key(sdkKey))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return int(count.Val()), nil
+}
+
+func (r *RedisStore) key(sdkKey string) string {
+	if r.Prefix == "" {
+		return defaultRedisPrefix + sdkKey
+	}
+	return r.Prefix + sdkKey
+}
+
+func (r *RedisStore) initClient() {
+	if r.Client != nil {
+		return
+	}
+	r.Client = redisclient.NewClient(r.Address, r.Password, r.Database, r.Config)
+}
+
+func init() {
+	redisStoreCreator := func() deadletter.Store {
+		return &RedisStore{}
+	}
+	deadletter.Add("redis", redisStoreCreator)
+}
@@ -0,0 +1,128 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-redis/redismock/v8"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/agent/plugins/deadletter"
+)
+
+type RedisStoreTestSuite struct {
+	suite.Suite
+	store *RedisStore
+	mock  redismock.ClientMock
+}
+
+func (r *RedisStoreTestSuite) SetupTest() {
+	var client *redis.Client
+	client, r.mock = redismock.NewClientMock()
+	r.store = &RedisStore{Client: client}
+}
+
+func (r *RedisStoreTestSuite) TearDownTest() {
+	r.NoError(r.mock.ExpectationsWereMet())
+}
+
+func (r *RedisStoreTestSuite) encode(batch deadletter.Batch) string {
+	data, err := json.Marshal(batch)
+	r.Require().NoError(err)
+	return string(data)
+}
+
+func (r *RedisStoreTestSuite) TestFirstCallConfiguresClient() {
+	store := &RedisStore{Address: "100", Password: "10", Database: 1}
+	_, _ = store.List("sdkKey")
+	r.NotNil(store.Client)
+	r.Equal("100", store.Client.(*redis.Client).Options().Addr)
+	r.Equal("10", store.Client.(*redis.Client).Options().Password)
+	r.Equal(1, store.Client.(*redis.Client).Options().DB)
+}
+
+func (r *RedisStoreTestSuite) TestAdd() {
+	batch := testBatch("1", 1)
+	data, err := json.Marshal(batch)
+	r.Require().NoError(err)
+	r.mock.ExpectHSet("optimizely-dead-letters-sdkKey", "1", data).SetVal(1)
+	r.NoError(r.store.Add("sdkKey", batch))
+}
+
+func (r *RedisStoreTestSuite) TestList() {
+	r.store.Prefix = "prefix-"
+	r.mock.ExpectHGetAll("prefix-sdkKey").SetVal(map[string]string{
+		"2": r.encode(testBatch("2", 2)),
+		"1": r.encode(testBatch("1", 1)),
+	})
+	batches, err := r.store.List("sdkKey")
+	r.NoError(err)
+	r.Equal([]deadletter.Batch{testBatch("1", 1), testBatch("2", 2)}, batches)
+}
+
+func (r *RedisStoreTestSuite) TestListError() {
+	r.mock.ExpectHGetAll("optimizely-dead-letters-sdkKey").SetErr(errors.New("unavailable"))
+	_, err := r.store.List("sdkKey")
+	r.EqualError(err, "unavailable")
+}
+
+func (r *RedisStoreTestSuite) TestGet() {
+	r.mock.ExpectHGet("optimizely-dead-letters-sdkKey", "1").SetVal(r.encode(testBatch("1", 1)))
+	batch, err := r.store.Get("sdkKey", "1")
+	r.NoError(err)
+	r.Equal(testBatch("1", 1), batch)
+
+	r.mock.ExpectHGet("optimizely-dead-letters-sdkKey", "2").RedisNil()
+	_, err = r.store.Get("sdkKey", "2")
+	r.Equal(deadletter.ErrNotFound, err)
+}
+
+func (r *RedisStoreTestSuite) TestRemove() {
+	r.mock.ExpectHDel("optimizely-dead-letters-sdkKey", "1").SetVal(1)
+	r.NoError(r.store.Remove("sdkKey", "1"))
+
+	r.mock.ExpectHDel("optimizely-dead-letters-sdkKey", "1").SetVal(0)
+	r.Equal(deadletter.ErrNotFound, r.store.Remove("sdkKey", "1"))
+}
+
+func (r *RedisStoreTestSuite) TestPurge() {
+	r.mock.ExpectTxPipeline()
+	r.mock.ExpectHLen("optimizely-dead-letters-sdkKey").SetVal(2)
+	r.mock.ExpectDel("optimizely-dead-letters-sdkKey").SetVal(1)
+	r.mock.ExpectTxPipelineExec()
+	purged, err := r.store.Purge("sdkKey")
+	r.NoError(err)
+	r.Equal(2, purged)
+}
+
+func (r *RedisStoreTestSuite) TestUnmarshalJSON() {
+	store := &RedisStore{}
+	r.NoError(store.UnmarshalJSON([]byte(`{"host":"localhost:6379","auth_token":"secret","database":2,"prefix":"dead-"}`)))
+	r.Equal("localhost:6379", store.Address)
+	r.Equal("secret", store.Password)
+	r.Equal(2, store.Database)
+	r.Equal("dead-", store.Prefix)
+}
+
+func TestRedisStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(RedisStoreTestSuite))
+}
@@ -0,0 +1,59 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package decisionlog //
+package decisionlog
+
+import (
+	"fmt"
+	"time"
+)
+
+// Record describes a single decision served by Agent
+type Record struct {
+	Timestamp    time.Time         `json:"timestamp"`
+	SDKKey       string            `json:"sdkKey"`
+	RequestID    string            `json:"requestId,omitempty"`
+	UserID       string            `json:"userId"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+	Type         string            `json:"type"`
+	FlagKey      string            `json:"flagKey,omitempty"`
+	RuleKey      string            `json:"ruleKey,omitempty"`
+	VariationKey string            `json:"variationKey,omitempty"`
+	Enabled      bool              `json:"enabled"`
+	Revision     string            `json:"revision,omitempty"`
+}
+
+// Sink stores batches of decision records. Sinks that hold resources can also implement io.Closer,
+// which is called once Agent shuts down.
+type Sink interface {
+	// Write stores the records in order. The slice must not be retained after Write returns
+	Write(records []Record) error
+}
+
+// Creator type defines a function for creating an instance of a Sink
+type Creator func() Sink
+
+// Creators stores the mapping of Creator against sinkName
+var Creators = map[string]Creator{}
+
+// Add registers a creator against sinkName
+func Add(sinkName string, creator Creator) {
+	if _, ok := Creators[sinkName]; ok {
+		panic(fmt.Sprintf("Decision Log Sink with name %q already exists", sinkName))
+	}
+	Creators[sinkName] = creator
+}
@@ -0,0 +1,65 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package decisionlog //
+package decisionlog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockSink struct {
+}
+
+// Write is used to store records
+func (m *MockSink) Write(records []Record) error {
+	return nil
+}
+
+func TestAdd(t *testing.T) {
+	mockSinkCreator := func() Sink {
+		return &MockSink{}
+	}
+
+	Add("mock", mockSinkCreator)
+	creator := Creators["mock"]()
+	if _, ok := creator.(*MockSink); !ok {
+		assert.Fail(t, "Cannot convert to type MockSink")
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			assert.Fail(t, "Should have recovered")
+		}
+	}()
+
+	mockSinkCreator := func() Sink {
+		return &MockSink{}
+	}
+
+	Add("mock", mockSinkCreator)
+	Add("mock", mockSinkCreator)
+	assert.Fail(t, "Should have panicked")
+}
+
+func TestDoesNotExist(t *testing.T) {
+	dne := Creators["DNE"]
+	assert.Nil(t, dne)
+}
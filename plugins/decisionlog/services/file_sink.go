@@ -0,0 +1,159 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/optimizely/agent/plugins/decisionlog"
+)
+
+const (
+	// defaultMaxSize is the size in bytes at which the log file is rotated when no maxSize is configured
+	defaultMaxSize = 100 * 1024 * 1024
+	// defaultMaxBackups is the number of rotated files kept when no maxBackups is configured
+	defaultMaxBackups = 5
+)
+
+// FileSink appends decision records to a newline delimited JSON file. Once the file grows past MaxSize
+// it is renamed to path.1, previous backups are shifted to path.2, path.3 and so on, and the oldest
+// backup beyond MaxBackups is removed.
+type FileSink struct {
+	Path       string `json:"path"`
+	MaxSize    int64  `json:"maxSize"`
+	MaxBackups int    `json:"maxBackups"`
+
+	lock sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Write appends the records to the log file, rotating it first when it is full
+func (f *FileSink) Write(records []decisionlog.Record) error {
+	if f.Path == "" {
+		return errors.New("decision log file path is not set")
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return err
+		}
+	}
+	if f.size >= f.maxSize() {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+
+	writer := bufio.NewWriter(f.file)
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		line = append(line, '\n')
+		n, err := writer.Write(line)
+		f.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// Close closes the log file
+func (f *FileSink) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *FileSink) open() error {
+	file, err := os.OpenFile(f.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *FileSink) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	backups := f.maxBackups()
+	if err := os.Remove(f.backup(backups)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := backups - 1; i > 0; i-- {
+		if err := os.Rename(f.backup(i), f.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(f.Path, f.backup(1)); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+func (f *FileSink) backup(i int) string {
+	return fmt.Sprintf("%s.%d", f.Path, i)
+}
+
+func (f *FileSink) maxSize() int64 {
+	if f.MaxSize <= 0 {
+		return defaultMaxSize
+	}
+	return f.MaxSize
+}
+
+func (f *FileSink) maxBackups() int {
+	if f.MaxBackups <= 0 {
+		return defaultMaxBackups
+	}
+	return f.MaxBackups
+}
+
+func init() {
+	fileSinkCreator := func() decisionlog.Sink {
+		return &FileSink{}
+	}
+	decisionlog.Add("file", fileSinkCreator)
+}
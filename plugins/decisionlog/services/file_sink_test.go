@@ -0,0 +1,105 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/agent/plugins/decisionlog"
+)
+
+type FileSinkTestSuite struct {
+	suite.Suite
+	path string
+	sink *FileSink
+}
+
+func (f *FileSinkTestSuite) SetupTest() {
+	f.path = filepath.Join(f.T().TempDir(), "decisions.ndjson")
+	f.sink = &FileSink{Path: f.path}
+}
+
+func (f *FileSinkTestSuite) TearDownTest() {
+	f.NoError(f.sink.Close())
+}
+
+func (f *FileSinkTestSuite) readRecords(path string) []decisionlog.Record {
+	file, err := os.Open(path)
+	f.Require().NoError(err)
+	defer file.Close()
+
+	records := []decisionlog.Record{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record decisionlog.Record
+		f.Require().NoError(json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	f.Require().NoError(scanner.Err())
+	return records
+}
+
+func (f *FileSinkTestSuite) TestWriteAppendsRecords() {
+	f.NoError(f.sink.Write([]decisionlog.Record{{UserID: "1", FlagKey: "flag"}}))
+	f.NoError(f.sink.Write([]decisionlog.Record{{UserID: "2", FlagKey: "flag"}}))
+
+	records := f.readRecords(f.path)
+	f.Len(records, 2)
+	f.Equal("1", records[0].UserID)
+	f.Equal("2", records[1].UserID)
+}
+
+func (f *FileSinkTestSuite) TestWriteAppendsToExistingFile() {
+	f.NoError(f.sink.Write([]decisionlog.Record{{UserID: "1"}}))
+	f.NoError(f.sink.Close())
+
+	f.sink = &FileSink{Path: f.path}
+	f.NoError(f.sink.Write([]decisionlog.Record{{UserID: "2"}}))
+	f.Len(f.readRecords(f.path), 2)
+}
+
+func (f *FileSinkTestSuite) TestWriteRotates() {
+	f.sink.MaxSize = 1
+	f.sink.MaxBackups = 2
+
+	f.NoError(f.sink.Write([]decisionlog.Record{{UserID: "1"}}))
+	f.NoError(f.sink.Write([]decisionlog.Record{{UserID: "2"}}))
+	f.NoError(f.sink.Write([]decisionlog.Record{{UserID: "3"}}))
+	f.NoError(f.sink.Write([]decisionlog.Record{{UserID: "4"}}))
+
+	f.Equal("4", f.readRecords(f.path)[0].UserID)
+	f.Equal("3", f.readRecords(f.path + ".1")[0].UserID)
+	f.Equal("2", f.readRecords(f.path + ".2")[0].UserID)
+	_, err := os.Stat(f.path + ".3")
+	f.True(os.IsNotExist(err))
+}
+
+func (f *FileSinkTestSuite) TestWriteWithoutPath() {
+	sink := &FileSink{}
+	f.EqualError(sink.Write([]decisionlog.Record{{UserID: "1"}}), "decision log file path is not set")
+}
+
+func TestFileSinkTestSuite(t *testing.T) {
+	suite.Run(t, new(FileSinkTestSuite))
+}
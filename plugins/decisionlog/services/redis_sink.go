@@ -0,0 +1,117 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/optimizely/agent/pkg/utils/redisauth"
+	"github.com/optimizely/agent/pkg/utils/redisclient"
+	"github.com/optimizely/agent/plugins/decisionlog"
+)
+
+var ctx = context.Background()
+
+// defaultRedisStream is the stream the records are added to when no stream is configured
+const defaultRedisStream = "optimizely-decisions"
+
+// RedisSink adds every decision record to a redis stream as a JSON encoded "record" field
+type RedisSink struct {
+	Client   redis.UniversalClient
+	Address  string `json:"host"`
+	Password string `json:"password"`
+	Database int    `json:"database"`
+	Stream   string `json:"stream"`
+	// MaxLen approximately caps the length of the stream, 0 keeps every record
+	MaxLen int64 `json:"maxLen"`
+	// Config holds the mode, TLS, pool and timeout settings of the connection
+	redisclient.Config
+	once sync.Once
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling with flexible password field names
+// Supports: auth_token, redis_secret, password (in order of preference)
+// Fallback: REDIS_DECISIONLOG_PASSWORD environment variable
+func (r *RedisSink) UnmarshalJSON(data []byte) error {
+	// Use an alias type to avoid infinite recursion
+	type Alias RedisSink
+	alias := (*Alias)(r)
+
+	// Use shared unmarshal logic with password extraction
+	password, err := redisauth.UnmarshalWithPasswordExtraction(data, alias, "REDIS_DECISIONLOG_PASSWORD")
+	if err != nil {
+		return err
+	}
+
+	r.Password = password
+	return nil
+}
+
+// Write adds the records to the stream in a single pipeline
+func (r *RedisSink) Write(records []decisionlog.Record) error {
+	r.once.Do(r.initClient)
+
+	pipe := r.Client.Pipeline()
+	for _, record := range records {
+		value, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: r.stream(),
+			MaxLen: r.MaxLen,
+			Approx: r.MaxLen > 0,
+			Values: map[string]interface{}{"record": string(value)},
+		})
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Close closes the redis client
+func (r *RedisSink) Close() error {
+	if r.Client == nil {
+		return nil
+	}
+	return r.Client.Close()
+}
+
+func (r *RedisSink) stream() string {
+	if r.Stream == "" {
+		return defaultRedisStream
+	}
+	return r.Stream
+}
+
+func (r *RedisSink) initClient() {
+	if r.Client != nil {
+		return
+	}
+	r.Client = redisclient.NewClient(r.Address, r.Password, r.Database, r.Config)
+}
+
+func init() {
+	redisSinkCreator := func() decisionlog.Sink {
+		return &RedisSink{}
+	}
+	decisionlog.Add("redis", redisSinkCreator)
+}
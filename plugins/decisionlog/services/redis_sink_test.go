@@ -0,0 +1,96 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-redis/redismock/v8"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/agent/plugins/decisionlog"
+)
+
+type RedisSinkTestSuite struct {
+	suite.Suite
+	sink *RedisSink
+	mock redismock.ClientMock
+}
+
+func (r *RedisSinkTestSuite) SetupTest() {
+	var client *redis.Client
+	client, r.mock = redismock.NewClientMock()
+	r.sink = &RedisSink{Client: client}
+}
+
+func (r *RedisSinkTestSuite) TearDownTest() {
+	r.NoError(r.mock.ExpectationsWereMet())
+}
+
+func (r *RedisSinkTestSuite) encode(record decisionlog.Record) string {
+	value, err := json.Marshal(record)
+	r.Require().NoError(err)
+	return string(value)
+}
+
+func (r *RedisSinkTestSuite) TestFirstWriteConfiguresClient() {
+	sink := &RedisSink{Address: "100", Password: "10", Database: 1}
+	_ = sink.Write([]decisionlog.Record{})
+	r.NotNil(sink.Client)
+	r.Equal("100", sink.Client.(*redis.Client).Options().Addr)
+	r.Equal("10", sink.Client.(*redis.Client).Options().Password)
+	r.Equal(1, sink.Client.(*redis.Client).Options().DB)
+}
+
+func (r *RedisSinkTestSuite) TestWrite() {
+	records := []decisionlog.Record{{UserID: "1"}, {UserID: "2"}}
+	r.mock.ExpectXAdd(&redis.XAddArgs{Stream: "optimizely-decisions", Values: map[string]interface{}{"record": r.encode(records[0])}}).SetVal("1-0")
+	r.mock.ExpectXAdd(&redis.XAddArgs{Stream: "optimizely-decisions", Values: map[string]interface{}{"record": r.encode(records[1])}}).SetVal("2-0")
+	r.NoError(r.sink.Write(records))
+}
+
+func (r *RedisSinkTestSuite) TestWriteWithStreamAndMaxLen() {
+	r.sink.Stream = "audit"
+	r.sink.MaxLen = 1000
+	record := decisionlog.Record{UserID: "1"}
+	r.mock.ExpectXAdd(&redis.XAddArgs{Stream: "audit", MaxLen: 1000, Approx: true, Values: map[string]interface{}{"record": r.encode(record)}}).SetVal("1-0")
+	r.NoError(r.sink.Write([]decisionlog.Record{record}))
+}
+
+func (r *RedisSinkTestSuite) TestWriteError() {
+	record := decisionlog.Record{UserID: "1"}
+	r.mock.ExpectXAdd(&redis.XAddArgs{Stream: "optimizely-decisions", Values: map[string]interface{}{"record": r.encode(record)}}).SetErr(errors.New("unavailable"))
+	r.EqualError(r.sink.Write([]decisionlog.Record{record}), "unavailable")
+}
+
+func (r *RedisSinkTestSuite) TestUnmarshalJSON() {
+	sink := &RedisSink{}
+	r.NoError(sink.UnmarshalJSON([]byte(`{"host":"localhost:6379","auth_token":"secret","database":2,"stream":"audit","maxLen":10}`)))
+	r.Equal("localhost:6379", sink.Address)
+	r.Equal("secret", sink.Password)
+	r.Equal(2, sink.Database)
+	r.Equal("audit", sink.Stream)
+	r.Equal(int64(10), sink.MaxLen)
+}
+
+func TestRedisSinkTestSuite(t *testing.T) {
+	suite.Run(t, new(RedisSinkTestSuite))
+}
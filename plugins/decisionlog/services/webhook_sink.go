@@ -0,0 +1,94 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/optimizely/agent/plugins/decisionlog"
+	"github.com/optimizely/agent/plugins/utils"
+)
+
+// defaultWebhookTimeout bounds each request when no timeout is configured
+const defaultWebhookTimeout = 10 * time.Second
+
+// WebhookSink posts every batch of decision records to a URL as a JSON array
+type WebhookSink struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Timeout utils.Duration    `json:"timeout"`
+	Client  *http.Client
+}
+
+// Write posts the records and fails unless the webhook responds with a 2xx status
+func (w *WebhookSink) Write(records []decisionlog.Record) error {
+	if w.URL == "" {
+		return errors.New("decision log webhook url is not set")
+	}
+
+	body, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range w.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := w.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("decision log webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (w *WebhookSink) client() *http.Client {
+	if w.Client != nil {
+		return w.Client
+	}
+	timeout := w.Timeout.Duration
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	w.Client = &http.Client{Timeout: timeout}
+	return w.Client
+}
+
+func init() {
+	webhookSinkCreator := func() decisionlog.Sink {
+		return &WebhookSink{}
+	}
+	decisionlog.Add("webhook", webhookSinkCreator)
+}
@@ -0,0 +1,73 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/optimizely/agent/plugins/decisionlog"
+	"github.com/optimizely/agent/plugins/utils"
+)
+
+func TestWebhookSinkWrite(t *testing.T) {
+	var received []decisionlog.Record
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sink := &WebhookSink{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}}
+	records := []decisionlog.Record{{UserID: "1", FlagKey: "flag"}, {UserID: "2", FlagKey: "flag"}}
+	assert.NoError(t, sink.Write(records))
+	assert.Equal(t, records, received)
+}
+
+func TestWebhookSinkWriteErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	sink := &WebhookSink{URL: server.URL}
+	assert.EqualError(t, sink.Write([]decisionlog.Record{{UserID: "1"}}), "decision log webhook responded with status 500")
+}
+
+func TestWebhookSinkWriteWithoutURL(t *testing.T) {
+	sink := &WebhookSink{}
+	assert.EqualError(t, sink.Write([]decisionlog.Record{{UserID: "1"}}), "decision log webhook url is not set")
+}
+
+func TestWebhookSinkTimeout(t *testing.T) {
+	sink := &WebhookSink{}
+	assert.Equal(t, defaultWebhookTimeout, sink.client().Timeout)
+
+	sink = &WebhookSink{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"url":"http://localhost","timeout":"2s"}`), sink))
+	assert.Equal(t, utils.Duration{Duration: 2 * time.Second}, sink.Timeout)
+	assert.Equal(t, 2*time.Second, sink.client().Timeout)
+}
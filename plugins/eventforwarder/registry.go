@@ -0,0 +1,46 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package eventforwarder //
+package eventforwarder
+
+import (
+	"fmt"
+
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+)
+
+// Forwarder sends a copy of the event batches dispatched for an SDK key to an additional destination
+type Forwarder interface {
+	// Forward sends the batch, an error means it should be retried
+	Forward(sdkKey string, logEvent event.LogEvent) error
+	// Close releases the resources held by the forwarder
+	Close() error
+}
+
+// Creator type defines a function for creating an instance of a Forwarder
+type Creator func() Forwarder
+
+// Creators stores the mapping of Creator against eventForwarderName
+var Creators = map[string]Creator{}
+
+// Add registers a creator against eventForwarderName
+func Add(eventForwarderName string, creator Creator) {
+	if _, ok := Creators[eventForwarderName]; ok {
+		panic(fmt.Sprintf("Event Forwarder with name %q already exists", eventForwarderName))
+	}
+	Creators[eventForwarderName] = creator
+}
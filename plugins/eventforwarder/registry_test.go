@@ -0,0 +1,71 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package eventforwarder //
+package eventforwarder
+
+import (
+	"testing"
+
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockForwarder struct {
+}
+
+// Forward is used to forward a batch
+func (m *MockForwarder) Forward(sdkKey string, logEvent event.LogEvent) error {
+	return nil
+}
+
+// Close is used to close the forwarder
+func (m *MockForwarder) Close() error {
+	return nil
+}
+
+func TestAdd(t *testing.T) {
+	mockForwarderCreator := func() Forwarder {
+		return &MockForwarder{}
+	}
+
+	Add("mock", mockForwarderCreator)
+	creator := Creators["mock"]()
+	if _, ok := creator.(*MockForwarder); !ok {
+		assert.Fail(t, "Cannot convert to type MockForwarder")
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			assert.Fail(t, "Should have recovered")
+		}
+	}()
+
+	mockForwarderCreator := func() Forwarder {
+		return &MockForwarder{}
+	}
+
+	Add("mock", mockForwarderCreator)
+	Add("mock", mockForwarderCreator)
+	assert.Fail(t, "Should have panicked")
+}
+
+func TestDoesNotExist(t *testing.T) {
+	dne := Creators["DNE"]
+	assert.Nil(t, dne)
+}
@@ -0,0 +1,82 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+
+	"github.com/optimizely/agent/plugins/eventforwarder"
+)
+
+// FileForwarder appends every event batch to a file as a line of newline delimited JSON
+type FileForwarder struct {
+	Path string `json:"path"`
+
+	lock sync.Mutex
+	file *os.File
+}
+
+// Forward appends the batch to the file, opening it on first use
+func (f *FileForwarder) Forward(sdkKey string, logEvent event.LogEvent) error {
+	if f.Path == "" {
+		return errors.New("event forwarder file path is not set")
+	}
+
+	line, err := json.Marshal(logEvent.Event)
+	if err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file == nil {
+		file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		f.file = file
+	}
+	_, err = f.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the file
+func (f *FileForwarder) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func init() {
+	fileForwarderCreator := func() eventforwarder.Forwarder {
+		return &FileForwarder{}
+	}
+	eventforwarder.Add("file", fileForwarderCreator)
+}
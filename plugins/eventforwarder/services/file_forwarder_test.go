@@ -0,0 +1,92 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/stretchr/testify/suite"
+)
+
+type FileForwarderTestSuite struct {
+	suite.Suite
+	path      string
+	forwarder *FileForwarder
+}
+
+func (f *FileForwarderTestSuite) SetupTest() {
+	f.path = filepath.Join(f.T().TempDir(), "events.ndjson")
+	f.forwarder = &FileForwarder{Path: f.path}
+}
+
+func (f *FileForwarderTestSuite) TearDownTest() {
+	f.NoError(f.forwarder.Close())
+}
+
+func (f *FileForwarderTestSuite) readBatches() []event.Batch {
+	file, err := os.Open(f.path)
+	f.Require().NoError(err)
+	defer file.Close()
+
+	batches := []event.Batch{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var batch event.Batch
+		f.Require().NoError(json.Unmarshal(scanner.Bytes(), &batch))
+		batches = append(batches, batch)
+	}
+	f.Require().NoError(scanner.Err())
+	return batches
+}
+
+func (f *FileForwarderTestSuite) TestForwardAppendsBatches() {
+	first := testLogEvent()
+	second := testLogEvent()
+	second.Event.Revision = "4"
+	f.NoError(f.forwarder.Forward("sdkKey", first))
+	f.NoError(f.forwarder.Forward("sdkKey", second))
+
+	f.Equal([]event.Batch{first.Event, second.Event}, f.readBatches())
+}
+
+func (f *FileForwarderTestSuite) TestForwardAppendsToExistingFile() {
+	f.NoError(f.forwarder.Forward("sdkKey", testLogEvent()))
+	f.NoError(f.forwarder.Close())
+	f.NoError(f.forwarder.Forward("sdkKey", testLogEvent()))
+
+	f.Len(f.readBatches(), 2)
+}
+
+func (f *FileForwarderTestSuite) TestForwardWithoutPath() {
+	forwarder := &FileForwarder{}
+	f.EqualError(forwarder.Forward("sdkKey", testLogEvent()), "event forwarder file path is not set")
+}
+
+func (f *FileForwarderTestSuite) TestForwardToMissingDirectory() {
+	forwarder := &FileForwarder{Path: filepath.Join(f.T().TempDir(), "missing", "events.ndjson")}
+	f.Error(forwarder.Forward("sdkKey", testLogEvent()))
+}
+
+func TestFileForwarderTestSuite(t *testing.T) {
+	suite.Run(t, new(FileForwarderTestSuite))
+}
@@ -0,0 +1,104 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+
+	"github.com/optimizely/agent/plugins/eventforwarder"
+	"github.com/optimizely/agent/plugins/utils"
+)
+
+// defaultHTTPTimeout bounds each request when no timeout is configured
+const defaultHTTPTimeout = 10 * time.Second
+
+// HTTPForwarder posts every event batch to a URL with the same JSON payload as the event endpoint
+type HTTPForwarder struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Timeout utils.Duration    `json:"timeout"`
+	Client  *http.Client
+}
+
+// Forward posts the batch and fails unless the URL responds with a 2xx status
+func (h *HTTPForwarder) Forward(sdkKey string, logEvent event.LogEvent) error {
+	if h.URL == "" {
+		return errors.New("event forwarder url is not set")
+	}
+
+	body, err := json.Marshal(logEvent.Event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range h.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := h.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("event forwarder url responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close releases the idle connections of the client
+func (h *HTTPForwarder) Close() error {
+	if h.Client != nil {
+		h.Client.CloseIdleConnections()
+	}
+	return nil
+}
+
+func (h *HTTPForwarder) client() *http.Client {
+	if h.Client != nil {
+		return h.Client
+	}
+	timeout := h.Timeout.Duration
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	h.Client = &http.Client{Timeout: timeout}
+	return h.Client
+}
+
+func init() {
+	httpForwarderCreator := func() eventforwarder.Forwarder {
+		return &HTTPForwarder{}
+	}
+	eventforwarder.Add("http", httpForwarderCreator)
+}
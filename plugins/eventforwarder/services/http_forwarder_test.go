@@ -0,0 +1,81 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/optimizely/agent/plugins/utils"
+)
+
+func testLogEvent() event.LogEvent {
+	return event.LogEvent{
+		EndPoint: "https://logx.optimizely.com/v1/events",
+		Event:    event.Batch{AccountID: "1", ProjectID: "2", Revision: "3", Visitors: []event.Visitor{{VisitorID: "user"}}},
+	}
+}
+
+func TestHTTPForwarderForward(t *testing.T) {
+	var received event.Batch
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	forwarder := &HTTPForwarder{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}}
+	logEvent := testLogEvent()
+	assert.NoError(t, forwarder.Forward("sdkKey", logEvent))
+	assert.Equal(t, logEvent.Event, received)
+	assert.NoError(t, forwarder.Close())
+}
+
+func TestHTTPForwarderForwardErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	forwarder := &HTTPForwarder{URL: server.URL}
+	assert.EqualError(t, forwarder.Forward("sdkKey", testLogEvent()), "event forwarder url responded with status 502")
+}
+
+func TestHTTPForwarderForwardWithoutURL(t *testing.T) {
+	forwarder := &HTTPForwarder{}
+	assert.EqualError(t, forwarder.Forward("sdkKey", testLogEvent()), "event forwarder url is not set")
+}
+
+func TestHTTPForwarderTimeout(t *testing.T) {
+	forwarder := &HTTPForwarder{}
+	assert.Equal(t, defaultHTTPTimeout, forwarder.client().Timeout)
+
+	forwarder = &HTTPForwarder{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"url":"http://localhost","timeout":"2s"}`), forwarder))
+	assert.Equal(t, utils.Duration{Duration: 2 * time.Second}, forwarder.Timeout)
+	assert.Equal(t, 2*time.Second, forwarder.client().Timeout)
+}
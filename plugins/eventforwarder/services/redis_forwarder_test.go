@@ -0,0 +1,95 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-redis/redismock/v8"
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/stretchr/testify/suite"
+)
+
+type RedisForwarderTestSuite struct {
+	suite.Suite
+	forwarder *RedisForwarder
+	mock      redismock.ClientMock
+}
+
+func (r *RedisForwarderTestSuite) SetupTest() {
+	var client *redis.Client
+	client, r.mock = redismock.NewClientMock()
+	r.forwarder = &RedisForwarder{Client: client}
+}
+
+func (r *RedisForwarderTestSuite) TearDownTest() {
+	r.NoError(r.mock.ExpectationsWereMet())
+}
+
+func (r *RedisForwarderTestSuite) encode(batch event.Batch) string {
+	value, err := json.Marshal(batch)
+	r.Require().NoError(err)
+	return string(value)
+}
+
+func (r *RedisForwarderTestSuite) TestFirstForwardConfiguresClient() {
+	forwarder := &RedisForwarder{Address: "100", Password: "10", Database: 1}
+	forwarder.once.Do(forwarder.initClient)
+	r.NotNil(forwarder.Client)
+	r.Equal("100", forwarder.Client.(*redis.Client).Options().Addr)
+	r.Equal("10", forwarder.Client.(*redis.Client).Options().Password)
+	r.Equal(1, forwarder.Client.(*redis.Client).Options().DB)
+	r.NoError(forwarder.Close())
+}
+
+func (r *RedisForwarderTestSuite) TestForward() {
+	logEvent := testLogEvent()
+	r.mock.ExpectXAdd(&redis.XAddArgs{Stream: "optimizely-events", Values: []interface{}{"sdkKey", "sdkKey", "event", r.encode(logEvent.Event)}}).SetVal("1-0")
+	r.NoError(r.forwarder.Forward("sdkKey", logEvent))
+}
+
+func (r *RedisForwarderTestSuite) TestForwardWithStreamAndMaxLen() {
+	r.forwarder.Stream = "events"
+	r.forwarder.MaxLen = 1000
+	logEvent := testLogEvent()
+	r.mock.ExpectXAdd(&redis.XAddArgs{Stream: "events", MaxLen: 1000, Approx: true, Values: []interface{}{"sdkKey", "sdkKey", "event", r.encode(logEvent.Event)}}).SetVal("1-0")
+	r.NoError(r.forwarder.Forward("sdkKey", logEvent))
+}
+
+func (r *RedisForwarderTestSuite) TestForwardError() {
+	logEvent := testLogEvent()
+	r.mock.ExpectXAdd(&redis.XAddArgs{Stream: "optimizely-events", Values: []interface{}{"sdkKey", "sdkKey", "event", r.encode(logEvent.Event)}}).SetErr(errors.New("unavailable"))
+	r.EqualError(r.forwarder.Forward("sdkKey", logEvent), "unavailable")
+}
+
+func (r *RedisForwarderTestSuite) TestUnmarshalJSON() {
+	forwarder := &RedisForwarder{}
+	r.NoError(forwarder.UnmarshalJSON([]byte(`{"host":"localhost:6379","auth_token":"secret","database":2,"stream":"events","maxLen":10}`)))
+	r.Equal("localhost:6379", forwarder.Address)
+	r.Equal("secret", forwarder.Password)
+	r.Equal(2, forwarder.Database)
+	r.Equal("events", forwarder.Stream)
+	r.Equal(int64(10), forwarder.MaxLen)
+}
+
+func TestRedisForwarderTestSuite(t *testing.T) {
+	suite.Run(t, new(RedisForwarderTestSuite))
+}
@@ -0,0 +1,77 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package eventqueue //
+package eventqueue
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+)
+
+// Store opens the persistent event queues of the SDK keys
+type Store interface {
+	// Open returns the event queue of the given sdkKey, holding at most maxSize events. The events left in the
+	// queue by a previous Agent process are part of the returned queue so that they are dispatched again
+	Open(sdkKey string, maxSize int) (Queue, error)
+}
+
+// Queue is an event queue of the batch event processor that outlives the Agent process
+type Queue interface {
+	event.Queue
+	// Close releases the resources held by the queue, the events it holds are kept
+	Close() error
+}
+
+// DiskUsage is implemented by the queues that keep their events on the local disk
+type DiskUsage interface {
+	// DiskUsage returns the number of bytes used by the queue on disk
+	DiskUsage() int64
+}
+
+// Creator type defines a function for creating an instance of a Store
+type Creator func() Store
+
+// Creators stores the mapping of Creator against eventQueueName
+var Creators = map[string]Creator{}
+
+// Add registers a creator against eventQueueName
+func Add(eventQueueName string, creator Creator) {
+	if _, ok := Creators[eventQueueName]; ok {
+		panic(fmt.Sprintf("Event Queue with name %q already exists", eventQueueName))
+	}
+	Creators[eventQueueName] = creator
+}
+
+// Encode serializes an event of the batch event processor queue
+func Encode(item interface{}) ([]byte, error) {
+	userEvent, ok := item.(event.UserEvent)
+	if !ok {
+		return nil, fmt.Errorf("unsupported event type %T", item)
+	}
+	return json.Marshal(userEvent)
+}
+
+// Decode deserializes an event serialized with Encode
+func Decode(data []byte) (interface{}, error) {
+	var userEvent event.UserEvent
+	if err := json.Unmarshal(data, &userEvent); err != nil {
+		return nil, err
+	}
+	return userEvent, nil
+}
@@ -0,0 +1,93 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package eventqueue //
+package eventqueue
+
+import (
+	"testing"
+
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockStore struct {
+}
+
+// Open is used to open the queue of an SDK key
+func (m *MockStore) Open(sdkKey string, maxSize int) (Queue, error) {
+	return nil, nil
+}
+
+func TestAdd(t *testing.T) {
+	mockStoreCreator := func() Store {
+		return &MockStore{}
+	}
+
+	Add("mock", mockStoreCreator)
+	creator := Creators["mock"]()
+	if _, ok := creator.(*MockStore); !ok {
+		assert.Fail(t, "Cannot convert to type MockStore")
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			assert.Fail(t, "Should have recovered")
+		}
+	}()
+
+	mockStoreCreator := func() Store {
+		return &MockStore{}
+	}
+
+	Add("mock", mockStoreCreator)
+	Add("mock", mockStoreCreator)
+	assert.Fail(t, "Should have panicked")
+}
+
+func TestDoesNotExist(t *testing.T) {
+	dne := Creators["DNE"]
+	assert.Nil(t, dne)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	revenue := int64(100)
+	userEvent := event.UserEvent{
+		Timestamp:    1,
+		UUID:         "uuid",
+		EventContext: event.Context{Revision: "1", ProjectID: "2"},
+		VisitorID:    "user",
+		Conversion: &event.ConversionEvent{
+			Key:        "purchase",
+			Attributes: []event.VisitorAttribute{{Key: "plan", Value: "gold"}},
+			Revenue:    &revenue,
+		},
+	}
+
+	data, err := Encode(userEvent)
+	assert.NoError(t, err)
+	decoded, err := Decode(data)
+	assert.NoError(t, err)
+	assert.Equal(t, userEvent, decoded)
+
+	_, err = Encode("event")
+	assert.EqualError(t, err, "unsupported event type string")
+
+	_, err = Decode([]byte("{"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,431 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/optimizely/agent/plugins/eventqueue"
+)
+
+const (
+	// defaultMaxSegmentSize is the size in bytes at which a new segment file is started when no maxSegmentSize is configured
+	defaultMaxSegmentSize = 4 * 1024 * 1024
+	segmentExtension      = ".ndjson"
+	headFileName          = "head"
+	lockFileName          = "lock"
+)
+
+// errLocked is returned by lockFile when the file is locked by another open file
+var errLocked = errors.New("locked")
+
+// FileStore keeps the event queue of each SDK key in a directory of append-only segment files
+type FileStore struct {
+	Dir            string `json:"dir"`
+	MaxSegmentSize int64  `json:"maxSegmentSize"`
+	// Sync flushes every event to the disk before it is queued, at the cost of throughput
+	Sync bool `json:"sync"`
+}
+
+// Open returns the queue of sdkKey kept in <dir>/<sdkKey>, replaying the events that were not removed. The
+// directory is locked until the queue is closed, so that it fails while the queue of sdkKey is open in this or
+// another process, which would replay and dispatch the same events.
+func (f *FileStore) Open(sdkKey string, maxSize int) (eventqueue.Queue, error) {
+	if f.Dir == "" {
+		return nil, errors.New("file event queue requires a dir")
+	}
+
+	maxSegmentSize := f.MaxSegmentSize
+	if maxSegmentSize <= 0 {
+		maxSegmentSize = defaultMaxSegmentSize
+	}
+	q := &FileQueue{
+		dir:            filepath.Join(f.Dir, url.PathEscape(sdkKey)),
+		maxSize:        maxSize,
+		maxSegmentSize: maxSegmentSize,
+		sync:           f.Sync,
+		segmentSizes:   map[uint64]int64{},
+	}
+	if err := os.MkdirAll(q.dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := q.lockDir(); err != nil {
+		return nil, err
+	}
+	if err := q.replay(); err != nil {
+		_ = q.lockFile.Close()
+		return nil, err
+	}
+	return q, nil
+}
+
+// position locates an event in the segment files
+type position struct {
+	Segment uint64 `json:"segment"`
+	Line    int    `json:"line"`
+}
+
+type fileQueueEntry struct {
+	item      interface{}
+	position  position
+	persisted bool
+}
+
+// FileQueue appends events to the current segment file and records the position of the first event that was
+// not removed in a head file. Segment files are deleted once all of their events are removed.
+type FileQueue struct {
+	lock           sync.Mutex
+	dir            string
+	maxSize        int
+	maxSegmentSize int64
+	sync           bool
+
+	entries      []fileQueueEntry
+	head         position
+	segment      uint64
+	lines        int
+	file         *os.File
+	segmentSizes map[uint64]int64
+	lockFile     *os.File
+}
+
+// Add appends the event to the queue. Events that can not be written are kept in memory only
+func (q *FileQueue) Add(item interface{}) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if len(q.entries) >= q.maxSize {
+		log.Warn().Msg("MaxQueueSize has been met. Discarding event")
+		return
+	}
+
+	entry := fileQueueEntry{item: item}
+	if pos, err := q.write(item); err != nil {
+		log.Error().Err(err).Str("dir", q.dir).Msg("Unable to persist event, it is kept in memory only")
+	} else {
+		entry.position = pos
+		entry.persisted = true
+	}
+	q.entries = append(q.entries, entry)
+}
+
+// Get returns the first count events of the queue
+func (q *FileQueue) Get(count int) []interface{} {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.items(count)
+}
+
+// Remove removes the first count events of the queue and returns them
+func (q *FileQueue) Remove(count int) []interface{} {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	items := q.items(count)
+	q.entries = q.entries[len(items):]
+
+	if len(q.entries) == 0 && q.file != nil {
+		// Every event of the current segment was removed, start a new one so that it can be deleted
+		q.closeSegment()
+	}
+	head := position{Segment: q.segment, Line: q.lines}
+	for _, entry := range q.entries {
+		if entry.persisted {
+			head = entry.position
+			break
+		}
+	}
+	if head != q.head {
+		if err := q.saveHead(head); err != nil {
+			log.Error().Err(err).Str("dir", q.dir).Msg("Unable to save event queue head, removed events may be dispatched again")
+		}
+		if head.Segment != q.head.Segment {
+			q.deleteSegmentsBefore(head.Segment)
+		}
+		q.head = head
+	}
+	return items
+}
+
+// Size returns the number of events in the queue
+func (q *FileQueue) Size() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return len(q.entries)
+}
+
+// DiskUsage returns the size in bytes of the segment files
+func (q *FileQueue) DiskUsage() int64 {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	var total int64
+	for _, size := range q.segmentSizes {
+		total += size
+	}
+	return total
+}
+
+// Close closes the current segment file and unlocks the directory, the events that were not removed are replayed
+// by the next Open
+func (q *FileQueue) Close() error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	var err error
+	if q.file != nil {
+		err = q.file.Close()
+		q.file = nil
+	}
+	if q.lockFile != nil {
+		if lockErr := q.lockFile.Close(); err == nil {
+			err = lockErr
+		}
+		q.lockFile = nil
+	}
+	return err
+}
+
+// lockDir takes the exclusive lock of the directory of the queue
+func (q *FileQueue) lockDir() error {
+	file, err := os.OpenFile(filepath.Join(q.dir, lockFileName), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := lockFile(file); err != nil {
+		_ = file.Close()
+		if errors.Is(err, errLocked) {
+			return fmt.Errorf("event queue in %s is already open", q.dir)
+		}
+		return err
+	}
+	q.lockFile = file
+	return nil
+}
+
+func (q *FileQueue) items(count int) []interface{} {
+	if count > len(q.entries) {
+		count = len(q.entries)
+	}
+	items := make([]interface{}, count)
+	for i := range items {
+		items[i] = q.entries[i].item
+	}
+	return items
+}
+
+// write appends the event to the current segment file, starting a new one once it is full
+func (q *FileQueue) write(item interface{}) (position, error) {
+	data, err := eventqueue.Encode(item)
+	if err != nil {
+		return position{}, err
+	}
+
+	if q.file != nil && q.segmentSizes[q.segment] >= q.maxSegmentSize {
+		q.closeSegment()
+	}
+	if q.file == nil {
+		file, err := os.OpenFile(q.segmentPath(q.segment), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return position{}, err
+		}
+		q.file = file
+	}
+
+	n, err := q.file.Write(append(data, '\n'))
+	q.segmentSizes[q.segment] += int64(n)
+	if err != nil {
+		return position{}, err
+	}
+	if q.sync {
+		if err := q.file.Sync(); err != nil {
+			return position{}, err
+		}
+	}
+
+	pos := position{Segment: q.segment, Line: q.lines}
+	q.lines++
+	return pos, nil
+}
+
+// closeSegment closes the current segment file, the next event is written to a new one
+func (q *FileQueue) closeSegment() {
+	if err := q.file.Close(); err != nil {
+		log.Warn().Err(err).Str("dir", q.dir).Msg("Unable to close event queue segment")
+	}
+	q.file = nil
+	q.segment++
+	q.lines = 0
+}
+
+// replay loads the events of the segment files that follow the head. Events are always appended to a new
+// segment afterwards, so that a line left partially written by a crash is never continued.
+func (q *FileQueue) replay() error {
+	if data, err := os.ReadFile(filepath.Join(q.dir, headFileName)); err == nil {
+		if err := json.Unmarshal(data, &q.head); err != nil {
+			log.Warn().Err(err).Str("dir", q.dir).Msg("Invalid event queue head, every stored event is replayed")
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	segments, err := q.listSegments()
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		q.segment = segment
+		if segment < q.head.Segment {
+			continue
+		}
+		if err := q.replaySegment(segment); err != nil {
+			return err
+		}
+	}
+
+	q.segment++
+	if len(q.entries) > 0 {
+		q.head = q.entries[0].position
+		log.Info().Int("events", len(q.entries)).Str("dir", q.dir).Msg("Replaying events of the event queue")
+	} else {
+		q.head = position{Segment: q.segment}
+	}
+	q.deleteSegmentsBefore(q.head.Segment)
+	return nil
+}
+
+func (q *FileQueue) replaySegment(segment uint64) error {
+	file, err := os.Open(q.segmentPath(segment))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for line := 0; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		q.segmentSizes[segment] += int64(len(data))
+		if errors.Is(err, io.EOF) {
+			if len(data) > 0 {
+				log.Warn().Str("dir", q.dir).Uint64("segment", segment).Msg("Skipping partially written event")
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if segment == q.head.Segment && line < q.head.Line {
+			continue
+		}
+
+		item, err := eventqueue.Decode(data)
+		if err != nil {
+			log.Warn().Err(err).Str("dir", q.dir).Uint64("segment", segment).Msg("Skipping invalid event")
+			continue
+		}
+		q.entries = append(q.entries, fileQueueEntry{item: item, position: position{Segment: segment, Line: line}, persisted: true})
+	}
+}
+
+// listSegments returns the numbers of the segment files in ascending order
+func (q *FileQueue) listSegments() ([]uint64, error) {
+	files, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := []uint64{}
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, segmentExtension) {
+			continue
+		}
+		if segment, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExtension), 10, 64); err == nil {
+			segments = append(segments, segment)
+		}
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i] < segments[j] })
+	return segments, nil
+}
+
+func (q *FileQueue) deleteSegmentsBefore(segment uint64) {
+	segments, err := q.listSegments()
+	if err != nil {
+		log.Warn().Err(err).Str("dir", q.dir).Msg("Unable to list event queue segments")
+		return
+	}
+	for _, s := range segments {
+		if s >= segment {
+			break
+		}
+		if err := os.Remove(q.segmentPath(s)); err != nil {
+			log.Warn().Err(err).Str("dir", q.dir).Uint64("segment", s).Msg("Unable to delete event queue segment")
+			continue
+		}
+		delete(q.segmentSizes, s)
+	}
+}
+
+// saveHead atomically replaces the head file
+func (q *FileQueue) saveHead(head position) error {
+	data, err := json.Marshal(head)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(q.dir, ".head-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(q.dir, headFileName))
+}
+
+func (q *FileQueue) segmentPath(segment uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", segment, segmentExtension))
+}
+
+func init() {
+	fileStoreCreator := func() eventqueue.Store {
+		return &FileStore{}
+	}
+	eventqueue.Add("file", fileStoreCreator)
+}
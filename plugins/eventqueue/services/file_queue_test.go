@@ -0,0 +1,209 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/agent/plugins/eventqueue"
+)
+
+type FileQueueTestSuite struct {
+	suite.Suite
+	store FileStore
+}
+
+func (f *FileQueueTestSuite) SetupTest() {
+	f.store = FileStore{Dir: filepath.Join(f.T().TempDir(), "events")}
+}
+
+func (f *FileQueueTestSuite) open() eventqueue.Queue {
+	q, err := f.store.Open("sdkKey", 100)
+	f.Require().NoError(err)
+	return q
+}
+
+func userEvent(visitorID string) event.UserEvent {
+	return event.UserEvent{
+		UUID:      "uuid-" + visitorID,
+		VisitorID: visitorID,
+		Conversion: &event.ConversionEvent{
+			Key:  "purchase",
+			Tags: map[string]interface{}{"revenue": float64(100)},
+		},
+	}
+}
+
+func (f *FileQueueTestSuite) segments() []string {
+	files, err := filepath.Glob(filepath.Join(f.store.Dir, "sdkKey", "*.ndjson"))
+	f.Require().NoError(err)
+	return files
+}
+
+func (f *FileQueueTestSuite) TestAddGetRemove() {
+	q := f.open()
+	defer q.Close()
+
+	q.Add(userEvent("user1"))
+	q.Add(userEvent("user2"))
+	q.Add(userEvent("user3"))
+	f.Equal(3, q.Size())
+	f.Equal([]interface{}{userEvent("user1"), userEvent("user2")}, q.Get(2))
+	f.Equal(3, q.Size())
+
+	f.Equal([]interface{}{userEvent("user1")}, q.Remove(1))
+	f.Equal(2, q.Size())
+	f.Equal([]interface{}{userEvent("user2"), userEvent("user3")}, q.Remove(5))
+	f.Equal(0, q.Size())
+	f.Empty(q.Get(1))
+}
+
+func (f *FileQueueTestSuite) TestReplay() {
+	q := f.open()
+	q.Add(userEvent("user1"))
+	q.Add(userEvent("user2"))
+	q.Add(userEvent("user3"))
+	q.Remove(1)
+	f.NoError(q.Close())
+
+	// Events that were not removed are replayed, and events added afterwards follow them
+	q = f.open()
+	f.Equal([]interface{}{userEvent("user2"), userEvent("user3")}, q.Get(5))
+	q.Add(userEvent("user4"))
+	q.Remove(2)
+	f.NoError(q.Close())
+
+	q = f.open()
+	defer q.Close()
+	f.Equal([]interface{}{userEvent("user4")}, q.Get(5))
+}
+
+func (f *FileQueueTestSuite) TestReplayWithoutClose() {
+	q := f.open()
+	q.Add(userEvent("user1"))
+	q.Add(userEvent("user2"))
+
+	// A process that crashed never closes its queue, its lock is released when it exits
+	f.NoError(q.(*FileQueue).lockFile.Close())
+	replayed := f.open()
+	defer replayed.Close()
+	f.Equal([]interface{}{userEvent("user1"), userEvent("user2")}, replayed.Get(5))
+}
+
+func (f *FileQueueTestSuite) TestSkipsPartiallyWrittenEvent() {
+	q := f.open()
+	q.Add(userEvent("user1"))
+	f.NoError(q.Close())
+
+	segments := f.segments()
+	f.Require().Len(segments, 1)
+	file, err := os.OpenFile(segments[0], os.O_APPEND|os.O_WRONLY, 0o644)
+	f.Require().NoError(err)
+	_, err = file.WriteString(`{"uuid":"uuid-user2","Visi`)
+	f.NoError(err)
+	f.NoError(file.Close())
+
+	q = f.open()
+	f.Equal([]interface{}{userEvent("user1")}, q.Get(5))
+	q.Add(userEvent("user3"))
+	f.NoError(q.Close())
+
+	q = f.open()
+	defer q.Close()
+	f.Equal([]interface{}{userEvent("user1"), userEvent("user3")}, q.Get(5))
+}
+
+func (f *FileQueueTestSuite) TestSegmentsAreDeletedOnceRemoved() {
+	f.store.MaxSegmentSize = 1
+	q := f.open()
+	defer q.Close()
+
+	q.Add(userEvent("user1"))
+	q.Add(userEvent("user2"))
+	q.Add(userEvent("user3"))
+	f.Len(f.segments(), 3)
+	diskUsage := q.(eventqueue.DiskUsage).DiskUsage()
+	f.Greater(diskUsage, int64(0))
+
+	q.Remove(2)
+	f.Len(f.segments(), 1)
+	f.Equal(diskUsage/3, q.(eventqueue.DiskUsage).DiskUsage())
+
+	q.Remove(1)
+	f.Empty(f.segments())
+	f.Equal(int64(0), q.(eventqueue.DiskUsage).DiskUsage())
+}
+
+func (f *FileQueueTestSuite) TestMaxSize() {
+	q, err := f.store.Open("sdkKey", 1)
+	f.Require().NoError(err)
+	defer q.Close()
+
+	q.Add(userEvent("user1"))
+	q.Add(userEvent("user2"))
+	f.Equal([]interface{}{userEvent("user1")}, q.Get(5))
+}
+
+func (f *FileQueueTestSuite) TestUnsupportedEventIsKeptInMemory() {
+	q := f.open()
+	q.Add("not an event")
+	q.Add(userEvent("user1"))
+	f.Equal([]interface{}{"not an event", userEvent("user1")}, q.Get(5))
+	f.NoError(q.Close())
+
+	q = f.open()
+	defer q.Close()
+	f.Equal([]interface{}{userEvent("user1")}, q.Get(5))
+}
+
+func (f *FileQueueTestSuite) TestSDKKeyIsEscaped() {
+	q, err := f.store.Open("../sdk/Key", 10)
+	f.Require().NoError(err)
+	defer q.Close()
+
+	_, err = os.Stat(filepath.Join(f.store.Dir, "..%2Fsdk%2FKey"))
+	f.NoError(err)
+}
+
+func (f *FileQueueTestSuite) TestOpenLocksTheQueue() {
+	q := f.open()
+	q.Add(userEvent("user1"))
+
+	_, err := f.store.Open("sdkKey", 100)
+	f.EqualError(err, "event queue in "+filepath.Join(f.store.Dir, "sdkKey")+" is already open")
+
+	f.NoError(q.Close())
+	q = f.open()
+	defer q.Close()
+	f.Equal([]interface{}{userEvent("user1")}, q.Get(5))
+}
+
+func (f *FileQueueTestSuite) TestOpenWithoutDir() {
+	f.store.Dir = ""
+	_, err := f.store.Open("sdkKey", 10)
+	f.EqualError(err, "file event queue requires a dir")
+}
+
+func TestFileQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(FileQueueTestSuite))
+}
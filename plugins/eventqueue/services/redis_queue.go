@@ -0,0 +1,458 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/rs/zerolog/log"
+
+	"github.com/optimizely/agent/pkg/utils/redisauth"
+	"github.com/optimizely/agent/pkg/utils/redisclient"
+	"github.com/optimizely/agent/plugins/eventqueue"
+	"github.com/optimizely/agent/plugins/utils"
+)
+
+var ctx = context.Background()
+
+const (
+	// defaultRedisPrefix is prepended to the queue keys when no prefix is configured
+	defaultRedisPrefix = "optimizely-events-"
+	// defaultLeaseTTL is the time after which the events of an unresponsive Agent are claimed by the others
+	defaultLeaseTTL = 30 * time.Second
+	// defaultDedupTTL is the time during which the UUIDs of dispatched events are remembered
+	defaultDedupTTL = 24 * time.Hour
+)
+
+// pushScript appends an event to the list unless it holds maxSize events already
+var pushScript = redis.NewScript(`
+if redis.call('LLEN', KEYS[1]) >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('RPUSH', KEYS[1], ARGV[2])
+return 1
+`)
+
+// claimScript moves the events of a consumer whose lease expired to the list of the calling consumer, up to maxSize
+// events. The consumer is forgotten once all of its events are claimed. Since the script runs atomically an event
+// is claimed by a single consumer. KEYS holds the consumers set, the list and lease of the expired consumer and the
+// list of the calling consumer, and ARGV the ID of the expired consumer and maxSize. It returns the number of
+// claimed events.
+var claimScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[3]) == 1 then
+	return 0
+end
+local claimed = 0
+while redis.call('LLEN', KEYS[4]) < tonumber(ARGV[2]) do
+	if not redis.call('LMOVE', KEYS[2], KEYS[4], 'LEFT', 'RIGHT') then
+		break
+	end
+	claimed = claimed + 1
+end
+if redis.call('EXISTS', KEYS[2]) == 0 then
+	redis.call('SREM', KEYS[1], ARGV[1])
+end
+return claimed
+`)
+
+// removeScript records the UUIDs of the dispatched events so that they are not dispatched again by the consumer
+// that claims them, and removes the events from the list. KEYS holds the list and then the dispatched key of every
+// event that has a UUID. ARGV holds the dedup TTL in milliseconds and then pairs of a flag, set to 1 when the event
+// has a dispatched key, and list member.
+var removeScript = redis.NewScript(`
+local key = 2
+for i = 2, #ARGV, 2 do
+	if ARGV[i] == '1' then
+		redis.call('SET', KEYS[key], 1, 'PX', ARGV[1])
+		key = key + 1
+	end
+	redis.call('LREM', KEYS[1], 1, ARGV[i + 1])
+end
+return 1
+`)
+
+// RedisStore keeps the event queues in redis so that the events of an Agent instance that stopped are dispatched
+// by the other instances. Every queue is a list owned by a single Agent instance, which renews a lease while it
+// runs. The events of a queue whose lease expired are claimed by another instance of the same SDK key.
+// The keys of an SDK key share the {prefix + sdkKey} hash tag, so that they are on the same node of a cluster.
+// The store requires redis 6.2 or later for LMOVE.
+type RedisStore struct {
+	Client   redis.UniversalClient
+	Address  string `json:"host"`
+	Password string `json:"password"`
+	Database int    `json:"database"`
+	Prefix   string `json:"prefix"`
+	// LeaseTTL is the time after which the events of an unresponsive Agent instance are claimed
+	LeaseTTL utils.Duration `json:"leaseTTL"`
+	// DedupTTL is the time during which dispatched events are not dispatched again by the instance claiming them
+	DedupTTL utils.Duration `json:"dedupTTL"`
+	// Config holds the mode, TLS, pool and timeout settings of the connection
+	redisclient.Config
+	once sync.Once
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling with flexible password field names
+// Supports: auth_token, redis_secret, password (in order of preference)
+// Fallback: REDIS_EVENTQUEUE_PASSWORD environment variable
+func (r *RedisStore) UnmarshalJSON(data []byte) error {
+	// Use an alias type to avoid infinite recursion
+	type Alias RedisStore
+	alias := (*Alias)(r)
+
+	// Use shared unmarshal logic with password extraction
+	password, err := redisauth.UnmarshalWithPasswordExtraction(data, alias, "REDIS_EVENTQUEUE_PASSWORD")
+	if err != nil {
+		return err
+	}
+
+	r.Password = password
+	return nil
+}
+
+// Open registers a new consumer for sdkKey and claims the events left by the consumers whose lease expired
+func (r *RedisStore) Open(sdkKey string, maxSize int) (eventqueue.Queue, error) {
+	r.once.Do(r.initClient)
+
+	q := r.newQueue(sdkKey, uuid.NewString(), maxSize)
+	if err := q.renew(); err != nil {
+		return nil, err
+	}
+	if _, err := q.claim(); err != nil {
+		log.Error().Err(err).Str("consumer", q.id).Msg("Unable to claim the events of expired consumers")
+	}
+
+	q.wg.Add(1)
+	go q.run()
+	return q, nil
+}
+
+func (r *RedisStore) newQueue(sdkKey, id string, maxSize int) *RedisQueue {
+	prefix := r.Prefix
+	if prefix == "" {
+		prefix = defaultRedisPrefix
+	}
+	leaseTTL := r.LeaseTTL.Duration
+	if leaseTTL <= 0 {
+		leaseTTL = defaultLeaseTTL
+	}
+	dedupTTL := r.DedupTTL.Duration
+	if dedupTTL <= 0 {
+		dedupTTL = defaultDedupTTL
+	}
+
+	keyPrefix := "{" + prefix + sdkKey + "}:"
+	return &RedisQueue{
+		client:     r.Client,
+		id:         id,
+		maxSize:    maxSize,
+		leaseTTL:   leaseTTL,
+		dedupTTL:   dedupTTL,
+		keyPrefix:  keyPrefix,
+		consumers:  keyPrefix + "consumers",
+		list:       keyPrefix + id,
+		lease:      keyPrefix + id + ":lease",
+		dispatched: keyPrefix + "dispatched:",
+		done:       make(chan struct{}),
+	}
+}
+
+func (r *RedisStore) initClient() {
+	if r.Client != nil {
+		return
+	}
+	r.Client = redisclient.NewClient(r.Address, r.Password, r.Database, r.Config)
+}
+
+// RedisQueue is the list of events of a single consumer, the events returned by Get are kept until they are
+// removed so that their UUIDs are recorded as dispatched even when the list was claimed in the meantime
+type RedisQueue struct {
+	client   redis.UniversalClient
+	id       string
+	maxSize  int
+	leaseTTL time.Duration
+	dedupTTL time.Duration
+
+	keyPrefix  string
+	consumers  string
+	list       string
+	lease      string
+	dispatched string
+
+	lock    sync.Mutex
+	pending []redisQueueMember
+	// failed is set when the events could not be read or removed, see Size
+	failed bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+type redisQueueMember struct {
+	uuid  string
+	value string
+	item  interface{}
+}
+
+// Add appends the event to the list of the consumer
+func (q *RedisQueue) Add(item interface{}) {
+	value, err := eventqueue.Encode(item)
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to encode event, it is discarded")
+		return
+	}
+
+	added, err := pushScript.Run(ctx, q.client, []string{q.list}, q.maxSize, string(value)).Int()
+	switch {
+	case err != nil:
+		log.Error().Err(err).Str("consumer", q.id).Msg("Unable to queue event, it is discarded")
+	case added == 0:
+		log.Warn().Msg("MaxQueueSize has been met. Discarding event")
+	}
+}
+
+// Get returns the first count events of the list, skipping the events already dispatched by another consumer
+func (q *RedisQueue) Get(count int) []interface{} {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	members, err := q.get(count)
+	if err != nil {
+		log.Error().Err(err).Str("consumer", q.id).Msg("Unable to get queued events")
+		q.failed = true
+		return []interface{}{}
+	}
+	q.pending = members
+
+	items := make([]interface{}, 0, len(members))
+	for _, member := range members {
+		items = append(items, member.item)
+	}
+	return items
+}
+
+// Remove records the first count events as dispatched and removes them from the list
+func (q *RedisQueue) Remove(count int) []interface{} {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	members := q.pending
+	q.pending = nil
+	if len(members) < count {
+		var err error
+		if members, err = q.get(count); err != nil {
+			log.Error().Err(err).Str("consumer", q.id).Msg("Unable to remove queued events")
+			q.failed = true
+			return []interface{}{}
+		}
+	}
+	if len(members) > count {
+		members = members[:count]
+	}
+	if len(members) == 0 {
+		return []interface{}{}
+	}
+
+	keys := make([]string, 0, 1+len(members))
+	keys = append(keys, q.list)
+	args := make([]interface{}, 0, 1+2*len(members))
+	args = append(args, q.dedupTTL.Milliseconds())
+	items := make([]interface{}, 0, len(members))
+	for _, member := range members {
+		if member.uuid != "" {
+			keys = append(keys, q.dispatched+member.uuid)
+			args = append(args, 1, member.value)
+		} else {
+			args = append(args, 0, member.value)
+		}
+		items = append(items, member.item)
+	}
+	if err := removeScript.Run(ctx, q.client, keys, args...).Err(); err != nil {
+		log.Error().Err(err).Str("consumer", q.id).Msg("Unable to remove queued events")
+		q.failed = true
+		return []interface{}{}
+	}
+	return items
+}
+
+// Size returns the number of events in the list. It returns 0 once after events could not be read or removed, so
+// that the event processor, which flushes while the queue is not empty, stops until its next flush instead of
+// retrying right away.
+func (q *RedisQueue) Size() int {
+	q.lock.Lock()
+	failed := q.failed
+	q.failed = false
+	q.lock.Unlock()
+	if failed {
+		return 0
+	}
+
+	size, err := q.client.LLen(ctx, q.list).Result()
+	if err != nil {
+		log.Error().Err(err).Str("consumer", q.id).Msg("Unable to get the event queue size")
+		return 0
+	}
+	return int(size)
+}
+
+// Close stops renewing the lease and releases it, so that the events left in the list are claimed by another
+// consumer right away
+func (q *RedisQueue) Close() error {
+	close(q.done)
+	q.wg.Wait()
+
+	if err := q.client.Del(ctx, q.lease).Err(); err != nil {
+		return err
+	}
+	size, err := q.client.LLen(ctx, q.list).Result()
+	if err != nil {
+		return err
+	}
+	if size == 0 {
+		return q.client.SRem(ctx, q.consumers, q.id).Err()
+	}
+	return nil
+}
+
+// get reads the first count events of the list. Events dispatched by another consumer and events that can not be
+// decoded are removed from the list and replaced by the following ones.
+func (q *RedisQueue) get(count int) ([]redisQueueMember, error) {
+	for {
+		values, err := q.client.LRange(ctx, q.list, 0, int64(count-1)).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		members := make([]redisQueueMember, 0, len(values))
+		checks := make([]*redis.IntCmd, 0, len(values))
+		pipe := q.client.Pipeline()
+		var discarded []string
+		for _, value := range values {
+			item, err := eventqueue.Decode([]byte(value))
+			if err != nil {
+				log.Error().Err(err).Str("consumer", q.id).Msg("Discarding invalid queued event")
+				discarded = append(discarded, value)
+				continue
+			}
+			member := redisQueueMember{value: value, item: item, uuid: eventUUID(item)}
+			members = append(members, member)
+			if member.uuid != "" {
+				checks = append(checks, pipe.Exists(ctx, q.dispatched+member.uuid))
+			} else {
+				checks = append(checks, nil)
+			}
+		}
+		if pipe.Len() > 0 {
+			if _, err := pipe.Exec(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		undispatched := members[:0]
+		for i, member := range members {
+			if checks[i] != nil && checks[i].Val() > 0 {
+				log.Debug().Str("uuid", member.uuid).Msg("Discarding queued event dispatched by another consumer")
+				discarded = append(discarded, member.value)
+				continue
+			}
+			undispatched = append(undispatched, member)
+		}
+		if len(discarded) == 0 {
+			return undispatched, nil
+		}
+
+		for _, value := range discarded {
+			if err := q.client.LRem(ctx, q.list, 1, value).Err(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// renew registers the consumer and extends its lease
+func (q *RedisQueue) renew() error {
+	if err := q.client.SAdd(ctx, q.consumers, q.id).Err(); err != nil {
+		return err
+	}
+	return q.client.Set(ctx, q.lease, 1, q.leaseTTL).Err()
+}
+
+// claim moves the events of the consumers whose lease expired to the list of this consumer
+func (q *RedisQueue) claim() (int, error) {
+	ids, err := q.client.SMembers(ctx, q.consumers).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	claimed := 0
+	for _, id := range ids {
+		if id == q.id {
+			continue
+		}
+		list := q.keyPrefix + id
+		n, err := claimScript.Run(ctx, q.client, []string{q.consumers, list, list + ":lease", q.list}, id, q.maxSize).Int()
+		if err != nil {
+			return claimed, err
+		}
+		claimed += n
+	}
+	if claimed > 0 {
+		log.Info().Int("events", claimed).Str("consumer", q.id).Msg("Claimed the events of expired consumers")
+	}
+	return claimed, nil
+}
+
+// run renews the lease and claims expired consumers three times per lease TTL until the queue is closed
+func (q *RedisQueue) run() {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.leaseTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.done:
+			return
+		case <-ticker.C:
+			if err := q.renew(); err != nil {
+				log.Error().Err(err).Str("consumer", q.id).Msg("Unable to renew the event queue lease")
+				continue
+			}
+			if _, err := q.claim(); err != nil {
+				log.Error().Err(err).Str("consumer", q.id).Msg("Unable to claim the events of expired consumers")
+			}
+		}
+	}
+}
+
+func eventUUID(item interface{}) string {
+	if userEvent, ok := item.(event.UserEvent); ok {
+		return userEvent.UUID
+	}
+	return ""
+}
+
+func init() {
+	redisStoreCreator := func() eventqueue.Store {
+		return &RedisStore{}
+	}
+	eventqueue.Add("redis", redisStoreCreator)
+}
@@ -0,0 +1,195 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-redis/redismock/v8"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/agent/plugins/eventqueue"
+	"github.com/optimizely/agent/plugins/utils"
+)
+
+const (
+	testList      = "{optimizely-events-sdkKey}:consumer"
+	testConsumers = "{optimizely-events-sdkKey}:consumers"
+)
+
+type RedisQueueTestSuite struct {
+	suite.Suite
+	store *RedisStore
+	queue *RedisQueue
+	mock  redismock.ClientMock
+}
+
+func (r *RedisQueueTestSuite) SetupTest() {
+	var client *redis.Client
+	client, r.mock = redismock.NewClientMock()
+	r.store = &RedisStore{Client: client, LeaseTTL: utils.Duration{Duration: time.Hour}}
+	r.queue = r.store.newQueue("sdkKey", "consumer", 100)
+}
+
+func (r *RedisQueueTestSuite) TearDownTest() {
+	r.NoError(r.mock.ExpectationsWereMet())
+}
+
+func (r *RedisQueueTestSuite) encode(visitorID string) string {
+	value, err := eventqueue.Encode(userEvent(visitorID))
+	r.Require().NoError(err)
+	return string(value)
+}
+
+func (r *RedisQueueTestSuite) TestFirstOpenConfiguresClient() {
+	store := &RedisStore{Address: "100", Password: "10", Database: 1}
+	_, err := store.Open("sdkKey", 100)
+	r.Error(err)
+	r.NotNil(store.Client)
+	r.Equal("100", store.Client.(*redis.Client).Options().Addr)
+	r.Equal("10", store.Client.(*redis.Client).Options().Password)
+	r.Equal(1, store.Client.(*redis.Client).Options().DB)
+}
+
+func (r *RedisQueueTestSuite) TestOpenAndClose() {
+	r.mock.Regexp().ExpectSAdd(`\{optimizely-events-sdkKey\}:consumers`, `.+`).SetVal(1)
+	r.mock.Regexp().ExpectSet(`\{optimizely-events-sdkKey\}:.+:lease`, 1, time.Hour).SetVal("OK")
+	r.mock.ExpectSMembers(testConsumers).SetVal([]string{"expired"})
+	r.mock.Regexp().ExpectEvalSha(claimScript.Hash(), []string{`\{optimizely-events-sdkKey\}:consumers`, `\{optimizely-events-sdkKey\}:expired`, `\{optimizely-events-sdkKey\}:expired:lease`, `\{optimizely-events-sdkKey\}:.+`}, "expired", 100).SetVal(int64(2))
+	q, err := r.store.Open("sdkKey", 100)
+	r.Require().NoError(err)
+
+	r.mock.Regexp().ExpectDel(`\{optimizely-events-sdkKey\}:.+:lease`).SetVal(1)
+	r.mock.Regexp().ExpectLLen(`\{optimizely-events-sdkKey\}:.+`).SetVal(0)
+	r.mock.Regexp().ExpectSRem(`\{optimizely-events-sdkKey\}:consumers`, `.+`).SetVal(1)
+	r.NoError(q.Close())
+}
+
+func (r *RedisQueueTestSuite) TestOpenError() {
+	r.mock.ExpectSAdd(testConsumers, "consumer").SetErr(errors.New("unavailable"))
+	r.EqualError(r.queue.renew(), "unavailable")
+}
+
+func (r *RedisQueueTestSuite) TestCloseKeepsQueuedEvents() {
+	r.mock.ExpectDel(testList + ":lease").SetVal(1)
+	r.mock.ExpectLLen(testList).SetVal(2)
+	r.NoError(r.queue.Close())
+}
+
+func (r *RedisQueueTestSuite) TestAdd() {
+	r.mock.ExpectEvalSha(pushScript.Hash(), []string{testList}, 100, r.encode("1")).SetVal(int64(1))
+	r.queue.Add(userEvent("1"))
+
+	// full queue and unsupported events are discarded
+	r.mock.ExpectEvalSha(pushScript.Hash(), []string{testList}, 100, r.encode("2")).SetVal(int64(0))
+	r.queue.Add(userEvent("2"))
+	r.queue.Add("unsupported")
+}
+
+func (r *RedisQueueTestSuite) TestSize() {
+	r.mock.ExpectLLen(testList).SetVal(3)
+	r.Equal(3, r.queue.Size())
+
+	r.mock.ExpectLLen(testList).SetErr(errors.New("unavailable"))
+	r.Equal(0, r.queue.Size())
+}
+
+func (r *RedisQueueTestSuite) TestSizeAfterGetError() {
+	r.mock.ExpectLRange(testList, 0, 1).SetErr(errors.New("unavailable"))
+	r.Empty(r.queue.Get(2))
+
+	// The event processor stops flushing instead of spinning on events it can not get
+	r.Equal(0, r.queue.Size())
+	r.mock.ExpectLLen(testList).SetVal(3)
+	r.Equal(3, r.queue.Size())
+}
+
+func (r *RedisQueueTestSuite) TestSizeAfterRemoveError() {
+	r.mock.ExpectLRange(testList, 0, 0).SetVal([]string{r.encode("1")})
+	r.mock.ExpectExists("{optimizely-events-sdkKey}:dispatched:uuid-1").SetVal(0)
+	r.mock.ExpectEvalSha(removeScript.Hash(), []string{testList, "{optimizely-events-sdkKey}:dispatched:uuid-1"}, int64(86400000), 1, r.encode("1")).SetErr(errors.New("unavailable"))
+	r.Empty(r.queue.Remove(1))
+	r.Equal(0, r.queue.Size())
+}
+
+func (r *RedisQueueTestSuite) TestGetAndRemove() {
+	r.mock.ExpectLRange(testList, 0, 1).SetVal([]string{r.encode("1"), r.encode("2")})
+	r.mock.ExpectExists("{optimizely-events-sdkKey}:dispatched:uuid-1").SetVal(0)
+	r.mock.ExpectExists("{optimizely-events-sdkKey}:dispatched:uuid-2").SetVal(0)
+	r.Equal([]interface{}{userEvent("1"), userEvent("2")}, r.queue.Get(2))
+
+	// the events returned by Get are removed even when they were claimed in the meantime
+	r.mock.ExpectEvalSha(removeScript.Hash(), []string{testList, "{optimizely-events-sdkKey}:dispatched:uuid-1"}, int64(86400000), 1, r.encode("1")).SetVal(int64(1))
+	r.Equal([]interface{}{userEvent("1")}, r.queue.Remove(1))
+}
+
+func (r *RedisQueueTestSuite) TestRemoveWithoutGet() {
+	r.mock.ExpectLRange(testList, 0, 0).SetVal([]string{r.encode("1")})
+	r.mock.ExpectExists("{optimizely-events-sdkKey}:dispatched:uuid-1").SetVal(0)
+	r.mock.ExpectEvalSha(removeScript.Hash(), []string{testList, "{optimizely-events-sdkKey}:dispatched:uuid-1"}, int64(86400000), 1, r.encode("1")).SetVal(int64(1))
+	r.Equal([]interface{}{userEvent("1")}, r.queue.Remove(1))
+}
+
+func (r *RedisQueueTestSuite) TestGetSkipsDispatchedEvents() {
+	r.mock.ExpectLRange(testList, 0, 1).SetVal([]string{r.encode("1"), "invalid", r.encode("2")})
+	r.mock.ExpectExists("{optimizely-events-sdkKey}:dispatched:uuid-1").SetVal(1)
+	r.mock.ExpectExists("{optimizely-events-sdkKey}:dispatched:uuid-2").SetVal(0)
+	r.mock.ExpectLRem(testList, 1, "invalid").SetVal(1)
+	r.mock.ExpectLRem(testList, 1, r.encode("1")).SetVal(1)
+	r.mock.ExpectLRange(testList, 0, 1).SetVal([]string{r.encode("2")})
+	r.mock.ExpectExists("{optimizely-events-sdkKey}:dispatched:uuid-2").SetVal(0)
+	r.Equal([]interface{}{userEvent("2")}, r.queue.Get(2))
+}
+
+func (r *RedisQueueTestSuite) TestGetError() {
+	r.mock.ExpectLRange(testList, 0, 1).SetErr(errors.New("unavailable"))
+	r.Empty(r.queue.Get(2))
+}
+
+func (r *RedisQueueTestSuite) TestClaim() {
+	r.mock.ExpectSMembers(testConsumers).SetVal([]string{"consumer", "first", "second"})
+	r.mock.ExpectEvalSha(claimScript.Hash(), []string{testConsumers, "{optimizely-events-sdkKey}:first", "{optimizely-events-sdkKey}:first:lease", testList}, "first", 100).SetVal(int64(3))
+	r.mock.ExpectEvalSha(claimScript.Hash(), []string{testConsumers, "{optimizely-events-sdkKey}:second", "{optimizely-events-sdkKey}:second:lease", testList}, "second", 100).SetVal(int64(0))
+	claimed, err := r.queue.claim()
+	r.NoError(err)
+	r.Equal(3, claimed)
+}
+
+func (r *RedisQueueTestSuite) TestClaimError() {
+	r.mock.ExpectSMembers(testConsumers).SetErr(errors.New("unavailable"))
+	_, err := r.queue.claim()
+	r.EqualError(err, "unavailable")
+}
+
+func (r *RedisQueueTestSuite) TestUnmarshalJSON() {
+	store := &RedisStore{}
+	r.NoError(store.UnmarshalJSON([]byte(`{"host":"localhost:6379","auth_token":"secret","database":2,"prefix":"events-","leaseTTL":"10s","dedupTTL":"1h"}`)))
+	r.Equal("localhost:6379", store.Address)
+	r.Equal("secret", store.Password)
+	r.Equal(2, store.Database)
+	r.Equal("events-", store.Prefix)
+	r.Equal(10*time.Second, store.LeaseTTL.Duration)
+	r.Equal(time.Hour, store.DedupTTL.Duration)
+}
+
+func TestRedisQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(RedisQueueTestSuite))
+}
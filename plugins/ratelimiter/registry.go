@@ -0,0 +1,44 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package ratelimiter //
+package ratelimiter
+
+import (
+	"fmt"
+	"time"
+)
+
+// Limiter keeps a token bucket per key. Buckets hold up to burst tokens and are refilled at rate tokens per second.
+type Limiter interface {
+	// Take removes a token from the bucket of the given key. It returns whether a token was available and,
+	// when it was not, how long to wait for the next one
+	Take(key string, rate float64, burst int) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// Creator type defines a function for creating an instance of a Limiter
+type Creator func() Limiter
+
+// Creators stores the mapping of Creator against rateLimiterName
+var Creators = map[string]Creator{}
+
+// Add registers a creator against rateLimiterName
+func Add(rateLimiterName string, creator Creator) {
+	if _, ok := Creators[rateLimiterName]; ok {
+		panic(fmt.Sprintf("Rate Limiter with name %q already exists", rateLimiterName))
+	}
+	Creators[rateLimiterName] = creator
+}
@@ -0,0 +1,66 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package ratelimiter //
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockLimiter struct {
+}
+
+// Take is used to take a token
+func (m *MockLimiter) Take(key string, rate float64, burst int) (bool, time.Duration, error) {
+	return true, 0, nil
+}
+
+func TestAdd(t *testing.T) {
+	mockLimiterCreator := func() Limiter {
+		return &MockLimiter{}
+	}
+
+	Add("mock", mockLimiterCreator)
+	creator := Creators["mock"]()
+	if _, ok := creator.(*MockLimiter); !ok {
+		assert.Fail(t, "Cannot convert to type MockLimiter")
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			assert.Fail(t, "Should have recovered")
+		}
+	}()
+
+	mockLimiterCreator := func() Limiter {
+		return &MockLimiter{}
+	}
+
+	Add("mock", mockLimiterCreator)
+	Add("mock", mockLimiterCreator)
+	assert.Fail(t, "Should have panicked")
+}
+
+func TestDoesNotExist(t *testing.T) {
+	dne := Creators["DNE"]
+	assert.Nil(t, dne)
+}
@@ -0,0 +1,89 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/optimizely/agent/plugins/ratelimiter"
+)
+
+// sweepInterval is how often buckets that have been refilled completely are removed
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// InMemoryLimiter keeps the token buckets in memory, limits are enforced by each Agent instance separately
+type InMemoryLimiter struct {
+	lock      sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// Take removes a token from the bucket of the given key
+func (l *InMemoryLimiter) Take(key string, rate float64, burst int) (bool, time.Duration, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	if l.buckets == nil {
+		l.buckets = make(map[string]*bucket)
+		l.lastSweep = now
+	}
+
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweep(now, rate, burst)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+	return false, time.Duration((1 - b.tokens) / rate * float64(time.Second)), nil
+}
+
+// sweep removes the buckets that would be full by now, they are recreated on demand
+func (l *InMemoryLimiter) sweep(now time.Time, rate float64, burst int) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*rate >= float64(burst) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+func init() {
+	inMemoryLimiterCreator := func() ratelimiter.Limiter {
+		return &InMemoryLimiter{now: time.Now}
+	}
+	ratelimiter.Add("in-memory", inMemoryLimiterCreator)
+}
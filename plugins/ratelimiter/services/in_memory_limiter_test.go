@@ -0,0 +1,98 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type InMemoryLimiterTestSuite struct {
+	suite.Suite
+	limiter *InMemoryLimiter
+	now     time.Time
+}
+
+func (s *InMemoryLimiterTestSuite) SetupTest() {
+	s.now = time.Unix(0, 0)
+	s.limiter = &InMemoryLimiter{now: func() time.Time { return s.now }}
+}
+
+func (s *InMemoryLimiterTestSuite) take(key string) (bool, time.Duration) {
+	allowed, retryAfter, err := s.limiter.Take(key, 2, 3)
+	s.NoError(err)
+	return allowed, retryAfter
+}
+
+func (s *InMemoryLimiterTestSuite) TestBurst() {
+	for i := 0; i < 3; i++ {
+		allowed, _ := s.take("one")
+		s.True(allowed)
+	}
+
+	allowed, retryAfter := s.take("one")
+	s.False(allowed)
+	s.Equal(500*time.Millisecond, retryAfter)
+
+	// Buckets are independent
+	allowed, _ = s.take("two")
+	s.True(allowed)
+}
+
+func (s *InMemoryLimiterTestSuite) TestRefill() {
+	for i := 0; i < 3; i++ {
+		s.take("one")
+	}
+
+	s.now = s.now.Add(250 * time.Millisecond)
+	allowed, retryAfter := s.take("one")
+	s.False(allowed)
+	s.Equal(250*time.Millisecond, retryAfter)
+
+	s.now = s.now.Add(250 * time.Millisecond)
+	allowed, _ = s.take("one")
+	s.True(allowed)
+
+	// Buckets never hold more than burst tokens
+	s.now = s.now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		allowed, _ = s.take("one")
+		s.True(allowed)
+	}
+	allowed, _ = s.take("one")
+	s.False(allowed)
+}
+
+func (s *InMemoryLimiterTestSuite) TestSweep() {
+	s.take("one")
+	s.take("two")
+	s.take("two")
+	s.take("two")
+	s.Len(s.limiter.buckets, 2)
+
+	s.now = s.now.Add(sweepInterval)
+	s.take("three")
+	s.Len(s.limiter.buckets, 1)
+	s.Contains(s.limiter.buckets, "three")
+}
+
+func TestInMemoryLimiterTestSuite(t *testing.T) {
+	suite.Run(t, new(InMemoryLimiterTestSuite))
+}
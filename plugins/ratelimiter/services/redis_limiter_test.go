@@ -0,0 +1,90 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-redis/redismock/v8"
+	"github.com/stretchr/testify/suite"
+)
+
+type RedisLimiterTestSuite struct {
+	suite.Suite
+	limiter *RedisLimiter
+	mock    redismock.ClientMock
+}
+
+func (r *RedisLimiterTestSuite) SetupTest() {
+	var client *redis.Client
+	client, r.mock = redismock.NewClientMock()
+	r.limiter = &RedisLimiter{Client: client}
+}
+
+func (r *RedisLimiterTestSuite) TearDownTest() {
+	r.NoError(r.mock.ExpectationsWereMet())
+}
+
+func (r *RedisLimiterTestSuite) TestFirstTakeConfiguresClient() {
+	limiter := &RedisLimiter{Address: "100", Password: "10", Database: 1}
+	_, _, _ = limiter.Take("key", 1, 1)
+	r.NotNil(limiter.Client)
+	r.Equal("100", limiter.Client.(*redis.Client).Options().Addr)
+	r.Equal("10", limiter.Client.(*redis.Client).Options().Password)
+	r.Equal(1, limiter.Client.(*redis.Client).Options().DB)
+}
+
+func (r *RedisLimiterTestSuite) TestTakeAllowed() {
+	r.mock.ExpectEvalSha(takeScript.Hash(), []string{"optimizely-ratelimit-key"}, 2.5, 5).SetVal([]interface{}{int64(1), int64(0)})
+	allowed, retryAfter, err := r.limiter.Take("key", 2.5, 5)
+	r.NoError(err)
+	r.True(allowed)
+	r.Equal(time.Duration(0), retryAfter)
+}
+
+func (r *RedisLimiterTestSuite) TestTakeLimited() {
+	r.limiter.Prefix = "custom-"
+	r.mock.ExpectEvalSha(takeScript.Hash(), []string{"custom-key"}, 2.5, 5).SetVal([]interface{}{int64(0), int64(400)})
+	allowed, retryAfter, err := r.limiter.Take("key", 2.5, 5)
+	r.NoError(err)
+	r.False(allowed)
+	r.Equal(400*time.Millisecond, retryAfter)
+}
+
+func (r *RedisLimiterTestSuite) TestTakeError() {
+	r.mock.ExpectEvalSha(takeScript.Hash(), []string{"optimizely-ratelimit-key"}, 2.5, 5).SetErr(errors.New("unavailable"))
+	allowed, _, err := r.limiter.Take("key", 2.5, 5)
+	r.EqualError(err, "unavailable")
+	r.False(allowed)
+}
+
+func (r *RedisLimiterTestSuite) TestUnmarshalJSON() {
+	limiter := &RedisLimiter{}
+	r.NoError(limiter.UnmarshalJSON([]byte(`{"host":"localhost:6379","auth_token":"secret","database":2,"prefix":"rl-"}`)))
+	r.Equal("localhost:6379", limiter.Address)
+	r.Equal("secret", limiter.Password)
+	r.Equal(2, limiter.Database)
+	r.Equal("rl-", limiter.Prefix)
+}
+
+func TestRedisLimiterTestSuite(t *testing.T) {
+	suite.Run(t, new(RedisLimiterTestSuite))
+}
@@ -18,11 +18,73 @@
 package userprofileservice
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/optimizely/go-sdk/v2/pkg/decision"
 )
 
+// ErrNotSupported is returned by a Manager when its configuration does not support the operation
+var ErrNotSupported = errors.New("operation is not supported by the user profile service")
+
+// Manager is implemented by the user profile services that can delete and enumerate the saved profiles
+type Manager interface {
+	// Delete removes the profile of the user, deleting a profile that does not exist is not an error
+	Delete(userID string) error
+	// Range calls fn with every saved profile and stops at the first error returned by fn
+	Range(fn func(profile decision.UserProfile) error) error
+}
+
+// Composite is implemented by the user profile services built on other user profile services of the configuration
+type Composite interface {
+	// ServiceNames returns the names of the configured user profile services to build on
+	ServiceNames() []string
+	// SetServices is called with the user profile services created for ServiceNames, in the same order
+	SetServices(services []decision.UserProfileService) error
+}
+
+// CircuitState is the state of the circuit breaker of a user profile service
+type CircuitState string
+
+// Circuit breaker states, requests are only rejected while the circuit is open
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// CircuitStats holds the state and the counters of the circuit breaker of a user profile service
+type CircuitStats struct {
+	// State is empty when the service has no circuit breaker
+	State CircuitState
+	// Retries is the number of requests that were retried
+	Retries int64
+	// Failures is the number of requests to the backend that failed, retries included
+	Failures int64
+	// Rejected is the number of requests that were not sent because the circuit was open
+	Rejected int64
+}
+
+// CircuitBreaker is implemented by the user profile services that stop calling their backend after repeated failures
+type CircuitBreaker interface {
+	// CircuitStats returns the state of the circuit breaker and its counters since the service was created
+	CircuitStats() CircuitStats
+}
+
+// LookupWithError is implemented by the user profile services that tell the users without a profile apart from
+// the lookups that failed
+type LookupWithError interface {
+	// LookupWithError returns the profile of the user, an empty profile when the user has none, or an error when
+	// the profile could not be looked up
+	LookupWithError(userID string) (decision.UserProfile, error)
+}
+
+// Evictions is implemented by the user profile services that evict profiles themselves
+type Evictions interface {
+	// Evictions returns the number of profiles evicted since the service was created
+	Evictions() int64
+}
+
 // Creator type defines a function for creating an instance of a UserProfileService
 type Creator func() decision.UserProfileService
 
@@ -0,0 +1,108 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/optimizely/agent/plugins/userprofileservice"
+)
+
+// circuitBreaker opens after threshold consecutive failures, the requests are rejected while it is open. Once
+// resetTimeout has elapsed a single request is let through to probe the backend, its outcome closes or reopens it.
+// A threshold of 0 disables the breaker, failures are still counted.
+type circuitBreaker struct {
+	threshold    int
+	resetTimeout time.Duration
+	now          func() time.Time
+
+	lock     sync.Mutex
+	state    userprofileservice.CircuitState
+	failed   int
+	openedAt time.Time
+	probing  bool
+	stats    userprofileservice.CircuitStats
+}
+
+func newCircuitBreaker(threshold int, resetTimeout time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold:    threshold,
+		resetTimeout: resetTimeout,
+		now:          time.Now,
+		state:        userprofileservice.CircuitClosed,
+	}
+}
+
+// allow reports whether a request can be sent, the caller must record its outcome when it is
+func (b *circuitBreaker) allow() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	switch b.state {
+	case userprofileservice.CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.resetTimeout {
+			b.stats.Rejected++
+			return false
+		}
+		b.state = userprofileservice.CircuitHalfOpen
+		b.probing = true
+		return true
+	case userprofileservice.CircuitHalfOpen:
+		if b.probing {
+			b.stats.Rejected++
+			return false
+		}
+		b.probing = true
+	}
+	return true
+}
+
+// record records the outcome of a request allowed by the breaker
+func (b *circuitBreaker) record(success bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.probing = false
+	if success {
+		b.failed = 0
+		b.state = userprofileservice.CircuitClosed
+		return
+	}
+
+	b.stats.Failures++
+	b.failed++
+	if b.threshold > 0 && (b.state == userprofileservice.CircuitHalfOpen || b.failed >= b.threshold) {
+		b.state = userprofileservice.CircuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+func (b *circuitBreaker) retried() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.stats.Retries++
+}
+
+func (b *circuitBreaker) circuitStats() userprofileservice.CircuitStats {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	stats := b.stats
+	stats.State = b.state
+	return stats
+}
@@ -0,0 +1,79 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/optimizely/agent/plugins/userprofileservice"
+)
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		assert.True(t, b.allow())
+		b.record(false)
+	}
+	assert.Equal(t, userprofileservice.CircuitOpen, b.circuitStats().State)
+	assert.False(t, b.allow())
+
+	// A single probe is let through once the reset timeout elapsed, a failed probe reopens the circuit
+	now = now.Add(time.Minute)
+	assert.True(t, b.allow())
+	assert.Equal(t, userprofileservice.CircuitHalfOpen, b.circuitStats().State)
+	assert.False(t, b.allow())
+	b.record(false)
+	assert.Equal(t, userprofileservice.CircuitOpen, b.circuitStats().State)
+	assert.False(t, b.allow())
+
+	now = now.Add(time.Minute)
+	assert.True(t, b.allow())
+	b.record(true)
+	assert.True(t, b.allow())
+
+	assert.Equal(t, userprofileservice.CircuitStats{
+		State:    userprofileservice.CircuitClosed,
+		Failures: 3,
+		Rejected: 3,
+	}, b.circuitStats())
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b := newCircuitBreaker(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		assert.True(t, b.allow())
+		b.record(false)
+	}
+	assert.Equal(t, userprofileservice.CircuitStats{State: userprofileservice.CircuitClosed, Failures: 10}, b.circuitStats())
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 0; attempt < 3; attempt++ {
+		delay := backoff(100*time.Millisecond, attempt)
+		max := 100 * time.Millisecond << attempt
+		assert.GreaterOrEqual(t, delay, max/2)
+		assert.Less(t, delay, max)
+	}
+	assert.Equal(t, time.Duration(0), backoff(0, 2))
+}
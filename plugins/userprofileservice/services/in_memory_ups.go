@@ -18,9 +18,12 @@
 package services
 
 import (
+	"container/list"
 	"sync"
+	"time"
 
 	"github.com/optimizely/agent/plugins/userprofileservice"
+	"github.com/optimizely/agent/plugins/utils"
 	"github.com/optimizely/go-sdk/v2/pkg/decision"
 )
 
@@ -30,29 +33,46 @@ const experimentBucketMapKey = "experiment_bucket_map"
 // InMemoryUserProfileService represents the in-memory implementation of UserProfileService interface
 type InMemoryUserProfileService struct {
 	Capacity int `json:"capacity"`
-	// StorageStrategy defines the storage strategy. Supported values include fifo and lifo.
-	StorageStrategy     string `json:"storageStrategy"`
+	// StorageStrategy defines the storage strategy. Supported values include fifo, lifo and lru.
+	StorageStrategy string `json:"storageStrategy"`
+	// TTL expires the profiles that were not saved for the given duration, 0 means profiles never expire
+	TTL                 utils.Duration `json:"ttl"`
 	ProfilesMap         map[string]decision.UserProfile
 	fifoOrderedProfiles chan string
 	lifoOrderedProfiles []string
+	lruOrderedProfiles  *list.List
+	lruElements         map[string]*list.Element
+	savedAt             map[string]time.Time
+	lastSweep           time.Time
+	evictions           int64
 	lock                sync.RWMutex
 	isReady             bool
 }
 
 // Lookup is used to retrieve past bucketing decisions for users
 func (u *InMemoryUserProfileService) Lookup(userID string) (profile decision.UserProfile) {
-	u.lock.RLock()
-	defer u.lock.RUnlock()
+	// lru needs the write lock to update the recency of the profile, the other strategies only read
+	if u.StorageStrategy == "lru" {
+		u.lock.Lock()
+		defer u.lock.Unlock()
+	} else {
+		u.lock.RLock()
+		defer u.lock.RUnlock()
+	}
 
 	// Check if UPS is ready
 	if !u.isReady {
 		return profile
 	}
 
-	if userProfile, ok := u.ProfilesMap[userID]; ok {
-		profile = userProfile
+	userProfile, ok := u.ProfilesMap[userID]
+	if !ok || u.expired(userID, time.Now()) {
+		return profile
 	}
-	return profile
+	if element, ok := u.lruElements[userID]; ok {
+		u.lruOrderedProfiles.MoveToBack(element)
+	}
+	return userProfile
 }
 
 // Save is used to save bucketing decisions for users
@@ -70,6 +90,9 @@ func (u *InMemoryUserProfileService) Save(profile decision.UserProfile) {
 			switch u.StorageStrategy {
 			case "lifo":
 				u.lifoOrderedProfiles = []string{}
+			case "lru":
+				u.lruOrderedProfiles = list.New()
+				u.lruElements = make(map[string]*list.Element, u.Capacity)
 			default:
 				// fifo by default
 				u.fifoOrderedProfiles = make(chan string, u.Capacity)
@@ -78,9 +101,15 @@ func (u *InMemoryUserProfileService) Save(profile decision.UserProfile) {
 		} else {
 			u.ProfilesMap = map[string]decision.UserProfile{}
 		}
+		if u.TTL.Duration > 0 {
+			u.savedAt = map[string]time.Time{}
+		}
 		u.isReady = true
 	}
 
+	now := time.Now()
+	u.removeExpired(now)
+
 	// check if profile does not exist already
 	if _, ok := u.ProfilesMap[profile.ID]; !ok {
 		if u.Capacity > 0 {
@@ -94,26 +123,140 @@ func (u *InMemoryUserProfileService) Save(profile decision.UserProfile) {
 					oldProfile = u.lifoOrderedProfiles[n]
 					u.lifoOrderedProfiles[n] = "" // Erase element (write zero value)
 					u.lifoOrderedProfiles = u.lifoOrderedProfiles[:n]
+				case "lru":
+					// the front of the list is the least recently used profile
+					oldProfile = u.lruOrderedProfiles.Remove(u.lruOrderedProfiles.Front()).(string)
+					delete(u.lruElements, oldProfile)
 				default:
 					// fifo by default
 					oldProfile = <-u.fifoOrderedProfiles
 				}
 				// remove entry from map
 				delete(u.ProfilesMap, oldProfile)
+				delete(u.savedAt, oldProfile)
+				u.evictions++
 			}
 
 			// Push new entry to ordered list
 			switch u.StorageStrategy {
 			case "lifo":
 				u.lifoOrderedProfiles = append(u.lifoOrderedProfiles, profile.ID)
+			case "lru":
+				u.lruElements[profile.ID] = u.lruOrderedProfiles.PushBack(profile.ID)
 			default:
 				// fifo by default
 				u.fifoOrderedProfiles <- profile.ID
 			}
 		}
+	} else if element, ok := u.lruElements[profile.ID]; ok {
+		u.lruOrderedProfiles.MoveToBack(element)
 	}
 	// Save new profile to map
 	u.ProfilesMap[profile.ID] = profile
+	if u.savedAt != nil {
+		u.savedAt[profile.ID] = now
+	}
+}
+
+// Evictions returns the number of profiles removed because the capacity was reached or their TTL expired
+func (u *InMemoryUserProfileService) Evictions() int64 {
+	u.lock.RLock()
+	defer u.lock.RUnlock()
+	return u.evictions
+}
+
+func (u *InMemoryUserProfileService) expired(userID string, now time.Time) bool {
+	if u.TTL.Duration <= 0 {
+		return false
+	}
+	savedAt, ok := u.savedAt[userID]
+	return !ok || now.Sub(savedAt) >= u.TTL.Duration
+}
+
+// removeExpired removes the expired profiles from the map and the ordered lists. Profiles are swept at most
+// once per TTL, expired profiles that were not swept yet are ignored by Lookup
+func (u *InMemoryUserProfileService) removeExpired(now time.Time) {
+	if u.TTL.Duration <= 0 || now.Sub(u.lastSweep) < u.TTL.Duration {
+		return
+	}
+	u.lastSweep = now
+
+	var removed int64
+	for userID := range u.ProfilesMap {
+		if u.expired(userID, now) {
+			u.remove(userID)
+			removed++
+		}
+	}
+	if removed == 0 {
+		return
+	}
+	u.evictions += removed
+	u.compactOrderedProfiles()
+}
+
+// Delete removes the profile of the user
+func (u *InMemoryUserProfileService) Delete(userID string) error {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	if _, ok := u.ProfilesMap[userID]; ok {
+		u.remove(userID)
+		u.compactOrderedProfiles()
+	}
+	return nil
+}
+
+// Range calls fn with every profile that has not expired, fn is called without holding the lock
+func (u *InMemoryUserProfileService) Range(fn func(profile decision.UserProfile) error) error {
+	u.lock.RLock()
+	now := time.Now()
+	profiles := make([]decision.UserProfile, 0, len(u.ProfilesMap))
+	for userID, profile := range u.ProfilesMap {
+		if !u.expired(userID, now) {
+			profiles = append(profiles, profile)
+		}
+	}
+	u.lock.RUnlock()
+
+	for _, profile := range profiles {
+		if err := fn(profile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove removes the profile from the map, the fifo and lifo ordered lists are compacted separately
+func (u *InMemoryUserProfileService) remove(userID string) {
+	delete(u.ProfilesMap, userID)
+	delete(u.savedAt, userID)
+	if element, ok := u.lruElements[userID]; ok {
+		u.lruOrderedProfiles.Remove(element)
+		delete(u.lruElements, userID)
+	}
+}
+
+// compactOrderedProfiles drops the removed profiles from the fifo and lifo ordered lists
+func (u *InMemoryUserProfileService) compactOrderedProfiles() {
+	if u.lifoOrderedProfiles != nil {
+		remaining := u.lifoOrderedProfiles[:0]
+		for _, userID := range u.lifoOrderedProfiles {
+			if _, ok := u.ProfilesMap[userID]; ok {
+				remaining = append(remaining, userID)
+			}
+		}
+		u.lifoOrderedProfiles = remaining
+	}
+	if u.fifoOrderedProfiles != nil {
+		// cycle through the queue once, keeping the order of the remaining profiles
+		for i := len(u.fifoOrderedProfiles); i > 0; i-- {
+			userID := <-u.fifoOrderedProfiles
+			if _, ok := u.ProfilesMap[userID]; ok {
+				u.fifoOrderedProfiles <- userID
+			}
+		}
+	}
 }
 
 func init() {
@@ -18,10 +18,13 @@
 package services
 
 import (
+	"errors"
 	"strconv"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/optimizely/agent/plugins/utils"
 	"github.com/optimizely/go-sdk/v2/pkg/decision"
 	"github.com/stretchr/testify/suite"
 )
@@ -432,6 +435,159 @@ func (im *InMemoryUPSTestSuite) TestZeroCapacityLifo() {
 	im.Nil(im.ups.fifoOrderedProfiles)
 }
 
+func (im *InMemoryUPSTestSuite) TestCapacityLru() {
+	im.ups = InMemoryUserProfileService{
+		StorageStrategy: "lru",
+		Capacity:        3,
+	}
+	for _, userID := range []string{"1", "2", "3"} {
+		im.ups.Save(newTestProfile(userID))
+	}
+
+	// Looking up 1 and saving 2 again makes 3 the least recently used profile
+	im.Equal(newTestProfile("1"), im.ups.Lookup("1"))
+	im.ups.Save(newTestProfile("2"))
+	im.ups.Save(newTestProfile("4"))
+
+	im.Equal(decision.UserProfile{}, im.ups.Lookup("3"))
+	for _, userID := range []string{"1", "2", "4"} {
+		im.Equal(newTestProfile(userID), im.ups.Lookup(userID))
+	}
+
+	// 1 is now the least recently used profile
+	im.ups.Save(newTestProfile("5"))
+	im.Equal(decision.UserProfile{}, im.ups.Lookup("1"))
+	im.Equal(3, len(im.ups.ProfilesMap))
+	im.Equal(3, im.ups.lruOrderedProfiles.Len())
+	im.Equal(int64(2), im.ups.Evictions())
+}
+
+func (im *InMemoryUPSTestSuite) TestLookupSharesTheLock() {
+	for _, strategy := range []string{"fifo", "lifo"} {
+		im.ups = InMemoryUserProfileService{StorageStrategy: strategy, Capacity: 3}
+		im.ups.Save(newTestProfile("1"))
+
+		// Lookups of the strategies that do not track recency do not wait for the other readers
+		im.ups.lock.RLock()
+		done := make(chan decision.UserProfile, 1)
+		go func() { done <- im.ups.Lookup("1") }()
+		select {
+		case profile := <-done:
+			im.Equal(newTestProfile("1"), profile, strategy)
+		case <-time.After(time.Second):
+			im.Fail("lookup waited for the read lock", strategy)
+		}
+		im.ups.lock.RUnlock()
+	}
+}
+
+func (im *InMemoryUPSTestSuite) TestCapacityEvictions() {
+	for i := 1; i <= 13; i++ {
+		im.ups.Save(newTestProfile(strconv.Itoa(i)))
+	}
+	im.Equal(int64(3), im.ups.Evictions())
+}
+
+func (im *InMemoryUPSTestSuite) TestTTL() {
+	im.ups.TTL = utils.Duration{Duration: time.Hour}
+	im.ups.Save(newTestProfile("1"))
+	im.Equal(newTestProfile("1"), im.ups.Lookup("1"))
+
+	im.ups.TTL = utils.Duration{Duration: 10 * time.Millisecond}
+	time.Sleep(20 * time.Millisecond)
+	im.Equal(decision.UserProfile{}, im.ups.Lookup("1"))
+
+	// Saving the profile again renews it
+	im.ups.TTL = utils.Duration{Duration: time.Hour}
+	im.ups.Save(newTestProfile("1"))
+	im.Equal(newTestProfile("1"), im.ups.Lookup("1"))
+}
+
+func (im *InMemoryUPSTestSuite) TestTTLRemovesExpiredProfiles() {
+	for _, strategy := range []string{"fifo", "lifo", "lru"} {
+		ups := &InMemoryUserProfileService{
+			StorageStrategy: strategy,
+			Capacity:        3,
+			TTL:             utils.Duration{Duration: 10 * time.Millisecond},
+		}
+		ups.Save(newTestProfile("1"))
+		ups.Save(newTestProfile("2"))
+		time.Sleep(20 * time.Millisecond)
+
+		ups.Save(newTestProfile("3"))
+		im.Equal(1, len(ups.ProfilesMap), strategy)
+		im.Equal(int64(2), ups.Evictions(), strategy)
+
+		// The capacity freed by the expired profiles is available again
+		ups.TTL = utils.Duration{Duration: time.Hour}
+		ups.Save(newTestProfile("4"))
+		ups.Save(newTestProfile("5"))
+		im.Equal(3, len(ups.ProfilesMap), strategy)
+		im.Equal(int64(2), ups.Evictions(), strategy)
+		for _, userID := range []string{"3", "4", "5"} {
+			im.Equal(newTestProfile(userID), ups.Lookup(userID), strategy)
+		}
+	}
+}
+
+func (im *InMemoryUPSTestSuite) TestDeleteAndRange() {
+	for _, strategy := range []string{"fifo", "lifo", "lru"} {
+		ups := &InMemoryUserProfileService{StorageStrategy: strategy, Capacity: 3}
+		for _, userID := range []string{"1", "2", "3"} {
+			ups.Save(newTestProfile(userID))
+		}
+
+		im.NoError(ups.Delete("2"))
+		im.NoError(ups.Delete("4"))
+		im.Equal(decision.UserProfile{}, ups.Lookup("2"))
+
+		profiles := map[string]decision.UserProfile{}
+		im.NoError(ups.Range(func(profile decision.UserProfile) error {
+			profiles[profile.ID] = profile
+			return nil
+		}))
+		im.Equal(map[string]decision.UserProfile{"1": newTestProfile("1"), "3": newTestProfile("3")}, profiles, strategy)
+
+		// The deleted profile no longer counts towards the capacity
+		ups.Save(newTestProfile("4"))
+		im.Equal(3, len(ups.ProfilesMap), strategy)
+		im.Equal(int64(0), ups.Evictions(), strategy)
+	}
+}
+
+func (im *InMemoryUPSTestSuite) TestRangeSkipsExpiredProfiles() {
+	im.ups.TTL = utils.Duration{Duration: 10 * time.Millisecond}
+	im.ups.Save(newTestProfile("1"))
+	time.Sleep(20 * time.Millisecond)
+
+	im.NoError(im.ups.Range(func(profile decision.UserProfile) error {
+		im.Fail("expired profile", profile.ID)
+		return nil
+	}))
+
+	// Range stops at the first error of fn
+	im.ups.TTL = utils.Duration{Duration: time.Hour}
+	im.ups.Save(newTestProfile("2"))
+	im.ups.Save(newTestProfile("3"))
+	calls := 0
+	im.Equal(errStop, im.ups.Range(func(decision.UserProfile) error {
+		calls++
+		return errStop
+	}))
+	im.Equal(1, calls)
+}
+
+func newTestProfile(userID string) decision.UserProfile {
+	return decision.UserProfile{
+		ID: userID,
+		ExperimentBucketMap: map[decision.UserDecisionKey]string{
+			decision.NewUserDecisionKey(userID): userID,
+		},
+	}
+}
+
+var errStop = errors.New("stop")
+
 func TestInMemoryUPSTestSuite(t *testing.T) {
 	suite.Run(t, new(InMemoryUPSTestSuite))
 }
@@ -20,24 +20,41 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/optimizely/agent/pkg/utils/redisauth"
+	"github.com/optimizely/agent/pkg/utils/redisclient"
 	"github.com/optimizely/agent/plugins/userprofileservice"
+	"github.com/optimizely/agent/plugins/utils"
 	"github.com/optimizely/go-sdk/v2/pkg/decision"
 	"github.com/rs/zerolog/log"
 )
 
 var ctx = context.Background()
 
+// redisScanCount is the number of keys requested from redis by every SCAN call of Range
+const redisScanCount = 100
+
+// redisGlobEscaper escapes the special characters of the SCAN MATCH patterns
+var redisGlobEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
 // RedisUserProfileService represents the redis implementation of UserProfileService interface
 type RedisUserProfileService struct {
-	Client     *redis.Client
+	Client     redis.UniversalClient
 	Expiration time.Duration
-	Address    string `json:"host"`
-	Password   string `json:"password"`
-	Database   int    `json:"database"`
+	// TTL expires the profiles that were not saved for the given duration, it takes precedence over Expiration
+	TTL utils.Duration `json:"ttl"`
+	// Prefix is prepended to the user IDs to name the keys of the profiles, only the keys with the prefix are
+	// exported. It is empty by default, so that the profiles saved under the bare user IDs are still looked up.
+	Prefix   string `json:"prefix"`
+	Address  string `json:"host"`
+	Password string `json:"password"`
+	Database int    `json:"database"`
+	// Config holds the mode, TLS, pool and timeout settings of the connection
+	redisclient.Config
 }
 
 // UnmarshalJSON implements custom JSON unmarshaling with flexible password field names
@@ -59,7 +76,14 @@ func (u *RedisUserProfileService) UnmarshalJSON(data []byte) error {
 }
 
 // Lookup is used to retrieve past bucketing decisions for users
-func (u *RedisUserProfileService) Lookup(userID string) (profile decision.UserProfile) {
+func (u *RedisUserProfileService) Lookup(userID string) decision.UserProfile {
+	profile, _ := u.LookupWithError(userID)
+	return profile
+}
+
+// LookupWithError returns the profile of the user like Lookup, and an error when redis could not be reached or
+// the profile could not be read
+func (u *RedisUserProfileService) LookupWithError(userID string) (profile decision.UserProfile, err error) {
 	profile = decision.UserProfile{
 		ID:                  "",
 		ExperimentBucketMap: make(map[decision.UserDecisionKey]string),
@@ -71,26 +95,28 @@ func (u *RedisUserProfileService) Lookup(userID string) (profile decision.UserPr
 	}
 
 	if userID == "" {
-		return profile
+		return profile, nil
 	}
 
 	// Check if profile exists
-	result, getError := u.Client.Get(ctx, userID).Result()
+	result, getError := u.Client.Get(ctx, u.Prefix+userID).Result()
 	if getError != nil {
 		log.Error().Msg(getError.Error())
-		return profile
+		if errors.Is(getError, redis.Nil) {
+			return profile, nil
+		}
+		return profile, getError
 	}
 
 	// Check if result was unmarshalled successfully
 	experimentBucketMap := map[string]interface{}{}
-	err := json.Unmarshal([]byte(result), &experimentBucketMap)
-	if err != nil {
+	if err = json.Unmarshal([]byte(result), &experimentBucketMap); err != nil {
 		log.Error().Msg(err.Error())
-		return profile
+		return profile, err
 	}
 
 	// Converting result to profile
-	return convertToUserProfile(map[string]interface{}{userIDKey: userID, experimentBucketMapKey: experimentBucketMap}, userIDKey)
+	return convertToUserProfile(map[string]interface{}{userIDKey: userID, experimentBucketMapKey: experimentBucketMap}, userIDKey), nil
 }
 
 // Save is used to save bucketing decisions for users
@@ -112,20 +138,69 @@ func (u *RedisUserProfileService) Save(profile decision.UserProfile) {
 
 	if finalProfile, err := json.Marshal(experimentBucketMap); err == nil {
 		// Log error message if something went wrong
-		if setError := u.Client.Set(ctx, profile.ID, finalProfile, u.Expiration).Err(); setError != nil {
+		if setError := u.Client.Set(ctx, u.Prefix+profile.ID, finalProfile, u.expiration()).Err(); setError != nil {
 			log.Error().Msg(setError.Error())
 		}
 	}
 }
 
-func (u *RedisUserProfileService) initClient() {
-	u.Client = redis.NewClient(&redis.Options{
-		Addr:     u.Address,
-		Password: u.Password,
-		DB:       u.Database,
+// Delete removes the profile of the user
+func (u *RedisUserProfileService) Delete(userID string) error {
+	if u.Client == nil {
+		u.initClient()
+	}
+	return u.Client.Del(ctx, u.Prefix+userID).Err()
+}
+
+// Range calls fn with every profile of the database. The keys starting with the prefix are scanned and the keys
+// that do not hold a profile are skipped
+func (u *RedisUserProfileService) Range(fn func(profile decision.UserProfile) error) error {
+	if u.Client == nil {
+		u.initClient()
+	}
+
+	// the keys of a cluster are scanned on every master
+	return redisclient.ForEachShard(ctx, u.Client, func(ctx context.Context, client redis.UniversalClient) error {
+		iter := client.Scan(ctx, 0, redisGlobEscaper.Replace(u.Prefix)+"*", redisScanCount).Iterator()
+		for iter.Next(ctx) {
+			key := iter.Val()
+			userID := strings.TrimPrefix(key, u.Prefix)
+			result, err := client.Get(ctx, key).Result()
+			if err != nil {
+				// the key expired since it was scanned or does not hold a string
+				continue
+			}
+
+			experimentBucketMap := map[string]map[string]string{}
+			if err := json.Unmarshal([]byte(result), &experimentBucketMap); err != nil {
+				continue
+			}
+			profile := decision.UserProfile{ID: userID, ExperimentBucketMap: map[decision.UserDecisionKey]string{}}
+			for experimentID, bucketMap := range experimentBucketMap {
+				decisionKey := decision.NewUserDecisionKey(experimentID)
+				if variationID, ok := bucketMap[decisionKey.Field]; ok {
+					profile.ExperimentBucketMap[decisionKey] = variationID
+				}
+			}
+			if err := fn(profile); err != nil {
+				return err
+			}
+		}
+		return iter.Err()
 	})
 }
 
+func (u *RedisUserProfileService) expiration() time.Duration {
+	if u.TTL.Duration > 0 {
+		return u.TTL.Duration
+	}
+	return u.Expiration
+}
+
+func (u *RedisUserProfileService) initClient() {
+	u.Client = redisclient.NewClient(u.Address, u.Password, u.Database, u.Config)
+}
+
 func init() {
 	redisUPSCreator := func() decision.UserProfileService {
 		return &RedisUserProfileService{
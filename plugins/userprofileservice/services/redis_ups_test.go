@@ -18,8 +18,12 @@
 package services
 
 import (
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/go-redis/redismock/v8"
+	"github.com/optimizely/agent/plugins/utils"
 	"github.com/optimizely/go-sdk/v2/pkg/decision"
 	"github.com/stretchr/testify/suite"
 )
@@ -34,6 +38,7 @@ func (r *RedisUPSTestSuite) SetupTest() {
 		Address:  "100",
 		Password: "10",
 		Database: 1,
+		Prefix:   "ups-",
 	}
 }
 
@@ -72,6 +77,106 @@ func (r *RedisUPSTestSuite) TestLookupNotSavedProfileID() {
 	r.Equal(expectedProfile, r.ups.Lookup("123"))
 }
 
+func (r *RedisUPSTestSuite) TestSaveWithTTL() {
+	client, mock := redismock.NewClientMock()
+	r.ups.Client = client
+	r.ups.Expiration = time.Minute
+	r.ups.TTL = utils.Duration{Duration: time.Hour}
+
+	profile := decision.UserProfile{
+		ID: "1",
+		ExperimentBucketMap: map[decision.UserDecisionKey]string{
+			decision.NewUserDecisionKey("1"): "1",
+		},
+	}
+	mock.ExpectSet("ups-1", []byte(`{"1":{"variation_id":"1"}}`), time.Hour).SetVal("OK")
+	r.ups.Save(profile)
+	r.NoError(mock.ExpectationsWereMet())
+
+	// Expiration is used when no TTL is set
+	r.ups.TTL = utils.Duration{}
+	mock.ExpectSet("ups-1", []byte(`{"1":{"variation_id":"1"}}`), time.Minute).SetVal("OK")
+	r.ups.Save(profile)
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func (r *RedisUPSTestSuite) TestDelete() {
+	client, mock := redismock.NewClientMock()
+	r.ups.Client = client
+
+	mock.ExpectDel("ups-1").SetVal(1)
+	r.NoError(r.ups.Delete("1"))
+
+	mock.ExpectDel("ups-2").SetErr(errStop)
+	r.Equal(errStop, r.ups.Delete("2"))
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func (r *RedisUPSTestSuite) TestRange() {
+	client, mock := redismock.NewClientMock()
+	r.ups.Client = client
+
+	mock.ExpectScan(0, "ups-*", redisScanCount).SetVal([]string{"ups-1", "ups-datafile", "ups-list", "ups-2"}, 0)
+	mock.ExpectGet("ups-1").SetVal(`{"exp1":{"variation_id":"var1"}}`)
+	mock.ExpectGet("ups-datafile").SetVal(`{"version":"4","revision":"1"}`)
+	mock.ExpectGet("ups-list").SetErr(errors.New("WRONGTYPE"))
+	mock.ExpectGet("ups-2").SetVal(`{"exp2":{"variation_id":"var2"}}`)
+
+	var profiles []decision.UserProfile
+	r.NoError(r.ups.Range(func(profile decision.UserProfile) error {
+		profiles = append(profiles, profile)
+		return nil
+	}))
+	r.Equal([]decision.UserProfile{
+		{ID: "1", ExperimentBucketMap: map[decision.UserDecisionKey]string{decision.NewUserDecisionKey("exp1"): "var1"}},
+		{ID: "2", ExperimentBucketMap: map[decision.UserDecisionKey]string{decision.NewUserDecisionKey("exp2"): "var2"}},
+	}, profiles)
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func (r *RedisUPSTestSuite) TestLookupWithPrefix() {
+	client, mock := redismock.NewClientMock()
+	r.ups.Client = client
+
+	mock.ExpectGet("ups-1").SetVal(`{"exp1":{"variation_id":"var1"}}`)
+	r.Equal(decision.UserProfile{
+		ID:                  "1",
+		ExperimentBucketMap: map[decision.UserDecisionKey]string{decision.NewUserDecisionKey("exp1"): "var1"},
+	}, r.ups.Lookup("1"))
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func (r *RedisUPSTestSuite) TestLookupWithError() {
+	client, mock := redismock.NewClientMock()
+	r.ups.Client = client
+
+	mock.ExpectGet("ups-1").RedisNil()
+	profile, err := r.ups.LookupWithError("1")
+	r.NoError(err)
+	r.Empty(profile.ID)
+
+	mock.ExpectGet("ups-1").SetErr(errStop)
+	_, err = r.ups.LookupWithError("1")
+	r.Equal(errStop, err)
+
+	mock.ExpectGet("ups-1").SetVal("invalid")
+	_, err = r.ups.LookupWithError("1")
+	r.Error(err)
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func (r *RedisUPSTestSuite) TestRangeEscapesPrefix() {
+	client, mock := redismock.NewClientMock()
+	r.ups.Client = client
+	r.ups.Prefix = "ups[*?]-"
+
+	mock.ExpectScan(0, `ups\[\*\?\]-*`, redisScanCount).SetVal([]string{}, 0)
+	r.NoError(r.ups.Range(func(profile decision.UserProfile) error {
+		return nil
+	}))
+	r.NoError(mock.ExpectationsWereMet())
+}
+
 func TestRedisUPSTestSuite(t *testing.T) {
 	suite.Run(t, new(RedisUPSTestSuite))
 }
@@ -0,0 +1,301 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSignatureHeader = "X-Signature"
+	defaultTimestampHeader = "X-Timestamp"
+	// tokens are refreshed this long before they expire, or halfway through their lifetime when it is shorter
+	tokenExpiryMargin = 30 * time.Second
+)
+
+// RestAuth configures the authentication of the requests of RestUserProfileService, the methods can be combined
+type RestAuth struct {
+	// OAuth2 sends a bearer token obtained with the client credentials grant
+	OAuth2 *RestOAuth2 `json:"oauth2"`
+	// TLS presents a client certificate to the host
+	TLS *RestTLS `json:"tls"`
+	// Signing signs the timestamp and body of the requests with HMAC-SHA256
+	Signing *RestSigning `json:"signing"`
+}
+
+// RestOAuth2 configures the OAuth2 client credentials grant, the client credentials are sent with basic auth
+type RestOAuth2 struct {
+	TokenURL string `json:"tokenURL"`
+	ClientID string `json:"clientID"`
+	// ClientSecret falls back to the REST_UPS_CLIENT_SECRET environment variable
+	ClientSecret string   `json:"clientSecret"`
+	Scopes       []string `json:"scopes"`
+}
+
+// RestTLS configures the client certificate presented to the host
+type RestTLS struct {
+	CertFile string `json:"certFile"`
+	KeyFile  string `json:"keyFile"`
+	// CAFile verifies the certificate of the host instead of the system roots
+	CAFile string `json:"caFile"`
+}
+
+// RestSigning configures the signature of the requests, which is the hex encoded HMAC-SHA256 of the timestamp and the
+// body joined by a dot, prefixed with "sha256="
+type RestSigning struct {
+	// Secret falls back to the REST_UPS_SIGNING_SECRET environment variable
+	Secret string `json:"secret"`
+	// Header holds the signature, X-Signature by default
+	Header string `json:"header"`
+	// TimestampHeader holds the Unix time of the request in seconds, X-Timestamp by default
+	TimestampHeader string `json:"timestampHeader"`
+}
+
+// transport returns the round tripper authenticating the requests, it is nil when no authentication is configured
+func (a RestAuth) transport() (http.RoundTripper, error) {
+	if a.OAuth2 == nil && a.TLS == nil && a.Signing == nil {
+		return nil, nil
+	}
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	if a.TLS != nil {
+		tlsConfig, err := a.TLS.config()
+		if err != nil {
+			return nil, err
+		}
+		base.TLSClientConfig = tlsConfig
+	}
+
+	var rt http.RoundTripper = base
+	if a.Signing != nil {
+		secret := a.Signing.Secret
+		if secret == "" {
+			secret = os.Getenv("REST_UPS_SIGNING_SECRET")
+		}
+		if secret == "" {
+			return nil, errors.New("signing requires a secret")
+		}
+		rt = &signingTransport{
+			next:            rt,
+			secret:          []byte(secret),
+			header:          valueOrDefault(a.Signing.Header, defaultSignatureHeader),
+			timestampHeader: valueOrDefault(a.Signing.TimestampHeader, defaultTimestampHeader),
+			now:             time.Now,
+		}
+	}
+	if a.OAuth2 != nil {
+		conf := *a.OAuth2
+		if conf.ClientSecret == "" {
+			conf.ClientSecret = os.Getenv("REST_UPS_CLIENT_SECRET")
+		}
+		if u, err := url.Parse(conf.TokenURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid oauth2 token url %q", conf.TokenURL)
+		}
+		rt = &oauth2Transport{
+			next:   rt,
+			conf:   conf,
+			client: &http.Client{Transport: base, Timeout: defaultRestTimeout},
+			now:    time.Now,
+		}
+	}
+	return rt, nil
+}
+
+func (t *RestTLS) config() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", t.CAFile)
+		}
+	}
+	return tlsConfig, nil
+}
+
+// signingTransport sets the timestamp and signature headers of the requests
+type signingTransport struct {
+	next            http.RoundTripper
+	secret          []byte
+	header          string
+	timestampHeader string
+	now             func() time.Time
+}
+
+func (t *signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+	}
+
+	timestamp := strconv.FormatInt(t.now().Unix(), 10)
+	signed := req.Clone(req.Context())
+	signed.Body = io.NopCloser(bytes.NewReader(body))
+	signed.Header.Set(t.timestampHeader, timestamp)
+	signed.Header.Set(t.header, sign(t.secret, timestamp, body))
+	return t.next.RoundTrip(signed)
+}
+
+func sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// oauth2Transport sets the bearer token of the requests, the token is cached until it is about to expire or the host
+// responds with 401, in which case the request is sent once more with a new token
+type oauth2Transport struct {
+	next   http.RoundTripper
+	conf   RestOAuth2
+	client *http.Client
+	now    func() time.Time
+
+	lock        sync.Mutex
+	accessToken string
+	expiry      time.Time
+}
+
+func (t *oauth2Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(withBearer(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || (req.Body != nil && req.GetBody == nil) {
+		return resp, err
+	}
+
+	t.invalidate(token)
+	if token, err = t.token(req.Context()); err != nil {
+		return resp, nil
+	}
+	retry := withBearer(req, token)
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	return t.next.RoundTrip(retry)
+}
+
+func withBearer(req *http.Request, token string) *http.Request {
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", "Bearer "+token)
+	return authorized
+}
+
+func (t *oauth2Transport) invalidate(token string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.accessToken == token {
+		t.accessToken = ""
+	}
+}
+
+// token returns the cached token, or fetches a new one while holding the lock so that it is fetched only once
+func (t *oauth2Transport) token(ctx context.Context) (string, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.accessToken != "" && (t.expiry.IsZero() || t.now().Before(t.expiry)) {
+		return t.accessToken, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(t.conf.Scopes) > 0 {
+		form.Set("scope", strings.Join(t.conf.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.conf.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(t.conf.ClientID), url.QueryEscape(t.conf.ClientSecret))
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("oauth2 token request failed with status %d", resp.StatusCode)
+	}
+
+	var tokenResponse struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return "", err
+	}
+	if tokenResponse.AccessToken == "" {
+		return "", errors.New("oauth2 token response has no access_token")
+	}
+
+	t.accessToken, t.expiry = tokenResponse.AccessToken, time.Time{}
+	if tokenResponse.ExpiresIn > 0 {
+		lifetime := time.Duration(tokenResponse.ExpiresIn) * time.Second
+		margin := tokenExpiryMargin
+		if margin > lifetime/2 {
+			margin = lifetime / 2
+		}
+		t.expiry = t.now().Add(lifetime - margin)
+	}
+	return t.accessToken, nil
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
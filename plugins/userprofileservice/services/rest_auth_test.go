@@ -0,0 +1,252 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+	"github.com/optimizely/go-sdk/v2/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAuthTestUPS(host string, auth RestAuth) *RestUserProfileService {
+	return &RestUserProfileService{
+		Requester:  utils.NewHTTPRequester(logging.GetLogger("", "")),
+		Host:       host,
+		LookupPath: "/ups/lookup",
+		SavePath:   "/ups/save",
+		Auth:       auth,
+	}
+}
+
+func lookupHandler(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, convertUserProfileToMap(newTestProfile("1"), userIDKey))
+}
+
+func TestOAuth2(t *testing.T) {
+	var fetched atomic.Int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, _ := r.BasicAuth()
+		assert.Equal(t, "agent", clientID)
+		assert.Equal(t, "secret", clientSecret)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "profiles:read profiles:write", r.PostForm.Get("scope"))
+		token := strconv.Itoa(int(fetched.Add(1)))
+		render.JSON(w, r, map[string]interface{}{"access_token": token, "token_type": "Bearer", "expires_in": 3600})
+	}))
+	defer tokenServer.Close()
+
+	var rejected atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first token is revoked once rejected is set
+		authorization := r.Header.Get("Authorization")
+		if authorization == "" || authorization == "Bearer 1" && rejected.Load() {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		lookupHandler(w, r)
+	}))
+	defer server.Close()
+
+	ups := newAuthTestUPS(server.URL, RestAuth{OAuth2: &RestOAuth2{
+		TokenURL:     tokenServer.URL,
+		ClientID:     "agent",
+		ClientSecret: "secret",
+		Scopes:       []string{"profiles:read", "profiles:write"},
+	}})
+
+	assert.Equal(t, newTestProfile("1"), ups.Lookup("1"))
+	assert.Equal(t, newTestProfile("1"), ups.Lookup("1"))
+	assert.Equal(t, int32(1), fetched.Load())
+
+	// A 401 response refreshes the token and the request is sent again
+	rejected.Store(true)
+	assert.Equal(t, newTestProfile("1"), ups.Lookup("1"))
+	assert.Equal(t, int32(2), fetched.Load())
+}
+
+func TestOAuth2TokenExpiry(t *testing.T) {
+	var fetched atomic.Int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched.Add(1)
+		render.JSON(w, r, map[string]interface{}{"access_token": "token", "expires_in": 60})
+	}))
+	defer tokenServer.Close()
+
+	now := time.Now()
+	transport := &oauth2Transport{
+		conf:   RestOAuth2{TokenURL: tokenServer.URL},
+		client: tokenServer.Client(),
+		now:    func() time.Time { return now },
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, elapsed := range []time.Duration{0, 29 * time.Second, 31 * time.Second} {
+		now = now.Add(elapsed)
+		token, err := transport.token(req.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "token", token)
+	}
+	// The token lasts 60s and is refreshed 30s before it expires
+	assert.Equal(t, int32(2), fetched.Load())
+}
+
+func TestOAuth2TokenFailure(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer tokenServer.Close()
+
+	var called atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called.Store(true)
+	}))
+	defer server.Close()
+
+	ups := newAuthTestUPS(server.URL, RestAuth{OAuth2: &RestOAuth2{TokenURL: tokenServer.URL}})
+	assert.Equal(t, decision.UserProfile{}, ups.Lookup("1"))
+	assert.False(t, called.Load())
+	assert.Equal(t, int64(1), ups.CircuitStats().Failures)
+}
+
+func TestSigning(t *testing.T) {
+	var saved atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		timestamp := r.Header.Get("X-Request-Time")
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), time.Unix(unix, 0), time.Minute)
+		if r.Header.Get(defaultSignatureHeader) != sign([]byte("secret"), timestamp, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/ups/save" {
+			profile := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal(body, &profile))
+			saved.Store(convertToUserProfile(profile, userIDKey).ID == "1")
+			return
+		}
+		lookupHandler(w, r)
+	}))
+	defer server.Close()
+
+	ups := newAuthTestUPS(server.URL, RestAuth{Signing: &RestSigning{Secret: "secret", TimestampHeader: "X-Request-Time"}})
+	assert.Equal(t, newTestProfile("1"), ups.Lookup("1"))
+	ups.Save(newTestProfile("1"))
+	assert.True(t, saved.Load())
+
+	ups = newAuthTestUPS(server.URL, RestAuth{Signing: &RestSigning{Secret: "wrong", TimestampHeader: "X-Request-Time"}})
+	assert.Equal(t, decision.UserProfile{}, ups.Lookup("1"))
+}
+
+func TestSigningRequiresSecret(t *testing.T) {
+	t.Setenv("REST_UPS_SIGNING_SECRET", "")
+	_, err := RestAuth{Signing: &RestSigning{}}.transport()
+	assert.Error(t, err)
+
+	t.Setenv("REST_UPS_SIGNING_SECRET", "secret")
+	transport, err := RestAuth{Signing: &RestSigning{}}.transport()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), transport.(*signingTransport).secret)
+}
+
+// writeTestCertificate writes a self-signed client certificate and its key to dir
+func writeTestCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "agent"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile = filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certFile, keyFile
+}
+
+func TestClientCertificate(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "agent", r.TLS.PeerCertificates[0].Subject.CommonName)
+		lookupHandler(w, r)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+	certFile, keyFile := writeTestCertificate(t, dir)
+
+	ups := newAuthTestUPS(server.URL, RestAuth{TLS: &RestTLS{CertFile: certFile, KeyFile: keyFile, CAFile: caFile}})
+	assert.Equal(t, newTestProfile("1"), ups.Lookup("1"))
+
+	// Without a certificate the handshake fails
+	ups = newAuthTestUPS(server.URL, RestAuth{TLS: &RestTLS{CAFile: caFile}})
+	assert.Equal(t, decision.UserProfile{}, ups.Lookup("1"))
+}
+
+func TestInvalidAuth(t *testing.T) {
+	var called atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called.Store(true)
+	}))
+	defer server.Close()
+
+	for _, auth := range []RestAuth{
+		{TLS: &RestTLS{CertFile: "missing.crt", KeyFile: "missing.key"}},
+		{OAuth2: &RestOAuth2{TokenURL: "token"}},
+	} {
+		ups := newAuthTestUPS(server.URL, auth)
+		assert.Equal(t, decision.UserProfile{}, ups.Lookup("1"))
+		ups.Save(newTestProfile("1"))
+		assert.Error(t, ups.authErr)
+	}
+	assert.False(t, called.Load())
+}
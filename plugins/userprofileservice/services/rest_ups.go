@@ -20,18 +20,35 @@ package services
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"sync"
+	"time"
 
 	"github.com/optimizely/agent/plugins/userprofileservice"
+	pluginutils "github.com/optimizely/agent/plugins/utils"
 	"github.com/optimizely/go-sdk/v2/pkg/decision"
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
 	"github.com/optimizely/go-sdk/v2/pkg/utils"
 	"github.com/rs/zerolog/log"
 )
 
+// Defaults of the resilience settings of RestUserProfileService
+const (
+	defaultRestTimeout          = 5 * time.Second
+	defaultRestRetries          = 1
+	defaultRestRetryInterval    = 100 * time.Millisecond
+	defaultRestFailureThreshold = 5
+	defaultRestResetTimeout     = 30 * time.Second
+)
+
+// errRestLookupFailed is returned by LookupWithError when the profile could not be requested
+var errRestLookupFailed = errors.New("unable to lookup the user profile")
+
 // RestUserProfileService represents the rest API implementation of UserProfileService interface
 type RestUserProfileService struct {
 	Requester    *utils.HTTPRequester
@@ -41,12 +58,40 @@ type RestUserProfileService struct {
 	LookupMethod string            `json:"lookupMethod"`
 	SavePath     string            `json:"savePath"`
 	SaveMethod   string            `json:"saveMethod"`
+	DeletePath   string            `json:"deletePath"`
+	DeleteMethod string            `json:"deleteMethod"`
+	ListPath     string            `json:"listPath"`
+	ListMethod   string            `json:"listMethod"`
 	UserIDKey    string            `json:"userIDKey"`
 	Async        bool              `json:"async"`
+	// Timeout bounds each request, 5s by default
+	Timeout pluginutils.Duration `json:"timeout"`
+	// Retries is the number of times a failed lookup or list request is retried, after a jittered exponential
+	// backoff starting at RetryInterval. Saves and deletes are not retried.
+	Retries       int                  `json:"retries"`
+	RetryInterval pluginutils.Duration `json:"retryInterval"`
+	// FailureThreshold consecutive failed requests open the circuit, lookups then return an empty profile and saves
+	// are dropped until a request sent after ResetTimeout succeeds. A threshold of 0 disables the circuit breaker.
+	FailureThreshold int                  `json:"failureThreshold"`
+	ResetTimeout     pluginutils.Duration `json:"resetTimeout"`
+	// Auth authenticates the requests, in addition to the static Headers
+	Auth RestAuth `json:"auth"`
+
+	once    sync.Once
+	breaker *circuitBreaker
+	authErr error
 }
 
 // Lookup is used to retrieve past bucketing decisions for users
-func (r *RestUserProfileService) Lookup(userID string) (profile decision.UserProfile) {
+func (r *RestUserProfileService) Lookup(userID string) decision.UserProfile {
+	profile, _ := r.LookupWithError(userID)
+	return profile
+}
+
+// LookupWithError returns the profile of the user like Lookup, and an error when the service could not be reached,
+// responded with a server error or an invalid profile, or when its circuit is open. Client errors are users
+// without a profile.
+func (r *RestUserProfileService) LookupWithError(userID string) (profile decision.UserProfile, err error) {
 	if userID == "" {
 		return
 	}
@@ -59,7 +104,10 @@ func (r *RestUserProfileService) Lookup(userID string) (profile decision.UserPro
 	userIDKey := r.getUserIDKey()
 	// Check if profile exists
 	parameters := map[string]interface{}{userIDKey: userID}
-	success, response := r.performRequest(requestURL, r.LookupMethod, parameters)
+	success, response, failed := r.request(requestURL, r.LookupMethod, parameters, r.Retries)
+	if failed {
+		return profile, errRestLookupFailed
+	}
 	if !success {
 		return
 	}
@@ -70,7 +118,7 @@ func (r *RestUserProfileService) Lookup(userID string) (profile decision.UserPro
 		return
 	}
 
-	return convertToUserProfile(userProfileMap, userIDKey)
+	return convertToUserProfile(userProfileMap, userIDKey), nil
 }
 
 // Save is used to save bucketing decisions for users
@@ -85,10 +133,114 @@ func (r *RestUserProfileService) Save(profile decision.UserProfile) {
 	}
 	userProfileMap := convertUserProfileToMap(profile, r.getUserIDKey())
 	if r.Async {
-		go r.performRequest(requestURL, r.SaveMethod, userProfileMap)
+		go r.request(requestURL, r.SaveMethod, userProfileMap, 0)
 		return
 	}
-	r.performRequest(requestURL, r.SaveMethod, userProfileMap)
+	r.request(requestURL, r.SaveMethod, userProfileMap, 0)
+}
+
+// Delete removes the profile of the user through the deletePath endpoint
+func (r *RestUserProfileService) Delete(userID string) error {
+	if r.DeletePath == "" {
+		return userprofileservice.ErrNotSupported
+	}
+
+	requestURL, err := r.getURL(r.DeletePath)
+	if err != nil {
+		return err
+	}
+	parameters := map[string]interface{}{r.getUserIDKey(): userID}
+	if success, _, _ := r.request(requestURL, r.DeleteMethod, parameters, 0); !success {
+		return fmt.Errorf("unable to delete the profile of user %q", userID)
+	}
+	return nil
+}
+
+// Range calls fn with every profile returned by the listPath endpoint, which responds with a JSON array of profiles
+func (r *RestUserProfileService) Range(fn func(profile decision.UserProfile) error) error {
+	if r.ListPath == "" {
+		return userprofileservice.ErrNotSupported
+	}
+
+	requestURL, err := r.getURL(r.ListPath)
+	if err != nil {
+		return err
+	}
+	success, response, _ := r.request(requestURL, r.ListMethod, map[string]interface{}{}, r.Retries)
+	if !success {
+		return errors.New("unable to list the user profiles")
+	}
+
+	var profileMaps []map[string]interface{}
+	if err := json.Unmarshal(response, &profileMaps); err != nil {
+		return err
+	}
+	userIDKey := r.getUserIDKey()
+	for _, profileMap := range profileMaps {
+		if profile := convertToUserProfile(profileMap, userIDKey); profile.ID != "" {
+			if err := fn(profile); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CircuitStats returns the state of the circuit breaker and the number of retried, failed and rejected requests
+func (r *RestUserProfileService) CircuitStats() userprofileservice.CircuitStats {
+	r.setup()
+	return r.breaker.circuitStats()
+}
+
+func (r *RestUserProfileService) setup() {
+	r.once.Do(func() {
+		r.breaker = newCircuitBreaker(r.FailureThreshold, r.ResetTimeout.Duration)
+		transport, err := r.Auth.transport()
+		if err != nil {
+			log.Error().Err(err).Msg("Unable to configure the authentication of the rest user profile service")
+			r.authErr = err
+			return
+		}
+		if transport != nil || r.Timeout.Duration > 0 {
+			client := http.Client{Transport: transport, Timeout: defaultRestTimeout}
+			if r.Timeout.Duration > 0 {
+				client.Timeout = r.Timeout.Duration
+			}
+			r.Requester = utils.NewHTTPRequester(logging.GetLogger("", "RestUserProfileService"), utils.Client(client))
+		}
+	})
+}
+
+// request performs the request through the circuit breaker, retrying it up to retries times when it fails. failed
+// is set when the last attempt failed, was rejected by the open circuit or could not be authenticated.
+func (r *RestUserProfileService) request(requestURL, method string, parameters map[string]interface{}, retries int) (success bool, response []byte, failed bool) {
+	r.setup()
+	if r.authErr != nil {
+		// unauthenticated requests would be rejected by the host
+		return false, nil, true
+	}
+	for attempt := 0; ; attempt++ {
+		if !r.breaker.allow() {
+			log.Debug().Msg("circuit of the rest user profile service is open, skipping request")
+			return false, nil, true
+		}
+		success, response, failed = r.performRequest(requestURL, method, parameters)
+		r.breaker.record(!failed)
+		if !failed || attempt >= retries {
+			return success, response, failed
+		}
+		time.Sleep(backoff(r.RetryInterval.Duration, attempt))
+		r.breaker.retried()
+	}
+}
+
+// backoff returns the delay before a retry, it doubles with every attempt and is jittered down by up to a half
+func backoff(interval time.Duration, attempt int) time.Duration {
+	delay := interval << attempt
+	if half := int64(delay / 2); half > 0 {
+		delay = time.Duration(half + rand.Int63n(half))
+	}
+	return delay
 }
 
 func (r *RestUserProfileService) getURL(endpointPath string) (string, error) {
@@ -106,7 +258,9 @@ func (r *RestUserProfileService) getUserIDKey() string {
 	return r.UserIDKey
 }
 
-func (r *RestUserProfileService) performRequest(requestURL, method string, parameters map[string]interface{}) (success bool, response []byte) {
+// performRequest sends a single request, failed is set when the service could not be reached or responded with a
+// server error, which are retried and trip the circuit breaker, unlike client errors
+func (r *RestUserProfileService) performRequest(requestURL, method string, parameters map[string]interface{}) (success bool, response []byte, failed bool) {
 	fHeaders := []utils.Header{}
 	for n, v := range r.Headers {
 		fHeaders = append(fHeaders, utils.Header{Name: n, Value: v})
@@ -156,16 +310,20 @@ func (r *RestUserProfileService) performRequest(requestURL, method string, param
 
 	response, _, code, err := r.Requester.Do(fURL.String(), restAPIMethod, body, fHeaders)
 	if err != nil {
-		return
+		return false, nil, code == 0 || code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
 	}
-	return code == http.StatusOK, response
+	return code == http.StatusOK, response, false
 }
 
 func init() {
 	restUPSCreator := func() decision.UserProfileService {
 		return &RestUserProfileService{
-			Requester: utils.NewHTTPRequester(logging.GetLogger("", "RestUserProfileService")),
-			Headers:   map[string]string{},
+			Requester:        utils.NewHTTPRequester(logging.GetLogger("", "RestUserProfileService")),
+			Headers:          map[string]string{},
+			Retries:          defaultRestRetries,
+			RetryInterval:    pluginutils.Duration{Duration: defaultRestRetryInterval},
+			FailureThreshold: defaultRestFailureThreshold,
+			ResetTimeout:     pluginutils.Duration{Duration: defaultRestResetTimeout},
 		}
 	}
 	userprofileservice.Add("rest", restUPSCreator)
@@ -22,10 +22,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/go-chi/render"
 	"github.com/optimizely/agent/pkg/handlers"
+	"github.com/optimizely/agent/plugins/userprofileservice"
 	"github.com/optimizely/go-sdk/v2/pkg/decision"
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
 	"github.com/optimizely/go-sdk/v2/pkg/utils"
@@ -39,6 +42,8 @@ type RestUPSTestSuite struct {
 	userProfile      decision.UserProfile
 	MethodUsed       string
 	savedUserProfile decision.UserProfile
+	deletedUserID    string
+	failures         atomic.Int32
 	wg               *sync.WaitGroup
 }
 
@@ -51,6 +56,7 @@ func (rups *RestUPSTestSuite) SetupTest() {
 		},
 	}
 	rups.savedUserProfile = decision.UserProfile{}
+	rups.failures.Store(0)
 	rups.ups.UserIDKey = "custom_user_id"
 	rups.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		rups.MethodUsed = r.Method
@@ -74,6 +80,24 @@ func (rups *RestUPSTestSuite) SetupTest() {
 			if rups.wg != nil {
 				rups.wg.Done()
 			}
+		case "/ups/delete":
+			userProfile := map[string]interface{}{}
+			_ = handlers.ParseRequestBody(r, &userProfile)
+			rups.deletedUserID, _ = userProfile[rups.ups.getUserIDKey()].(string)
+			w.WriteHeader(http.StatusOK)
+		case "/ups/list":
+			w.Header().Set("Content-Type", "application/json")
+			render.JSON(w, r, []map[string]interface{}{
+				convertUserProfileToMap(rups.userProfile, rups.ups.getUserIDKey()),
+				{"invalid": "profile"},
+			})
+		case "/ups/flaky":
+			// fails until the remaining failures are used up, then responds like the lookup endpoint
+			if rups.failures.Add(-1) >= 0 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fallthrough
 		case "/ups/lookup":
 			userProfileMap := convertUserProfileToMap(rups.userProfile, rups.ups.getUserIDKey())
 			w.Header().Set("Content-Type", "application/json")
@@ -221,6 +245,133 @@ func (rups *RestUPSTestSuite) TestCustomUserID() {
 	rups.Equal(userIDKey, ups.getUserIDKey())
 }
 
+func (rups *RestUPSTestSuite) TestDelete() {
+	rups.ErrorIs(rups.ups.Delete("1"), userprofileservice.ErrNotSupported)
+
+	rups.ups.DeletePath = "/ups/delete"
+	rups.NoError(rups.ups.Delete("1"))
+	rups.Equal("1", rups.deletedUserID)
+	rups.Equal("POST", rups.MethodUsed)
+
+	rups.ups.DeleteMethod = "DELETE"
+	rups.NoError(rups.ups.Delete("2"))
+	rups.Equal("2", rups.deletedUserID)
+	rups.Equal("DELETE", rups.MethodUsed)
+
+	rups.ups.DeletePath = "/ups/invalid"
+	rups.Error(rups.ups.Delete("1"))
+}
+
+func (rups *RestUPSTestSuite) TestRange() {
+	fn := func(profile decision.UserProfile) error {
+		rups.Equal(rups.userProfile, profile)
+		return nil
+	}
+	rups.ErrorIs(rups.ups.Range(fn), userprofileservice.ErrNotSupported)
+
+	// Profiles without a user ID are skipped
+	rups.ups.ListPath = "/ups/list"
+	rups.ups.ListMethod = "GET"
+	calls := 0
+	rups.NoError(rups.ups.Range(func(profile decision.UserProfile) error {
+		calls++
+		return fn(profile)
+	}))
+	rups.Equal(1, calls)
+	rups.Equal("GET", rups.MethodUsed)
+
+	rups.ups.ListPath = "/ups/invalid"
+	rups.Error(rups.ups.Range(fn))
+}
+
+func (rups *RestUPSTestSuite) TestLookupRetries() {
+	rups.ups.LookupPath = "/ups/flaky"
+	rups.ups.Retries = 2
+	rups.ups.RetryInterval.Duration = time.Millisecond
+	rups.failures.Store(2)
+
+	rups.Equal(rups.userProfile, rups.ups.Lookup("1"))
+	rups.Equal(userprofileservice.CircuitStats{
+		State:    userprofileservice.CircuitClosed,
+		Retries:  2,
+		Failures: 2,
+	}, rups.ups.CircuitStats())
+}
+
+func (rups *RestUPSTestSuite) TestLookupClientErrorIsNotRetried() {
+	rups.ups.LookupPath = "/ups/invalid"
+	rups.ups.Retries = 2
+
+	rups.Empty(rups.ups.Lookup("1"))
+	rups.Equal(userprofileservice.CircuitStats{State: userprofileservice.CircuitClosed}, rups.ups.CircuitStats())
+}
+
+func (rups *RestUPSTestSuite) TestCircuitBreakerFailsOpen() {
+	rups.ups.LookupPath = "/ups/flaky"
+	rups.ups.FailureThreshold = 2
+	rups.ups.ResetTimeout.Duration = time.Hour
+	rups.failures.Store(2)
+
+	rups.Empty(rups.ups.Lookup("1"))
+	rups.Empty(rups.ups.Lookup("1"))
+	// The backend recovered but the circuit stays open until the reset timeout elapses
+	rups.Empty(rups.ups.Lookup("1"))
+	rups.ups.Save(rups.userProfile)
+	rups.Empty(rups.savedUserProfile)
+
+	rups.Equal(userprofileservice.CircuitStats{
+		State:    userprofileservice.CircuitOpen,
+		Failures: 2,
+		Rejected: 2,
+	}, rups.ups.CircuitStats())
+}
+
+func (rups *RestUPSTestSuite) TestLookupWithError() {
+	rups.ups.LookupPath = "/ups/flaky"
+	rups.ups.Retries = 0
+	rups.ups.FailureThreshold = 2
+	rups.ups.ResetTimeout.Duration = time.Hour
+	rups.failures.Store(1)
+
+	_, err := rups.ups.LookupWithError("1")
+	rups.Equal(errRestLookupFailed, err)
+	profile, err := rups.ups.LookupWithError("1")
+	rups.NoError(err)
+	rups.Equal(rups.userProfile, profile)
+
+	// Client errors are users without a profile
+	rups.ups.LookupPath = "/ups/invalid"
+	profile, err = rups.ups.LookupWithError("1")
+	rups.NoError(err)
+	rups.Empty(profile)
+
+	// Lookups rejected by the open circuit failed
+	rups.ups.LookupPath = "/ups/flaky"
+	rups.failures.Store(2)
+	_, err = rups.ups.LookupWithError("1")
+	rups.Error(err)
+	_, err = rups.ups.LookupWithError("1")
+	rups.Error(err)
+	_, err = rups.ups.LookupWithError("1")
+	rups.Equal(errRestLookupFailed, err)
+	rups.Equal(int64(1), rups.ups.CircuitStats().Rejected)
+}
+
+func (rups *RestUPSTestSuite) TestTimeout() {
+	blocked := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-blocked
+	}))
+	defer server.Close()
+	defer close(blocked)
+
+	rups.ups.Host = server.URL
+	rups.ups.Timeout.Duration = 10 * time.Millisecond
+
+	rups.Empty(rups.ups.Lookup("1"))
+	rups.Equal(int64(1), rups.ups.CircuitStats().Failures)
+}
+
 func TestRestUPSTestSuite(t *testing.T) {
 	suite.Run(t, new(RestUPSTestSuite))
 }
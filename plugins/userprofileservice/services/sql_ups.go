@@ -0,0 +1,313 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	// Register the database/sql drivers of the supported databases, they are pure Go so that they are
+	// available in the cgo-free release builds
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+	"github.com/rs/zerolog/log"
+	_ "modernc.org/sqlite"
+
+	"github.com/optimizely/agent/plugins/userprofileservice"
+	"github.com/optimizely/agent/plugins/utils"
+)
+
+const (
+	defaultSQLTable        = "optimizely_user_profiles"
+	defaultSQLTimeout      = 5 * time.Second
+	defaultSQLMaxOpenConns = 10
+	defaultSQLMaxIdleConns = 2
+)
+
+// tableNamePattern only accepts plain, optionally schema qualified, table names since they are part of the queries
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// sqlDialect holds the database/sql driver and the statements that differ between the supported databases
+type sqlDialect struct {
+	driver      string
+	placeholder func(n int) string
+	upsert      string
+}
+
+var sqlDialects = map[string]sqlDialect{
+	"postgres": {
+		driver:      "postgres",
+		placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+		upsert:      "ON CONFLICT (user_id) DO UPDATE SET experiment_bucket_map = excluded.experiment_bucket_map, updated_at = excluded.updated_at",
+	},
+	"mysql": {
+		driver:      "mysql",
+		placeholder: func(int) string { return "?" },
+		upsert:      "ON DUPLICATE KEY UPDATE experiment_bucket_map = VALUES(experiment_bucket_map), updated_at = VALUES(updated_at)",
+	},
+	"sqlite3": {
+		driver:      "sqlite",
+		placeholder: func(int) string { return "?" },
+		upsert:      "ON CONFLICT (user_id) DO UPDATE SET experiment_bucket_map = excluded.experiment_bucket_map, updated_at = excluded.updated_at",
+	},
+}
+
+// sqlPools shares the connection pool of a database between the user profile services of every SDK key
+var sqlPools = struct {
+	sync.Mutex
+	dbs map[string]*sql.DB
+}{dbs: map[string]*sql.DB{}}
+
+// SQLUserProfileService represents the database/sql implementation of UserProfileService interface,
+// profiles are stored in a table that is created when it does not exist
+type SQLUserProfileService struct {
+	DB *sql.DB
+	// Driver is one of "postgres", "mysql" or "sqlite3"
+	Driver string `json:"driver"`
+	// DSN is the data source name of the driver, it falls back to the SQL_UPS_DSN environment variable
+	DSN   string `json:"dsn"`
+	Table string `json:"table"`
+	// Timeout bounds every query
+	Timeout         utils.Duration `json:"timeout"`
+	MaxOpenConns    int            `json:"maxOpenConns"`
+	MaxIdleConns    int            `json:"maxIdleConns"`
+	ConnMaxLifetime utils.Duration `json:"connMaxLifetime"`
+
+	lock    sync.Mutex
+	ready   bool
+	dialect sqlDialect
+}
+
+// Lookup is used to retrieve past bucketing decisions for users
+func (s *SQLUserProfileService) Lookup(userID string) decision.UserProfile {
+	profile, _ := s.LookupWithError(userID)
+	return profile
+}
+
+// LookupWithError returns the profile of the user like Lookup, and an error when the database could not be queried
+// or the profile could not be read
+func (s *SQLUserProfileService) LookupWithError(userID string) (profile decision.UserProfile, err error) {
+	profile = decision.UserProfile{
+		ID:                  "",
+		ExperimentBucketMap: make(map[decision.UserDecisionKey]string),
+	}
+	if userID == "" {
+		return profile, nil
+	}
+	if err := s.init(); err != nil {
+		log.Error().Err(err).Msg("Unable to initialize the sql user profile service")
+		return profile, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout())
+	defer cancel()
+
+	var result string
+	query := fmt.Sprintf("SELECT experiment_bucket_map FROM %s WHERE user_id = %s", s.table(), s.dialect.placeholder(1))
+	if err := s.DB.QueryRowContext(ctx, query, userID).Scan(&result); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return profile, nil
+		}
+		log.Error().Err(err).Msg("Unable to lookup user profile")
+		return profile, err
+	}
+
+	experimentBucketMap := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(result), &experimentBucketMap); err != nil {
+		log.Error().Msg(err.Error())
+		return profile, err
+	}
+
+	return convertToUserProfile(map[string]interface{}{userIDKey: userID, experimentBucketMapKey: experimentBucketMap}, userIDKey), nil
+}
+
+// Save is used to save bucketing decisions for users
+func (s *SQLUserProfileService) Save(profile decision.UserProfile) {
+	if profile.ID == "" {
+		return
+	}
+	if err := s.init(); err != nil {
+		log.Error().Err(err).Msg("Unable to initialize the sql user profile service")
+		return
+	}
+
+	experimentBucketMap := map[string]interface{}{}
+	for k, v := range profile.ExperimentBucketMap {
+		experimentBucketMap[k.ExperimentID] = map[string]string{k.Field: v}
+	}
+	finalProfile, err := json.Marshal(experimentBucketMap)
+	if err != nil {
+		log.Error().Msg(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout())
+	defer cancel()
+
+	p := s.dialect.placeholder
+	statement := fmt.Sprintf("INSERT INTO %s (user_id, experiment_bucket_map, updated_at) VALUES (%s, %s, %s) %s",
+		s.table(), p(1), p(2), p(3), s.dialect.upsert)
+	if _, err := s.DB.ExecContext(ctx, statement, profile.ID, string(finalProfile), time.Now().UnixMilli()); err != nil {
+		log.Error().Err(err).Msg("Unable to save user profile")
+	}
+}
+
+// Delete removes the profile of the user
+func (s *SQLUserProfileService) Delete(userID string) error {
+	if err := s.init(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout())
+	defer cancel()
+
+	statement := fmt.Sprintf("DELETE FROM %s WHERE user_id = %s", s.table(), s.dialect.placeholder(1))
+	_, err := s.DB.ExecContext(ctx, statement, userID)
+	return err
+}
+
+// Range calls fn with every profile of the table ordered by user ID. The query is not bound by the timeout
+// since fn is called while the rows are read
+func (s *SQLUserProfileService) Range(fn func(profile decision.UserProfile) error) error {
+	if err := s.init(); err != nil {
+		return err
+	}
+
+	rows, err := s.DB.Query(fmt.Sprintf("SELECT user_id, experiment_bucket_map FROM %s ORDER BY user_id", s.table()))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID, result string
+		if err := rows.Scan(&userID, &result); err != nil {
+			return err
+		}
+		experimentBucketMap := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(result), &experimentBucketMap); err != nil {
+			log.Error().Err(err).Str("userId", userID).Msg("Skipping invalid user profile")
+			continue
+		}
+		profile := convertToUserProfile(map[string]interface{}{userIDKey: userID, experimentBucketMapKey: experimentBucketMap}, userIDKey)
+		if err := fn(profile); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// init opens the connection pool and creates the table, it is retried on the next call when it fails
+func (s *SQLUserProfileService) init() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.ready {
+		return nil
+	}
+
+	dialect, ok := sqlDialects[s.Driver]
+	if !ok {
+		return fmt.Errorf("unsupported sql driver %q", s.Driver)
+	}
+	if !tableNamePattern.MatchString(s.table()) {
+		return fmt.Errorf("invalid sql table name %q", s.table())
+	}
+
+	if s.DB == nil {
+		db, err := s.open(dialect.driver)
+		if err != nil {
+			return err
+		}
+		s.DB = db
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout())
+	defer cancel()
+
+	schema := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (user_id VARCHAR(255) NOT NULL PRIMARY KEY, experiment_bucket_map TEXT NOT NULL, updated_at BIGINT NOT NULL)", s.table())
+	if _, err := s.DB.ExecContext(ctx, schema); err != nil {
+		return err
+	}
+
+	s.dialect = dialect
+	s.ready = true
+	return nil
+}
+
+func (s *SQLUserProfileService) open(driver string) (*sql.DB, error) {
+	dsn := s.DSN
+	if dsn == "" {
+		dsn = os.Getenv("SQL_UPS_DSN")
+	}
+
+	sqlPools.Lock()
+	defer sqlPools.Unlock()
+
+	key := driver + " " + dsn
+	if db, ok := sqlPools.dbs[key]; ok {
+		return db, nil
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	maxOpenConns := s.MaxOpenConns
+	if maxOpenConns <= 0 {
+		maxOpenConns = defaultSQLMaxOpenConns
+	}
+	maxIdleConns := s.MaxIdleConns
+	if maxIdleConns <= 0 {
+		maxIdleConns = defaultSQLMaxIdleConns
+	}
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxIdleConns)
+	db.SetConnMaxLifetime(s.ConnMaxLifetime.Duration)
+
+	sqlPools.dbs[key] = db
+	return db, nil
+}
+
+func (s *SQLUserProfileService) table() string {
+	if s.Table == "" {
+		return defaultSQLTable
+	}
+	return s.Table
+}
+
+func (s *SQLUserProfileService) timeout() time.Duration {
+	if s.Timeout.Duration <= 0 {
+		return defaultSQLTimeout
+	}
+	return s.Timeout.Duration
+}
+
+func init() {
+	sqlUPSCreator := func() decision.UserProfileService {
+		return &SQLUserProfileService{}
+	}
+	userprofileservice.Add("sql", sqlUPSCreator)
+}
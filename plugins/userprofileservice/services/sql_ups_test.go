@@ -0,0 +1,174 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/agent/plugins/utils"
+)
+
+type SQLUPSTestSuite struct {
+	suite.Suite
+	dsn string
+	ups *SQLUserProfileService
+}
+
+func (s *SQLUPSTestSuite) SetupTest() {
+	s.dsn = filepath.Join(s.T().TempDir(), "profiles.db")
+	s.ups = &SQLUserProfileService{Driver: "sqlite3", DSN: s.dsn}
+}
+
+func (s *SQLUPSTestSuite) profile(userID string, variations map[string]string) decision.UserProfile {
+	profile := decision.UserProfile{ID: userID, ExperimentBucketMap: map[decision.UserDecisionKey]string{}}
+	for experimentID, variationID := range variations {
+		profile.ExperimentBucketMap[decision.NewUserDecisionKey(experimentID)] = variationID
+	}
+	return profile
+}
+
+func (s *SQLUPSTestSuite) TestSaveAndLookup() {
+	profile := s.profile("1", map[string]string{"exp1": "var1", "exp2": "var2"})
+	s.ups.Save(profile)
+	s.Equal(profile, s.ups.Lookup("1"))
+
+	// Saving again replaces the profile
+	profile = s.profile("1", map[string]string{"exp1": "var3"})
+	s.ups.Save(profile)
+	s.Equal(profile, s.ups.Lookup("1"))
+}
+
+func (s *SQLUPSTestSuite) TestDeleteAndRange() {
+	s.ups.Save(s.profile("2", map[string]string{"exp1": "var2"}))
+	s.ups.Save(s.profile("1", map[string]string{"exp1": "var1"}))
+	s.ups.Save(s.profile("3", map[string]string{"exp1": "var3"}))
+
+	s.NoError(s.ups.Delete("3"))
+	s.NoError(s.ups.Delete("4"))
+
+	var profiles []decision.UserProfile
+	s.NoError(s.ups.Range(func(profile decision.UserProfile) error {
+		profiles = append(profiles, profile)
+		return nil
+	}))
+	s.Equal([]decision.UserProfile{
+		s.profile("1", map[string]string{"exp1": "var1"}),
+		s.profile("2", map[string]string{"exp1": "var2"}),
+	}, profiles)
+
+	// Range stops at the first error of fn
+	calls := 0
+	s.Equal(errStop, s.ups.Range(func(profile decision.UserProfile) error {
+		calls++
+		return errStop
+	}))
+	s.Equal(1, calls)
+}
+
+func (s *SQLUPSTestSuite) TestCreatesTable() {
+	s.ups.Table = "sticky_bucketing"
+	s.ups.Save(s.profile("1", map[string]string{"exp1": "var1"}))
+
+	var count int
+	s.Require().NoError(s.ups.DB.QueryRow("SELECT COUNT(*) FROM sticky_bucketing WHERE updated_at > 0").Scan(&count))
+	s.Equal(1, count)
+}
+
+func (s *SQLUPSTestSuite) TestSharesConnectionPool() {
+	s.ups.Lookup("1")
+	other := &SQLUserProfileService{Driver: "sqlite3", DSN: s.dsn}
+	other.Lookup("1")
+	s.NotNil(s.ups.DB)
+	s.Equal(s.ups.DB, other.DB)
+	s.Equal(defaultSQLMaxOpenConns, s.ups.DB.Stats().MaxOpenConnections)
+}
+
+func (s *SQLUPSTestSuite) TestLookupNotSavedProfileID() {
+	expectedProfile := decision.UserProfile{
+		ID:                  "",
+		ExperimentBucketMap: map[decision.UserDecisionKey]string{},
+	}
+	s.Equal(expectedProfile, s.ups.Lookup("123"))
+	s.Equal(expectedProfile, s.ups.Lookup(""))
+}
+
+func (s *SQLUPSTestSuite) TestSaveEmptyProfileID() {
+	s.ups.Save(s.profile("", map[string]string{"exp1": "var1"}))
+	s.Nil(s.ups.DB)
+}
+
+func (s *SQLUPSTestSuite) TestInvalidConfiguration() {
+	s.ups.Driver = "oracle"
+	s.EqualError(s.ups.init(), `unsupported sql driver "oracle"`)
+
+	s.ups.Driver = "sqlite3"
+	s.ups.Table = "profiles; DROP TABLE users"
+	s.EqualError(s.ups.init(), `invalid sql table name "profiles; DROP TABLE users"`)
+	s.Nil(s.ups.DB)
+
+	// Initialization is retried once the configuration is valid
+	s.ups.Table = "profiles"
+	s.NoError(s.ups.init())
+	s.True(s.ups.ready)
+}
+
+func (s *SQLUPSTestSuite) TestLookupWithError() {
+	profile, err := s.ups.LookupWithError("1")
+	s.NoError(err)
+	s.Empty(profile.ID)
+
+	s.ups.Table = "profiles; DROP TABLE users"
+	_, err = s.ups.LookupWithError("1")
+	s.Error(err)
+}
+
+func (s *SQLUPSTestSuite) TestDSNFromEnvironment() {
+	s.T().Setenv("SQL_UPS_DSN", s.dsn)
+	ups := &SQLUserProfileService{Driver: "sqlite3"}
+	profile := s.profile("1", map[string]string{"exp1": "var1"})
+	ups.Save(profile)
+	s.Equal(profile, s.ups.Lookup("1"))
+}
+
+func (s *SQLUPSTestSuite) TestUnmarshalJSON() {
+	ups := &SQLUserProfileService{}
+	s.NoError(json.Unmarshal([]byte(`{"driver":"postgres","dsn":"postgres://localhost/agent","table":"public.profiles","timeout":"2s","maxOpenConns":20,"maxIdleConns":5,"connMaxLifetime":"5m"}`), ups))
+	s.Equal("postgres", ups.Driver)
+	s.Equal("postgres://localhost/agent", ups.DSN)
+	s.Equal("public.profiles", ups.Table)
+	s.Equal(2*time.Second, ups.timeout())
+	s.Equal(20, ups.MaxOpenConns)
+	s.Equal(5, ups.MaxIdleConns)
+	s.Equal(utils.Duration{Duration: 5 * time.Minute}, ups.ConnMaxLifetime)
+}
+
+func TestSQLUPSTestSuite(t *testing.T) {
+	suite.Run(t, new(SQLUPSTestSuite))
+}
+
+func TestSQLDialects(t *testing.T) {
+	if sqlDialects["postgres"].placeholder(2) != "$2" || sqlDialects["mysql"].placeholder(2) != "?" || sqlDialects["sqlite3"].placeholder(2) != "?" {
+		t.Error("unexpected placeholders")
+	}
+}
@@ -0,0 +1,250 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+	"github.com/rs/zerolog/log"
+
+	"github.com/optimizely/agent/plugins/userprofileservice"
+)
+
+const defaultWriteBehindQueueSize = 1000
+
+// errStoreCircuitOpen is the error of the store lookups made while the circuit of the store is open
+var errStoreCircuitOpen = errors.New("circuit of the user profile store is open")
+
+// TieredUserProfileService layers a cache user profile service, the in-memory one by default, in front of a store
+// user profile service such as redis or rest. Lookups are read through the cache and saves are written through to
+// both services, so that the store is only called for the users missing from the cache.
+type TieredUserProfileService struct {
+	// Cache is the name of the user profile service used as the cache, its capacity and ttl bound the cached profiles
+	Cache string `json:"cache"`
+	// Store is the name of the user profile service holding the profiles
+	Store string `json:"store"`
+	// NegativeCaching caches the users that have no profile in the store as empty profiles. Lookups that failed
+	// are not cached, see storeFailed.
+	NegativeCaching bool `json:"negativeCaching"`
+	// WriteBehind saves profiles to the store asynchronously, saves wait when WriteBehindQueueSize saves are pending
+	WriteBehind          bool `json:"writeBehind"`
+	WriteBehindQueueSize int  `json:"writeBehindQueueSize"`
+
+	cache decision.UserProfileService
+	store decision.UserProfileService
+
+	lock   sync.RWMutex
+	once   sync.Once
+	closed bool
+	queue  chan writeBehind
+	done   chan struct{}
+}
+
+// writeBehind is a profile waiting to be saved to the store, or a flush that is closed once the saves queued
+// before it were written
+type writeBehind struct {
+	profile decision.UserProfile
+	flushed chan struct{}
+}
+
+// ServiceNames returns the names of the cache and store user profile services
+func (t *TieredUserProfileService) ServiceNames() []string {
+	cache := t.Cache
+	if cache == "" {
+		cache = "in-memory"
+	}
+	return []string{cache, t.Store}
+}
+
+// SetServices sets the cache and store user profile services
+func (t *TieredUserProfileService) SetServices(services []decision.UserProfileService) error {
+	if len(services) != 2 {
+		return errors.New("tiered user profile service requires a cache and a store")
+	}
+	t.cache, t.store = services[0], services[1]
+	return nil
+}
+
+// Lookup returns the cached profile of the user, or the profile of the store which is then cached. Nothing is
+// cached when the store failed, since the user may have a profile that the store could not return.
+func (t *TieredUserProfileService) Lookup(userID string) decision.UserProfile {
+	if t.cache == nil || t.store == nil {
+		return decision.UserProfile{}
+	}
+
+	if profile := t.cache.Lookup(userID); profile.ID != "" {
+		return profile
+	}
+
+	profile, err := t.lookupStore(userID)
+	if err != nil {
+		log.Warn().Err(err).Msg("Unable to lookup user profile in the store, it is not cached")
+		return profile
+	}
+	if profile.ID != "" {
+		t.cache.Save(profile)
+	} else if t.NegativeCaching && userID != "" {
+		t.cache.Save(decision.UserProfile{ID: userID, ExperimentBucketMap: map[decision.UserDecisionKey]string{}})
+	}
+	return profile
+}
+
+// lookupStore returns the profile of the store and an error when the lookup failed, which is reported by stores
+// implementing userprofileservice.LookupWithError, or assumed while the circuit of the store is open
+func (t *TieredUserProfileService) lookupStore(userID string) (decision.UserProfile, error) {
+	if store, ok := t.store.(userprofileservice.LookupWithError); ok {
+		return store.LookupWithError(userID)
+	}
+	profile := t.store.Lookup(userID)
+	if t.storeFailed() {
+		return profile, errStoreCircuitOpen
+	}
+	return profile, nil
+}
+
+// storeFailed reports whether the circuit of the store is open
+func (t *TieredUserProfileService) storeFailed() bool {
+	return t.CircuitStats().State == userprofileservice.CircuitOpen
+}
+
+// Save saves the profile to the cache and to the store, asynchronously with WriteBehind. Profiles are neither
+// cached nor written through while the circuit of the store is open, so that the cache does not hold profiles
+// that the store rejected.
+func (t *TieredUserProfileService) Save(profile decision.UserProfile) {
+	if t.cache == nil || t.store == nil || profile.ID == "" {
+		return
+	}
+	if t.storeFailed() {
+		log.Warn().Msg("Circuit of the user profile store is open, the profile is not saved")
+		return
+	}
+	t.cache.Save(profile)
+
+	if t.WriteBehind {
+		t.once.Do(t.startWriteBehind)
+		t.lock.RLock()
+		defer t.lock.RUnlock()
+		if !t.closed {
+			t.queue <- writeBehind{profile: profile}
+			return
+		}
+	}
+	t.store.Save(profile)
+}
+
+// flush waits until the profiles pending write behind are saved to the store
+func (t *TieredUserProfileService) flush() {
+	if !t.WriteBehind {
+		return
+	}
+	t.once.Do(t.startWriteBehind)
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	if t.closed {
+		return
+	}
+	flushed := make(chan struct{})
+	t.queue <- writeBehind{flushed: flushed}
+	<-flushed
+}
+
+// Close saves the profiles pending write behind to the store, later saves are written through
+func (t *TieredUserProfileService) Close() {
+	t.once.Do(func() {})
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.closed {
+		return
+	}
+	t.closed = true
+	if t.queue != nil {
+		close(t.queue)
+		<-t.done
+	}
+}
+
+// Delete removes the profile of the user from the store and from the cache. The saves pending write behind are
+// written first, so that they do not restore the profile once it is deleted.
+func (t *TieredUserProfileService) Delete(userID string) error {
+	store, ok := t.store.(userprofileservice.Manager)
+	if !ok {
+		return userprofileservice.ErrNotSupported
+	}
+	t.flush()
+	if err := store.Delete(userID); err != nil {
+		return err
+	}
+	if cache, ok := t.cache.(userprofileservice.Manager); ok {
+		return cache.Delete(userID)
+	}
+	return nil
+}
+
+// Range calls fn with every profile of the store
+func (t *TieredUserProfileService) Range(fn func(profile decision.UserProfile) error) error {
+	store, ok := t.store.(userprofileservice.Manager)
+	if !ok {
+		return userprofileservice.ErrNotSupported
+	}
+	return store.Range(fn)
+}
+
+// Evictions returns the number of profiles evicted from the cache
+func (t *TieredUserProfileService) Evictions() int64 {
+	if cache, ok := t.cache.(userprofileservice.Evictions); ok {
+		return cache.Evictions()
+	}
+	return 0
+}
+
+// CircuitStats returns the circuit breaker stats of the store, their state is empty when the store has no breaker
+func (t *TieredUserProfileService) CircuitStats() userprofileservice.CircuitStats {
+	if store, ok := t.store.(userprofileservice.CircuitBreaker); ok {
+		return store.CircuitStats()
+	}
+	return userprofileservice.CircuitStats{}
+}
+
+func (t *TieredUserProfileService) startWriteBehind() {
+	size := t.WriteBehindQueueSize
+	if size <= 0 {
+		size = defaultWriteBehindQueueSize
+	}
+	t.queue = make(chan writeBehind, size)
+	t.done = make(chan struct{})
+
+	go func() {
+		defer close(t.done)
+		for item := range t.queue {
+			if item.flushed != nil {
+				close(item.flushed)
+				continue
+			}
+			t.store.Save(item.profile)
+		}
+	}()
+}
+
+func init() {
+	tieredUPSCreator := func() decision.UserProfileService {
+		return &TieredUserProfileService{}
+	}
+	userprofileservice.Add("tiered", tieredUPSCreator)
+}
@@ -0,0 +1,279 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package services //
+package services
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/agent/plugins/userprofileservice"
+)
+
+// countingUPS counts the lookups and saves of an in-memory user profile service
+type countingUPS struct {
+	InMemoryUserProfileService
+	lock    sync.Mutex
+	lookups int
+	saves   int
+	saved   chan string
+}
+
+func (c *countingUPS) Lookup(userID string) decision.UserProfile {
+	c.lock.Lock()
+	c.lookups++
+	c.lock.Unlock()
+	return c.InMemoryUserProfileService.Lookup(userID)
+}
+
+func (c *countingUPS) Save(profile decision.UserProfile) {
+	c.lock.Lock()
+	c.saves++
+	c.lock.Unlock()
+	c.InMemoryUserProfileService.Save(profile)
+	if c.saved != nil {
+		c.saved <- profile.ID
+	}
+}
+
+func (c *countingUPS) counts() (lookups, saves int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lookups, c.saves
+}
+
+// failingUPS reports err from its lookups, and its circuit as open when open is set
+type failingUPS struct {
+	countingUPS
+	err  error
+	open bool
+}
+
+func (f *failingUPS) LookupWithError(userID string) (decision.UserProfile, error) {
+	if f.err != nil {
+		return decision.UserProfile{}, f.err
+	}
+	return f.Lookup(userID), nil
+}
+
+func (f *failingUPS) CircuitStats() userprofileservice.CircuitStats {
+	if f.open {
+		return userprofileservice.CircuitStats{State: userprofileservice.CircuitOpen}
+	}
+	return userprofileservice.CircuitStats{State: userprofileservice.CircuitClosed}
+}
+
+// circuitUPS reports the circuit state of store without implementing userprofileservice.LookupWithError
+type circuitUPS struct {
+	store *failingUPS
+}
+
+func (c circuitUPS) Lookup(userID string) decision.UserProfile {
+	return c.store.Lookup(userID)
+}
+
+func (c circuitUPS) Save(profile decision.UserProfile) {
+	c.store.Save(profile)
+}
+
+func (c circuitUPS) CircuitStats() userprofileservice.CircuitStats {
+	return c.store.CircuitStats()
+}
+
+// lookupOnlyUPS does not implement the userprofileservice.Manager interface
+type lookupOnlyUPS struct {
+	decision.UserProfileService
+}
+
+type TieredUPSTestSuite struct {
+	suite.Suite
+	cache *InMemoryUserProfileService
+	store *countingUPS
+	ups   *TieredUserProfileService
+}
+
+func (t *TieredUPSTestSuite) SetupTest() {
+	t.cache = &InMemoryUserProfileService{Capacity: 1, StorageStrategy: "lru"}
+	t.store = &countingUPS{}
+	t.ups = &TieredUserProfileService{Store: "redis"}
+	t.NoError(t.ups.SetServices([]decision.UserProfileService{t.cache, t.store}))
+}
+
+func (t *TieredUPSTestSuite) TestServiceNames() {
+	t.Equal([]string{"in-memory", "redis"}, t.ups.ServiceNames())
+
+	t.ups.Cache = "lru-cache"
+	t.Equal([]string{"lru-cache", "redis"}, t.ups.ServiceNames())
+
+	t.Error(t.ups.SetServices([]decision.UserProfileService{t.cache}))
+}
+
+func (t *TieredUPSTestSuite) TestReadThrough() {
+	t.store.InMemoryUserProfileService.Save(newTestProfile("1"))
+
+	t.Equal(newTestProfile("1"), t.ups.Lookup("1"))
+	t.Equal(newTestProfile("1"), t.ups.Lookup("1"))
+	lookups, _ := t.store.counts()
+	t.Equal(1, lookups)
+	t.Equal(newTestProfile("1"), t.cache.Lookup("1"))
+}
+
+func (t *TieredUPSTestSuite) TestNegativeCaching() {
+	t.Equal(decision.UserProfile{}, t.ups.Lookup("1"))
+	t.Equal(decision.UserProfile{}, t.ups.Lookup("1"))
+	lookups, _ := t.store.counts()
+	t.Equal(2, lookups)
+
+	t.ups.NegativeCaching = true
+	t.Equal(decision.UserProfile{}, t.ups.Lookup("1"))
+	empty := decision.UserProfile{ID: "1", ExperimentBucketMap: map[decision.UserDecisionKey]string{}}
+	t.Equal(empty, t.ups.Lookup("1"))
+	lookups, _ = t.store.counts()
+	t.Equal(3, lookups)
+
+	// Saving a profile replaces the cached miss
+	t.ups.Save(newTestProfile("1"))
+	t.Equal(newTestProfile("1"), t.ups.Lookup("1"))
+}
+
+func (t *TieredUPSTestSuite) TestFailedLookupIsNotCached() {
+	store := &failingUPS{err: errStop}
+	t.NoError(t.ups.SetServices([]decision.UserProfileService{t.cache, store}))
+	t.ups.NegativeCaching = true
+
+	t.Equal(decision.UserProfile{}, t.ups.Lookup("1"))
+	t.Equal(decision.UserProfile{}, t.cache.Lookup("1"))
+
+	// The profile of the store is returned once it recovers
+	store.err = nil
+	store.InMemoryUserProfileService.Save(newTestProfile("1"))
+	t.Equal(newTestProfile("1"), t.ups.Lookup("1"))
+}
+
+func (t *TieredUPSTestSuite) TestOpenCircuitSkipsCachingAndWriteThrough() {
+	store := &failingUPS{open: true}
+	t.NoError(t.ups.SetServices([]decision.UserProfileService{t.cache, circuitUPS{store}}))
+	t.ups.NegativeCaching = true
+
+	// Lookups of stores that do not report errors fail while their circuit is open
+	t.Equal(decision.UserProfile{}, t.ups.Lookup("1"))
+	t.Equal(decision.UserProfile{}, t.cache.Lookup("1"))
+
+	t.ups.Save(newTestProfile("1"))
+	t.Equal(decision.UserProfile{}, t.cache.Lookup("1"))
+	_, saves := store.counts()
+	t.Equal(0, saves)
+
+	store.open = false
+	t.ups.Save(newTestProfile("1"))
+	t.Equal(newTestProfile("1"), t.cache.Lookup("1"))
+	_, saves = store.counts()
+	t.Equal(1, saves)
+}
+
+func (t *TieredUPSTestSuite) TestWriteThrough() {
+	t.ups.Save(newTestProfile("1"))
+	t.ups.Save(newTestProfile("2"))
+
+	_, saves := t.store.counts()
+	t.Equal(2, saves)
+	t.Equal(newTestProfile("2"), t.cache.Lookup("2"))
+
+	// 1 was evicted from the cache and is read from the store
+	t.Equal(newTestProfile("1"), t.ups.Lookup("1"))
+	t.Equal(int64(2), t.ups.Evictions())
+}
+
+func (t *TieredUPSTestSuite) TestWriteBehind() {
+	t.store.saved = make(chan string, 10)
+	t.ups.WriteBehind = true
+	t.ups.WriteBehindQueueSize = 1
+
+	t.ups.Save(newTestProfile("1"))
+	t.Equal(newTestProfile("1"), t.cache.Lookup("1"))
+	t.Equal("1", <-t.store.saved)
+
+	t.ups.Save(newTestProfile("2"))
+	t.ups.Save(newTestProfile("3"))
+	t.ups.Close()
+	_, saves := t.store.counts()
+	t.Equal(3, saves)
+
+	// Profiles are written through once closed
+	t.ups.Save(newTestProfile("4"))
+	_, saves = t.store.counts()
+	t.Equal(4, saves)
+	t.ups.Close()
+}
+
+func (t *TieredUPSTestSuite) TestDeleteAndRange() {
+	t.ups.Save(newTestProfile("1"))
+	t.NoError(t.ups.Delete("1"))
+	t.Equal(decision.UserProfile{}, t.cache.Lookup("1"))
+	t.Equal(decision.UserProfile{}, t.store.InMemoryUserProfileService.Lookup("1"))
+
+	t.ups.Save(newTestProfile("2"))
+	var profiles []decision.UserProfile
+	t.NoError(t.ups.Range(func(profile decision.UserProfile) error {
+		profiles = append(profiles, profile)
+		return nil
+	}))
+	t.Equal([]decision.UserProfile{newTestProfile("2")}, profiles)
+
+	t.NoError(t.ups.SetServices([]decision.UserProfileService{t.cache, lookupOnlyUPS{t.store}}))
+	t.ErrorIs(t.ups.Delete("2"), userprofileservice.ErrNotSupported)
+	t.ErrorIs(t.ups.Range(func(decision.UserProfile) error { return nil }), userprofileservice.ErrNotSupported)
+}
+
+func (t *TieredUPSTestSuite) TestDeleteWaitsForWriteBehind() {
+	t.store.saved = make(chan string)
+	t.ups.WriteBehind = true
+
+	t.ups.Save(newTestProfile("2"))
+	t.ups.Save(newTestProfile("1"))
+	deleted := make(chan error)
+	go func() { deleted <- t.ups.Delete("1") }()
+
+	// The queued save of 1 is written before it is deleted
+	t.Equal("2", <-t.store.saved)
+	t.Equal("1", <-t.store.saved)
+	t.NoError(<-deleted)
+	t.ups.Close()
+	t.Equal(decision.UserProfile{}, t.store.InMemoryUserProfileService.Lookup("1"))
+	t.Equal(decision.UserProfile{}, t.ups.Lookup("1"))
+}
+
+func (t *TieredUPSTestSuite) TestCircuitStats() {
+	t.Equal(userprofileservice.CircuitStats{}, t.ups.CircuitStats())
+
+	store := &RestUserProfileService{}
+	t.NoError(t.ups.SetServices([]decision.UserProfileService{t.cache, store}))
+	t.Equal(userprofileservice.CircuitStats{State: userprofileservice.CircuitClosed}, t.ups.CircuitStats())
+}
+
+func (t *TieredUPSTestSuite) TestWithoutServices() {
+	ups := &TieredUserProfileService{}
+	ups.Save(newTestProfile("1"))
+	t.Equal(decision.UserProfile{}, ups.Lookup("1"))
+}
+
+func TestTieredUPSTestSuite(t *testing.T) {
+	suite.Run(t, new(TieredUPSTestSuite))
+}